@@ -13,6 +13,7 @@ const Debate = lazy(() => import('./pages/Debate'));
 const Equity = lazy(() => import('./pages/Equity'));
 const History = lazy(() => import('./pages/History'));
 const Ranking = lazy(() => import('./pages/Ranking'));
+const Usage = lazy(() => import('./pages/Usage'));
 
 const PageLoader = () => (
   <div className="flex items-center justify-center h-screen">
@@ -56,6 +57,11 @@ function App() {
                 <Ranking />
               </Suspense>
             } />
+            <Route path="usage" element={
+              <Suspense fallback={<PageLoader />}>
+                <Usage />
+              </Suspense>
+            } />
             <Route path="strategies" element={
               <Suspense fallback={<PageLoader />}>
                 <Strategies />
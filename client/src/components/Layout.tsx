@@ -10,6 +10,7 @@ import {
   TrendingUp,
   History,
   Trophy,
+  Coins,
   Zap,
   MoreHorizontal,
   X,
@@ -30,6 +31,7 @@ const navItems = [
   { to: '/equity', icon: TrendingUp, label: 'Equity', description: 'Performance' },
   { to: '/history', icon: History, label: 'History', description: 'Trade log' },
   { to: '/ranking', icon: Trophy, label: 'Ranking', description: 'Symbol profits' },
+  { to: '/usage', icon: Coins, label: 'AI Usage', description: 'LLM spend' },
   { to: '/strategies', icon: Sparkles, label: 'Strategies', description: 'Define rules' },
   { to: '/config', icon: Settings, label: 'Config', description: 'API keys' },
   { to: '/logs', icon: Activity, label: 'Logs', description: 'AI decisions' },
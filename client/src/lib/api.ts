@@ -51,6 +51,22 @@ export const createStrategy = (data: any) => api.post('/strategies', data);
 export const updateStrategy = (id: string, data: any) => api.put(`/strategies/${id}`, data);
 export const deleteStrategy = (id: string) => api.delete(`/strategies/${id}`);
 export const activateStrategy = (id: string) => api.post(`/strategies/${id}/activate`);
+export const getStrategyVersions = (id: string) => api.get(`/strategies/${id}/versions`);
+export const diffStrategyVersions = (id: string, from: number, to?: number) =>
+  api.get(`/strategies/${id}/versions/diff`, { params: { from, to } });
+export const rollbackStrategy = (id: string, version: number) =>
+  api.post(`/strategies/${id}/versions/${version}/rollback`);
+export const exportStrategy = (id: string, format: 'json' | 'yaml') =>
+  api.get(`/strategies/${id}/export`, { params: { format }, responseType: 'text' });
+export const importStrategy = (content: string, format: 'json' | 'yaml') =>
+  api.post('/strategies/import', content, {
+    params: { format },
+    headers: { 'Content-Type': format === 'yaml' ? 'application/yaml' : 'application/json' },
+    transformRequest: [(data) => data],
+  });
+export const getStrategyTemplates = () => api.get('/strategy-templates');
+export const createStrategyFromTemplate = (id: string, name?: string) =>
+  api.post(`/strategy-templates/${id}`, { name });
 export const getDefaultConfig = () => api.get('/strategies/default-config');
 export const recommendPairs = (data?: { count: number; turbo?: boolean }) => api.post('/strategies/recommend-pairs', data); // New function
 
@@ -70,6 +86,16 @@ export const getPositions = (traderId: string) => api.get(`/positions?trader_id=
 export const getDecisions = (traderId: string) => api.get(`/decisions?trader_id=${traderId}`);
 export const getTrades = (traderId: string) => api.get(`/trades?trader_id=${traderId}`);
 export const getEquityHistory = (traderId: string) => api.get(`/equity-history?trader_id=${traderId}`);
+export const getLessons = (traderId: string) => api.get(`/lessons?trader_id=${traderId}`);
+export const getCalibration = (traderId: string, targetHitRate?: number) =>
+  api.get(`/calibration?trader_id=${traderId}${targetHitRate ? `&target_hit_rate=${targetHitRate}` : ''}`);
+
+// Decision audits. Filters: symbol, block_reason ('none' for unblocked), executed, from, to, limit, full
+export const listDecisionAudits = (traderId: string, filters: Record<string, string | number | boolean> = {}) =>
+  api.get('/decisions/audit', { params: { trader_id: traderId, ...filters } });
+export const getDecisionAudit = (id: number) => api.get(`/decisions/audit/${id}`);
+export const getAuditBlockReasons = (traderId: string, from?: string, to?: string) =>
+  api.get('/decisions/audit/block-reasons', { params: { trader_id: traderId, from, to } });
 
 // Health
 export const getHealth = () => api.get('/health');
@@ -83,6 +109,10 @@ export const getBacktestMetrics = (runId: string) => api.get(`/backtest/${runId}
 export const getBacktestEquity = (runId: string) => api.get(`/backtest/${runId}/equity`);
 export const getBacktestTrades = (runId: string) => api.get(`/backtest/${runId}/trades`);
 export const deleteBacktest = (runId: string) => api.delete(`/backtest/${runId}`);
+export const listPromptEvals = () => api.get('/backtest/eval');
+export const startPromptEval = (data: any) => api.post('/backtest/eval', data);
+export const getPromptEval = (evalId: string) => api.get(`/backtest/eval/${evalId}`);
+export const stopPromptEval = (evalId: string) => api.post(`/backtest/eval/${evalId}/stop`);
 
 // Debate API
 export const listDebates = () => api.get('/debate/sessions');
@@ -91,9 +121,30 @@ export const getDebate = (sessionId: string) => api.get(`/debate/sessions/${sess
 export const startDebate = (sessionId: string) => api.post(`/debate/sessions/${sessionId}/start`);
 export const stopDebate = (sessionId: string) => api.post(`/debate/sessions/${sessionId}/stop`);
 export const deleteDebate = (sessionId: string) => api.delete(`/debate/sessions/${sessionId}`);
+export const getDebatePersonalities = () => api.get('/debate/personalities');
+export const createDebatePersonality = (data: any) => api.post('/debate/personalities', data);
+export const updateDebatePersonality = (id: string, data: any) => api.put(`/debate/personalities/${id}`, data);
+export const deleteDebatePersonality = (id: string) => api.delete(`/debate/personalities/${id}`);
 
 // Settings API
 export const getSettings = () => api.get('/settings');
 export const updateSettings = (data: any) => api.put('/settings', data);
+export const getAIModels = (provider: string) => api.get('/ai/models', { params: { provider } });
+
+// AI Usage API
+export const getAIUsage = (params: { group_by: string; trader_id?: string; caller_type?: string; from?: string; to?: string }) =>
+  api.get('/ai/usage', { params });
+export const getAICalls = (params: { limit?: number; trader_id?: string; caller_type?: string }) =>
+  api.get('/ai/usage/calls', { params });
+export const getAIBudgets = () => api.get('/ai/budgets');
+export const getAIPrices = () => api.get('/ai/prices');
+export const setAIPrice = (data: any) => api.put('/ai/prices', data);
+export const deleteAIPrice = (model: string) => api.delete('/ai/prices', { params: { model } });
+
+// Prompt templates API
+export const getPrompts = () => api.get('/prompts');
+export const savePromptTemplate = (data: { variant: string; name: string; language?: string; body: string }) =>
+  api.post('/prompts', data);
+export const reloadPrompts = () => api.post('/prompts/reload');
 
 export default api;
@@ -1,8 +1,8 @@
 import { useEffect, useState } from 'react';
 import { motion, Reorder, useDragControls } from 'framer-motion';
-import { getTraders, getStrategies, createTrader, updateTrader, deleteTrader, getSettings, updateSettings } from '../lib/api';
+import { getTraders, getStrategies, createTrader, updateTrader, deleteTrader, getSettings, updateSettings, getAIModels } from '../lib/api';
 import type { Trader, Strategy } from '../types';
-import { Plus, Pencil, Trash2, Save, Eye, EyeOff, Settings, RefreshCw, Zap, AlertTriangle, Key, Globe, GripVertical } from 'lucide-react';
+import { Plus, Pencil, Trash2, Save, Eye, EyeOff, Settings, RefreshCw, Zap, AlertTriangle, Key, Globe, GripVertical, Server } from 'lucide-react';
 import { Button } from '@/components/ui/button';
 import { Input } from '@/components/ui/input';
 import { Label } from '@/components/ui/label';
@@ -18,11 +18,39 @@ import { useConfirm, useAlert } from '@/components/ui/confirm-modal';
 interface GlobalSettings {
   openrouter_api_key: string;
   openrouter_model: string;
+  openrouter_base_url: string;
+  openrouter_fallback: boolean;
+  openai_api_key: string;
+  openai_base_url: string;
+  anthropic_api_key: string;
+  anthropic_base_url: string;
+  deepseek_api_key: string;
+  deepseek_base_url: string;
+  ollama_base_url: string;
+  local_ai_base_url: string;
+  local_ai_api_key: string;
   binance_api_key: string;
   binance_secret_key: string;
   binance_testnet: boolean;
 }
 
+// Native providers debate participants can call directly instead of through OpenRouter
+const NATIVE_PROVIDERS = [
+  { id: 'openai', name: 'OpenAI', keyPlaceholder: 'sk-...', urlPlaceholder: 'https://api.openai.com/v1' },
+  { id: 'anthropic', name: 'Anthropic', keyPlaceholder: 'sk-ant-...', urlPlaceholder: 'https://api.anthropic.com/v1' },
+  { id: 'deepseek', name: 'DeepSeek', keyPlaceholder: 'sk-...', urlPlaceholder: 'https://api.deepseek.com/v1' },
+] as const;
+
+// Providers a trader's decisions can go to
+const TRADER_PROVIDERS = [
+  { id: 'openrouter', name: 'OpenRouter' },
+  { id: 'openai', name: 'OpenAI (native)' },
+  { id: 'anthropic', name: 'Anthropic (native)' },
+  { id: 'deepseek', name: 'DeepSeek (native)' },
+  { id: 'ollama', name: 'Ollama (self-hosted)' },
+  { id: 'local', name: 'OpenAI-compatible (self-hosted)' },
+];
+
 const TraderItem = ({
   trader,
   strategies,
@@ -111,11 +139,22 @@ export default function Config() {
   const [globalSettings, setGlobalSettings] = useState<GlobalSettings>({
     openrouter_api_key: '',
     openrouter_model: 'deepseek/deepseek-v3.2',
+    openrouter_base_url: '',
+    openrouter_fallback: true,
+    openai_api_key: '',
+    openai_base_url: '',
+    anthropic_api_key: '',
+    anthropic_base_url: '',
+    deepseek_api_key: '',
+    deepseek_base_url: '',
+    ollama_base_url: '',
+    local_ai_base_url: '',
+    local_ai_api_key: '',
     binance_api_key: '',
     binance_secret_key: '',
     binance_testnet: true,
   });
-  const [settingsConfigured, setSettingsConfigured] = useState({ openrouter: false, binance: false });
+  const [settingsConfigured, setSettingsConfigured] = useState<Record<string, boolean>>({ openrouter: false, binance: false });
   const [savingSettings, setSavingSettings] = useState(false);
   const { confirm, ConfirmDialog } = useConfirm();
   const { alert, AlertDialog } = useAlert();
@@ -270,6 +309,22 @@ export default function Config() {
     setShowSecrets((prev) => ({ ...prev, [field]: !prev[field] }));
   };
 
+  // Models offered by the trader's provider (suggestions for the model field)
+  const [providerModels, setProviderModels] = useState<string[]>([]);
+
+  const loadProviderModels = async (provider: string) => {
+    try {
+      const res = await getAIModels(provider);
+      setProviderModels(res.data.models || []);
+    } catch (err: any) {
+      alert({
+        title: 'Could not list models',
+        description: err.response?.data?.error || 'Is the server running and its base URL set?',
+        variant: 'danger',
+      });
+    }
+  };
+
   const handleReorder = (newOrder: Trader[]) => {
     setTraders(newOrder);
     const orderIds = newOrder.map(t => t.id);
@@ -369,7 +424,7 @@ export default function Config() {
             </div>
             <div>
               <h3 className="font-semibold text-lg">Global Settings</h3>
-              <p className="text-sm text-muted-foreground">Configure API keys for OpenRouter and default Binance credentials</p>
+              <p className="text-sm text-muted-foreground">Configure API keys for OpenRouter, native debate providers and default Binance credentials</p>
             </div>
           </div>
 
@@ -426,6 +481,24 @@ export default function Config() {
                   </SelectContent>
                 </Select>
               </div>
+
+              <div className="space-y-2">
+                <Label>Base URL (optional)</Label>
+                <Input
+                  value={globalSettings.openrouter_base_url}
+                  onChange={(e) => setGlobalSettings({ ...globalSettings, openrouter_base_url: e.target.value })}
+                  className="glass"
+                  placeholder="https://openrouter.ai/api/v1"
+                />
+              </div>
+
+              <div className="flex items-center gap-2">
+                <Checkbox
+                  checked={globalSettings.openrouter_fallback}
+                  onCheckedChange={(v) => setGlobalSettings({ ...globalSettings, openrouter_fallback: !!v })}
+                />
+                <Label className="cursor-pointer">Use as fallback for debate providers without a key</Label>
+              </div>
             </div>
 
             {/* Default Binance Settings */}
@@ -492,6 +565,101 @@ export default function Config() {
             </div>
           </div>
 
+          {/* Native AI providers for debate participants */}
+          <div className="mt-6 space-y-4">
+            <div className="flex items-center gap-2 text-sm font-medium text-purple-400">
+              <Globe className="w-4 h-4" />
+              Debate AI Providers
+            </div>
+            <p className="text-xs text-muted-foreground">
+              Participants with a native provider call its API directly. Leave a key empty to route that provider through OpenRouter.
+            </p>
+            <div className="grid gap-6 lg:grid-cols-3">
+              {NATIVE_PROVIDERS.map((provider) => {
+                const keyField = `${provider.id}_api_key` as const;
+                const urlField = `${provider.id}_base_url` as const;
+                return (
+                  <div key={provider.id} className="space-y-2">
+                    <div className="flex items-center gap-2">
+                      <Label>{provider.name} API Key</Label>
+                      {settingsConfigured[provider.id] && (
+                        <GlowBadge variant="success">Configured</GlowBadge>
+                      )}
+                    </div>
+                    <div className="relative">
+                      <Input
+                        type={showSecrets[`global_${provider.id}`] ? 'text' : 'password'}
+                        value={globalSettings[keyField]}
+                        onChange={(e) => setGlobalSettings({ ...globalSettings, [keyField]: e.target.value })}
+                        className="glass pr-10"
+                        placeholder={provider.keyPlaceholder}
+                      />
+                      <Button
+                        type="button"
+                        variant="ghost"
+                        size="icon"
+                        className="absolute right-1 top-1/2 -translate-y-1/2 h-8 w-8"
+                        onClick={() => toggleShowSecret(`global_${provider.id}`)}
+                      >
+                        {showSecrets[`global_${provider.id}`] ? <EyeOff className="h-4 w-4" /> : <Eye className="h-4 w-4" />}
+                      </Button>
+                    </div>
+                    <Input
+                      value={globalSettings[urlField]}
+                      onChange={(e) => setGlobalSettings({ ...globalSettings, [urlField]: e.target.value })}
+                      className="glass"
+                      placeholder={provider.urlPlaceholder}
+                    />
+                  </div>
+                );
+              })}
+            </div>
+          </div>
+
+          {/* Self-hosted models */}
+          <div className="mt-6 space-y-4">
+            <div className="flex items-center gap-2 text-sm font-medium text-emerald-400">
+              <Server className="w-4 h-4" />
+              Self-Hosted Models
+            </div>
+            <p className="text-xs text-muted-foreground">
+              Run decisions and debate participants on your own hardware. Set a base URL to enable a server; local models never fall back to OpenRouter.
+            </p>
+            <div className="grid gap-6 lg:grid-cols-2">
+              <div className="space-y-2">
+                <div className="flex items-center gap-2">
+                  <Label>Ollama Base URL</Label>
+                  {settingsConfigured.ollama && <GlowBadge variant="success">Enabled</GlowBadge>}
+                </div>
+                <Input
+                  value={globalSettings.ollama_base_url}
+                  onChange={(e) => setGlobalSettings({ ...globalSettings, ollama_base_url: e.target.value })}
+                  className="glass"
+                  placeholder="http://localhost:11434"
+                />
+              </div>
+              <div className="space-y-2">
+                <div className="flex items-center gap-2">
+                  <Label>OpenAI-Compatible Base URL</Label>
+                  {settingsConfigured.local && <GlowBadge variant="success">Enabled</GlowBadge>}
+                </div>
+                <Input
+                  value={globalSettings.local_ai_base_url}
+                  onChange={(e) => setGlobalSettings({ ...globalSettings, local_ai_base_url: e.target.value })}
+                  className="glass"
+                  placeholder="http://localhost:8000/v1 (llama.cpp, vLLM)"
+                />
+                <Input
+                  type={showSecrets.global_local ? 'text' : 'password'}
+                  value={globalSettings.local_ai_api_key}
+                  onChange={(e) => setGlobalSettings({ ...globalSettings, local_ai_api_key: e.target.value })}
+                  className="glass"
+                  placeholder="API key (optional)"
+                />
+              </div>
+            </div>
+          </div>
+
           <div className="flex justify-end mt-6">
             <Button onClick={handleSaveSettings} disabled={savingSettings}>
               {savingSettings ? (
@@ -730,7 +898,9 @@ export default function Config() {
                           <SelectValue />
                         </SelectTrigger>
                         <SelectContent>
-                          <SelectItem value="openrouter">OpenRouter</SelectItem>
+                          {TRADER_PROVIDERS.map((p) => (
+                            <SelectItem key={p.id} value={p.id}>{p.name}</SelectItem>
+                          ))}
                         </SelectContent>
                       </Select>
                     </div>
@@ -748,7 +918,37 @@ export default function Config() {
                     </div>
                   </div>
 
-                  {editingTrader.config?.use_custom_model ? (
+                  {(editingTrader.config?.ai_provider || 'openrouter') !== 'openrouter' ? (
+                    <div className="space-y-2">
+                      <Label>Model</Label>
+                      <div className="flex gap-2">
+                        <Input
+                          value={editingTrader.config?.ai_model || ''}
+                          onChange={(e) => setEditingTrader({
+                            ...editingTrader,
+                            config: { ...editingTrader.config!, ai_model: e.target.value }
+                          })}
+                          className="glass"
+                          list="provider-models"
+                          placeholder="e.g., llama3.1:8b, gpt-4o-mini, deepseek-chat"
+                        />
+                        <Button
+                          type="button"
+                          variant="outline"
+                          className="glass"
+                          onClick={() => loadProviderModels(editingTrader.config?.ai_provider || '')}
+                        >
+                          Load models
+                        </Button>
+                      </div>
+                      <datalist id="provider-models">
+                        {providerModels.map((m) => <option key={m} value={m} />)}
+                      </datalist>
+                      <p className="text-xs text-muted-foreground">
+                        Uses the key or base URL from Global Settings. The model ID is sent to the provider as-is.
+                      </p>
+                    </div>
+                  ) : editingTrader.config?.use_custom_model ? (
                     <div className="space-y-2">
                       <Label>Custom Model ID</Label>
                       <Input
@@ -800,6 +1000,58 @@ export default function Config() {
                     </div>
                   )}
 
+                  {/* AI Fallback Chain */}
+                  <div className="space-y-2">
+                    <Label>Fallback Models</Label>
+                    <Input
+                      key={editingTrader.id || 'new'}
+                      defaultValue={(editingTrader.config?.fallback_models || [])
+                        .map((b) => `${b.provider}:${b.model}`)
+                        .join(', ')}
+                      onBlur={(e) => setEditingTrader({
+                        ...editingTrader,
+                        config: {
+                          ...editingTrader.config!,
+                          fallback_models: e.target.value
+                            .split(',')
+                            .map((entry) => entry.trim())
+                            .filter(Boolean)
+                            .map((entry) => {
+                              const sep = entry.indexOf(':');
+                              return sep > 0
+                                ? { provider: entry.slice(0, sep), model: entry.slice(sep + 1) }
+                                : { provider: 'openrouter', model: entry };
+                            }),
+                        },
+                      })}
+                      className="glass"
+                      placeholder="e.g., openrouter:google/gemini-2.5-flash, deepseek:deepseek-chat"
+                    />
+                    <p className="text-xs text-muted-foreground">
+                      Tried in order when the model above fails or keeps failing. Native providers use the API keys from Global Settings.
+                    </p>
+                  </div>
+
+                  {/* Monthly AI Budget */}
+                  <div className="space-y-2">
+                    <Label>Monthly AI Budget (USD)</Label>
+                    <Input
+                      type="number"
+                      min={0}
+                      step="0.01"
+                      value={editingTrader.config?.monthly_ai_budget_usd || ''}
+                      onChange={(e) => setEditingTrader({
+                        ...editingTrader,
+                        config: { ...editingTrader.config!, monthly_ai_budget_usd: parseFloat(e.target.value) || 0 },
+                      })}
+                      className="glass"
+                      placeholder="Unlimited"
+                    />
+                    <p className="text-xs text-muted-foreground">
+                      AI calls for this trader (and debates run for it) pause until next month once spend reaches the budget. Costs come from the AI Usage price table.
+                    </p>
+                  </div>
+
                   {/* Reasoning Mode Toggle */}
                   <div className="flex items-center justify-between p-3 rounded-lg bg-purple-500/10 border border-purple-500/20">
                     <div className="flex items-center gap-3">
@@ -817,6 +1069,24 @@ export default function Config() {
                       })}
                     />
                   </div>
+
+                  {/* AI Call Recording */}
+                  <div className="flex items-center justify-between p-3 rounded-lg bg-white/5 border border-white/10">
+                    <div className="flex items-center gap-3">
+                      <Server className="w-4 h-4 text-muted-foreground" />
+                      <div>
+                        <span className="font-medium text-sm">Record AI Calls</span>
+                        <p className="text-xs text-muted-foreground">Save prompts and responses so decisions can be replayed offline with replay-decision</p>
+                      </div>
+                    </div>
+                    <Checkbox
+                      checked={editingTrader.config?.record_ai_calls ?? false}
+                      onCheckedChange={(v) => setEditingTrader({
+                        ...editingTrader,
+                        config: { ...editingTrader.config!, record_ai_calls: !!v }
+                      })}
+                    />
+                  </div>
                 </div>
               </GlassCard>
 
@@ -12,8 +12,6 @@ import {
   XCircle,
   Brain,
   Timer,
-  Eye,
-  EyeOff,
 } from 'lucide-react';
 import {
   listDebates,
@@ -22,10 +20,17 @@ import {
   startDebate,
   stopDebate,
   deleteDebate,
+  getDebatePersonalities,
+  createDebatePersonality,
+  updateDebatePersonality,
+  deleteDebatePersonality,
+  getTraders,
 } from '../lib/api';
+import type { Trader } from '../types';
 import { Button } from '@/components/ui/button';
 import { Input } from '@/components/ui/input';
 import { Label } from '@/components/ui/label';
+import { Textarea } from '@/components/ui/textarea';
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/components/ui/select';
 import { ScrollArea } from '@/components/ui/scroll-area';
 import { Checkbox } from '@/components/ui/checkbox';
@@ -36,8 +41,20 @@ import { ProgressStat } from '@/components/ui/stat-card';
 import { SpotlightCard, AnimatedBorderCard } from '@/components/ui/spotlight-card';
 import { useConfirm, useAlert } from '@/components/ui/confirm-modal';
 
-// Personality types with colors and emojis
-const PERSONALITIES = [
+interface PersonalityOption {
+  id: string;
+  name: string;
+  emoji: string;
+  color: string;
+  description: string;
+  prompt_fragment?: string;
+  bias?: string;
+  risk_tolerance?: string;
+  builtin?: boolean;
+}
+
+// Built-in personality types with colors and emojis, used until the server list loads
+const PERSONALITIES: PersonalityOption[] = [
   { id: 'bull', name: 'Bull', emoji: '🐂', color: '#22C55E', description: 'Optimistic, looks for long opportunities' },
   { id: 'bear', name: 'Bear', emoji: '🐻', color: '#EF4444', description: 'Skeptical, focuses on risks' },
   { id: 'analyst', name: 'Analyst', emoji: '📊', color: '#3B82F6', description: 'Neutral, data-driven analysis' },
@@ -55,6 +72,35 @@ const AI_MODELS = [
   { id: 'openai/gpt-4o-mini', name: 'GPT-4o Mini', provider: 'openrouter' },
 ];
 
+// Where a participant's calls go; native providers fall back to OpenRouter when no key is set, self-hosted ones never do
+const PROVIDERS = [
+  { id: 'openrouter', name: 'via OpenRouter' },
+  { id: 'openai', name: 'OpenAI (native)' },
+  { id: 'anthropic', name: 'Anthropic (native)' },
+  { id: 'deepseek', name: 'DeepSeek (native)' },
+  { id: 'ollama', name: 'Ollama (self-hosted)' },
+  { id: 'local', name: 'OpenAI-compatible (self-hosted)' },
+];
+
+// How votes are combined into the final decisions
+const CONSENSUS_MODES = [
+  { id: 'confidence', name: 'Confidence-weighted', description: 'Each vote counts by its stated confidence' },
+  { id: 'accuracy', name: 'Accuracy-weighted', description: 'Votes count by the participant\'s historical hit rate' },
+  { id: 'calibrated', name: 'Calibrated (Brier)', description: 'Confidence corrected by track record and calibration' },
+  { id: 'median', name: 'Median', description: 'Median stance from short to long, equal weights' },
+  { id: 'unanimous', name: 'Unanimous', description: 'Act only when every participant agrees' },
+];
+
+const EMPTY_PERSONALITY_FORM = {
+  name: '',
+  description: '',
+  prompt_fragment: '',
+  emoji: '',
+  color: '#94A3B8',
+  bias: 'neutral',
+  risk_tolerance: 'moderate',
+};
+
 interface DebateSession {
   id: string;
   name: string;
@@ -71,6 +117,32 @@ interface DebateSession {
   cycle_interval_minutes?: number;
   cycle_count?: number;
   next_cycle_at?: string;
+  consensus_mode?: string;
+  consensus_weights?: ConsensusWeight[];
+  judge?: { ai_model_id: string; ai_model_name: string; provider: string };
+  convergence_threshold?: number;
+  token_budget?: number;
+  tokens_used?: number;
+  cost_budget_usd?: number;
+  cost_used_usd?: number;
+  stop_reason?: string;
+  verdict?: {
+    decisions: any[];
+    rationale: string;
+    accepted: boolean;
+    error?: string;
+  };
+}
+
+interface ConsensusWeight {
+  ai_model_id: string;
+  ai_model_name: string;
+  personality: string;
+  weight: number;
+  predictions: number;
+  hit_rate: number;
+  brier_score: number;
+  pnl_contribution: number; // Realized PnL (USDT) credited to backed votes
 }
 
 interface Message {
@@ -81,6 +153,7 @@ interface Message {
   message_type: string;
   content: string;
   confidence: number;
+  parse_path?: 'schema' | 'lenient' | 'fallback';
   created_at: string;
 }
 
@@ -104,19 +177,27 @@ export default function Debate() {
     participants: [] as { ai_model_id: string; ai_model_name: string; provider: string; personality: string }[],
     auto_cycle: false,
     cycle_interval_minutes: 5,
-    // Binance credentials for auto-execution
-    binance_api_key: '',
-    binance_secret_key: '',
-    binance_testnet: true,
+    consensus_mode: 'confidence',
+    judge_model_id: '', // '' = no judge
+    convergence_threshold: 1, // 0 = always run every round
+    token_budget: 0, // 0 = unlimited
+    cost_budget_usd: 0, // 0 = unlimited
   });
 
-  // Show/hide secrets
-  const [showSecrets, setShowSecrets] = useState(false);
+  // Built-in and custom personalities
+  const [personalities, setPersonalities] = useState<PersonalityOption[]>(PERSONALITIES);
+  const [showPersonalityForm, setShowPersonalityForm] = useState(false);
+  const [editingPersonalityId, setEditingPersonalityId] = useState<string | null>(null);
+  const [personalityForm, setPersonalityForm] = useState(EMPTY_PERSONALITY_FORM);
+
+  // Traders available for auto-execution
+  const [traders, setTraders] = useState<Trader[]>([]);
 
   // Selected model and personality for adding participants
   const [selectedModel, setSelectedModel] = useState(AI_MODELS[0].id);
   const [selectedPersonality, setSelectedPersonality] = useState(PERSONALITIES[0].id);
   const [customModelId, setCustomModelId] = useState('');
+  const [selectedProvider, setSelectedProvider] = useState(PROVIDERS[0].id);
 
   // Countdown timer state
   const [countdown, setCountdown] = useState<string>('');
@@ -160,8 +241,16 @@ export default function Debate() {
 
   const loadData = async () => {
     try {
-      const sessionsRes = await listDebates().catch(() => ({ data: { sessions: [] } }));
+      const [sessionsRes, tradersRes, personalitiesRes] = await Promise.all([
+        listDebates().catch(() => ({ data: { sessions: [] } })),
+        getTraders().catch(() => ({ data: { traders: [] } })),
+        getDebatePersonalities().catch(() => ({ data: PERSONALITIES })),
+      ]);
       setSessions(sessionsRes.data.sessions || []);
+      setTraders(tradersRes.data.traders || []);
+      if (Array.isArray(personalitiesRes.data) && personalitiesRes.data.length > 0) {
+        setPersonalities(personalitiesRes.data);
+      }
       if (sessionsRes.data.sessions?.length > 0 && !selectedSession) {
         setSelectedSession(sessionsRes.data.sessions[0].id);
       }
@@ -203,7 +292,7 @@ export default function Debate() {
         {
           ai_model_id: model.id,
           ai_model_name: model.name,
-          provider: model.provider,
+          provider: selectedProvider,
           personality,
         },
       ],
@@ -227,6 +316,15 @@ export default function Debate() {
       return;
     }
 
+    if (formData.auto_execute && !formData.trader_id) {
+      alert({
+        title: 'Validation Error',
+        description: 'Select a trader to enable auto-execution',
+        variant: 'warning',
+      });
+      return;
+    }
+
     setCreating(true);
     try {
       const data = {
@@ -238,9 +336,17 @@ export default function Debate() {
         participants: formData.participants,
         auto_cycle: formData.auto_cycle,
         cycle_interval_minutes: formData.cycle_interval_minutes,
-        binance_api_key: formData.binance_api_key,
-        binance_secret_key: formData.binance_secret_key,
-        binance_testnet: formData.binance_testnet,
+        consensus_mode: formData.consensus_mode,
+        convergence_threshold: formData.convergence_threshold,
+        token_budget: formData.token_budget,
+        cost_budget_usd: formData.cost_budget_usd,
+        judge: formData.judge_model_id
+          ? {
+              ai_model_id: formData.judge_model_id,
+              ai_model_name: AI_MODELS.find((m) => m.id === formData.judge_model_id)?.name ?? formData.judge_model_id,
+              provider: 'openrouter',
+            }
+          : undefined,
       };
       const res = await createDebate(data);
       setSelectedSession(res.data.id || res.data.session_id);
@@ -254,9 +360,11 @@ export default function Debate() {
         participants: [],
         auto_cycle: false,
         cycle_interval_minutes: 5,
-        binance_api_key: '',
-        binance_secret_key: '',
-        binance_testnet: true,
+        consensus_mode: 'confidence',
+        judge_model_id: '',
+        convergence_threshold: 1,
+        token_budget: 0,
+        cost_budget_usd: 0,
       });
       await loadData();
     } catch (err: any) {
@@ -308,7 +416,63 @@ export default function Debate() {
     }
   };
 
-  const getPersonality = (id: string) => PERSONALITIES.find((p) => p.id === id);
+  const handleEditPersonality = (p: PersonalityOption) => {
+    setEditingPersonalityId(p.id);
+    setPersonalityForm({
+      name: p.name,
+      description: p.description,
+      prompt_fragment: p.prompt_fragment || '',
+      emoji: p.emoji,
+      color: p.color,
+      bias: p.bias || 'neutral',
+      risk_tolerance: p.risk_tolerance || 'moderate',
+    });
+    setShowPersonalityForm(true);
+  };
+
+  const handleSavePersonality = async () => {
+    try {
+      if (editingPersonalityId) {
+        await updateDebatePersonality(editingPersonalityId, personalityForm);
+      } else {
+        const res = await createDebatePersonality(personalityForm);
+        setSelectedPersonality(res.data.id);
+      }
+      setShowPersonalityForm(false);
+      setEditingPersonalityId(null);
+      setPersonalityForm(EMPTY_PERSONALITY_FORM);
+      await loadData();
+    } catch (err: any) {
+      alert({
+        title: 'Error',
+        description: err.response?.data?.error || 'Failed to save personality',
+        variant: 'danger',
+      });
+    }
+  };
+
+  const handleDeletePersonality = async (id: string) => {
+    const confirmed = await confirm({
+      title: 'Delete Personality',
+      description: 'Debates already using it will fall back to a neutral analyst role.',
+      confirmText: 'Delete',
+      variant: 'danger',
+    });
+    if (!confirmed) return;
+    try {
+      await deleteDebatePersonality(id);
+      setPersonalities(personalities.filter((p) => p.id !== id));
+      if (selectedPersonality === id) setSelectedPersonality(PERSONALITIES[0].id);
+    } catch (err: any) {
+      alert({
+        title: 'Error',
+        description: err.response?.data?.error || 'Failed to delete personality',
+        variant: 'danger',
+      });
+    }
+  };
+
+  const getPersonality = (id: string) => personalities.find((p) => p.id === id);
 
   if (loading) {
     return (
@@ -430,6 +594,99 @@ export default function Debate() {
                   </div>
                 </div>
 
+                {/* Judge */}
+                <div className="space-y-2">
+                  <Label>Judge (optional)</Label>
+                  <Select
+                    value={formData.judge_model_id || 'none'}
+                    onValueChange={(v) => setFormData({ ...formData, judge_model_id: v === 'none' ? '' : v })}
+                  >
+                    <SelectTrigger className="glass">
+                      <SelectValue />
+                    </SelectTrigger>
+                    <SelectContent>
+                      <SelectItem value="none">No judge - use consensus</SelectItem>
+                      {AI_MODELS.map((model) => (
+                        <SelectItem key={model.id} value={model.id}>
+                          ⚖️ {model.name}
+                        </SelectItem>
+                      ))}
+                    </SelectContent>
+                  </Select>
+                  <p className="text-xs text-muted-foreground">
+                    Reads the full transcript and votes and rules one decision per symbol. Falls back to the
+                    consensus if its ruling fails validation.
+                  </p>
+                </div>
+
+                {/* Consensus Mode */}
+                <div className="space-y-2">
+                  <Label>Consensus Mode</Label>
+                  <Select
+                    value={formData.consensus_mode}
+                    onValueChange={(v) => setFormData({ ...formData, consensus_mode: v })}
+                  >
+                    <SelectTrigger className="glass">
+                      <SelectValue />
+                    </SelectTrigger>
+                    <SelectContent>
+                      {CONSENSUS_MODES.map((mode) => (
+                        <SelectItem key={mode.id} value={mode.id}>
+                          {mode.name}
+                        </SelectItem>
+                      ))}
+                    </SelectContent>
+                  </Select>
+                  <p className="text-xs text-muted-foreground">
+                    {CONSENSUS_MODES.find((m) => m.id === formData.consensus_mode)?.description}
+                  </p>
+                </div>
+
+                {/* Early Termination */}
+                <div className="grid grid-cols-2 gap-4">
+                  <div className="space-y-2">
+                    <Label>End Rounds Early When</Label>
+                    <Select
+                      value={formData.convergence_threshold.toString()}
+                      onValueChange={(v) => setFormData({ ...formData, convergence_threshold: Number(v) })}
+                    >
+                      <SelectTrigger className="glass">
+                        <SelectValue />
+                      </SelectTrigger>
+                      <SelectContent>
+                        <SelectItem value="1">All participants agree</SelectItem>
+                        <SelectItem value="0.75">75% agree</SelectItem>
+                        <SelectItem value="0.6">60% agree</SelectItem>
+                        <SelectItem value="0">Never (run every round)</SelectItem>
+                      </SelectContent>
+                    </Select>
+                  </div>
+                  <div className="space-y-2">
+                    <Label>Token Budget per Cycle</Label>
+                    <Input
+                      type="number"
+                      min={0}
+                      step={1000}
+                      className="glass"
+                      placeholder="0 = unlimited"
+                      value={formData.token_budget || ''}
+                      onChange={(e) => setFormData({ ...formData, token_budget: Math.max(0, Number(e.target.value) || 0) })}
+                    />
+                  </div>
+                  <div className="space-y-2">
+                    <Label>Cost Budget per Cycle (USD)</Label>
+                    <Input
+                      type="number"
+                      min={0}
+                      step={0.01}
+                      className="glass"
+                      placeholder="0 = unlimited"
+                      value={formData.cost_budget_usd || ''}
+                      onChange={(e) => setFormData({ ...formData, cost_budget_usd: Math.max(0, Number(e.target.value) || 0) })}
+                    />
+                  </div>
+                </div>
+
                 {/* Auto-Cycle Settings */}
                 <div className="grid grid-cols-2 gap-4">
                   <div className="space-y-2">
@@ -468,51 +725,31 @@ export default function Debate() {
                   )}
                 </div>
 
-                {/* Binance Credentials (shown when auto_execute is enabled) */}
+                {/* Trader (shown when auto_execute is enabled) */}
                 {formData.auto_execute && (
-                  <div className="space-y-4 p-4 rounded-lg bg-yellow-500/10 border border-yellow-500/20">
+                  <div className="space-y-3 p-4 rounded-lg bg-yellow-500/10 border border-yellow-500/20">
                     <div className="flex items-center gap-2 text-yellow-500">
-                      <span className="text-sm font-medium">Binance API Credentials (Required for Auto-Execute)</span>
-                    </div>
-                    <div className="grid grid-cols-2 gap-4">
-                      <div className="space-y-2">
-                        <Label>API Key</Label>
-                        <Input
-                          value={formData.binance_api_key}
-                          onChange={(e) => setFormData({ ...formData, binance_api_key: e.target.value })}
-                          placeholder="Enter Binance API Key"
-                          className="glass font-mono text-xs"
-                        />
-                      </div>
-                      <div className="space-y-2">
-                        <Label>Secret Key</Label>
-                        <div className="relative">
-                          <Input
-                            type={showSecrets ? 'text' : 'password'}
-                            value={formData.binance_secret_key}
-                            onChange={(e) => setFormData({ ...formData, binance_secret_key: e.target.value })}
-                            placeholder="Enter Binance Secret Key"
-                            className="glass font-mono text-xs pr-10"
-                          />
-                          <button
-                            type="button"
-                            onClick={() => setShowSecrets(!showSecrets)}
-                            className="absolute right-2 top-1/2 -translate-y-1/2 text-muted-foreground hover:text-foreground"
-                          >
-                            {showSecrets ? <EyeOff className="h-4 w-4" /> : <Eye className="h-4 w-4" />}
-                          </button>
-                        </div>
-                      </div>
-                    </div>
-                    <div className="flex items-center gap-2">
-                      <Checkbox
-                        checked={formData.binance_testnet}
-                        onCheckedChange={(v) => setFormData({ ...formData, binance_testnet: !!v })}
-                      />
-                      <span className="text-sm text-muted-foreground">
-                        Use Testnet (recommended for testing)
-                      </span>
+                      <span className="text-sm font-medium">Execute Through Trader (Required for Auto-Execute)</span>
                     </div>
+                    <Select
+                      value={formData.trader_id}
+                      onValueChange={(v) => setFormData({ ...formData, trader_id: v })}
+                    >
+                      <SelectTrigger className="glass">
+                        <SelectValue placeholder="Select a trader" />
+                      </SelectTrigger>
+                      <SelectContent>
+                        {traders.map((trader) => (
+                          <SelectItem key={trader.id} value={trader.id}>
+                            {trader.name}{trader.status === 'running' ? '' : ' (stopped)'}
+                          </SelectItem>
+                        ))}
+                      </SelectContent>
+                    </Select>
+                    <p className="text-xs text-muted-foreground">
+                      Consensus decisions go through this trader's risk controls, position sizing and
+                      bracket orders. The trader must be running when decisions are executed.
+                    </p>
                   </div>
                 )}
 
@@ -541,12 +778,24 @@ export default function Debate() {
                         onChange={(e) => setCustomModelId(e.target.value)}
                       />
                     )}
+                    <Select value={selectedProvider} onValueChange={setSelectedProvider}>
+                      <SelectTrigger className="glass flex-1">
+                        <SelectValue placeholder="Provider" />
+                      </SelectTrigger>
+                      <SelectContent>
+                        {PROVIDERS.map((provider) => (
+                          <SelectItem key={provider.id} value={provider.id}>
+                            {provider.name}
+                          </SelectItem>
+                        ))}
+                      </SelectContent>
+                    </Select>
                     <Select value={selectedPersonality} onValueChange={setSelectedPersonality}>
                       <SelectTrigger className="glass flex-1">
                         <SelectValue placeholder="Personality" />
                       </SelectTrigger>
                       <SelectContent>
-                        {PERSONALITIES.map((p) => (
+                        {personalities.map((p) => (
                           <SelectItem key={p.id} value={p.id}>
                             {p.emoji} {p.name}
                           </SelectItem>
@@ -563,7 +812,7 @@ export default function Debate() {
 
                   {/* Quick add buttons */}
                   <div className="flex flex-wrap gap-2">
-                    {PERSONALITIES.map((p) => (
+                    {personalities.map((p) => (
                       <Button
                         key={p.id}
                         variant="outline"
@@ -576,6 +825,116 @@ export default function Debate() {
                     ))}
                   </div>
 
+                  {/* Custom personalities */}
+                  <div className="space-y-2 p-3 rounded-lg bg-white/5">
+                    <div className="flex items-center justify-between">
+                      <span className="text-xs text-muted-foreground">Custom personalities</span>
+                      <Button
+                        variant="ghost"
+                        size="sm"
+                        onClick={() => {
+                          setEditingPersonalityId(null);
+                          setPersonalityForm(EMPTY_PERSONALITY_FORM);
+                          setShowPersonalityForm(!showPersonalityForm);
+                        }}
+                      >
+                        <Plus className="w-3 h-3 mr-1" />
+                        New
+                      </Button>
+                    </div>
+                    <div className="flex flex-wrap gap-2">
+                      {personalities
+                        .filter((p) => !p.builtin && !PERSONALITIES.some((b) => b.id === p.id))
+                        .map((p) => (
+                          <div key={p.id} className="flex items-center gap-1 text-xs">
+                            <button className="hover:underline" onClick={() => handleEditPersonality(p)}>
+                              {p.emoji} {p.name}
+                            </button>
+                            <Button
+                              size="icon"
+                              variant="ghost"
+                              className="h-5 w-5"
+                              onClick={() => handleDeletePersonality(p.id)}
+                            >
+                              <Trash2 className="h-3 w-3" />
+                            </Button>
+                          </div>
+                        ))}
+                    </div>
+                    {showPersonalityForm && (
+                      <div className="space-y-2">
+                        <div className="grid grid-cols-4 gap-2">
+                          <Input
+                            className="glass col-span-2"
+                            placeholder="Name"
+                            value={personalityForm.name}
+                            disabled={!!editingPersonalityId}
+                            onChange={(e) => setPersonalityForm({ ...personalityForm, name: e.target.value })}
+                          />
+                          <Input
+                            className="glass"
+                            placeholder="Emoji"
+                            value={personalityForm.emoji}
+                            onChange={(e) => setPersonalityForm({ ...personalityForm, emoji: e.target.value })}
+                          />
+                          <Input
+                            className="glass"
+                            type="color"
+                            value={personalityForm.color}
+                            onChange={(e) => setPersonalityForm({ ...personalityForm, color: e.target.value })}
+                          />
+                        </div>
+                        <Input
+                          className="glass"
+                          placeholder="Description, e.g. Trades funding-rate extremes"
+                          value={personalityForm.description}
+                          onChange={(e) => setPersonalityForm({ ...personalityForm, description: e.target.value })}
+                        />
+                        <Textarea
+                          className="glass"
+                          rows={3}
+                          placeholder="System prompt fragment (optional)"
+                          value={personalityForm.prompt_fragment}
+                          onChange={(e) => setPersonalityForm({ ...personalityForm, prompt_fragment: e.target.value })}
+                        />
+                        <div className="flex gap-2">
+                          <Select
+                            value={personalityForm.bias}
+                            onValueChange={(v) => setPersonalityForm({ ...personalityForm, bias: v })}
+                          >
+                            <SelectTrigger className="glass flex-1">
+                              <SelectValue placeholder="Bias" />
+                            </SelectTrigger>
+                            <SelectContent>
+                              <SelectItem value="neutral">Neutral</SelectItem>
+                              <SelectItem value="bullish">Bullish</SelectItem>
+                              <SelectItem value="bearish">Bearish</SelectItem>
+                            </SelectContent>
+                          </Select>
+                          <Select
+                            value={personalityForm.risk_tolerance}
+                            onValueChange={(v) => setPersonalityForm({ ...personalityForm, risk_tolerance: v })}
+                          >
+                            <SelectTrigger className="glass flex-1">
+                              <SelectValue placeholder="Risk" />
+                            </SelectTrigger>
+                            <SelectContent>
+                              <SelectItem value="conservative">Conservative</SelectItem>
+                              <SelectItem value="moderate">Moderate</SelectItem>
+                              <SelectItem value="aggressive">Aggressive</SelectItem>
+                            </SelectContent>
+                          </Select>
+                          <Button
+                            onClick={handleSavePersonality}
+                            disabled={!personalityForm.name.trim() || !personalityForm.description.trim()}
+                          >
+                            {editingPersonalityId ? 'Update' : 'Save'}
+                          </Button>
+                        </div>
+                      </div>
+                    )}
+                  </div>
+
                   {/* Selected participants */}
                   <div className="space-y-2">
                     {formData.participants.map((p, i) => {
@@ -590,6 +949,9 @@ export default function Debate() {
                             <span>{personality?.emoji}</span>
                             <span className="text-sm">{p.ai_model_name}</span>
                             <GlowBadge variant="secondary">{personality?.name}</GlowBadge>
+                            {p.provider !== 'openrouter' && (
+                              <GlowBadge variant="secondary">{p.provider}</GlowBadge>
+                            )}
                           </div>
                           <Button
                             size="icon"
@@ -769,7 +1131,14 @@ export default function Debate() {
                       </h2>
                       <p className="text-sm text-muted-foreground">
                         Round {sessionDetails.current_round} of {sessionDetails.max_rounds}
+                        {sessionDetails.tokens_used ? ` • ${sessionDetails.tokens_used.toLocaleString()} tokens` : ''}
+                        {sessionDetails.token_budget ? ` / ${sessionDetails.token_budget.toLocaleString()}` : ''}
+                        {sessionDetails.cost_used_usd ? ` • $${sessionDetails.cost_used_usd.toFixed(4)}` : ''}
+                        {sessionDetails.cost_budget_usd ? ` / $${sessionDetails.cost_budget_usd.toFixed(2)}` : ''}
                       </p>
+                      {sessionDetails.stop_reason && (
+                        <p className="text-xs text-muted-foreground">Skipped remaining rounds: {sessionDetails.stop_reason}</p>
+                      )}
                     </div>
                     <div className="flex gap-2">
                       {sessionDetails.status === 'running' && (
@@ -867,6 +1236,11 @@ export default function Debate() {
                                     <span className="text-lg">{personality?.emoji}</span>
                                     <span className="font-medium">{msg.ai_model_name}</span>
                                     <GlowBadge variant="secondary">{msg.message_type}</GlowBadge>
+                                    {msg.parse_path && msg.parse_path !== 'schema' && (
+                                      <GlowBadge variant={msg.parse_path === 'fallback' ? 'danger' : 'warning'}>
+                                        {msg.parse_path} parse
+                                      </GlowBadge>
+                                    )}
                                   </div>
                                   <span className="text-xs text-muted-foreground">
                                     Round {msg.round}
@@ -940,7 +1314,50 @@ export default function Debate() {
                   <h3 className="font-semibold mb-4 flex items-center gap-2">
                     <CheckCircle2 className="w-5 h-5 text-green-400" />
                     Final Consensus Decisions
+                    {sessionDetails.consensus_mode && (
+                      <GlowBadge variant="secondary">
+                        {CONSENSUS_MODES.find((m) => m.id === sessionDetails.consensus_mode)?.name ?? sessionDetails.consensus_mode}
+                      </GlowBadge>
+                    )}
                   </h3>
+                  {sessionDetails.verdict && (
+                    <div
+                      className={`mb-4 p-3 rounded-lg text-sm ${
+                        sessionDetails.verdict.accepted ? 'bg-white/5' : 'bg-yellow-500/10 border border-yellow-500/20'
+                      }`}
+                    >
+                      <p className="font-medium mb-1">
+                        ⚖️ Judge {sessionDetails.judge?.ai_model_name}:{' '}
+                        {sessionDetails.verdict.accepted ? 'ruling accepted' : 'ruling rejected, consensus used'}
+                      </p>
+                      {sessionDetails.verdict.error && (
+                        <p className="text-xs text-yellow-400 mb-1">{sessionDetails.verdict.error}</p>
+                      )}
+                      {sessionDetails.verdict.rationale && (
+                        <p className="text-muted-foreground whitespace-pre-wrap">{sessionDetails.verdict.rationale}</p>
+                      )}
+                    </div>
+                  )}
+                  {sessionDetails.consensus_weights && sessionDetails.consensus_weights.length > 0 && (
+                    <div className="mb-4 space-y-1">
+                      {sessionDetails.consensus_weights.map((w) => {
+                        const personality = getPersonality(w.personality);
+                        return (
+                          <div key={`${w.ai_model_id}-${w.personality}`} className="flex items-center justify-between text-xs">
+                            <span>
+                              {personality?.emoji} {w.ai_model_name || w.ai_model_id}
+                            </span>
+                            <span className="text-muted-foreground">
+                              weight {w.weight.toFixed(2)}
+                              {w.predictions > 0
+                                ? ` · hit ${(w.hit_rate * 100).toFixed(0)}% of ${w.predictions} · Brier ${w.brier_score.toFixed(2)} · PnL $${w.pnl_contribution.toFixed(2)}`
+                                : ' · no track record'}
+                            </span>
+                          </div>
+                        );
+                      })}
+                    </div>
+                  )}
                   <div className="grid gap-3">
                     {sessionDetails.final_decisions.map((dec: any, i: number) => (
                       <SpotlightCard key={i} className="p-4">
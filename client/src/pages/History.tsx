@@ -53,6 +53,8 @@ interface Trade {
   commission: number;
   timestamp: string;
   order_id: number;
+  position_side?: string;
+  strategy_version_id?: number;
 }
 
 export default function History() {
@@ -1,7 +1,32 @@
 import { useEffect, useState } from 'react';
 import { motion, AnimatePresence } from 'framer-motion';
-import { getStrategies, createStrategy, updateStrategy, deleteStrategy, getDefaultConfig, recommendPairs } from '../lib/api';
-import type { Strategy, StrategyConfig } from '../types';
+import {
+  getStrategies,
+  createStrategy,
+  updateStrategy,
+  deleteStrategy,
+  getDefaultConfig,
+  recommendPairs,
+  getDebatePersonalities,
+  getStrategyVersions,
+  diffStrategyVersions,
+  rollbackStrategy,
+  exportStrategy,
+  importStrategy,
+  getStrategyTemplates,
+  createStrategyFromTemplate,
+  getPrompts,
+} from '../lib/api';
+import type {
+  ConfigChange,
+  DebatePanelConfig,
+  EntryExecutionConfig,
+  ReflectionConfig,
+  Strategy,
+  StrategyConfig,
+  StrategyTemplate,
+  StrategyVersion,
+} from '../types';
 import {
   Plus,
   Pencil,
@@ -24,6 +49,10 @@ import {
   X,
   Download,
   Upload,
+  History,
+  RotateCcw,
+  FileUp,
+  LayoutTemplate,
 } from 'lucide-react';
 import type { LucideIcon } from 'lucide-react';
 import { Button } from '@/components/ui/button';
@@ -106,9 +135,25 @@ export default function Strategies() {
   const { confirm, ConfirmDialog } = useConfirm();
   const { alert, AlertDialog } = useAlert();
 
+  // Personalities a debate participant can take, including custom ones
+  const [personalityOptions, setPersonalityOptions] = useState([
+    { id: 'bull', name: 'Bull' },
+    { id: 'bear', name: 'Bear' },
+    { id: 'analyst', name: 'Analyst' },
+    { id: 'contrarian', name: 'Contrarian' },
+    { id: 'risk_manager', name: 'Risk Manager' },
+  ]);
+  const [promptVariants, setPromptVariants] = useState<string[]>(['default']);
+
   useEffect(() => {
     loadStrategies();
     loadDefaultConfig();
+    getDebatePersonalities()
+      .then((res) => Array.isArray(res.data) && res.data.length > 0 && setPersonalityOptions(res.data))
+      .catch(() => {});
+    getPrompts()
+      .then((res) => Array.isArray(res.data.variants) && res.data.variants.length > 0 && setPromptVariants(res.data.variants))
+      .catch(() => {});
   }, []);
 
   const loadStrategies = async () => {
@@ -193,10 +238,218 @@ export default function Strategies() {
     }
   };
 
+  // Version history dialog
+  const [historyStrategy, setHistoryStrategy] = useState<Strategy | null>(null);
+  const [versions, setVersions] = useState<StrategyVersion[]>([]);
+  const [diffVersion, setDiffVersion] = useState<number | null>(null);
+  const [diffChanges, setDiffChanges] = useState<ConfigChange[]>([]);
+
+  const openHistory = async (strategy: Strategy) => {
+    setHistoryStrategy(strategy);
+    setDiffVersion(null);
+    setDiffChanges([]);
+    try {
+      const res = await getStrategyVersions(strategy.id);
+      setVersions(res.data.versions || []);
+    } catch (err) {
+      console.error('Failed to load versions:', err);
+    }
+  };
+
+  const showDiff = async (version: number) => {
+    if (!historyStrategy) return;
+    try {
+      const res = await diffStrategyVersions(historyStrategy.id, version);
+      setDiffVersion(version);
+      setDiffChanges(res.data.changes || []);
+    } catch (err) {
+      console.error('Failed to diff versions:', err);
+    }
+  };
+
+  const handleRollback = async (version: number) => {
+    if (!historyStrategy) return;
+    const confirmed = await confirm({
+      title: `Roll back to v${version}`,
+      description: 'Running traders switch to this config immediately. The rollback is saved as a new version.',
+      confirmText: 'Roll back',
+      variant: 'warning',
+    });
+    if (!confirmed) return;
+    try {
+      const res = await rollbackStrategy(historyStrategy.id, version);
+      await loadStrategies();
+      await openHistory(res.data);
+    } catch (err: any) {
+      alert({
+        title: 'Error',
+        description: err.response?.data?.error || 'Failed to roll back strategy',
+        variant: 'danger',
+      });
+    }
+  };
+
+  // Download a saved strategy in the server's portable format
+  const handleDownload = async (strategy: Strategy, format: 'json' | 'yaml') => {
+    try {
+      const res = await exportStrategy(strategy.id, format);
+      const blob = new Blob([res.data], { type: format === 'yaml' ? 'application/yaml' : 'application/json' });
+      const url = URL.createObjectURL(blob);
+      const a = document.createElement('a');
+      a.href = url;
+      a.download = `strategy-${strategy.name.replace(/\s+/g, '-').toLowerCase()}-v${strategy.version ?? 1}.${format}`;
+      document.body.appendChild(a);
+      a.click();
+      document.body.removeChild(a);
+      URL.revokeObjectURL(url);
+    } catch (err: any) {
+      alert({
+        title: 'Export Failed',
+        description: err.response?.data?.error || 'Failed to export strategy',
+        variant: 'danger',
+      });
+    }
+  };
+
+  // Create a new strategy from an exported JSON or YAML file
+  const handleUpload = () => {
+    const input = document.createElement('input');
+    input.type = 'file';
+    input.accept = '.json,.yaml,.yml';
+    input.onchange = async (e) => {
+      const file = (e.target as HTMLInputElement).files?.[0];
+      if (!file) return;
+      try {
+        const format = file.name.endsWith('.json') ? 'json' : 'yaml';
+        const res = await importStrategy(await file.text(), format);
+        await loadStrategies();
+        alert({
+          title: 'Imported!',
+          description: `Strategy "${res.data.name}" created from ${file.name}.`,
+        });
+      } catch (err: any) {
+        alert({
+          title: 'Import Failed',
+          description: err.response?.data?.error || 'Failed to import strategy',
+          variant: 'danger',
+        });
+      }
+    };
+    input.click();
+  };
+
+  // Template library dialog
+  const [templates, setTemplates] = useState<StrategyTemplate[]>([]);
+  const [showTemplates, setShowTemplates] = useState(false);
+
+  const openTemplates = async () => {
+    setShowTemplates(true);
+    try {
+      const res = await getStrategyTemplates();
+      setTemplates(res.data.templates || []);
+    } catch (err) {
+      console.error('Failed to load templates:', err);
+    }
+  };
+
+  const handleUseTemplate = async (template: StrategyTemplate) => {
+    try {
+      await createStrategyFromTemplate(template.id);
+      setShowTemplates(false);
+      loadStrategies();
+    } catch (err: any) {
+      alert({
+        title: 'Error',
+        description: err.response?.data?.error || 'Failed to create strategy from template',
+        variant: 'danger',
+      });
+    }
+  };
+
+  const formatConfigValue = (value: unknown) =>
+    value === undefined || value === null ? '—' : typeof value === 'object' ? JSON.stringify(value) : String(value);
+
   const toggleSection = (section: string) => {
     setExpandedSections((prev) => ({ ...prev, [section]: !prev[section] }));
   };
 
+  // Entry execution settings may be missing on strategies saved before they existed
+  const entryExecution: EntryExecutionConfig = {
+    order_type: 'market',
+    price_source: 'best',
+    timeout_secs: 30,
+    timeout_action: 'market',
+    max_chase_pct: 0.5,
+    ...editingStrategy?.config.entry_execution,
+  };
+
+  const updateEntryExecution = (patch: Partial<EntryExecutionConfig>) => {
+    if (!editingStrategy) return;
+    setEditingStrategy({
+      ...editingStrategy,
+      config: {
+        ...editingStrategy.config,
+        entry_execution: { ...entryExecution, ...patch },
+      },
+    });
+  };
+
+  // Reflection settings may be missing on strategies saved before they existed
+  const reflection: ReflectionConfig = {
+    enabled: false,
+    interval_minutes: 60,
+    lookback_trades: 100,
+    min_trades: 3,
+    max_lessons: 5,
+    llm_summary: false,
+    ...editingStrategy?.config.reflection,
+  };
+
+  const updateReflection = (patch: Partial<ReflectionConfig>) => {
+    if (!editingStrategy) return;
+    setEditingStrategy({
+      ...editingStrategy,
+      config: {
+        ...editingStrategy.config,
+        reflection: { ...reflection, ...patch },
+      },
+    });
+  };
+
+  // Debate panel used in debate decision mode
+  const debatePanel: DebatePanelConfig = {
+    participants: [],
+    max_rounds: 2,
+    language: 'en-US',
+    ...editingStrategy?.config.debate,
+  };
+  const [newParticipantModel, setNewParticipantModel] = useState('');
+  const [newParticipantPersonality, setNewParticipantPersonality] = useState('analyst');
+  const [newParticipantProvider, setNewParticipantProvider] = useState('openrouter');
+
+  const updateDebatePanel = (patch: Partial<DebatePanelConfig>) => {
+    if (!editingStrategy) return;
+    setEditingStrategy({
+      ...editingStrategy,
+      config: {
+        ...editingStrategy.config,
+        debate: { ...debatePanel, ...patch },
+      },
+    });
+  };
+
+  const addDebateParticipant = () => {
+    const model = newParticipantModel.trim();
+    if (!model) return;
+    updateDebatePanel({
+      participants: [
+        ...debatePanel.participants,
+        { ai_model_id: model, ai_model_name: model, provider: newParticipantProvider, personality: newParticipantPersonality },
+      ],
+    });
+    setNewParticipantModel('');
+  };
+
   // Export strategy config to JSON file
   const handleExport = () => {
     if (!editingStrategy) return;
@@ -299,6 +552,12 @@ export default function Strategies() {
           <Button variant="outline" size="icon" onClick={loadStrategies} className="glass">
             <RefreshCw className="h-4 w-4" />
           </Button>
+          <Button variant="outline" size="icon" onClick={handleUpload} className="glass" title="Import strategy (JSON or YAML)">
+            <FileUp className="h-4 w-4" />
+          </Button>
+          <Button variant="outline" size="icon" onClick={openTemplates} className="glass" title="New from template">
+            <LayoutTemplate className="h-4 w-4" />
+          </Button>
           <Button onClick={handleCreate} disabled={!defaultConfig} className="hidden sm:flex">
             <Plus className="w-4 h-4 mr-2" />
             New Strategy
@@ -333,6 +592,7 @@ export default function Strategies() {
                         <Target className="w-4 h-4 text-primary" />
                       </div>
                       <h3 className="font-semibold text-lg">{strategy.name}</h3>
+                      {strategy.version ? <GlowBadge variant="secondary">v{strategy.version}</GlowBadge> : null}
                       <GlowBadge variant={strategy.is_active ? 'success' : 'secondary'}>
                         {strategy.is_active ? 'Active' : 'Inactive'}
                       </GlowBadge>
@@ -401,6 +661,24 @@ export default function Strategies() {
                     >
                       <Pencil className="h-4 w-4" />
                     </Button>
+                    <Button
+                      variant="outline"
+                      size="icon"
+                      className="glass"
+                      title="Version history"
+                      onClick={() => openHistory(strategy)}
+                    >
+                      <History className="h-4 w-4" />
+                    </Button>
+                    <Button
+                      variant="outline"
+                      size="icon"
+                      className="glass"
+                      title="Export as YAML"
+                      onClick={() => handleDownload(strategy, 'yaml')}
+                    >
+                      <Download className="h-4 w-4" />
+                    </Button>
                     <Button
                       variant="outline"
                       size="icon"
@@ -417,6 +695,93 @@ export default function Strategies() {
         )}
       </div>
 
+      {/* Template Library */}
+      <Dialog open={showTemplates} onOpenChange={setShowTemplates}>
+        <DialogContent className="max-w-2xl">
+          <DialogHeader>
+            <DialogTitle className="flex items-center gap-2">
+              <LayoutTemplate className="w-5 h-5 text-primary" />
+              Strategy Templates
+            </DialogTitle>
+          </DialogHeader>
+          <div className="space-y-3 max-h-[60vh] overflow-y-auto">
+            {templates.map((t) => (
+              <div key={t.id} className="flex items-start justify-between gap-4 p-3 rounded-lg bg-white/5">
+                <div>
+                  <div className="font-medium">{t.name}</div>
+                  <p className="text-sm text-muted-foreground">{t.description}</p>
+                  <div className="flex flex-wrap gap-2 mt-2 text-xs text-muted-foreground">
+                    <span>{t.config.trading_interval}m interval</span>
+                    <span>·</span>
+                    <span>{t.config.risk_control.max_positions} positions</span>
+                    <span>·</span>
+                    <span>{t.config.risk_control.max_leverage}x max leverage</span>
+                  </div>
+                </div>
+                <Button size="sm" onClick={() => handleUseTemplate(t)}>
+                  <Plus className="w-4 h-4 mr-1" />
+                  Use
+                </Button>
+              </div>
+            ))}
+          </div>
+        </DialogContent>
+      </Dialog>
+
+      {/* Version History */}
+      <Dialog open={!!historyStrategy} onOpenChange={(open) => !open && setHistoryStrategy(null)}>
+        <DialogContent className="w-[95vw] max-w-3xl glass-card border-white/10 max-h-[85vh] overflow-y-auto">
+          <DialogHeader>
+            <DialogTitle className="flex items-center gap-2">
+              <History className="w-5 h-5 text-primary" />
+              {historyStrategy?.name} — Version History
+            </DialogTitle>
+          </DialogHeader>
+          <div className="space-y-2 mt-2">
+            {versions.map((v) => {
+              const current = v.version === versions[0]?.version;
+              return (
+                <div key={v.id} className="p-3 rounded-lg bg-white/5 space-y-2">
+                  <div className="flex items-center justify-between gap-2">
+                    <div className="flex items-center gap-2 text-sm">
+                      <GlowBadge variant={current ? 'success' : 'secondary'}>v{v.version}</GlowBadge>
+                      <span className="text-muted-foreground">{v.note}</span>
+                      <span className="text-xs text-muted-foreground">{new Date(v.created_at).toLocaleString()}</span>
+                    </div>
+                    {!current && (
+                      <div className="flex gap-2">
+                        <Button variant="ghost" size="sm" onClick={() => showDiff(v.version)}>
+                          Diff vs current
+                        </Button>
+                        <Button variant="outline" size="sm" className="glass" onClick={() => handleRollback(v.version)}>
+                          <RotateCcw className="w-3 h-3 mr-1" />
+                          Roll back
+                        </Button>
+                      </div>
+                    )}
+                  </div>
+                  {diffVersion === v.version && (
+                    <div className="text-xs font-mono space-y-1">
+                      {diffChanges.length === 0 ? (
+                        <p className="text-muted-foreground">Config is identical to the current version.</p>
+                      ) : (
+                        diffChanges.map((c) => (
+                          <div key={c.path} className="grid grid-cols-3 gap-2">
+                            <span className="text-muted-foreground truncate">{c.path}</span>
+                            <span className="text-red-400 truncate">{formatConfigValue(c.from)}</span>
+                            <span className="text-green-400 truncate">{formatConfigValue(c.to)}</span>
+                          </div>
+                        ))
+                      )}
+                    </div>
+                  )}
+                </div>
+              );
+            })}
+          </div>
+        </DialogContent>
+      </Dialog>
+
       {/* Confirmation and Alert Dialogs */}
       {ConfirmDialog}
       {AlertDialog}
@@ -1099,6 +1464,96 @@ export default function Strategies() {
                         </div>
                       </div>
 
+                      {/* Confidence Calibration */}
+                      <div className="pt-4 border-t border-white/10 mt-4 space-y-4">
+                        <div className="flex items-center gap-2">
+                          <Target className="w-4 h-4 text-primary" />
+                          <h4 className="font-medium text-sm text-primary">Confidence Calibration</h4>
+                        </div>
+                        <p className="text-xs text-muted-foreground">
+                          Checks the AI's stated confidence against the trades it opened. Applies once the model has enough closed trades.
+                        </p>
+
+                        <div className="grid grid-cols-1 sm:grid-cols-3 gap-4">
+                          <div className="space-y-2">
+                            <Label>Mode</Label>
+                            <Select
+                              value={editingStrategy.config.risk_control.confidence_calibration || 'off'}
+                              onValueChange={(v) => setEditingStrategy({
+                                ...editingStrategy,
+                                config: {
+                                  ...editingStrategy.config,
+                                  risk_control: {
+                                    ...editingStrategy.config.risk_control,
+                                    confidence_calibration: v === 'off' ? '' : (v as 'calibrated' | 'auto_threshold')
+                                  }
+                                }
+                              })}
+                            >
+                              <SelectTrigger className="glass">
+                                <SelectValue />
+                              </SelectTrigger>
+                              <SelectContent>
+                                <SelectItem value="off">Off (stated confidence)</SelectItem>
+                                <SelectItem value="calibrated">Gate on calibrated probability</SelectItem>
+                                <SelectItem value="auto_threshold">Auto-raise min confidence</SelectItem>
+                              </SelectContent>
+                            </Select>
+                          </div>
+                          <div className="space-y-2">
+                            <Label>Min Closed Trades</Label>
+                            <Input
+                              type="number"
+                              value={editingStrategy.config.risk_control.calibration_min_trades ?? 20}
+                              onChange={(e) => setEditingStrategy({
+                                ...editingStrategy,
+                                config: {
+                                  ...editingStrategy.config,
+                                  risk_control: { ...editingStrategy.config.risk_control, calibration_min_trades: parseInt(e.target.value) }
+                                }
+                              })}
+                              className="glass"
+                              placeholder="Default: 20"
+                            />
+                          </div>
+                          <div className="space-y-2">
+                            <Label>Target Win Rate %</Label>
+                            <Input
+                              type="number"
+                              step="1"
+                              value={editingStrategy.config.risk_control.calibration_target_hit_rate ?? 50}
+                              onChange={(e) => setEditingStrategy({
+                                ...editingStrategy,
+                                config: {
+                                  ...editingStrategy.config,
+                                  risk_control: { ...editingStrategy.config.risk_control, calibration_target_hit_rate: parseFloat(e.target.value) }
+                                }
+                              })}
+                              className="glass"
+                              placeholder="Default: 50"
+                            />
+                            <p className="text-xs text-muted-foreground">Used by auto-raise</p>
+                          </div>
+                        </div>
+
+                        <label className="flex items-center gap-3 p-3 rounded-lg bg-primary/5 border border-primary/20 cursor-pointer hover:bg-primary/10 transition-colors">
+                          <Checkbox
+                            checked={editingStrategy.config.risk_control.calibrated_sizing ?? false}
+                            onCheckedChange={(c) => setEditingStrategy({
+                              ...editingStrategy,
+                              config: {
+                                ...editingStrategy.config,
+                                risk_control: { ...editingStrategy.config.risk_control, calibrated_sizing: !!c }
+                              }
+                            })}
+                          />
+                          <div>
+                            <span className="font-medium">Calibrated Sizing</span>
+                            <p className="text-xs text-muted-foreground">Shrink new positions when the model has been overconfident</p>
+                          </div>
+                        </label>
+                      </div>
+
                       {/* Emergency Shutdown */}
                       <div className="pt-4 border-t border-white/10 mt-4 space-y-4">
                         <div className="flex items-center gap-2">
@@ -1345,6 +1800,113 @@ export default function Strategies() {
                           )}
                         </div>
 
+                        {/* Hedge Mode */}
+                        <div className="p-4 rounded-lg bg-sky-400/5 border border-sky-400/20 space-y-3">
+                          <label className="flex items-center gap-3 cursor-pointer">
+                            <Checkbox
+                              checked={editingStrategy.config.risk_control.enable_hedge_mode ?? false}
+                              onCheckedChange={(c) => setEditingStrategy({
+                                ...editingStrategy,
+                                config: {
+                                  ...editingStrategy.config,
+                                  risk_control: {
+                                    ...editingStrategy.config.risk_control,
+                                    enable_hedge_mode: !!c
+                                  }
+                                }
+                              })}
+                              className="data-[state=checked]:bg-sky-400 data-[state=checked]:border-sky-400 data-[state=checked]:text-black"
+                            />
+                            <div>
+                              <span className="font-medium text-sky-300">Hedge Mode</span>
+                              <p className="text-xs text-muted-foreground">Allow a LONG and a SHORT on the same symbol (account switches to hedge mode on start; requires no open positions/orders)</p>
+                            </div>
+                          </label>
+                        </div>
+
+                        {/* Entry Orders */}
+                        <div className="p-4 rounded-lg bg-teal-400/5 border border-teal-400/20 space-y-3">
+                          <div>
+                            <span className="font-medium text-teal-300">Entry Orders</span>
+                            <p className="text-xs text-muted-foreground">Limit/post-only entries save taker fees; SL/TP are placed once the entry fills</p>
+                          </div>
+                          <div className="grid grid-cols-1 sm:grid-cols-2 gap-3">
+                            <div className="space-y-2">
+                              <Label className="text-xs">Order Type</Label>
+                              <Select
+                                value={entryExecution.order_type}
+                                onValueChange={(v) => updateEntryExecution({ order_type: v as EntryExecutionConfig['order_type'] })}
+                              >
+                                <SelectTrigger className="glass h-8 text-sm">
+                                  <SelectValue />
+                                </SelectTrigger>
+                                <SelectContent>
+                                  <SelectItem value="market">Market</SelectItem>
+                                  <SelectItem value="limit">Limit (GTC)</SelectItem>
+                                  <SelectItem value="post_only">Post-Only (GTX)</SelectItem>
+                                </SelectContent>
+                              </Select>
+                            </div>
+                            {entryExecution.order_type !== 'market' && (
+                              <>
+                                <div className="space-y-2">
+                                  <Label className="text-xs">Limit Price</Label>
+                                  <Select
+                                    value={entryExecution.price_source}
+                                    onValueChange={(v) => updateEntryExecution({ price_source: v as EntryExecutionConfig['price_source'] })}
+                                  >
+                                    <SelectTrigger className="glass h-8 text-sm">
+                                      <SelectValue />
+                                    </SelectTrigger>
+                                    <SelectContent>
+                                      <SelectItem value="best">Best Bid/Ask</SelectItem>
+                                      <SelectItem value="ai">AI Suggested</SelectItem>
+                                    </SelectContent>
+                                  </Select>
+                                </div>
+                                <div className="space-y-2">
+                                  <Label className="text-xs">Timeout (secs)</Label>
+                                  <Input
+                                    type="number"
+                                    min="1"
+                                    value={entryExecution.timeout_secs}
+                                    onChange={(e) => updateEntryExecution({ timeout_secs: parseInt(e.target.value) })}
+                                    className="glass h-8 text-sm"
+                                    placeholder="30"
+                                  />
+                                </div>
+                                <div className="space-y-2">
+                                  <Label className="text-xs">On Timeout</Label>
+                                  <Select
+                                    value={entryExecution.timeout_action}
+                                    onValueChange={(v) => updateEntryExecution({ timeout_action: v as EntryExecutionConfig['timeout_action'] })}
+                                  >
+                                    <SelectTrigger className="glass h-8 text-sm">
+                                      <SelectValue />
+                                    </SelectTrigger>
+                                    <SelectContent>
+                                      <SelectItem value="market">Convert to Market</SelectItem>
+                                      <SelectItem value="cancel">Cancel</SelectItem>
+                                    </SelectContent>
+                                  </Select>
+                                </div>
+                                <div className="space-y-2">
+                                  <Label className="text-xs">Cancel if Price Runs Away (%)</Label>
+                                  <Input
+                                    type="number"
+                                    step="0.1"
+                                    min="0"
+                                    value={entryExecution.max_chase_pct}
+                                    onChange={(e) => updateEntryExecution({ max_chase_pct: parseFloat(e.target.value) })}
+                                    className="glass h-8 text-sm"
+                                    placeholder="0.5"
+                                  />
+                                </div>
+                              </>
+                            )}
+                          </div>
+                        </div>
+
                         {/* Noise Zone Protection */}
                         <div className="space-y-3">
                           <label className="flex items-start space-x-3 cursor-pointer">
@@ -1463,6 +2025,275 @@ export default function Strategies() {
                   {/* AI Settings */}
                   <CollapsibleSection title="AI Settings" icon={Brain} isExpanded={expandedSections.aiPrompt} onToggle={() => toggleSection('aiPrompt')}>
                     <div className="space-y-4">
+                      {/* Decision Mode */}
+                      <div className="space-y-2">
+                        <Label>Decision Mode</Label>
+                        <Select
+                          value={editingStrategy.config.decision_mode || 'single_model'}
+                          onValueChange={(v) => setEditingStrategy({
+                            ...editingStrategy,
+                            config: { ...editingStrategy.config, decision_mode: v as StrategyConfig['decision_mode'] }
+                          })}
+                        >
+                          <SelectTrigger className="glass">
+                            <SelectValue />
+                          </SelectTrigger>
+                          <SelectContent>
+                            <SelectItem value="single_model">Single Model</SelectItem>
+                            <SelectItem value="debate">Debate Panel (multi-model consensus)</SelectItem>
+                          </SelectContent>
+                        </Select>
+                        <p className="text-xs text-muted-foreground">
+                          In debate mode the panel debates all pairs each cycle and its consensus goes through the same risk controls. Each cycle costs one AI call per participant per round, plus votes.
+                        </p>
+                      </div>
+
+                      {editingStrategy.config.decision_mode === 'debate' && (
+                        <div className="p-4 rounded-lg bg-violet-400/5 border border-violet-400/20 space-y-3">
+                          <div className="grid grid-cols-1 sm:grid-cols-2 gap-3">
+                            <div className="space-y-2">
+                              <Label className="text-xs">Rounds</Label>
+                              <Input
+                                type="number"
+                                min="1"
+                                max="5"
+                                value={debatePanel.max_rounds}
+                                onChange={(e) => updateDebatePanel({ max_rounds: parseInt(e.target.value) })}
+                                className="glass h-8 text-sm"
+                              />
+                            </div>
+                            <div className="space-y-2">
+                              <Label className="text-xs">Language</Label>
+                              <Select value={debatePanel.language} onValueChange={(v) => updateDebatePanel({ language: v })}>
+                                <SelectTrigger className="glass h-8 text-sm">
+                                  <SelectValue />
+                                </SelectTrigger>
+                                <SelectContent>
+                                  <SelectItem value="en-US">English</SelectItem>
+                                  <SelectItem value="zh-CN">Chinese</SelectItem>
+                                </SelectContent>
+                              </Select>
+                            </div>
+                          </div>
+
+                          <div className="space-y-2">
+                            <Label className="text-xs">Consensus Mode</Label>
+                            <Select
+                              value={debatePanel.consensus_mode || 'confidence'}
+                              onValueChange={(v) => updateDebatePanel({ consensus_mode: v as DebatePanelConfig['consensus_mode'] })}
+                            >
+                              <SelectTrigger className="glass h-8 text-sm">
+                                <SelectValue />
+                              </SelectTrigger>
+                              <SelectContent>
+                                <SelectItem value="confidence">Confidence-weighted</SelectItem>
+                                <SelectItem value="accuracy">Accuracy-weighted (hit rate)</SelectItem>
+                                <SelectItem value="calibrated">Calibrated (Brier)</SelectItem>
+                                <SelectItem value="median">Median</SelectItem>
+                                <SelectItem value="unanimous">Unanimous</SelectItem>
+                              </SelectContent>
+                            </Select>
+                          </div>
+
+                          <div className="grid grid-cols-1 sm:grid-cols-2 gap-3">
+                            <div className="space-y-2">
+                              <Label className="text-xs">End rounds early when</Label>
+                              <Select
+                                value={(debatePanel.convergence_threshold ?? 0).toString()}
+                                onValueChange={(v) => updateDebatePanel({ convergence_threshold: Number(v) })}
+                              >
+                                <SelectTrigger className="glass h-8 text-sm">
+                                  <SelectValue />
+                                </SelectTrigger>
+                                <SelectContent>
+                                  <SelectItem value="1">All agree</SelectItem>
+                                  <SelectItem value="0.75">75% agree</SelectItem>
+                                  <SelectItem value="0.6">60% agree</SelectItem>
+                                  <SelectItem value="0">Never</SelectItem>
+                                </SelectContent>
+                              </Select>
+                            </div>
+                            <div className="space-y-2">
+                              <Label className="text-xs">Token budget per cycle</Label>
+                              <Input
+                                type="number"
+                                min="0"
+                                step="1000"
+                                placeholder="0 = unlimited"
+                                value={debatePanel.token_budget || ''}
+                                onChange={(e) => updateDebatePanel({ token_budget: Math.max(0, parseInt(e.target.value) || 0) })}
+                                className="glass h-8 text-sm"
+                              />
+                            </div>
+                            <div className="space-y-2">
+                              <Label className="text-xs">Cost budget per cycle (USD)</Label>
+                              <Input
+                                type="number"
+                                min="0"
+                                step="0.01"
+                                placeholder="0 = unlimited"
+                                value={debatePanel.cost_budget_usd || ''}
+                                onChange={(e) => updateDebatePanel({ cost_budget_usd: Math.max(0, parseFloat(e.target.value) || 0) })}
+                                className="glass h-8 text-sm"
+                              />
+                            </div>
+                          </div>
+
+                          <div className="space-y-2">
+                            <Label className="text-xs">Judge model (optional)</Label>
+                            <Input
+                              value={debatePanel.judge?.ai_model_id ?? ''}
+                              onChange={(e) => {
+                                const model = e.target.value.trim();
+                                updateDebatePanel({
+                                  judge: model
+                                    ? { ai_model_id: model, ai_model_name: model, provider: 'openrouter', personality: 'judge' }
+                                    : undefined,
+                                });
+                              }}
+                              className="glass h-8 text-sm"
+                              placeholder="Rules on the debate; empty = consensus only"
+                            />
+                          </div>
+
+                          <Label className="text-xs">Participants ({debatePanel.participants.length}, min 2)</Label>
+                          {debatePanel.participants.map((p, i) => (
+                            <div key={i} className="flex items-center justify-between text-sm p-2 rounded bg-white/5">
+                              <span className="font-mono text-xs">{p.ai_model_name}</span>
+                              <div className="flex items-center gap-2">
+                                <span className="text-xs text-muted-foreground">
+                                  {p.provider && p.provider !== 'openrouter' ? `${p.provider} · ` : ''}{p.personality}
+                                </span>
+                                <button
+                                  type="button"
+                                  onClick={() => updateDebatePanel({ participants: debatePanel.participants.filter((_, j) => j !== i) })}
+                                  className="text-muted-foreground hover:text-red-400"
+                                >
+                                  <X className="w-3 h-3" />
+                                </button>
+                              </div>
+                            </div>
+                          ))}
+                          <div className="flex gap-2">
+                            <Input
+                              value={newParticipantModel}
+                              onChange={(e) => setNewParticipantModel(e.target.value)}
+                              className="glass h-8 text-sm flex-1"
+                              placeholder="Model ID (e.g. deepseek/deepseek-v3.2)"
+                            />
+                            <Select value={newParticipantProvider} onValueChange={setNewParticipantProvider}>
+                              <SelectTrigger className="glass h-8 text-sm w-32">
+                                <SelectValue />
+                              </SelectTrigger>
+                              <SelectContent>
+                                <SelectItem value="openrouter">OpenRouter</SelectItem>
+                                <SelectItem value="openai">OpenAI</SelectItem>
+                                <SelectItem value="anthropic">Anthropic</SelectItem>
+                                <SelectItem value="deepseek">DeepSeek</SelectItem>
+                                <SelectItem value="ollama">Ollama</SelectItem>
+                                <SelectItem value="local">Local (OpenAI API)</SelectItem>
+                              </SelectContent>
+                            </Select>
+                            <Select value={newParticipantPersonality} onValueChange={setNewParticipantPersonality}>
+                              <SelectTrigger className="glass h-8 text-sm w-40">
+                                <SelectValue />
+                              </SelectTrigger>
+                              <SelectContent>
+                                {personalityOptions.map((p) => (
+                                  <SelectItem key={p.id} value={p.id}>{p.name}</SelectItem>
+                                ))}
+                              </SelectContent>
+                            </Select>
+                            <Button type="button" variant="outline" size="sm" onClick={addDebateParticipant}>
+                              <Plus className="w-4 h-4" />
+                            </Button>
+                          </div>
+                        </div>
+                      )}
+
+                      {/* Prompt Variant */}
+                      <div className="space-y-2">
+                        <Label>Prompt Variant</Label>
+                        <p className="text-sm text-muted-foreground mb-2">
+                          Prompt template set used for this strategy's decisions and debates. Add variants under the server's prompts directory.
+                        </p>
+                        <Select
+                          value={editingStrategy.config.prompt_variant || 'default'}
+                          onValueChange={(v) => setEditingStrategy({
+                            ...editingStrategy,
+                            config: { ...editingStrategy.config, prompt_variant: v === 'default' ? '' : v }
+                          })}
+                        >
+                          <SelectTrigger className="glass">
+                            <SelectValue />
+                          </SelectTrigger>
+                          <SelectContent>
+                            {promptVariants.map((v) => (
+                              <SelectItem key={v} value={v}>{v}</SelectItem>
+                            ))}
+                          </SelectContent>
+                        </Select>
+                      </div>
+
+                      {/* Self-Reflection */}
+                      <div className="p-4 rounded-lg bg-violet-400/5 border border-violet-400/20 space-y-3">
+                        <label className="flex items-center gap-3 cursor-pointer">
+                          <Checkbox
+                            checked={reflection.enabled}
+                            onCheckedChange={(c) => updateReflection({ enabled: !!c })}
+                            className="data-[state=checked]:bg-violet-400 data-[state=checked]:border-violet-400 data-[state=checked]:text-black"
+                          />
+                          <div>
+                            <span className="font-medium text-violet-300">Learn From Closed Trades</span>
+                            <p className="text-xs text-muted-foreground">Summarize closed trades per symbol, direction and setup, and add the lessons to each prompt</p>
+                          </div>
+                        </label>
+                        {reflection.enabled && (
+                          <div className="grid grid-cols-1 sm:grid-cols-3 gap-3">
+                            <div className="space-y-2">
+                              <Label className="text-xs">Reflect Every (min)</Label>
+                              <Input
+                                type="number"
+                                min="1"
+                                value={reflection.interval_minutes}
+                                onChange={(e) => updateReflection({ interval_minutes: parseInt(e.target.value) })}
+                                className="glass h-8 text-sm"
+                                placeholder="60"
+                              />
+                            </div>
+                            <div className="space-y-2">
+                              <Label className="text-xs">Min Trades per Lesson</Label>
+                              <Input
+                                type="number"
+                                min="1"
+                                value={reflection.min_trades}
+                                onChange={(e) => updateReflection({ min_trades: parseInt(e.target.value) })}
+                                className="glass h-8 text-sm"
+                                placeholder="3"
+                              />
+                            </div>
+                            <div className="space-y-2">
+                              <Label className="text-xs">Lessons per Prompt</Label>
+                              <Input
+                                type="number"
+                                min="1"
+                                value={reflection.max_lessons}
+                                onChange={(e) => updateReflection({ max_lessons: parseInt(e.target.value) })}
+                                className="glass h-8 text-sm"
+                                placeholder="5"
+                              />
+                            </div>
+                            <label className="flex items-center gap-2 cursor-pointer sm:col-span-3">
+                              <Checkbox
+                                checked={reflection.llm_summary}
+                                onCheckedChange={(c) => updateReflection({ llm_summary: !!c })}
+                              />
+                              <span className="text-xs text-muted-foreground">Have the AI write the lessons (one extra AI call per pass)</span>
+                            </label>
+                          </div>
+                        )}
+                      </div>
+
                       {/* Custom Prompt */}
                       <div className="space-y-2">
                         <Label>Custom AI Prompt</Label>
@@ -0,0 +1,315 @@
+import { useEffect, useState } from 'react';
+import { motion } from 'framer-motion';
+import { Coins, RefreshCw, Plus, Trash2, PauseCircle, Hash, AlertTriangle } from 'lucide-react';
+import { getAIUsage, getAIBudgets, getAIPrices, setAIPrice, deleteAIPrice, getTraders } from '../lib/api';
+import type { AISpendRow, AIBudget, ModelPrice, Trader } from '../types';
+import { Button } from '@/components/ui/button';
+import { Input } from '@/components/ui/input';
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/components/ui/select';
+import { GlassCard } from '@/components/ui/glass-card';
+import { GlowBadge } from '@/components/ui/glow-badge';
+import { StatCard } from '@/components/ui/stat-card';
+import { useConfirm, useAlert } from '@/components/ui/confirm-modal';
+
+const GROUP_BY_OPTIONS = [
+  { id: 'trader', name: 'By Trader' },
+  { id: 'day', name: 'By Day' },
+  { id: 'model', name: 'By Model' },
+  { id: 'caller', name: 'By Caller' },
+];
+
+const RANGE_OPTIONS = [
+  { id: '7', name: 'Last 7 days' },
+  { id: '30', name: 'Last 30 days' },
+  { id: 'month', name: 'This month' },
+  { id: 'all', name: 'All time' },
+];
+
+// rangeStart returns the from= date (YYYY-MM-DD) for a range option
+const rangeStart = (range: string): string | undefined => {
+  const d = new Date();
+  if (range === 'all') return undefined;
+  if (range === 'month') d.setDate(1);
+  else d.setDate(d.getDate() - Number(range) + 1);
+  return `${d.getFullYear()}-${String(d.getMonth() + 1).padStart(2, '0')}-${String(d.getDate()).padStart(2, '0')}`;
+};
+
+export default function Usage() {
+  const [groupBy, setGroupBy] = useState('trader');
+  const [range, setRange] = useState('30');
+  const [rows, setRows] = useState<AISpendRow[]>([]);
+  const [total, setTotal] = useState(0);
+  const [budgets, setBudgets] = useState<AIBudget[]>([]);
+  const [prices, setPrices] = useState<ModelPrice[]>([]);
+  const [traders, setTraders] = useState<Trader[]>([]);
+  const [newPrice, setNewPrice] = useState<ModelPrice>({ model: '', prompt_per_mtok: 0, completion_per_mtok: 0 });
+  const [loading, setLoading] = useState(true);
+  const confirm = useConfirm();
+  const alert = useAlert();
+
+  const loadUsage = async () => {
+    try {
+      const res = await getAIUsage({ group_by: groupBy, from: rangeStart(range) });
+      setRows(res.data.rows || []);
+      setTotal(res.data.total_cost_usd || 0);
+    } catch (err) {
+      console.error('Failed to load AI usage:', err);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const loadBudgetsAndPrices = async () => {
+    try {
+      const [budgetsRes, pricesRes, tradersRes] = await Promise.all([getAIBudgets(), getAIPrices(), getTraders()]);
+      setBudgets(budgetsRes.data.budgets || []);
+      setPrices(pricesRes.data.prices || []);
+      setTraders(tradersRes.data.traders || []);
+    } catch (err) {
+      console.error('Failed to load AI budgets:', err);
+    }
+  };
+
+  useEffect(() => {
+    loadUsage();
+  }, [groupBy, range]);
+
+  useEffect(() => {
+    loadBudgetsAndPrices();
+  }, []);
+
+  const refresh = () => {
+    loadUsage();
+    loadBudgetsAndPrices();
+  };
+
+  const handleSavePrice = async (price: ModelPrice) => {
+    if (!price.model.trim()) {
+      await alert({ title: 'Missing model', description: 'Enter a model ID, e.g. deepseek/deepseek-chat', variant: 'warning' });
+      return;
+    }
+    try {
+      await setAIPrice({ ...price, model: price.model.trim() });
+      setNewPrice({ model: '', prompt_per_mtok: 0, completion_per_mtok: 0 });
+      loadBudgetsAndPrices();
+    } catch (err: any) {
+      await alert({ title: 'Save failed', description: err.response?.data?.error || 'Failed to save price', variant: 'danger' });
+    }
+  };
+
+  const handleDeletePrice = async (model: string) => {
+    const ok = await confirm({
+      title: 'Delete price',
+      description: `Calls to ${model} will be recorded at $0 until it's priced again. Past calls keep their cost.`,
+      confirmText: 'Delete',
+      variant: 'danger',
+    });
+    if (!ok) return;
+    await deleteAIPrice(model);
+    loadBudgetsAndPrices();
+  };
+
+  // Trader IDs are shown by name where we know it
+  const label = (row: AISpendRow) => {
+    if (groupBy === 'trader') {
+      if (!row.key) return 'Unattributed (backtests, system)';
+      return traders.find((t) => t.id === row.key)?.name || row.key;
+    }
+    return row.key || 'unknown';
+  };
+
+  const totalCalls = rows.reduce((sum, r) => sum + r.calls, 0);
+  const totalTokens = rows.reduce((sum, r) => sum + r.prompt_tokens + r.completion_tokens, 0);
+  const pausedCount = budgets.filter((b) => b.paused).length;
+
+  if (loading) {
+    return (
+      <div className="flex items-center justify-center h-full">
+        <span className="text-muted-foreground">Loading AI usage...</span>
+      </div>
+    );
+  }
+
+  return (
+    <div className="p-4 lg:p-6 space-y-4 lg:space-y-6">
+      {/* Header */}
+      <div className="flex flex-col sm:flex-row justify-between items-start sm:items-center gap-4">
+        <motion.div initial={{ opacity: 0, x: -20 }} animate={{ opacity: 1, x: 0 }}>
+          <h1 className="text-2xl lg:text-3xl font-bold text-gradient flex items-center gap-3">
+            <Coins className="w-6 h-6 lg:w-8 lg:h-8 text-amber-400" />
+            AI Usage
+          </h1>
+          <p className="text-sm lg:text-base text-muted-foreground">
+            LLM tokens and spend across traders, debates and backtests
+          </p>
+        </motion.div>
+
+        <div className="flex gap-2 w-full sm:w-auto">
+          <Select value={range} onValueChange={setRange}>
+            <SelectTrigger className="flex-1 sm:w-[150px] glass">
+              <SelectValue />
+            </SelectTrigger>
+            <SelectContent>
+              {RANGE_OPTIONS.map((o) => (
+                <SelectItem key={o.id} value={o.id}>{o.name}</SelectItem>
+              ))}
+            </SelectContent>
+          </Select>
+          <Select value={groupBy} onValueChange={setGroupBy}>
+            <SelectTrigger className="flex-1 sm:w-[150px] glass">
+              <SelectValue />
+            </SelectTrigger>
+            <SelectContent>
+              {GROUP_BY_OPTIONS.map((o) => (
+                <SelectItem key={o.id} value={o.id}>{o.name}</SelectItem>
+              ))}
+            </SelectContent>
+          </Select>
+          <Button variant="outline" size="icon" onClick={refresh} className="glass">
+            <RefreshCw className="h-4 w-4" />
+          </Button>
+        </div>
+      </div>
+
+      {/* Stats */}
+      <div className="grid gap-4 md:grid-cols-4">
+        <StatCard title="Spend" value={total} icon={Coins} iconClassName="bg-amber-500/20 text-amber-400" prefix="$" decimals={4} delay={0} />
+        <StatCard title="AI Calls" value={totalCalls} icon={Hash} decimals={0} delay={1} />
+        <StatCard title="Tokens" value={totalTokens} icon={Hash} decimals={0} delay={2} />
+        <StatCard title="Traders Paused" value={pausedCount} icon={PauseCircle} decimals={0} delay={3} />
+      </div>
+
+      {/* Spend breakdown */}
+      <GlassCard>
+        <h3 className="text-lg font-semibold mb-4">Spend {GROUP_BY_OPTIONS.find((o) => o.id === groupBy)?.name.toLowerCase()}</h3>
+        {rows.length === 0 ? (
+          <p className="text-sm text-muted-foreground">No AI calls recorded in this period</p>
+        ) : (
+          <div className="overflow-x-auto">
+            <table className="w-full text-sm">
+              <thead>
+                <tr className="text-left text-muted-foreground border-b border-white/10">
+                  <th className="py-2 pr-4 font-medium">{groupBy === 'day' ? 'Day' : groupBy === 'model' ? 'Model' : groupBy === 'caller' ? 'Caller' : 'Trader'}</th>
+                  <th className="py-2 pr-4 font-medium text-right">Calls</th>
+                  <th className="py-2 pr-4 font-medium text-right">Failed</th>
+                  <th className="py-2 pr-4 font-medium text-right">Prompt</th>
+                  <th className="py-2 pr-4 font-medium text-right">Completion</th>
+                  <th className="py-2 pr-4 font-medium text-right">Avg Latency</th>
+                  <th className="py-2 font-medium text-right">Cost</th>
+                </tr>
+              </thead>
+              <tbody>
+                {rows.map((row) => (
+                  <tr key={row.key} className="border-b border-white/5">
+                    <td className="py-2 pr-4 font-medium">{label(row)}</td>
+                    <td className="py-2 pr-4 text-right font-mono">{row.calls}</td>
+                    <td className={`py-2 pr-4 text-right font-mono ${row.failed_calls > 0 ? 'text-red-400' : ''}`}>{row.failed_calls}</td>
+                    <td className="py-2 pr-4 text-right font-mono">{row.prompt_tokens.toLocaleString()}</td>
+                    <td className="py-2 pr-4 text-right font-mono">{row.completion_tokens.toLocaleString()}</td>
+                    <td className="py-2 pr-4 text-right font-mono">{(row.avg_latency_ms / 1000).toFixed(1)}s</td>
+                    <td className="py-2 text-right font-mono">${row.cost_usd.toFixed(4)}</td>
+                  </tr>
+                ))}
+              </tbody>
+            </table>
+          </div>
+        )}
+      </GlassCard>
+
+      <div className="grid gap-4 lg:grid-cols-2">
+        {/* Budgets */}
+        <GlassCard>
+          <h3 className="text-lg font-semibold mb-1">Monthly Budgets</h3>
+          <p className="text-xs text-muted-foreground mb-4">Set a trader's budget in Config. Debates run for a trader count towards it.</p>
+          <div className="space-y-3">
+            {budgets.map((b) => {
+              const pct = b.budget_usd > 0 ? Math.min(100, (b.spent_usd / b.budget_usd) * 100) : 0;
+              return (
+                <div key={b.trader_id} className="p-3 rounded-lg bg-white/5">
+                  <div className="flex justify-between items-center mb-2">
+                    <span className="font-medium">{b.trader_name}</span>
+                    <div className="flex items-center gap-2">
+                      {b.paused && (
+                        <GlowBadge variant="danger">
+                          <AlertTriangle className="w-3 h-3 mr-1" />
+                          Paused
+                        </GlowBadge>
+                      )}
+                      <span className="font-mono text-sm">
+                        ${b.spent_usd.toFixed(2)}{b.budget_usd > 0 ? ` / $${b.budget_usd.toFixed(2)}` : ' (no limit)'}
+                      </span>
+                    </div>
+                  </div>
+                  {b.budget_usd > 0 && (
+                    <div className="h-1.5 rounded-full bg-white/10 overflow-hidden">
+                      <div
+                        className={`h-full ${b.paused ? 'bg-red-500' : pct > 80 ? 'bg-amber-500' : 'bg-green-500'}`}
+                        style={{ width: `${pct}%` }}
+                      />
+                    </div>
+                  )}
+                </div>
+              );
+            })}
+            {budgets.length === 0 && <p className="text-sm text-muted-foreground">No traders</p>}
+          </div>
+        </GlassCard>
+
+        {/* Price table */}
+        <GlassCard>
+          <h3 className="text-lg font-semibold mb-1">Model Prices</h3>
+          <p className="text-xs text-muted-foreground mb-4">
+            USD per million tokens. Applies to new calls; OpenRouter IDs also match without the vendor prefix.
+          </p>
+          <div className="space-y-2">
+            <div className="grid grid-cols-[1fr_90px_90px_36px] gap-2 text-xs text-muted-foreground">
+              <span>Model</span>
+              <span>Prompt</span>
+              <span>Completion</span>
+              <span />
+            </div>
+            {prices.map((p) => (
+              <div key={p.model} className="grid grid-cols-[1fr_90px_90px_36px] gap-2 items-center">
+                <span className="text-sm font-mono truncate" title={p.model}>{p.model}</span>
+                <span className="text-sm font-mono">${p.prompt_per_mtok}</span>
+                <span className="text-sm font-mono">${p.completion_per_mtok}</span>
+                <Button variant="ghost" size="icon" className="h-8 w-8 text-red-400" onClick={() => handleDeletePrice(p.model)}>
+                  <Trash2 className="h-4 w-4" />
+                </Button>
+              </div>
+            ))}
+            <div className="grid grid-cols-[1fr_90px_90px_36px] gap-2 items-center pt-2">
+              <Input
+                value={newPrice.model}
+                onChange={(e) => setNewPrice({ ...newPrice, model: e.target.value })}
+                className="glass h-8"
+                placeholder="deepseek/deepseek-chat"
+              />
+              <Input
+                type="number"
+                min={0}
+                step="0.01"
+                value={newPrice.prompt_per_mtok || ''}
+                onChange={(e) => setNewPrice({ ...newPrice, prompt_per_mtok: parseFloat(e.target.value) || 0 })}
+                className="glass h-8"
+                placeholder="0.27"
+              />
+              <Input
+                type="number"
+                min={0}
+                step="0.01"
+                value={newPrice.completion_per_mtok || ''}
+                onChange={(e) => setNewPrice({ ...newPrice, completion_per_mtok: parseFloat(e.target.value) || 0 })}
+                className="glass h-8"
+                placeholder="1.10"
+              />
+              <Button variant="outline" size="icon" className="glass h-8 w-8" onClick={() => handleSavePrice(newPrice)}>
+                <Plus className="h-4 w-4" />
+              </Button>
+            </div>
+          </div>
+        </GlassCard>
+      </div>
+    </div>
+  );
+}
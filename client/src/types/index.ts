@@ -4,20 +4,79 @@ export interface Strategy {
   description: string;
   is_active: boolean;
   config: StrategyConfig;
+  version_id?: number;
+  version?: number;
   created_at: string;
   updated_at: string;
 }
 
+// Immutable snapshot of a strategy, one per change
+export interface StrategyVersion {
+  id: number;
+  strategy_id: string;
+  version: number;
+  name: string;
+  description: string;
+  config: StrategyConfig;
+  note: string;
+  created_at: string;
+}
+
+export interface ConfigChange {
+  path: string;
+  from: unknown;
+  to: unknown;
+}
+
+export interface StrategyTemplate {
+  id: string;
+  name: string;
+  description: string;
+  config: StrategyConfig;
+}
+
 export interface StrategyConfig {
   coin_source: CoinSourceConfig;
   indicators: IndicatorConfig;
   risk_control: RiskControlConfig;
   ai: AIConfig;
+  entry_execution?: EntryExecutionConfig;
   custom_prompt: string;
+  prompt_variant?: string;
   trading_interval: number;
   turbo_mode: boolean;
   simple_mode?: boolean;
   trading_mode?: 'strategy' | 'copy_trade';
+  decision_mode?: 'single_model' | 'debate';
+  debate?: DebatePanelConfig;
+  reflection?: ReflectionConfig;
+}
+
+export interface ReflectionConfig {
+  enabled: boolean;
+  interval_minutes: number; // Minutes between reflection passes
+  lookback_trades: number; // Closed trades each pass looks at
+  min_trades: number; // Trades a symbol/direction/setup needs for a lesson
+  max_lessons: number; // Lessons added to each prompt
+  llm_summary: boolean; // AI writes the lessons instead of the stats summary
+}
+
+export interface DebatePanelConfig {
+  participants: DebateParticipantConfig[];
+  max_rounds: number;
+  language: string;
+  consensus_mode?: 'confidence' | 'accuracy' | 'calibrated' | 'median' | 'unanimous';
+  judge?: DebateParticipantConfig;
+  convergence_threshold?: number; // 0 = always run every round
+  token_budget?: number; // Tokens per cycle, 0 = unlimited
+  cost_budget_usd?: number; // USD per cycle from the AI price table, 0 = unlimited
+}
+
+export interface DebateParticipantConfig {
+  ai_model_id: string;
+  ai_model_name: string;
+  provider: string;
+  personality: string;
 }
 
 export interface AIConfig {
@@ -25,6 +84,106 @@ export interface AIConfig {
   reasoning_model: string;
 }
 
+export interface TradeLesson {
+  id: number;
+  trader_id: string;
+  symbol: string;
+  side: 'long' | 'short';
+  setup: string;
+  trades: number;
+  wins: number;
+  stop_outs: number;
+  loss_streak: number;
+  total_pnl: number;
+  avg_pnl_pct: number;
+  last_exit_time: string;
+  summary: string;
+  source: 'stats' | 'llm';
+  updated_at: string;
+}
+
+export interface CalibrationBucket {
+  min: number;
+  max: number;
+  trades: number;
+  wins: number;
+  hit_rate: number;
+  avg_confidence: number;
+  avg_pnl: number;
+}
+
+export interface CalibrationCurve {
+  ai_model: string;
+  trades: number;
+  wins: number;
+  hit_rate: number;
+  avg_confidence: number;
+  brier_score: number;
+  buckets: CalibrationBucket[];
+  suggested_min_confidence: number;
+}
+
+export interface PromptRef {
+  name: string;
+  variant: string;
+  language?: string;
+  version: number;
+  source: string;
+  hash: string;
+}
+
+export interface GuardVerdict {
+  guard: string;
+  verdict: 'pass' | 'block' | 'adjust';
+  detail?: string;
+}
+
+export interface AuditOrder {
+  purpose: 'entry' | 'close' | 'stop_loss' | 'take_profit';
+  order_id: number;
+  side: string;
+  type: string;
+  quantity: number;
+  price: number;
+  status?: string;
+}
+
+export interface DecisionAudit {
+  id: number;
+  trader_id: string;
+  decision_id: number;
+  symbol: string;
+  timestamp: string;
+  source: string;
+  ai_model: string;
+  debate_session_id?: string;
+  strategy_version_id: number;
+  system_prompt?: string;
+  user_prompt?: string;
+  prompts?: PromptRef[];
+  market_snapshot?: string;
+  raw_output?: string;
+  parse_path?: string;
+  decision?: unknown;
+  action: string;
+  confidence: number;
+  calibrated_confidence?: number;
+  guards: GuardVerdict[];
+  block_reason?: string;
+  error?: string;
+  executed: boolean;
+  orders: AuditOrder[];
+  realized_pnl: number;
+}
+
+export interface EntryExecutionConfig {
+  order_type: 'market' | 'limit' | 'post_only';
+  price_source: 'best' | 'ai';
+  timeout_secs: number;
+  timeout_action: 'market' | 'cancel';
+  max_chase_pct: number;
+}
+
 export interface CoinSourceConfig {
   source_type: string;
   static_coins: string[];
@@ -60,6 +219,11 @@ export interface RiskControlConfig {
   min_confidence: number;
   min_risk_reward_ratio: number;
   high_confidence_close_threshold?: number;
+  // Confidence Calibration
+  confidence_calibration?: '' | 'calibrated' | 'auto_threshold';
+  calibration_min_trades?: number;
+  calibration_target_hit_rate?: number;
+  calibrated_sizing?: boolean;
   max_daily_loss_pct?: number;
   max_drawdown_pct?: number;
   stop_trading_mins?: number;
@@ -82,6 +246,8 @@ export interface RiskControlConfig {
   noise_zone_lower_bound?: number;
   noise_zone_upper_bound?: number;
   min_hold_before_close?: number;
+  // Hedge Mode
+  enable_hedge_mode?: boolean;
 }
 
 export interface Trader {
@@ -107,6 +273,17 @@ export interface TraderConfig {
   // Per-trader OpenRouter config (falls back to global if empty)
   openrouter_api_key?: string;
   openrouter_model?: string;
+  // Tried in order when the primary model fails or its circuit is open
+  fallback_models?: AIBackend[];
+  // AI calls pause for the rest of the month once spend reaches this (0 = unlimited)
+  monthly_ai_budget_usd?: number;
+  // Save prompts/responses for offline replay (cmd/replay-decision)
+  record_ai_calls?: boolean;
+}
+
+export interface AIBackend {
+  provider: string; // openrouter, openai, anthropic, deepseek
+  model: string;
 }
 
 export interface Position {
@@ -127,3 +304,29 @@ export interface Decision {
   decisions: string;
   executed: boolean;
 }
+
+export interface AISpendRow {
+  key: string; // Trader ID, day, model or caller_type:caller_id
+  calls: number;
+  failed_calls: number;
+  prompt_tokens: number;
+  completion_tokens: number;
+  cost_usd: number;
+  avg_latency_ms: number;
+}
+
+export interface ModelPrice {
+  model: string;
+  prompt_per_mtok: number; // USD per million tokens
+  completion_per_mtok: number;
+  updated_at?: string;
+}
+
+export interface AIBudget {
+  trader_id: string;
+  trader_name: string;
+  budget_usd: number; // 0 = unlimited
+  spent_usd: number;
+  paused: boolean;
+  resets_at: string;
+}
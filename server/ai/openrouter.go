@@ -9,7 +9,11 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"strings"
 	"time"
+
+	"auto-trader-ahh/decision"
+	"auto-trader-ahh/mcp"
 )
 
 const OpenRouterBaseURL = "https://openrouter.ai/api/v1"
@@ -18,6 +22,10 @@ type Client struct {
 	apiKey     string
 	model      string
 	httpClient *http.Client
+	backend    mcp.AIClient // Optional; when set, chats go through it instead of OpenRouter directly
+	observe    mcp.CallObserver
+	gate       mcp.CallGate
+	recorder   mcp.Cassette // Optional; every chat is saved to it
 }
 
 type Message struct {
@@ -26,10 +34,11 @@ type Message struct {
 }
 
 type ChatRequest struct {
-	Model       string    `json:"model"`
-	Messages    []Message `json:"messages"`
-	MaxTokens   int       `json:"max_tokens,omitempty"`
-	Temperature float64   `json:"temperature,omitempty"`
+	Model          string                 `json:"model"`
+	Messages       []Message              `json:"messages"`
+	MaxTokens      int                    `json:"max_tokens,omitempty"`
+	Temperature    float64                `json:"temperature,omitempty"`
+	ResponseFormat map[string]interface{} `json:"response_format,omitempty"`
 }
 
 type ChatResponse struct {
@@ -58,18 +67,23 @@ type ChatResponse struct {
 type ChatResult struct {
 	Content   string
 	Reasoning string
+	Usage     mcp.Usage
+	Provider  string // Provider that served the chat
 }
 
 type TradingDecision struct {
-	Action        string  `json:"action"`          // BUY, SELL, HOLD, CLOSE
-	Symbol        string  `json:"symbol"`          // Trading pair
-	Confidence    float64 `json:"confidence"`      // 0-100
-	Reasoning     string  `json:"reasoning"`       // AI's reasoning
-	StopLossPct   float64 `json:"stop_loss_pct"`   // Stop loss as percentage (e.g., 2.0 = 2%)
-	TakeProfitPct float64 `json:"take_profit_pct"` // Take profit as percentage (e.g., 6.0 = 6%)
+	Action        string  `json:"action"`                // BUY, SELL, HOLD, CLOSE
+	Symbol        string  `json:"symbol"`                // Trading pair
+	Confidence    float64 `json:"confidence"`            // 0-100
+	Reasoning     string  `json:"reasoning"`             // AI's reasoning
+	StopLossPct   float64 `json:"stop_loss_pct"`         // Stop loss as percentage (e.g., 2.0 = 2%)
+	TakeProfitPct float64 `json:"take_profit_pct"`       // Take profit as percentage (e.g., 6.0 = 6%)
+	EntryPrice    float64 `json:"entry_price,omitempty"` // Optional limit price for BUY/SELL (used by limit entry mode)
 	// Legacy fields for backward compatibility
 	StopLoss   float64 `json:"stop_loss,omitempty"`   // Deprecated: use StopLossPct
 	TakeProfit float64 `json:"take_profit,omitempty"` // Deprecated: use TakeProfitPct
+
+	ParsePath string `json:"-"` // How the response was parsed: schema or lenient
 }
 
 func NewClient(apiKey, model string) *Client {
@@ -114,6 +128,25 @@ func (c *Client) SetModel(model string) {
 	c.model = model
 }
 
+// SetBackend routes chats through an mcp client, e.g. a FallbackClient that
+// fails over between providers. Reasoning traces aren't available this way.
+func (c *Client) SetBackend(backend mcp.AIClient) {
+	c.backend = backend
+}
+
+// SetMeter checks gate before, and reports to observe after, every direct
+// OpenRouter request. A backend set with SetBackend does its own metering.
+func (c *Client) SetMeter(observe mcp.CallObserver, gate mcp.CallGate) {
+	c.observe = observe
+	c.gate = gate
+}
+
+// SetRecorder saves every chat's request and response to a cassette, for
+// replaying decisions offline
+func (c *Client) SetRecorder(cassette mcp.Cassette) {
+	c.recorder = cassette
+}
+
 // GetModel returns the current model
 func (c *Client) GetModel() string {
 	return c.model
@@ -129,11 +162,60 @@ func (c *Client) Chat(messages []Message) (string, error) {
 
 // ChatWithReasoning returns both content and reasoning (for reasoning models)
 func (c *Client) ChatWithReasoning(messages []Message) (*ChatResult, error) {
+	return c.chatWithSchema(messages, nil)
+}
+
+// chatWithSchema runs a chat, constrained to a response schema when one is
+// given, and saves it to the recorder
+func (c *Client) chatWithSchema(messages []Message, schema *mcp.ResponseSchema) (*ChatResult, error) {
+	result, err := c.chat(messages, schema)
+	if c.recorder != nil {
+		c.record(messages, schema, result, err)
+	}
+	return result, err
+}
+
+// record saves a chat to the recorder. Save errors are only logged.
+func (c *Client) record(messages []Message, schema *mcp.ResponseSchema, result *ChatResult, err error) {
+	provider := mcp.ProviderOpenRouter
+	if c.backend != nil {
+		provider = c.backend.GetProvider()
+	}
+	req := toMCPRequest(messages, schema)
+	req.Model = c.model
+
+	var resp *mcp.Response
+	if result != nil {
+		resp = &mcp.Response{Content: result.Content, Usage: result.Usage, Timestamp: time.Now()}
+	}
+	if saveErr := c.recorder.Save(mcp.NewRecording(provider, req, resp, err)); saveErr != nil {
+		log.Printf("[OpenRouter] Failed to record chat: %v", saveErr)
+	}
+}
+
+func (c *Client) chat(messages []Message, schema *mcp.ResponseSchema) (*ChatResult, error) {
+	if c.backend != nil {
+		return c.chatViaBackend(messages, schema)
+	}
+	if c.gate != nil {
+		if err := c.gate(); err != nil {
+			return nil, err
+		}
+	}
+
 	const maxRetries = 3
 	var lastErr error
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		result, err := c.doChat(messages, attempt)
+		start := time.Now()
+		result, err := c.doChat(messages, schema, attempt)
+		if c.observe != nil {
+			rec := mcp.CallRecord{Provider: mcp.ProviderOpenRouter, Model: c.model, Latency: time.Since(start), Err: err}
+			if result != nil {
+				rec.Usage = result.Usage
+			}
+			c.observe(rec)
+		}
 		if err == nil {
 			return result, nil
 		}
@@ -156,6 +238,37 @@ func (c *Client) ChatWithReasoning(messages []Message) (*ChatResult, error) {
 	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
+// chatViaBackend sends the chat through the configured mcp backend
+func (c *Client) chatViaBackend(messages []Message, schema *mcp.ResponseSchema) (*ChatResult, error) {
+	resp, err := c.backend.CallWithRequest(toMCPRequest(messages, schema))
+	if err != nil {
+		return nil, err
+	}
+	if resp.Backend != "" {
+		log.Printf("[OpenRouter] Response served by %s in %v", resp.Backend, resp.Duration.Round(time.Millisecond))
+	}
+	provider := resp.Provider
+	if provider == "" {
+		provider = c.backend.GetProvider()
+	}
+	return &ChatResult{Content: resp.Content, Usage: resp.Usage, Provider: provider}, nil
+}
+
+// toMCPRequest converts chat messages to an mcp request with the same
+// sampling settings as a direct OpenRouter chat
+func toMCPRequest(messages []Message, schema *mcp.ResponseSchema) *mcp.Request {
+	req := &mcp.Request{
+		Messages:       make([]mcp.Message, 0, len(messages)),
+		MaxTokens:      4096,
+		Temperature:    0.7,
+		ResponseSchema: schema,
+	}
+	for _, m := range messages {
+		req.Messages = append(req.Messages, mcp.Message{Role: m.Role, Content: m.Content})
+	}
+	return req
+}
+
 // isRetryableError checks if the error is transient and worth retrying
 func isRetryableError(err error) bool {
 	if err == nil {
@@ -211,7 +324,7 @@ func toLower(s string) string {
 }
 
 // doChat performs a single chat request
-func (c *Client) doChat(messages []Message, attempt int) (*ChatResult, error) {
+func (c *Client) doChat(messages []Message, schema *mcp.ResponseSchema, attempt int) (*ChatResult, error) {
 	start := time.Now()
 
 	req := ChatRequest{
@@ -220,6 +333,9 @@ func (c *Client) doChat(messages []Message, attempt int) (*ChatResult, error) {
 		MaxTokens:   4096,
 		Temperature: 0.7,
 	}
+	if schema != nil {
+		req.ResponseFormat = schema.OpenAIResponseFormat()
+	}
 
 	body, err := json.Marshal(req)
 	if err != nil {
@@ -280,6 +396,12 @@ func (c *Client) doChat(messages []Message, attempt int) (*ChatResult, error) {
 	result := &ChatResult{
 		Content:   chatResp.Choices[0].Message.Content,
 		Reasoning: chatResp.Choices[0].Message.Reasoning,
+		Provider:  mcp.ProviderOpenRouter,
+		Usage: mcp.Usage{
+			PromptTokens:     chatResp.Usage.PromptTokens,
+			CompletionTokens: chatResp.Usage.CompletionTokens,
+			TotalTokens:      chatResp.Usage.TotalTokens,
+		},
 	}
 
 	// Log if reasoning was returned
@@ -290,100 +412,54 @@ func (c *Client) doChat(messages []Message, attempt int) (*ChatResult, error) {
 	return result, nil
 }
 
-func (c *Client) GetTradingDecision(marketData string) (*TradingDecision, string, error) {
-	systemPrompt := `You are a cryptocurrency futures trader AI. Balance profitability with risk management.
-
-## TRADING PHILOSOPHY: BALANCED MODE
-
-Look for QUALITY setups but also trade on MODERATE opportunities when available.
-
-## RESPONSE FORMAT
-
-You MUST respond with ONLY a valid JSON object:
-
-{
-  "action": "BUY" | "SELL" | "HOLD" | "CLOSE",
-  "symbol": "<EXACT_SYMBOL_FROM_DATA>",
-  "confidence": 0-100,
-  "reasoning": "Brief explanation",
-  "stop_loss_pct": 2.0,
-  "take_profit_pct": 6.0
+// SignalPrompt selects and fills the single-symbol signal prompt templates
+type SignalPrompt struct {
+	Variant    string // Prompt variant, default when empty
+	Symbol     string
+	MarketData string
+	Simple     bool // Minimal prompt like v1.4.7: less overthinking, trust SL/TP
+	Turbo      bool // Aggressive scalping instructions
 }
 
-## CONFIDENCE DEFINITION (IMPORTANT!)
-
-Confidence = "How sure are you about YOUR recommended action?"
-
-**For BUY/SELL:**
-- 75-100: Strong trade setup, take this trade
-- 65-74: Moderate setup, trade with caution and tighter stops
-- <65: Don't use BUY/SELL, use HOLD instead
-
-**For HOLD:**
-- 80-100: Confidently waiting, no good opportunity right now
-- 60-79: Uncertain market, better to wait
-- <60: Market is confusing, definitely wait
-
-**For CLOSE:**
-- 95-100: Only use for significant losses (> -1.5%) or significant profits (> +1.5%)
-- <95: DO NOT CLOSE - trust your Stop Loss order
-- NEVER close a position that is -1% to +1% (this is normal market noise!)
-
-## ACTION DEFINITIONS
-
-- **BUY** = Open a LONG position (confident price will go UP)
-- **SELL** = Open a SHORT position (confident price will go DOWN)  
-- **HOLD** = No action. Waiting is the best choice right now.
-- **CLOSE** = Close the current position (RARELY USED)
-
-## WHEN TO TRADE (BUY/SELL)
-
-✅ Trade when:
-- Entry score is 2/4 or higher (MODERATE or STRONG)
-- Trend direction is clear (EMA9 not crossing EMA21)
-- RSI is in a safe range (not extreme overbought/oversold)
-- You would confidently put money on this direction
-
-❌ Don't trade when:
-- Entry score is 0-1/4 (WEAK signals)
-- RSI > 75 for LONG or RSI < 25 for SHORT
-- EMA9 and EMA21 are crossing or very close
-- Multiple conflicting signals
-
-## STOP LOSS & TAKE PROFIT
-
-- For STRONG setups: stop_loss_pct: 2-3%, take_profit_pct: 6-9%
-- For MODERATE setups: stop_loss_pct: 1-2%, take_profit_pct: 3-6%
-- Always maintain 3:1 reward-to-risk ratio
-
-## CRITICAL: POSITION MANAGEMENT RULES
-
-If you have an existing position:
-
-⚠️ **ALMOST ALWAYS RECOMMEND HOLD** for existing positions!
-
-The SL/TP orders on the exchange will handle exits. You should only recommend CLOSE in rare cases:
+// RenderedPrompt is the prompt text sent to the AI and the template versions
+// that rendered it, in system, user order
+type RenderedPrompt struct {
+	System string
+	User   string
+	Refs   []decision.PromptRef
+}
 
-✅ CLOSE is OK when:
-- Loss > 1.5% AND momentum has completely reversed
-- Profit > 1.5% AND you see strong reversal signals
+// Render fills the prompt templates
+func (p SignalPrompt) Render() RenderedPrompt {
+	registry := decision.DefaultPromptRegistry()
+	data := decision.SignalPromptData{Symbol: p.Symbol, MarketData: p.MarketData, Simple: p.Simple, Turbo: p.Turbo}
 
-❌ NEVER CLOSE when:
-- Position is between -1.5% and +1.5% (NOISE ZONE)
-- Position was opened less than 10 minutes ago
-- You're just "uncertain" - that's not a reason to close
+	systemName := decision.PromptSignalSystem
+	if p.Simple {
+		systemName = decision.PromptSignalSimpleSystem
+	}
+	systemPrompt, systemRef := registry.RenderOrDefault(p.Variant, systemName, "", data)
+	userPrompt, userRef := registry.RenderOrDefault(p.Variant, decision.PromptSignalUser, "", data)
+	return RenderedPrompt{System: systemPrompt, User: userPrompt, Refs: []decision.PromptRef{systemRef, userRef}}
+}
 
-**Default behavior for existing positions: HOLD**
-Trust your stop loss. It's there for a reason.`
+// GetTradingDecision asks for a single-symbol decision. It returns the raw
+// response and the prompt exactly as sent.
+func (c *Client) GetTradingDecision(p SignalPrompt) (*TradingDecision, string, RenderedPrompt, error) {
+	prompt := p.Render()
+	schema := decision.SignalResponseSchema("BUY", "SELL", "HOLD", "CLOSE")
+	if p.Simple {
+		schema = decision.SignalResponseSchema("BUY", "SELL", "HOLD")
+	}
 
 	messages := []Message{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: "Analyze this market data and provide your trading decision:\n\n" + marketData},
+		{Role: "system", Content: prompt.System},
+		{Role: "user", Content: prompt.User},
 	}
 
-	result, err := c.ChatWithReasoning(messages)
+	result, err := c.chatWithSchema(messages, schema)
 	if err != nil {
-		return nil, "", fmt.Errorf("AI chat failed: %w", err)
+		return nil, "", prompt, fmt.Errorf("AI chat failed: %w", err)
 	}
 
 	// Log reasoning if present (from reasoning models like deepseek-r1)
@@ -392,82 +468,36 @@ Trust your stop loss. It's there for a reason.`
 	}
 
 	response := result.Content
-
-	// Parse JSON from response
-	var decision TradingDecision
-	if err := json.Unmarshal([]byte(response), &decision); err != nil {
-		// Try to extract JSON from response if wrapped in markdown
-		start := bytes.Index([]byte(response), []byte("{"))
-		end := bytes.LastIndex([]byte(response), []byte("}"))
-		if start >= 0 && end > start {
-			jsonStr := response[start : end+1]
-			if err := json.Unmarshal([]byte(jsonStr), &decision); err != nil {
-				return nil, response, fmt.Errorf("failed to parse AI decision: %w", err)
-			}
-		} else {
-			return nil, response, fmt.Errorf("no JSON found in response")
-		}
+	tradingDecision, err := ParseTradingDecision(response, mcp.SupportsResponseSchema(result.Provider))
+	if err != nil {
+		return nil, response, prompt, err
 	}
-
-	return &decision, response, nil
+	return tradingDecision, response, prompt, nil
 }
 
-// GetTradingDecisionSimple uses a minimal prompt like v1.4.7
-// This is used when Simple Mode is enabled - less overthinking, trust SL/TP
-func (c *Client) GetTradingDecisionSimple(marketData string) (*TradingDecision, string, error) {
-	systemPrompt := `You are a cryptocurrency futures trader. Make clear BUY, SELL, or HOLD decisions.
-
-RESPONSE FORMAT:
-{
-  "action": "BUY" | "SELL" | "HOLD",
-  "symbol": "<SYMBOL>",
-  "confidence": 0-100,
-  "reasoning": "Brief explanation",
-  "stop_loss_pct": 2.0,
-  "take_profit_pct": 6.0
-}
-
-ACTIONS:
-- BUY = Open LONG, expect price UP
-- SELL = Open SHORT, expect price DOWN
-- HOLD = No trade, wait
-
-SIMPLE RULES:
-1. Trade WITH the trend (EMA direction)
-2. Use momentum confirmation (MACD)
-3. Avoid extreme RSI (>70 or <30)
-4. If unsure, HOLD
-5. For existing positions: almost always HOLD, trust your SL/TP`
-
-	messages := []Message{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: "Analyze and decide:\n\n" + marketData},
-	}
-
-	result, err := c.ChatWithReasoning(messages)
-	if err != nil {
-		return nil, "", fmt.Errorf("AI chat failed: %w", err)
+// ParseTradingDecision parses a trading decision from an AI response. When
+// the provider was sent the schema (constrained), a bare JSON object is
+// decoded strictly; anything else goes through the lenient parser, which digs
+// the object out of markdown or prose. ParsePath records which one succeeded.
+func ParseTradingDecision(response string, constrained bool) (*TradingDecision, error) {
+	var d TradingDecision
+	if constrained {
+		if err := json.Unmarshal([]byte(strings.TrimSpace(response)), &d); err == nil && d.Action != "" {
+			d.ParsePath = decision.ParsePathSchema
+			return &d, nil
+		}
 	}
 
-	if result.Reasoning != "" {
-		log.Printf("[OpenRouter] AI Reasoning:\n%s", result.Reasoning)
+	// Try to extract JSON from response if wrapped in markdown
+	d = TradingDecision{}
+	start := bytes.Index([]byte(response), []byte("{"))
+	end := bytes.LastIndex([]byte(response), []byte("}"))
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("no JSON found in response")
 	}
-
-	response := result.Content
-
-	var decision TradingDecision
-	if err := json.Unmarshal([]byte(response), &decision); err != nil {
-		start := bytes.Index([]byte(response), []byte("{"))
-		end := bytes.LastIndex([]byte(response), []byte("}"))
-		if start >= 0 && end > start {
-			jsonStr := response[start : end+1]
-			if err := json.Unmarshal([]byte(jsonStr), &decision); err != nil {
-				return nil, response, fmt.Errorf("failed to parse AI decision: %w", err)
-			}
-		} else {
-			return nil, response, fmt.Errorf("no JSON found in response")
-		}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &d); err != nil {
+		return nil, fmt.Errorf("failed to parse AI decision: %w", err)
 	}
-
-	return &decision, response, nil
+	d.ParsePath = decision.ParsePathLenient
+	return &d, nil
 }
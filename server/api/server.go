@@ -5,13 +5,17 @@ import (
 	"crypto/subtle"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"auto-trader-ahh/ai"
 	"auto-trader-ahh/backtest"
+	"auto-trader-ahh/calibration"
 	"auto-trader-ahh/config"
 	"auto-trader-ahh/debate"
 	"auto-trader-ahh/decision"
@@ -21,6 +25,7 @@ import (
 	"auto-trader-ahh/mcp"
 	"auto-trader-ahh/store"
 	"auto-trader-ahh/trader"
+	"auto-trader-ahh/usage"
 )
 
 type Server struct {
@@ -39,6 +44,12 @@ type Server struct {
 	accessPasskey   string
 	cfg             *config.Config
 	hub             *events.Hub
+	aiUsageStore    *store.AIUsageStore
+	meter           *usage.Meter
+	promptStore     *store.PromptTemplateStore
+	positionStore   *store.PositionStore
+	lessonStore     *store.TradeLessonStore
+	auditStore      *store.DecisionAuditStore
 }
 
 func NewServer(port string, em *trader.EngineManager, cfg *config.Config) *Server {
@@ -48,14 +59,11 @@ func NewServer(port string, em *trader.EngineManager, cfg *config.Config) *Serve
 	// Create Binance client for backtest
 	binanceClient := exchange.NewBinanceClient(cfg.BinanceAPIKey, cfg.BinanceSecretKey, cfg.BinanceTestnet)
 
-	// Create debate engine and register AI client
+	// Create debate engine (provider clients are registered by reloadConfig)
 	debateEng := debate.NewEngine()
-	debateEng.RegisterClient("openrouter", aiClient)
-	debateEng.RegisterClient("openai", aiClient)    // Use OpenRouter for all providers
-	debateEng.RegisterClient("anthropic", aiClient) // OpenRouter supports these models
-	debateEng.RegisterClient("deepseek", aiClient)
 
 	equityStore := store.NewEquityStore()
+	meter := usage.NewMeter()
 
 	srv := &Server{
 		port:            port,
@@ -73,11 +81,33 @@ func NewServer(port string, em *trader.EngineManager, cfg *config.Config) *Serve
 		accessPasskey:   cfg.AccessPasskey,
 		cfg:             cfg,
 		hub:             em.GetHub(),
+		aiUsageStore:    store.NewAIUsageStore(),
+		meter:           meter,
+		promptStore:     store.NewPromptTemplateStore(),
+		positionStore:   store.NewPositionStore(),
+		lessonStore:     store.NewTradeLessonStore(),
+		auditStore:      store.NewDecisionAuditStore(),
 	}
+	srv.backtestManager.SetUsageMeter(meter)
 
 	// Wire up debate engine with market context provider and trade executor
 	debateEng.SetMarketContextProvider(srv.buildDebateMarketContextForCycle)
 	debateEng.SetTradeExecutor(srv.executeDebateDecisions)
+	debateEng.SetUsageMeter(meter)
+	em.SetDebateEngine(debateEng)
+
+	// Apply saved settings and register per-provider debate clients
+	srv.reloadConfig()
+
+	// Load prompt templates before anything renders a prompt
+	if err := srv.loadPromptTemplates(); err != nil {
+		log.Printf("[Prompt] %v, using built-in templates", err)
+	}
+
+	// Reload persisted debates and resume auto-cycle sessions
+	if err := debateEng.Restore(context.Background()); err != nil {
+		log.Printf("[Debate] %v", err)
+	}
 
 	return srv
 }
@@ -97,6 +127,9 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/api/strategies/active", s.authMiddleware(s.handleActiveStrategy))
 	mux.HandleFunc("/api/strategies/default-config", s.authMiddleware(s.handleDefaultConfig))
 	mux.HandleFunc("/api/strategies/recommend-pairs", s.authMiddleware(s.handleRecommendPairs))
+	mux.HandleFunc("/api/strategies/import", s.authMiddleware(s.handleStrategyImport))
+	mux.HandleFunc("/api/strategy-templates", s.authMiddleware(s.handleStrategyTemplates))
+	mux.HandleFunc("/api/strategy-templates/", s.authMiddleware(s.handleStrategyTemplate))
 
 	// Trader endpoints
 	mux.HandleFunc("/api/traders", s.authMiddleware(s.handleTraders))
@@ -107,23 +140,42 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/api/account", s.authMiddleware(s.handleAccount))
 	mux.HandleFunc("/api/positions", s.authMiddleware(s.handlePositions))
 	mux.HandleFunc("/api/decisions", s.authMiddleware(s.handleDecisions))
+	mux.HandleFunc("/api/decisions/audit", s.authMiddleware(s.handleDecisionAudits))
+	mux.HandleFunc("/api/decisions/audit/block-reasons", s.authMiddleware(s.handleAuditBlockReasons))
+	mux.HandleFunc("/api/decisions/audit/", s.authMiddleware(s.handleDecisionAudit))
 	mux.HandleFunc("/api/trades", s.authMiddleware(s.handleTrades))
+	mux.HandleFunc("/api/lessons", s.authMiddleware(s.handleLessons))
+	mux.HandleFunc("/api/calibration", s.authMiddleware(s.handleCalibration))
 	mux.HandleFunc("/api/equity-history", s.authMiddleware(s.handleEquityHistory))
 
 	// Backtest endpoints
 	mux.HandleFunc("/api/backtest", s.authMiddleware(s.handleBacktests))
 	mux.HandleFunc("/api/backtest/start", s.authMiddleware(s.handleBacktestStart))
+	mux.HandleFunc("/api/backtest/eval", s.authMiddleware(s.handleEvals))
+	mux.HandleFunc("/api/backtest/eval/", s.authMiddleware(s.handleEval))
 	mux.HandleFunc("/api/backtest/", s.authMiddleware(s.handleBacktest))
 
 	// Debate endpoints
 	mux.HandleFunc("/api/debate/sessions", s.authMiddleware(s.handleDebateSessions))
 	mux.HandleFunc("/api/debate/sessions/", s.authMiddleware(s.handleDebateSession))
+	mux.HandleFunc("/api/debate/performance", s.authMiddleware(s.handleDebatePerformance))
+	mux.HandleFunc("/api/debate/personalities", s.authMiddleware(s.handleDebatePersonalities))
+	mux.HandleFunc("/api/debate/personalities/", s.authMiddleware(s.handleDebatePersonality))
 
 	// Settings endpoints
 	mux.HandleFunc("/api/settings", s.authMiddleware(s.handleSettings))
+	mux.HandleFunc("/api/ai/models", s.authMiddleware(s.handleAIModels))
+	mux.HandleFunc("/api/ai/usage", s.authMiddleware(s.handleAIUsage))
+	mux.HandleFunc("/api/ai/usage/calls", s.authMiddleware(s.handleAICalls))
+	mux.HandleFunc("/api/ai/prices", s.authMiddleware(s.handleAIPrices))
+	mux.HandleFunc("/api/ai/budgets", s.authMiddleware(s.handleAIBudgets))
+	mux.HandleFunc("/api/prompts", s.authMiddleware(s.handlePrompts))
+	mux.HandleFunc("/api/prompts/reload", s.authMiddleware(s.handlePromptsReload))
 
 	// System endpoints
 	mux.HandleFunc("/api/logs/stream", s.authMiddleware(s.handleLogStream))
+	mux.HandleFunc("/api/market/stream", s.authMiddleware(s.handleMarketStream))
+	mux.HandleFunc("/api/exchange/rate-limits", s.authMiddleware(s.handleRateLimits))
 
 	// Wrap with CORS middleware
 	handler := corsMiddleware(mux)
@@ -286,6 +338,18 @@ func (s *Server) handleStrategy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Version history: /api/strategies/{id}/versions[/...]
+	if parts := splitPath(id); len(parts) >= 2 && parts[1] == "versions" {
+		s.handleStrategyVersions(w, r, parts[0], parts[2:])
+		return
+	}
+
+	// Export: /api/strategies/{id}/export?format=json|yaml
+	if parts := splitPath(id); len(parts) == 2 && parts[1] == "export" {
+		s.handleStrategyExport(w, r, parts[0])
+		return
+	}
+
 	// Handle activate endpoint
 	if len(id) > 9 && id[len(id)-9:] == "/activate" {
 		if r.Method == "POST" {
@@ -339,6 +403,219 @@ func (s *Server) handleStrategy(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleStrategyVersions serves a strategy's version history:
+//
+//	GET  /api/strategies/{id}/versions                      - list, newest first
+//	GET  /api/strategies/{id}/versions/diff?from=1&to=3     - field-level config diff (to defaults to current)
+//	GET  /api/strategies/{id}/versions/{version}            - one version
+//	POST /api/strategies/{id}/versions/{version}/rollback   - restore it as a new version
+func (s *Server) handleStrategyVersions(w http.ResponseWriter, r *http.Request, strategyID string, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == "GET":
+		versions, err := s.strategyStore.ListVersions(strategyID)
+		if err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.jsonResponse(w, map[string]interface{}{"versions": versions})
+
+	case len(rest) == 1 && rest[0] == "diff" && r.Method == "GET":
+		from, err := s.strategyVersion(strategyID, r.URL.Query().Get("from"))
+		if err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		to, err := s.strategyVersion(strategyID, r.URL.Query().Get("to"))
+		if err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		changes, err := store.DiffConfigs(from.Config, to.Config)
+		if err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.jsonResponse(w, map[string]interface{}{
+			"from":    from.Version,
+			"to":      to.Version,
+			"changes": changes,
+		})
+
+	case len(rest) == 1 && r.Method == "GET":
+		version, err := s.strategyVersion(strategyID, rest[0])
+		if err != nil {
+			s.errorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.jsonResponse(w, version)
+
+	case len(rest) == 2 && rest[1] == "rollback" && r.Method == "POST":
+		version, err := strconv.Atoi(rest[0])
+		if err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Invalid version")
+			return
+		}
+		strategy, err := s.strategyStore.Rollback(strategyID, version)
+		if err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := s.engineManager.ReloadStrategyForTraders(strategyID); err != nil {
+			log.Printf("Warning: failed to reload strategy for running traders: %v", err)
+		}
+		s.jsonResponse(w, strategy)
+
+	default:
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// strategyVersion loads a version by number; empty means the current version
+func (s *Server) strategyVersion(strategyID, version string) (*store.StrategyVersion, error) {
+	if version == "" {
+		versions, err := s.strategyStore.ListVersions(strategyID)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("strategy %s has no versions", strategyID)
+		}
+		return versions[0], nil
+	}
+	n, err := strconv.Atoi(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version: %s", version)
+	}
+	v, err := s.strategyStore.GetVersion(strategyID, n)
+	if err != nil {
+		return nil, fmt.Errorf("version %d not found", n)
+	}
+	return v, nil
+}
+
+func (s *Server) handleStrategyExport(w http.ResponseWriter, r *http.Request, strategyID string) {
+	if r.Method != "GET" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	strategy, err := s.strategyStore.Get(strategyID)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Strategy not found")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = store.FormatJSON
+	}
+	if format != store.FormatJSON && format != store.FormatYAML {
+		s.errorResponse(w, http.StatusBadRequest, "format must be json or yaml")
+		return
+	}
+
+	data, err := store.ExportStrategy(strategy, format)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	contentType := "application/json"
+	if format == store.FormatYAML {
+		contentType = "application/yaml"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "strategy-"+strategyID+"."+format))
+	w.Write(data)
+}
+
+// handleStrategyImport creates a strategy from an exported JSON or YAML
+// document. The format comes from ?format=, then the Content-Type, and is
+// otherwise detected from the body.
+func (s *Server) handleStrategyImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		contentType := r.Header.Get("Content-Type")
+		switch {
+		case strings.Contains(contentType, "yaml"):
+			format = store.FormatYAML
+		case strings.Contains(contentType, "json"):
+			format = store.FormatJSON
+		}
+	}
+
+	strategy, err := store.ImportStrategy(data, format)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.strategyStore.Create(strategy); err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.jsonResponse(w, strategy)
+}
+
+func (s *Server) handleStrategyTemplates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	s.jsonResponse(w, map[string]interface{}{"templates": store.StrategyTemplates()})
+}
+
+// handleStrategyTemplate serves one template (GET) or creates a strategy
+// from it (POST, optional {"name": "..."} body)
+func (s *Server) handleStrategyTemplate(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Path[len("/api/strategy-templates/"):]
+	if id == "" {
+		s.errorResponse(w, http.StatusBadRequest, "Template ID required")
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		template, err := store.GetStrategyTemplate(id)
+		if err != nil {
+			s.errorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.jsonResponse(w, template)
+
+	case "POST":
+		var req struct {
+			Name string `json:"name"`
+		}
+		if r.Body != nil {
+			json.NewDecoder(r.Body).Decode(&req)
+		}
+		strategy, err := store.NewStrategyFromTemplate(id, req.Name)
+		if err != nil {
+			s.errorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err := s.strategyStore.Create(strategy); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.jsonResponse(w, strategy)
+
+	default:
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
 func (s *Server) handleActiveStrategy(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -479,7 +756,8 @@ Result:`, targetCount)
 
 	// 4. Call AI
 	// Using CallWithMessages since GetCompletion is not available in interface
-	response, err := s.aiClient.CallWithMessages("You are a smart crypto trading assistant.", prompt)
+	client := s.meter.Wrap(s.aiClient, store.CallerSystem, "recommend-pairs", "")
+	response, err := client.CallWithMessages("You are a smart crypto trading assistant.", prompt)
 	if err != nil {
 		s.errorResponse(w, http.StatusInternalServerError, "AI Request failed: "+err.Error())
 		return
@@ -643,6 +921,29 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, status)
 }
 
+func (s *Server) handleMarketStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"enabled": s.cfg.MarketStreamEnabled,
+		"streams": s.engineManager.GetMarketStreamStats(),
+	})
+}
+
+func (s *Server) handleRateLimits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"limiters": exchange.GetRateLimiterStats(),
+	})
+}
+
 func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request) {
 	traderID := r.URL.Query().Get("trader_id")
 	if traderID == "" {
@@ -724,80 +1025,331 @@ func (s *Server) handleTrades(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func splitPath(path string) []string {
-	var parts []string
-	current := ""
-	for _, c := range path {
-		if c == '/' {
-			if current != "" {
-				parts = append(parts, current)
-				current = ""
-			}
-		} else {
-			current += string(c)
+// auditFilter reads a decision audit filter from the query:
+// trader_id, symbol, block_reason, executed and from/to as a date or RFC 3339 time
+func auditFilter(r *http.Request) (store.AuditFilter, error) {
+	q := r.URL.Query()
+	f := store.AuditFilter{
+		TraderID:    q.Get("trader_id"),
+		Symbol:      q.Get("symbol"),
+		BlockReason: q.Get("block_reason"),
+	}
+	if v := q.Get("executed"); v != "" {
+		executed, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid executed: %s", v)
 		}
+		f.Executed = &executed
 	}
-	if current != "" {
-		parts = append(parts, current)
+	parse := func(name, v string, endOfDay bool) (time.Time, error) {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, nil
+		}
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return t, fmt.Errorf("invalid %s: %s", name, v)
+		}
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
 	}
-	return parts
+	var err error
+	if v := q.Get("from"); v != "" {
+		if f.From, err = parse("from", v, false); err != nil {
+			return f, err
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if f.To, err = parse("to", v, true); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
 }
 
-// ============ BACKTEST ENDPOINTS ============
-
-func (s *Server) handleBacktests(w http.ResponseWriter, r *http.Request) {
+// handleDecisionAudits lists decision audits, newest first:
+//
+//	GET /api/decisions/audit?trader_id=&symbol=&block_reason=&executed=&from=&to=&limit=&full=true
+//
+// Prompts and raw AI output are only included with full=true.
+func (s *Server) handleDecisionAudits(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	runs := s.backtestManager.ListRuns()
-	s.jsonResponse(w, map[string]interface{}{"backtests": runs})
+	f, err := auditFilter(r)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := 100
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 1000 {
+		limit = l
+	}
+	full, _ := strconv.ParseBool(r.URL.Query().Get("full"))
+
+	audits, err := s.auditStore.List(f, limit, full)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.jsonResponse(w, map[string]interface{}{"audits": audits})
 }
 
-func (s *Server) handleBacktestStart(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
+// handleDecisionAudit returns one decision audit with its prompts and raw output
+func (s *Server) handleDecisionAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
 		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	var cfg backtest.Config
-	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
-		s.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/decisions/audit/"), 10, 64)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid audit ID")
+		return
+	}
+	audit, err := s.auditStore.Get(id)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Audit not found")
 		return
 	}
+	s.jsonResponse(w, audit)
+}
 
-	if err := cfg.Validate(); err != nil {
+// handleAuditBlockReasons counts blocked decisions by guard, with the same
+// filters as the audit list
+func (s *Server) handleAuditBlockReasons(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	f, err := auditFilter(r)
+	if err != nil {
 		s.errorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	counts, err := s.auditStore.CountBlockReasons(f)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.jsonResponse(w, map[string]interface{}{"block_reasons": counts})
+}
 
-	runID, err := s.backtestManager.Start(context.Background(), &cfg)
+// handleLessons returns a trader's reflection lessons and the journaled
+// closed positions they were drawn from
+func (s *Server) handleLessons(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	traderID := r.URL.Query().Get("trader_id")
+	if traderID == "" {
+		s.errorResponse(w, http.StatusBadRequest, "trader_id required")
+		return
+	}
+
+	lessons, err := s.lessonStore.ListByTrader(traderID)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	closed, err := s.positionStore.GetClosedPositions(traderID, 100)
 	if err != nil {
 		s.errorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	s.jsonResponse(w, map[string]string{"run_id": runID, "status": "started"})
+	s.jsonResponse(w, map[string]interface{}{
+		"lessons":          lessons,
+		"closed_positions": closed,
+	})
 }
 
-func (s *Server) handleBacktest(w http.ResponseWriter, r *http.Request) {
-	// Extract path: /api/backtest/{runId} or /api/backtest/{runId}/action
-	path := r.URL.Path[len("/api/backtest/"):]
-	parts := splitPath(path)
+// handleCalibration returns a trader's confidence reliability curves, one per
+// model, from its journaled closed positions. Each curve carries the
+// confidence floor auto_threshold would pick for target_hit_rate (default 50).
+func (s *Server) handleCalibration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
 
-	if len(parts) == 0 {
-		s.errorResponse(w, http.StatusBadRequest, "Run ID required")
+	traderID := r.URL.Query().Get("trader_id")
+	if traderID == "" {
+		s.errorResponse(w, http.StatusBadRequest, "trader_id required")
 		return
 	}
+	width := calibration.DefaultBucketWidth
+	if v, err := strconv.Atoi(r.URL.Query().Get("bucket_width")); err == nil && v > 0 && v <= 50 {
+		width = v
+	}
+	target := 50.0
+	if v, err := strconv.ParseFloat(r.URL.Query().Get("target_hit_rate"), 64); err == nil && v > 0 && v <= 100 {
+		target = v
+	}
 
-	runID := parts[0]
-	action := ""
-	if len(parts) > 1 {
-		action = parts[1]
+	closed, err := s.positionStore.GetClosedPositions(traderID, 1000)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
 	}
 
-	switch action {
+	type curveResponse struct {
+		*calibration.Curve
+		SuggestedMinConfidence int `json:"suggested_min_confidence"` // 0 when no floor reaches the target
+	}
+	curves := make([]curveResponse, 0)
+	for _, c := range calibration.Build(closed, width) {
+		threshold, _ := c.Threshold(target, 1)
+		curves = append(curves, curveResponse{Curve: c, SuggestedMinConfidence: threshold})
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"curves":          curves,
+		"bucket_width":    width,
+		"target_hit_rate": target,
+	})
+}
+
+func splitPath(path string) []string {
+	var parts []string
+	current := ""
+	for _, c := range path {
+		if c == '/' {
+			if current != "" {
+				parts = append(parts, current)
+				current = ""
+			}
+		} else {
+			current += string(c)
+		}
+	}
+	if current != "" {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// ============ BACKTEST ENDPOINTS ============
+
+func (s *Server) handleBacktests(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	runs := s.backtestManager.ListRuns()
+	s.jsonResponse(w, map[string]interface{}{"backtests": runs})
+}
+
+func (s *Server) handleBacktestStart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var cfg backtest.Config
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	runID, err := s.backtestManager.Start(context.Background(), &cfg)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.jsonResponse(w, map[string]string{"run_id": runID, "status": "started"})
+}
+
+// handleEvals lists prompt evaluations (GET) or starts one (POST)
+func (s *Server) handleEvals(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		s.jsonResponse(w, map[string]interface{}{"evals": s.backtestManager.ListEvals()})
+
+	case "POST":
+		var cfg backtest.EvalConfig
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		evalID, err := s.backtestManager.StartEval(context.Background(), &cfg)
+		if err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.jsonResponse(w, map[string]string{"eval_id": evalID, "status": "started"})
+
+	default:
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleEval returns an evaluation's comparison report, or stops it
+func (s *Server) handleEval(w http.ResponseWriter, r *http.Request) {
+	// Extract path: /api/backtest/eval/{evalId} or /api/backtest/eval/{evalId}/stop
+	parts := splitPath(r.URL.Path[len("/api/backtest/eval/"):])
+	if len(parts) == 0 {
+		s.errorResponse(w, http.StatusBadRequest, "Eval ID required")
+		return
+	}
+	evalID := parts[0]
+
+	if len(parts) > 1 && parts[1] == "stop" {
+		if r.Method != "POST" {
+			s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		if err := s.backtestManager.StopEval(evalID); err != nil {
+			s.errorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.jsonResponse(w, map[string]string{"status": "stopped"})
+		return
+	}
+
+	if r.Method != "GET" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	report, err := s.backtestManager.GetEval(evalID)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.jsonResponse(w, report)
+}
+
+func (s *Server) handleBacktest(w http.ResponseWriter, r *http.Request) {
+	// Extract path: /api/backtest/{runId} or /api/backtest/{runId}/action
+	path := r.URL.Path[len("/api/backtest/"):]
+	parts := splitPath(path)
+
+	if len(parts) == 0 {
+		s.errorResponse(w, http.StatusBadRequest, "Run ID required")
+		return
+	}
+
+	runID := parts[0]
+	action := ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+
+	switch action {
 	case "stop":
 		if r.Method != "POST" {
 			s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -886,6 +1438,87 @@ func (s *Server) handleBacktest(w http.ResponseWriter, r *http.Request) {
 
 // ============ DEBATE ENDPOINTS ============
 
+// handleDebatePersonalities lists built-in and custom personalities or creates a custom one
+func (s *Server) handleDebatePersonalities(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		profiles, err := s.debateEngine.Personalities()
+		if err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.jsonResponse(w, profiles)
+
+	case "POST":
+		var req store.DebatePersonality
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		profile, err := s.debateEngine.CreatePersonality(&req)
+		if err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.jsonResponse(w, profile)
+
+	default:
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleDebatePersonality updates or deletes a custom personality
+func (s *Server) handleDebatePersonality(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Path[len("/api/debate/personalities/"):]
+	if id == "" {
+		s.errorResponse(w, http.StatusBadRequest, "Personality ID required")
+		return
+	}
+
+	switch r.Method {
+	case "PUT":
+		var req store.DebatePersonality
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		profile, err := s.debateEngine.UpdatePersonality(id, &req)
+		if err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.jsonResponse(w, profile)
+
+	case "DELETE":
+		if err := s.debateEngine.DeletePersonality(id); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.jsonResponse(w, map[string]string{"status": "deleted"})
+
+	default:
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleDebatePerformance returns each participant's (model + personality) scored track record
+func (s *Server) handleDebatePerformance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	stats, err := s.debateEngine.Performance()
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if stats == nil {
+		stats = []*store.DebateParticipantStats{}
+	}
+	s.jsonResponse(w, stats)
+}
+
 func (s *Server) handleDebateSessions(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
@@ -898,6 +1531,10 @@ func (s *Server) handleDebateSessions(w http.ResponseWriter, r *http.Request) {
 			s.errorResponse(w, http.StatusBadRequest, "Invalid request body")
 			return
 		}
+		if req.AutoExecute && req.TraderID == "" {
+			s.errorResponse(w, http.StatusBadRequest, "auto_execute requires a trader_id")
+			return
+		}
 
 		session, err := s.debateEngine.CreateSession(&req)
 		if err != nil {
@@ -962,6 +1599,18 @@ func (s *Server) handleDebateSession(w http.ResponseWriter, r *http.Request) {
 		}
 		s.jsonResponse(w, map[string]string{"status": "stopped"})
 
+	case "history":
+		if r.Method != "GET" {
+			s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		decisions, err := s.debateEngine.History(sessionID)
+		if err != nil {
+			s.errorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.jsonResponse(w, map[string]interface{}{"decisions": decisions})
+
 	case "":
 		// No action - CRUD on session
 		switch r.Method {
@@ -974,8 +1623,10 @@ func (s *Server) handleDebateSession(w http.ResponseWriter, r *http.Request) {
 			s.jsonResponse(w, session)
 
 		case "DELETE":
-			// For now, just stop it
-			s.debateEngine.Stop(sessionID)
+			if err := s.debateEngine.Delete(sessionID); err != nil {
+				s.errorResponse(w, http.StatusNotFound, err.Error())
+				return
+			}
 			s.jsonResponse(w, map[string]string{"status": "deleted"})
 
 		default:
@@ -1210,122 +1861,49 @@ func (s *Server) buildDebateMarketContextForCycle(symbols []string) (*debate.Mar
 	}, nil
 }
 
-// executeDebateDecisions executes the consensus decisions from a debate
-// Uses session-specific Binance credentials for trade execution
+// executeDebateDecisions submits the consensus decisions from a debate to the
+// session's linked trader, so they get the same risk controls, sizing and
+// bookkeeping as the trader's own AI decisions
 func (s *Server) executeDebateDecisions(session *debate.Session, decisions []*debate.Decision) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+	if session.TraderID == "" {
+		return fmt.Errorf("auto-execute requires a linked trader")
+	}
+	if !s.engineManager.IsRunning(session.TraderID) {
+		return fmt.Errorf("linked trader %s is not running", session.TraderID)
+	}
 
-	// Create session-specific Binance client using session's credentials
-	binanceClient := exchange.NewBinanceClient(
-		session.BinanceAPIKey,
-		session.BinanceSecretKey,
-		session.BinanceTestnet,
-	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
 
+	source := "debate:" + session.ID
 	for _, d := range decisions {
 		if d.Action == "wait" || d.Action == "hold" {
 			log.Printf("[Debate] Skipping %s for %s", d.Action, d.Symbol)
 			continue
 		}
 
-		if d.Confidence < 60 {
-			log.Printf("[Debate] Skipping %s - low confidence: %d%%", d.Symbol, d.Confidence)
-			continue
-		}
-
-		log.Printf("[Debate] Executing %s on %s (confidence: %d%%)", d.Action, d.Symbol, d.Confidence)
+		log.Printf("[Debate] Submitting %s on %s to trader %s (confidence: %d%%)", d.Action, d.Symbol, session.TraderID, d.Confidence)
 
-		// Get current price
-		ticker, err := binanceClient.GetTicker(ctx, d.Symbol)
+		result, err := s.engineManager.ExecuteDecision(ctx, session.TraderID, &ai.TradingDecision{
+			Action:     d.Action,
+			Symbol:     d.Symbol,
+			Confidence: float64(d.Confidence),
+			Reasoning:  d.Reasoning,
+			StopLoss:   d.StopLoss,
+			TakeProfit: d.TakeProfit,
+		}, source)
 		if err != nil {
-			log.Printf("[Debate] Failed to get price for %s: %v", d.Symbol, err)
 			d.Error = err.Error()
 			continue
 		}
-
-		// Calculate position size
-		positionSizeUSD := d.PositionSizeUSD
-		if positionSizeUSD <= 0 {
-			// Use position percentage of account
-			account, err := binanceClient.GetAccountInfo(ctx)
-			if err == nil && d.PositionPct > 0 {
-				positionSizeUSD = account.AvailableBalance * d.PositionPct
-			} else {
-				positionSizeUSD = 100.0 // Default $100
-			}
-		}
-
-		quantity := positionSizeUSD / ticker.Price
-
-		// Execute the trade based on action
-		var side string
-		switch d.Action {
-		case "open_long", "BUY":
-			side = "BUY"
-		case "open_short", "SELL":
-			side = "SELL"
-		case "close_long":
-			side = "SELL"
-		case "close_short":
-			side = "BUY"
-		default:
-			log.Printf("[Debate] Unknown action: %s", d.Action)
-			continue
-		}
-
-		order, err := binanceClient.PlaceOrder(ctx, d.Symbol, side, "MARKET", quantity, 0, false)
-		if err != nil {
-			log.Printf("[Debate] Failed to execute %s on %s: %v", d.Action, d.Symbol, err)
-			d.Error = err.Error()
+		if result.Error != "" {
+			log.Printf("[Debate] %s on %s not executed: %s", d.Action, d.Symbol, result.Error)
+			d.Error = result.Error
 			continue
 		}
 
 		d.Executed = true
 		d.ExecutedAt = time.Now()
-		d.OrderID = fmt.Sprintf("%d", order.OrderID)
-		log.Printf("[Debate] Executed order %d: %s %s %.4f @ %.2f", order.OrderID, side, d.Symbol, quantity, ticker.Price)
-
-		// Place stop-loss and take-profit orders for new positions
-		if d.Action == "open_long" || d.Action == "open_short" || d.Action == "BUY" || d.Action == "SELL" {
-			isLong := d.Action == "open_long" || d.Action == "BUY"
-			closeSide := "SELL"
-			if !isLong {
-				closeSide = "BUY"
-			}
-
-			// Place stop-loss order
-			if d.StopLoss > 0 {
-				slOrder, err := binanceClient.PlaceStopLoss(ctx, d.Symbol, closeSide, 0, d.StopLoss)
-				if err != nil {
-					log.Printf("[Debate] Failed to place stop-loss for %s: %v", d.Symbol, err)
-				} else {
-					log.Printf("[Debate] Stop-loss placed for %s: ID=%d @ %.2f", d.Symbol, slOrder.OrderID, d.StopLoss)
-				}
-			}
-
-			// Place take-profit order
-			if d.TakeProfit > 0 {
-				tpOrder, err := binanceClient.PlaceTakeProfit(ctx, d.Symbol, closeSide, 0, d.TakeProfit)
-				if err != nil {
-					log.Printf("[Debate] Failed to place take-profit for %s: %v", d.Symbol, err)
-				} else {
-					log.Printf("[Debate] Take-profit placed for %s: ID=%d @ %.2f", d.Symbol, tpOrder.OrderID, d.TakeProfit)
-				}
-			}
-		}
-	}
-
-	// Save equity snapshot after execution
-	account, err := binanceClient.GetAccountInfo(ctx)
-	if err == nil {
-		s.equityStore.Save(&store.EquitySnapshot{
-			TraderID:      "debate_" + session.ID,
-			Timestamp:     time.Now(),
-			TotalEquity:   account.TotalMarginBalance,
-			Balance:       account.TotalWalletBalance,
-			UnrealizedPnL: account.TotalUnrealizedProfit,
-		})
 	}
 
 	return nil
@@ -1348,6 +1926,11 @@ func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
 			"configured": map[string]bool{
 				"openrouter": settings.OpenRouterAPIKey != "" || s.cfg.OpenRouterAPIKey != "",
 				"binance":    settings.BinanceAPIKey != "" || s.cfg.BinanceAPIKey != "",
+				"openai":     settings.OpenAIAPIKey != "",
+				"anthropic":  settings.AnthropicAPIKey != "",
+				"deepseek":   settings.DeepSeekAPIKey != "",
+				"ollama":     settings.OllamaBaseURL != "",
+				"local":      settings.LocalAIBaseURL != "",
 			},
 		}
 		s.jsonResponse(w, response)
@@ -1372,6 +1955,18 @@ func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
 		if isMasked(req.BinanceSecretKey) {
 			req.BinanceSecretKey = existing.BinanceSecretKey
 		}
+		if isMasked(req.OpenAIAPIKey) {
+			req.OpenAIAPIKey = existing.OpenAIAPIKey
+		}
+		if isMasked(req.AnthropicAPIKey) {
+			req.AnthropicAPIKey = existing.AnthropicAPIKey
+		}
+		if isMasked(req.DeepSeekAPIKey) {
+			req.DeepSeekAPIKey = existing.DeepSeekAPIKey
+		}
+		if isMasked(req.LocalAIAPIKey) {
+			req.LocalAIAPIKey = existing.LocalAIAPIKey
+		}
 
 		if err := s.settingsStore.SaveGlobalSettings(&req); err != nil {
 			s.errorResponse(w, http.StatusInternalServerError, err.Error())
@@ -1388,6 +1983,318 @@ func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleAIModels lists the models a configured provider serves, so
+// self-hosted models can be picked by name:
+//
+//	GET /api/ai/models?provider=ollama|local|openai|deepseek|openrouter
+func (s *Server) handleAIModels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	settings, err := s.settingsStore.GetGlobalSettings()
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	provider := r.URL.Query().Get("provider")
+	var client *mcp.Client
+	switch provider {
+	case mcp.ProviderOllama:
+		client = mcp.NewOllamaClient(settings.OllamaBaseURL, "")
+	case mcp.ProviderLocal:
+		client = mcp.NewLocalClient(settings.LocalAIBaseURL, settings.LocalAIAPIKey, "")
+	case mcp.ProviderOpenAI, mcp.ProviderDeepSeek, mcp.ProviderOpenRouter:
+		apiKey, baseURL := settings.OpenAIAPIKey, settings.OpenAIBaseURL
+		if provider == mcp.ProviderDeepSeek {
+			apiKey, baseURL = settings.DeepSeekAPIKey, settings.DeepSeekBaseURL
+		} else if provider == mcp.ProviderOpenRouter {
+			apiKey, baseURL = settings.OpenRouterAPIKey, settings.OpenRouterBaseURL
+			if apiKey == "" {
+				apiKey = s.cfg.OpenRouterAPIKey
+			}
+		}
+		opts := []mcp.Option{mcp.WithProvider(provider), mcp.WithAPIKey(apiKey)}
+		if baseURL != "" {
+			opts = append(opts, mcp.WithBaseURL(baseURL))
+		}
+		client = mcp.NewClient(opts...)
+	default:
+		s.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("model listing not supported for provider %q", provider))
+		return
+	}
+	client.SetTimeout(10 * time.Second)
+
+	models, err := client.ListModels()
+	if err != nil {
+		s.errorResponse(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	s.jsonResponse(w, map[string]interface{}{"provider": provider, "models": models})
+}
+
+// ============ AI USAGE ENDPOINTS ============
+
+// spendFilter reads trader_id, caller_type and from/to (YYYY-MM-DD, to is
+// inclusive) from the query string
+func spendFilter(r *http.Request) (store.SpendFilter, error) {
+	q := r.URL.Query()
+	f := store.SpendFilter{
+		TraderID:   q.Get("trader_id"),
+		CallerType: q.Get("caller_type"),
+	}
+	if from := q.Get("from"); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			return f, fmt.Errorf("invalid from date: %s", from)
+		}
+		f.From = t
+	}
+	if to := q.Get("to"); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			return f, fmt.Errorf("invalid to date: %s", to)
+		}
+		f.To = t.AddDate(0, 0, 1)
+	}
+	return f, nil
+}
+
+// handleAIUsage aggregates AI spend:
+//
+//	GET /api/ai/usage?group_by=trader|day|model|caller&trader_id=&caller_type=&from=&to=
+func (s *Server) handleAIUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	f, err := spendFilter(r)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = "trader"
+	}
+
+	rows, err := s.aiUsageStore.Spend(groupBy, f)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	total := 0.0
+	for _, row := range rows {
+		total += row.CostUSD
+	}
+	s.jsonResponse(w, map[string]interface{}{
+		"group_by":       groupBy,
+		"rows":           rows,
+		"total_cost_usd": total,
+	})
+}
+
+func (s *Server) handleAICalls(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	f, err := spendFilter(r)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := 100
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 1000 {
+		limit = l
+	}
+
+	calls, err := s.aiUsageStore.RecentCalls(f, limit)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.jsonResponse(w, map[string]interface{}{"calls": calls})
+}
+
+// handleAIPrices manages the model price table (USD per million tokens).
+// Model IDs contain slashes, so DELETE takes the model as ?model=.
+func (s *Server) handleAIPrices(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		prices, err := s.aiUsageStore.ListPrices()
+		if err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.jsonResponse(w, map[string]interface{}{"prices": prices})
+
+	case "PUT":
+		var price store.ModelPrice
+		if err := json.NewDecoder(r.Body).Decode(&price); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if price.Model == "" || price.PromptPerMTok < 0 || price.CompletionPerMTok < 0 {
+			s.errorResponse(w, http.StatusBadRequest, "model is required and prices can't be negative")
+			return
+		}
+		if err := s.aiUsageStore.SetPrice(&price); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.jsonResponse(w, price)
+
+	case "DELETE":
+		model := r.URL.Query().Get("model")
+		if model == "" {
+			s.errorResponse(w, http.StatusBadRequest, "model is required")
+			return
+		}
+		if err := s.aiUsageStore.DeletePrice(model); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.jsonResponse(w, map[string]string{"status": "deleted"})
+
+	default:
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleAIBudgets reports each trader's AI spend this month against its budget
+func (s *Server) handleAIBudgets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	traders, err := s.traderStore.List()
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	budgets := make([]map[string]interface{}, 0, len(traders))
+	for _, t := range traders {
+		status, err := s.meter.Budget(t.ID)
+		if err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		budgets = append(budgets, map[string]interface{}{
+			"trader_id":   t.ID,
+			"trader_name": t.Name,
+			"budget_usd":  status.BudgetUSD,
+			"spent_usd":   status.SpentUSD,
+			"paused":      status.Paused,
+			"resets_at":   status.ResetsAt,
+		})
+	}
+	s.jsonResponse(w, map[string]interface{}{"budgets": budgets})
+}
+
+// loadPromptTemplates builds a prompt registry from the built-in templates,
+// the prompts directory and the DB, and makes it the default
+func (s *Server) loadPromptTemplates() error {
+	registry := decision.NewPromptRegistry()
+	if err := registry.LoadDir(s.cfg.PromptsDir); err != nil {
+		return fmt.Errorf("failed to load prompts from %s: %w", s.cfg.PromptsDir, err)
+	}
+
+	saved, err := s.promptStore.List()
+	if err != nil {
+		return fmt.Errorf("failed to load saved prompts: %w", err)
+	}
+	for _, t := range saved {
+		tmpl, err := decision.NewPromptTemplate(t.Variant, t.Name, t.Language, t.Version, decision.PromptSourceDB, t.Body)
+		if err != nil {
+			log.Printf("[Prompt] Skipping saved template %s/%s v%d: %v", t.Variant, t.Name, t.Version, err)
+			continue
+		}
+		registry.Add(tmpl)
+	}
+
+	decision.SetDefaultPromptRegistry(registry)
+	log.Printf("[Prompt] Loaded %d templates in variants %v", len(registry.List()), registry.Variants())
+	return nil
+}
+
+// handlePrompts lists the prompt templates in use (GET) or saves a new
+// version of one (POST)
+func (s *Server) handlePrompts(w http.ResponseWriter, r *http.Request) {
+	registry := decision.DefaultPromptRegistry()
+
+	switch r.Method {
+	case "GET":
+		s.jsonResponse(w, map[string]interface{}{
+			"templates": registry.List(),
+			"variants":  registry.Variants(),
+		})
+
+	case "POST":
+		var req store.PromptTemplate
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.Variant == "" || req.Name == "" || req.Body == "" {
+			s.errorResponse(w, http.StatusBadRequest, "variant, name and body are required")
+			return
+		}
+		if req.Language == string(decision.LangEnglish) {
+			req.Language = ""
+		}
+
+		// Check the body parses before saving it
+		if _, err := decision.NewPromptTemplate(req.Variant, req.Name, req.Language, 1, decision.PromptSourceDB, req.Body); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Outrank the current version so the saved one takes effect
+		req.Version = 1
+		if cur, ok := registry.Get(req.Variant, req.Name, req.Language); ok &&
+			cur.Variant == req.Variant && cur.Language == req.Language {
+			req.Version = cur.Version + 1
+		}
+		if err := s.promptStore.Save(&req); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		tmpl, _ := decision.NewPromptTemplate(req.Variant, req.Name, req.Language, req.Version, decision.PromptSourceDB, req.Body)
+		registry.Add(tmpl)
+		log.Printf("[Prompt] Saved %s", tmpl.Ref())
+		s.jsonResponse(w, tmpl)
+
+	default:
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handlePromptsReload reloads the prompt templates from disk and the DB
+func (s *Server) handlePromptsReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		s.errorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if err := s.loadPromptTemplates(); err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	registry := decision.DefaultPromptRegistry()
+	s.jsonResponse(w, map[string]interface{}{
+		"templates": registry.List(),
+		"variants":  registry.Variants(),
+	})
+}
+
 // isMasked checks if a string contains masked characters
 func isMasked(s string) bool {
 	return len(s) > 0 && (s == "****" || (len(s) > 8 && s[4:8] == "****"))
@@ -1412,11 +2319,17 @@ func (s *Server) reloadConfig() {
 	}
 
 	// Update AI client
-	s.aiClient = mcp.NewOpenRouterClient(apiKey, model)
-	s.debateEngine.RegisterClient("openrouter", s.aiClient)
-	s.debateEngine.RegisterClient("openai", s.aiClient)
-	s.debateEngine.RegisterClient("anthropic", s.aiClient)
-	s.debateEngine.RegisterClient("deepseek", s.aiClient)
+	if settings.OpenRouterBaseURL != "" {
+		s.aiClient = mcp.NewClient(
+			mcp.WithProvider(mcp.ProviderOpenRouter),
+			mcp.WithAPIKey(apiKey),
+			mcp.WithModel(model),
+			mcp.WithBaseURL(settings.OpenRouterBaseURL),
+		)
+	} else {
+		s.aiClient = mcp.NewOpenRouterClient(apiKey, model)
+	}
+	s.configureDebateClients(settings)
 
 	// Update Binance client
 	binanceKey := settings.BinanceAPIKey
@@ -1434,10 +2347,57 @@ func (s *Server) reloadConfig() {
 
 	s.binanceClient = exchange.NewBinanceClient(binanceKey, binanceSecret, testnet)
 	s.backtestManager = backtest.NewManager(s.aiClient, s.binanceClient)
+	s.backtestManager.SetUsageMeter(s.meter)
 
 	log.Printf("Config reloaded: OpenRouter model=%s, Binance testnet=%v", model, testnet)
 }
 
+// configureDebateClients gives the debate engine a native client for every
+// provider with an API key, alongside OpenRouter as the optional fallback
+func (s *Server) configureDebateClients(settings *store.GlobalSettings) {
+	clients := map[string]mcp.AIClient{
+		mcp.ProviderOpenRouter: s.aiClient,
+	}
+
+	native := []struct {
+		provider, apiKey, baseURL string
+	}{
+		{mcp.ProviderOpenAI, settings.OpenAIAPIKey, settings.OpenAIBaseURL},
+		{mcp.ProviderAnthropic, settings.AnthropicAPIKey, settings.AnthropicBaseURL},
+		{mcp.ProviderDeepSeek, settings.DeepSeekAPIKey, settings.DeepSeekBaseURL},
+	}
+	var configured []string
+	for _, p := range native {
+		if p.apiKey == "" {
+			continue
+		}
+		opts := []mcp.Option{mcp.WithProvider(p.provider), mcp.WithAPIKey(p.apiKey)}
+		if p.baseURL != "" {
+			opts = append(opts, mcp.WithBaseURL(p.baseURL))
+		}
+		clients[p.provider] = mcp.NewClient(opts...)
+		configured = append(configured, p.provider)
+	}
+
+	// Self-hosted models are enabled by their base URL; no key needed
+	if settings.OllamaBaseURL != "" {
+		clients[mcp.ProviderOllama] = mcp.NewOllamaClient(settings.OllamaBaseURL, "")
+		configured = append(configured, mcp.ProviderOllama)
+	}
+	if settings.LocalAIBaseURL != "" {
+		clients[mcp.ProviderLocal] = mcp.NewLocalClient(settings.LocalAIBaseURL, settings.LocalAIAPIKey, "")
+		configured = append(configured, mcp.ProviderLocal)
+	}
+
+	fallback := ""
+	if settings.OpenRouterFallback {
+		fallback = mcp.ProviderOpenRouter
+	}
+	s.debateEngine.SetClients(clients, fallback)
+
+	log.Printf("Debate providers: native=%v, fallback=%q", configured, fallback)
+}
+
 // ============ SYSTEM ENDPOINTS ============
 
 func (s *Server) handleLogStream(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,659 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"auto-trader-ahh/decision"
+	"auto-trader-ahh/mcp"
+)
+
+// A prompt evaluation replays decision points on historical bars through two
+// arms (prompt variants and/or models) and scores every opening decision
+// against what the price did next. Nothing is traded, so a prompt change can
+// be measured before it risks money.
+
+// Exit reasons of a scored decision
+const (
+	ExitTakeProfit = "take_profit"
+	ExitStopLoss   = "stop_loss"
+	ExitHorizon    = "horizon"
+)
+
+// EvalArm is one side of a prompt evaluation
+type EvalArm struct {
+	Name          string `json:"name"`                     // Label in the report, defaults to variant/model
+	PromptVariant string `json:"prompt_variant,omitempty"` // Prompt registry variant, default when empty
+	Model         string `json:"model,omitempty"`          // Overrides the AI client's model
+}
+
+// EvalConfig configures a prompt evaluation
+type EvalConfig struct {
+	EvalID            string    `json:"eval_id"`
+	Name              string    `json:"name"`
+	BacktestRunID     string    `json:"backtest_run_id,omitempty"` // Reuse a run's bars and decision points
+	Symbols           []string  `json:"symbols"`
+	DecisionTimeframe string    `json:"decision_timeframe"`
+	StartTS           int64     `json:"start_ts"`
+	EndTS             int64     `json:"end_ts"`
+	SampleEveryNBars  int       `json:"sample_every_n_bars"` // Decision point spacing when not reusing a run
+	MaxSamples        int       `json:"max_samples"`         // Decision points are thinned evenly to this many
+	HorizonBars       int       `json:"horizon_bars"`        // Bars after a decision used to score it
+	InitialBalance    float64   `json:"initial_balance"`
+	BTCETHLeverage    int       `json:"btc_eth_leverage"`
+	AltcoinLeverage   int       `json:"altcoin_leverage"`
+	BTCETHPosRatio    float64   `json:"btc_eth_pos_ratio"`
+	AltcoinPosRatio   float64   `json:"altcoin_pos_ratio"`
+	Language          string    `json:"language"`
+	Arms              []EvalArm `json:"arms"` // Exactly two: A and B
+}
+
+// Validate checks the config and fills in defaults
+func (c *EvalConfig) Validate() error {
+	if len(c.Arms) != 2 {
+		return fmt.Errorf("an evaluation needs exactly 2 arms, got %d", len(c.Arms))
+	}
+	if c.Arms[0].PromptVariant == c.Arms[1].PromptVariant && c.Arms[0].Model == c.Arms[1].Model {
+		return fmt.Errorf("arms must differ in prompt variant or model")
+	}
+	for i := range c.Arms {
+		if c.Arms[i].Name == "" {
+			c.Arms[i].Name = c.Arms[i].label()
+		}
+	}
+	if c.Arms[0].Name == c.Arms[1].Name {
+		c.Arms[1].Name += " (B)"
+	}
+	if c.BacktestRunID == "" && (len(c.Symbols) == 0 || c.StartTS <= 0 || c.EndTS <= c.StartTS) {
+		return fmt.Errorf("symbols and a time range are required without a backtest run")
+	}
+
+	if c.DecisionTimeframe == "" {
+		c.DecisionTimeframe = "1h"
+	}
+	if c.SampleEveryNBars <= 0 {
+		c.SampleEveryNBars = 4
+	}
+	if c.MaxSamples <= 0 {
+		c.MaxSamples = 50
+	}
+	if c.HorizonBars <= 0 {
+		c.HorizonBars = 12
+	}
+	if c.InitialBalance <= 0 {
+		c.InitialBalance = 10000
+	}
+	if c.BTCETHLeverage <= 0 {
+		c.BTCETHLeverage = 20
+	}
+	if c.AltcoinLeverage <= 0 {
+		c.AltcoinLeverage = 10
+	}
+	if c.BTCETHPosRatio <= 0 {
+		c.BTCETHPosRatio = 0.3
+	}
+	if c.AltcoinPosRatio <= 0 {
+		c.AltcoinPosRatio = 0.15
+	}
+	if c.Language == "" {
+		c.Language = "en-US"
+	}
+	return nil
+}
+
+func (a EvalArm) label() string {
+	variant := a.PromptVariant
+	if variant == "" {
+		variant = decision.DefaultPromptVariant
+	}
+	if a.Model == "" {
+		return variant
+	}
+	return variant + "/" + a.Model
+}
+
+// EvalOutcome scores one opening decision against the bars that followed
+type EvalOutcome struct {
+	Symbol       string  `json:"symbol"`
+	Action       string  `json:"action"`
+	Confidence   int     `json:"confidence"`
+	EntryPrice   float64 `json:"entry_price"`
+	StopLoss     float64 `json:"stop_loss"`
+	TakeProfit   float64 `json:"take_profit"`
+	ExitPrice    float64 `json:"exit_price"`
+	Exit         string  `json:"exit"`          // take_profit, stop_loss or horizon
+	DirectionHit bool    `json:"direction_hit"` // Price at the horizon moved the decision's way
+	RMultiple    float64 `json:"r_multiple"`    // Exit PnL in units of the stop distance
+	HasR         bool    `json:"has_r"`         // False without a stop on the right side of entry
+}
+
+// EvalArmResult is one arm's decision at a decision point
+type EvalArmResult struct {
+	Arm        string               `json:"arm"`
+	ParsePath  string               `json:"parse_path,omitempty"`
+	Decisions  []decision.Decision  `json:"decisions"`
+	Outcomes   []EvalOutcome        `json:"outcomes"`
+	Prompts    []decision.PromptRef `json:"prompts,omitempty"`
+	DurationMs int64                `json:"duration_ms"`
+	Error      string               `json:"error,omitempty"` // AI, parse or validation failure; nothing is scored
+}
+
+// EvalSample is one decision point, run through both arms
+type EvalSample struct {
+	Timestamp int64           `json:"timestamp"`
+	Arms      []EvalArmResult `json:"arms"`
+}
+
+// EvalArmStats aggregates an arm's scored decisions
+type EvalArmStats struct {
+	Arm           string         `json:"arm"`
+	PromptVariant string         `json:"prompt_variant,omitempty"`
+	Model         string         `json:"model,omitempty"`
+	Samples       int            `json:"samples"`
+	Errors        int            `json:"errors"`
+	Opens         int            `json:"opens"` // Scored opening decisions
+	Passive       int            `json:"passive"`
+	DirectionHits int            `json:"direction_hits"`
+	HitRate       float64        `json:"hit_rate"` // DirectionHits / Opens
+	StopHits      int            `json:"stop_hits"`
+	StopHitRate   float64        `json:"stop_hit_rate"`
+	TargetHits    int            `json:"target_hits"`
+	TargetHitRate float64        `json:"target_hit_rate"`
+	AvgR          float64        `json:"avg_r"` // Mean R-multiple over opens with a valid stop
+	TotalR        float64        `json:"total_r"`
+	ParsePaths    map[string]int `json:"parse_paths"`
+}
+
+// EvalReport is the comparison report of an evaluation
+type EvalReport struct {
+	EvalID       string          `json:"eval_id"`
+	Name         string          `json:"name"`
+	Status       RunStatus       `json:"status"`
+	Config       *EvalConfig     `json:"config"`
+	Progress     float64         `json:"progress"`
+	TotalSamples int             `json:"total_samples"`
+	Arms         []*EvalArmStats `json:"arms"`
+	Agreement    float64         `json:"agreement"` // Share of symbol decisions where both arms chose the same action
+	Winner       string          `json:"winner"`    // Arm with the better average R, "tie", or empty before any scored opens
+	Samples      []EvalSample    `json:"samples,omitempty"`
+	StartedAt    time.Time       `json:"started_at"`
+	CompletedAt  time.Time       `json:"completed_at,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// EvalRunner runs a prompt evaluation
+type EvalRunner struct {
+	config  *EvalConfig
+	engines []*decision.Engine
+	klines  map[string][]Kline // symbol -> klines
+	points  []int64            // Decision bar close times, empty to derive from the bars
+	report  *EvalReport
+	mu      sync.RWMutex
+	cancel  context.CancelFunc
+}
+
+// NewEvalRunner creates an evaluation with one decision engine per arm
+func NewEvalRunner(cfg *EvalConfig, client mcp.AIClient) *EvalRunner {
+	lang := decision.LangEnglish
+	if cfg.Language == "zh-CN" {
+		lang = decision.LangChinese
+	}
+
+	r := &EvalRunner{
+		config: cfg,
+		klines: make(map[string][]Kline),
+		report: &EvalReport{
+			EvalID: cfg.EvalID,
+			Name:   cfg.Name,
+			Status: StatusPending,
+			Config: cfg,
+		},
+	}
+
+	for _, arm := range cfg.Arms {
+		engine := decision.NewEngine(client, lang)
+		engine.SetPromptVariant(arm.PromptVariant)
+		engine.SetModel(arm.Model)
+		engine.SetValidationConfig(&decision.ValidationConfig{
+			AccountEquity:     cfg.InitialBalance,
+			BTCETHLeverage:    cfg.BTCETHLeverage,
+			AltcoinLeverage:   cfg.AltcoinLeverage,
+			BTCETHPosRatio:    cfg.BTCETHPosRatio,
+			AltcoinPosRatio:   cfg.AltcoinPosRatio,
+			MinPositionBTCETH: 60,
+			MinPositionAlt:    12,
+			// The validator estimates R:R without the entry price; the
+			// scoring measures the real R-multiple instead
+			MinRiskReward: 0,
+		})
+		r.engines = append(r.engines, engine)
+		r.report.Arms = append(r.report.Arms, &EvalArmStats{
+			Arm:           arm.Name,
+			PromptVariant: arm.PromptVariant,
+			Model:         arm.Model,
+			ParsePaths:    make(map[string]int),
+		})
+	}
+	return r
+}
+
+// LoadKlines loads historical klines for a symbol
+func (r *EvalRunner) LoadKlines(symbol string, klines []Kline) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.klines[symbol] = klines
+}
+
+// SetDecisionPoints evaluates at these bar close times instead of every
+// SampleEveryNBars bars
+func (r *EvalRunner) SetDecisionPoints(points []int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.points = points
+}
+
+// GetReport returns a snapshot of the report; withSamples includes every
+// decision point
+func (r *EvalRunner) GetReport(withSamples bool) *EvalReport {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	report := *r.report
+	report.Arms = make([]*EvalArmStats, len(r.report.Arms))
+	for i, stats := range r.report.Arms {
+		s := *stats
+		s.ParsePaths = make(map[string]int, len(stats.ParsePaths))
+		for path, n := range stats.ParsePaths {
+			s.ParsePaths[path] = n
+		}
+		report.Arms[i] = &s
+	}
+	report.Samples = nil
+	if withSamples {
+		report.Samples = append([]EvalSample(nil), r.report.Samples...)
+	}
+	return &report
+}
+
+// Start runs the evaluation to completion or cancellation
+func (r *EvalRunner) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	defer cancel()
+
+	r.mu.Lock()
+	r.report.Status = StatusRunning
+	r.report.StartedAt = time.Now()
+	r.mu.Unlock()
+
+	err := r.run(ctx)
+
+	r.mu.Lock()
+	if err != nil {
+		r.report.Status = StatusFailed
+		r.report.Error = err.Error()
+	} else {
+		r.report.Status = StatusCompleted
+	}
+	r.report.CompletedAt = time.Now()
+	r.mu.Unlock()
+	return err
+}
+
+// Stop cancels a running evaluation
+func (r *EvalRunner) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+func (r *EvalRunner) run(ctx context.Context) error {
+	r.mu.RLock()
+	points := r.decisionPoints()
+	r.mu.RUnlock()
+	if len(points) == 0 {
+		return fmt.Errorf("no decision points with %d bars of outcome after them", r.config.HorizonBars)
+	}
+
+	r.mu.Lock()
+	r.report.TotalSamples = len(points)
+	r.mu.Unlock()
+
+	log.Printf("[Eval] %s: %d decision points, %s vs %s", r.config.EvalID, len(points),
+		r.config.Arms[0].Name, r.config.Arms[1].Name)
+
+	for i, ts := range points {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		sample := EvalSample{Timestamp: ts}
+		for a, engine := range r.engines {
+			sample.Arms = append(sample.Arms, r.runArm(r.config.Arms[a].Name, engine, ts))
+		}
+
+		r.mu.Lock()
+		r.report.Samples = append(r.report.Samples, sample)
+		r.report.Progress = float64(i+1) / float64(len(points)) * 100
+		summarizeEval(r.report, r.config.Symbols)
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// runArm asks one arm for a decision at ts and scores what it opened
+func (r *EvalRunner) runArm(name string, engine *decision.Engine, ts int64) EvalArmResult {
+	result := EvalArmResult{Arm: name}
+
+	fullDecision, err := engine.MakeDecision(r.buildContext(ts))
+	if fullDecision != nil {
+		result.ParsePath = fullDecision.ParsePath
+		result.Decisions = fullDecision.Decisions
+		result.Prompts = fullDecision.Prompts
+		result.DurationMs = fullDecision.AIRequestDurationMs
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	for _, d := range fullDecision.Decisions {
+		bars := r.klines[d.Symbol]
+		idx := barIndexAt(bars, ts)
+		if idx < 0 || idx+r.config.HorizonBars >= len(bars) {
+			continue
+		}
+		if outcome, ok := scoreDecision(d, bars[idx].Close, bars[idx+1:idx+1+r.config.HorizonBars]); ok {
+			result.Outcomes = append(result.Outcomes, outcome)
+		}
+	}
+	return result
+}
+
+// decisionPoints returns the bar close times to evaluate at, each with
+// HorizonBars of outcome after it, thinned evenly to MaxSamples
+func (r *EvalRunner) decisionPoints() []int64 {
+	var primary []Kline
+	for _, klines := range r.klines {
+		if len(klines) > len(primary) {
+			primary = klines
+		}
+	}
+
+	var points []int64
+	if len(r.points) > 0 {
+		for _, ts := range r.points {
+			if idx := barIndexAt(primary, ts); idx >= 0 && idx+r.config.HorizonBars < len(primary) {
+				points = append(points, ts)
+			}
+		}
+	} else {
+		for i := r.config.SampleEveryNBars - 1; i+r.config.HorizonBars < len(primary); i += r.config.SampleEveryNBars {
+			bar := primary[i]
+			if bar.CloseTime >= r.config.StartTS && bar.OpenTime <= r.config.EndTS {
+				points = append(points, bar.CloseTime)
+			}
+		}
+	}
+
+	if len(points) <= r.config.MaxSamples {
+		return points
+	}
+	thinned := make([]int64, 0, r.config.MaxSamples)
+	step := float64(len(points)) / float64(r.config.MaxSamples)
+	for i := 0; i < r.config.MaxSamples; i++ {
+		thinned = append(thinned, points[int(float64(i)*step)])
+	}
+	return thinned
+}
+
+// buildContext builds a flat-account decision context from the bars up to ts
+func (r *EvalRunner) buildContext(ts int64) *decision.Context {
+	marketData := make(map[string]*decision.MarketData)
+	for symbol, bars := range r.klines {
+		idx := barIndexAt(bars, ts)
+		if idx < 0 {
+			continue
+		}
+		bar := bars[idx]
+		data := &decision.MarketData{
+			Symbol:       symbol,
+			Price:        bar.Close,
+			HighPrice24h: bar.High,
+			LowPrice24h:  bar.Low,
+			Timestamp:    time.UnixMilli(bar.CloseTime),
+		}
+
+		// 24h statistics from the bars that closed in the last day
+		for j := idx; j >= 0 && bars[j].CloseTime > ts-24*60*60*1000; j-- {
+			data.HighPrice24h = maxFloat(data.HighPrice24h, bars[j].High)
+			data.LowPrice24h = minFloat(data.LowPrice24h, bars[j].Low)
+			data.Volume24h += bars[j].Volume * bars[j].Close
+			if bars[j].Open > 0 {
+				data.Change24h = (bar.Close - bars[j].Open) / bars[j].Open * 100
+			}
+		}
+
+		// Recent bars for templates that render them
+		for j := maxInt(0, idx-49); j <= idx; j++ {
+			k := bars[j]
+			data.Klines = append(data.Klines, decision.Kline{
+				OpenTime: k.OpenTime, Open: k.Open, High: k.High, Low: k.Low,
+				Close: k.Close, Volume: k.Volume, CloseTime: k.CloseTime,
+			})
+		}
+		marketData[symbol] = data
+	}
+
+	return &decision.Context{
+		CurrentTime: time.UnixMilli(ts).UTC().Format(time.RFC3339),
+		Account: decision.AccountInfo{
+			TotalEquity:      r.config.InitialBalance,
+			AvailableBalance: r.config.InitialBalance,
+		},
+		MarketDataMap:   marketData,
+		BTCETHLeverage:  r.config.BTCETHLeverage,
+		AltcoinLeverage: r.config.AltcoinLeverage,
+		BTCETHPosRatio:  r.config.BTCETHPosRatio,
+		AltcoinPosRatio: r.config.AltcoinPosRatio,
+	}
+}
+
+// barIndexAt returns the index of the last bar closed at or before ts, or -1
+func barIndexAt(bars []Kline, ts int64) int {
+	return sort.Search(len(bars), func(i int) bool { return bars[i].CloseTime > ts }) - 1
+}
+
+// scoreDecision scores an opening decision entered at entry against the
+// bars that followed. The stop is checked before the target when one bar
+// spans both. Passive and closing decisions aren't scored.
+func scoreDecision(d decision.Decision, entry float64, future []Kline) (EvalOutcome, bool) {
+	var dir float64
+	switch d.Action {
+	case decision.ActionOpenLong:
+		dir = 1
+	case decision.ActionOpenShort:
+		dir = -1
+	default:
+		return EvalOutcome{}, false
+	}
+	if entry <= 0 || len(future) == 0 {
+		return EvalOutcome{}, false
+	}
+
+	last := future[len(future)-1].Close
+	out := EvalOutcome{
+		Symbol:       d.Symbol,
+		Action:       d.Action,
+		Confidence:   d.Confidence,
+		EntryPrice:   entry,
+		StopLoss:     d.StopLoss,
+		TakeProfit:   d.TakeProfit,
+		ExitPrice:    last,
+		Exit:         ExitHorizon,
+		DirectionHit: (last-entry)*dir > 0,
+	}
+
+	for _, bar := range future {
+		adverse, favorable := bar.Low, bar.High
+		if dir < 0 {
+			adverse, favorable = bar.High, bar.Low
+		}
+		if d.StopLoss > 0 && (adverse-d.StopLoss)*dir <= 0 {
+			out.Exit, out.ExitPrice = ExitStopLoss, d.StopLoss
+			break
+		}
+		if d.TakeProfit > 0 && (favorable-d.TakeProfit)*dir >= 0 {
+			out.Exit, out.ExitPrice = ExitTakeProfit, d.TakeProfit
+			break
+		}
+	}
+
+	if risk := (entry - d.StopLoss) * dir; d.StopLoss > 0 && risk > 0 {
+		out.RMultiple = (out.ExitPrice - entry) * dir / risk
+		out.HasR = true
+	}
+	return out, true
+}
+
+// summarizeEval recomputes the arm statistics, agreement and winner from
+// the samples
+func summarizeEval(report *EvalReport, symbols []string) {
+	var rCounts []int
+	for i, stats := range report.Arms {
+		*stats = EvalArmStats{
+			Arm:           stats.Arm,
+			PromptVariant: stats.PromptVariant,
+			Model:         stats.Model,
+			ParsePaths:    make(map[string]int),
+		}
+		rCount := 0
+		for _, sample := range report.Samples {
+			if i >= len(sample.Arms) {
+				continue
+			}
+			res := sample.Arms[i]
+			stats.Samples++
+			if res.ParsePath != "" {
+				stats.ParsePaths[res.ParsePath]++
+			}
+			if res.Error != "" {
+				stats.Errors++
+				continue
+			}
+			for _, d := range res.Decisions {
+				if decision.IsPassiveAction(d.Action) {
+					stats.Passive++
+				}
+			}
+			for _, o := range res.Outcomes {
+				stats.Opens++
+				if o.DirectionHit {
+					stats.DirectionHits++
+				}
+				switch o.Exit {
+				case ExitStopLoss:
+					stats.StopHits++
+				case ExitTakeProfit:
+					stats.TargetHits++
+				}
+				if o.HasR {
+					stats.TotalR += o.RMultiple
+					rCount++
+				}
+			}
+		}
+		if stats.Opens > 0 {
+			stats.HitRate = float64(stats.DirectionHits) / float64(stats.Opens)
+			stats.StopHitRate = float64(stats.StopHits) / float64(stats.Opens)
+			stats.TargetHitRate = float64(stats.TargetHits) / float64(stats.Opens)
+		}
+		if rCount > 0 {
+			stats.AvgR = stats.TotalR / float64(rCount)
+		}
+		rCounts = append(rCounts, rCount)
+	}
+
+	report.Agreement = evalAgreement(report.Samples, symbols)
+
+	report.Winner = ""
+	if len(report.Arms) == 2 && rCounts[0] > 0 && rCounts[1] > 0 {
+		a, b := report.Arms[0], report.Arms[1]
+		switch {
+		case a.AvgR > b.AvgR, a.AvgR == b.AvgR && a.HitRate > b.HitRate:
+			report.Winner = a.Arm
+		case b.AvgR > a.AvgR, b.HitRate > a.HitRate:
+			report.Winner = b.Arm
+		default:
+			report.Winner = "tie"
+		}
+	}
+}
+
+// evalAgreement is the share of symbol decisions, over samples where both
+// arms answered, on which they chose the same action. A symbol without a
+// decision counts as wait.
+func evalAgreement(samples []EvalSample, symbols []string) float64 {
+	same, total := 0, 0
+	for _, sample := range samples {
+		if len(sample.Arms) != 2 || sample.Arms[0].Error != "" || sample.Arms[1].Error != "" {
+			continue
+		}
+		a, b := symbolActions(sample.Arms[0].Decisions), symbolActions(sample.Arms[1].Decisions)
+		for _, symbol := range symbols {
+			total++
+			if actionFor(a, symbol) == actionFor(b, symbol) {
+				same++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(same) / float64(total)
+}
+
+func symbolActions(decisions []decision.Decision) map[string]string {
+	actions := make(map[string]string, len(decisions))
+	for _, d := range decisions {
+		actions[d.Symbol] = d.Action
+	}
+	return actions
+}
+
+func actionFor(actions map[string]string, symbol string) string {
+	if action, ok := actions[symbol]; ok {
+		return action
+	}
+	if action, ok := actions["ALL"]; ok {
+		return action
+	}
+	return decision.ActionWait
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,149 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"auto-trader-ahh/decision"
+	"auto-trader-ahh/mcp"
+)
+
+func TestScoreDecision(t *testing.T) {
+	bars := func(hlc ...[3]float64) []Kline {
+		out := make([]Kline, len(hlc))
+		for i, b := range hlc {
+			out[i] = Kline{High: b[0], Low: b[1], Close: b[2]}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		dec      decision.Decision
+		future   []Kline
+		wantExit string
+		wantHit  bool
+		wantR    float64
+		wantOK   bool
+	}{
+		{
+			name:     "long hits target",
+			dec:      decision.Decision{Action: decision.ActionOpenLong, StopLoss: 98, TakeProfit: 106},
+			future:   bars([3]float64{102, 99, 101}, [3]float64{107, 101, 105}),
+			wantExit: ExitTakeProfit, wantHit: true, wantR: 3, wantOK: true,
+		},
+		{
+			name:     "short stopped out",
+			dec:      decision.Decision{Action: decision.ActionOpenShort, StopLoss: 102, TakeProfit: 94},
+			future:   bars([3]float64{103, 99, 101}, [3]float64{100, 95, 96}),
+			wantExit: ExitStopLoss, wantHit: true, wantR: -1, wantOK: true,
+		},
+		{
+			name:     "bar spanning both levels counts as the stop",
+			dec:      decision.Decision{Action: decision.ActionOpenLong, StopLoss: 98, TakeProfit: 106},
+			future:   bars([3]float64{107, 97, 100}),
+			wantExit: ExitStopLoss, wantHit: false, wantR: -1, wantOK: true,
+		},
+		{
+			name:     "horizon exit without a stop has no R",
+			dec:      decision.Decision{Action: decision.ActionOpenLong},
+			future:   bars([3]float64{101, 99, 99.5}),
+			wantExit: ExitHorizon, wantHit: false, wantR: 0, wantOK: true,
+		},
+		{
+			name:     "horizon exit in units of the stop",
+			dec:      decision.Decision{Action: decision.ActionOpenShort, StopLoss: 104, TakeProfit: 90},
+			future:   bars([3]float64{101, 97, 98}),
+			wantExit: ExitHorizon, wantHit: true, wantR: 0.5, wantOK: true,
+		},
+		{
+			name:   "wait is not scored",
+			dec:    decision.Decision{Action: decision.ActionWait},
+			future: bars([3]float64{101, 99, 100}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := scoreDecision(tt.dec, 100, tt.future)
+			if ok != tt.wantOK {
+				t.Fatalf("scored = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got.Exit != tt.wantExit || got.DirectionHit != tt.wantHit || math.Abs(got.RMultiple-tt.wantR) > 1e-9 {
+				t.Errorf("got exit %s, hit %v, R %.2f; want %s, %v, %.2f",
+					got.Exit, got.DirectionHit, got.RMultiple, tt.wantExit, tt.wantHit, tt.wantR)
+			}
+		})
+	}
+}
+
+func TestEvalRunner_ComparesArms(t *testing.T) {
+	// Hourly bars: flat at 100 for a day, then a steady climb
+	var klines []Kline
+	for i := 0; i < 40; i++ {
+		price := 100.0
+		if i >= 24 {
+			price = 100 + float64(i-23)
+		}
+		klines = append(klines, Kline{
+			OpenTime:  int64(i) * 3600_000,
+			CloseTime: int64(i+1)*3600_000 - 1,
+			Open:      price, High: price + 0.5, Low: price - 0.5, Close: price, Volume: 10,
+		})
+	}
+
+	long := `{"reasoning":"Breakout","decisions":[{"symbol":"BTCUSDT","action":"open_long","confidence":80,"leverage":5,"position_pct":0,"position_size_usd":1000,"stop_loss":%g,"take_profit":%g,"reasoning":"Up"}]}`
+	wait := `{"reasoning":"Flat","decisions":[{"symbol":"BTCUSDT","action":"wait","confidence":50,"leverage":0,"position_pct":0,"position_size_usd":0,"stop_loss":0,"take_profit":0,"reasoning":"No setup"}]}`
+	// Calls alternate arm A, arm B at each decision point
+	client := mcp.NewScriptedClient(
+		wait, fmt.Sprintf(long, 99.0, 200.0),
+		wait, fmt.Sprintf(long, 120.0, 200.0),
+	)
+
+	cfg := &EvalConfig{
+		EvalID:      "eval_test",
+		Symbols:     []string{"BTCUSDT"},
+		StartTS:     1,
+		EndTS:       40 * 3600_000,
+		MaxSamples:  2,
+		HorizonBars: 4,
+		Arms:        []EvalArm{{PromptVariant: "default"}, {PromptVariant: "default", Model: "other"}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	runner := NewEvalRunner(cfg, client)
+	runner.LoadKlines("BTCUSDT", klines)
+	runner.SetDecisionPoints([]int64{klines[23].CloseTime, klines[30].CloseTime, klines[38].CloseTime})
+
+	if err := runner.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	report := runner.GetReport(true)
+
+	// The last point has no 4 bars of outcome after it
+	if report.TotalSamples != 2 || len(report.Samples) != 2 || client.Remaining() != 0 {
+		t.Fatalf("ran %d of %d samples, %d replies left", len(report.Samples), report.TotalSamples, client.Remaining())
+	}
+	if client.Requests[1].Model != "other" {
+		t.Errorf("arm B model = %q, want other", client.Requests[1].Model)
+	}
+
+	a, b := report.Arms[0], report.Arms[1]
+	if a.Arm != "default" || a.Passive != 2 || a.Opens != 0 {
+		t.Errorf("arm A = %+v", a)
+	}
+	// Entered at 100 with the stop at 99 and at 107 with the stop at 120
+	// (wrong side: scored but without R)
+	if b.Arm != "default/other" || b.Opens != 2 || b.DirectionHits != 2 || b.TotalR != 4 || b.AvgR != 4 {
+		t.Errorf("arm B = %+v", b)
+	}
+	if report.Agreement != 0 || report.Winner != "" {
+		t.Errorf("agreement %.2f, winner %q", report.Agreement, report.Winner)
+	}
+}
@@ -4,20 +4,25 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
 	"time"
 
 	"auto-trader-ahh/exchange"
 	"auto-trader-ahh/mcp"
+	"auto-trader-ahh/store"
+	"auto-trader-ahh/usage"
 )
 
 // Manager manages multiple backtest runs
 type Manager struct {
 	runners  map[string]*Runner
+	evals    map[string]*EvalRunner
 	metadata map[string]*RunMetadata
 	cancels  map[string]context.CancelFunc
 	client   mcp.AIClient
 	exchange *exchange.BinanceClient
+	meter    *usage.Meter // Records each run's AI calls; nil disables accounting
 	mu       sync.RWMutex
 }
 
@@ -25,6 +30,7 @@ type Manager struct {
 func NewManager(client mcp.AIClient, exch *exchange.BinanceClient) *Manager {
 	return &Manager{
 		runners:  make(map[string]*Runner),
+		evals:    make(map[string]*EvalRunner),
 		metadata: make(map[string]*RunMetadata),
 		cancels:  make(map[string]context.CancelFunc),
 		client:   client,
@@ -32,6 +38,13 @@ func NewManager(client mcp.AIClient, exch *exchange.BinanceClient) *Manager {
 	}
 }
 
+// SetUsageMeter records the AI calls of runs started from now on
+func (m *Manager) SetUsageMeter(meter *usage.Meter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.meter = meter
+}
+
 // Start starts a new backtest run
 func (m *Manager) Start(ctx context.Context, cfg *Config) (string, error) {
 	if cfg.RunID == "" {
@@ -49,7 +62,7 @@ func (m *Manager) Start(ctx context.Context, cfg *Config) (string, error) {
 		return "", fmt.Errorf("backtest %s already exists", cfg.RunID)
 	}
 
-	runner := NewRunner(cfg, m.client)
+	runner := NewRunner(cfg, m.meter.Wrap(m.client, store.CallerBacktest, cfg.RunID, ""))
 	m.runners[cfg.RunID] = runner
 	m.metadata[cfg.RunID] = runner.GetMetadata()
 	m.mu.Unlock()
@@ -62,29 +75,8 @@ func (m *Manager) Start(ctx context.Context, cfg *Config) (string, error) {
 		m.mu.Unlock()
 
 		// Fetch klines from Binance if exchange client is available
-		if m.exchange != nil {
-			for _, symbol := range cfg.Symbols {
-				exchKlines, err := m.exchange.GetHistoricalKlines(runCtx, symbol, cfg.DecisionTimeframe, cfg.StartTS, cfg.EndTS)
-				if err != nil {
-					log.Printf("Backtest %s: failed to fetch klines for %s: %v\n", cfg.RunID, symbol, err)
-					continue
-				}
-				// Convert exchange.Kline to backtest.Kline
-				klines := make([]Kline, len(exchKlines))
-				for i, k := range exchKlines {
-					klines[i] = Kline{
-						OpenTime:  k.OpenTime,
-						Open:      k.Open,
-						High:      k.High,
-						Low:       k.Low,
-						Close:     k.Close,
-						Volume:    k.Volume,
-						CloseTime: k.CloseTime,
-					}
-				}
-				runner.LoadKlines(symbol, klines)
-				log.Printf("Backtest %s: loaded %d klines for %s\n", cfg.RunID, len(klines), symbol)
-			}
+		for symbol, klines := range m.fetchKlines(runCtx, cfg.RunID, cfg.Symbols, cfg.DecisionTimeframe, cfg.StartTS, cfg.EndTS) {
+			runner.LoadKlines(symbol, klines)
 		}
 
 		if err := runner.Start(runCtx); err != nil {
@@ -100,6 +92,39 @@ func (m *Manager) Start(ctx context.Context, cfg *Config) (string, error) {
 	return cfg.RunID, nil
 }
 
+// fetchKlines fetches historical klines from Binance, skipping symbols that
+// fail. Returns nothing without an exchange client.
+func (m *Manager) fetchKlines(ctx context.Context, id string, symbols []string, timeframe string, startTS, endTS int64) map[string][]Kline {
+	result := make(map[string][]Kline)
+	if m.exchange == nil {
+		return result
+	}
+
+	for _, symbol := range symbols {
+		exchKlines, err := m.exchange.GetHistoricalKlines(ctx, symbol, timeframe, startTS, endTS)
+		if err != nil {
+			log.Printf("Backtest %s: failed to fetch klines for %s: %v\n", id, symbol, err)
+			continue
+		}
+		// Convert exchange.Kline to backtest.Kline
+		klines := make([]Kline, len(exchKlines))
+		for i, k := range exchKlines {
+			klines[i] = Kline{
+				OpenTime:  k.OpenTime,
+				Open:      k.Open,
+				High:      k.High,
+				Low:       k.Low,
+				Close:     k.Close,
+				Volume:    k.Volume,
+				CloseTime: k.CloseTime,
+			}
+		}
+		result[symbol] = klines
+		log.Printf("Backtest %s: loaded %d klines for %s\n", id, len(klines), symbol)
+	}
+	return result
+}
+
 // Stop stops a running backtest
 func (m *Manager) Stop(runID string) error {
 	m.mu.RLock()
@@ -213,3 +238,107 @@ func (m *Manager) LoadKlines(runID, symbol string, klines []Kline) error {
 	runner.LoadKlines(symbol, klines)
 	return nil
 }
+
+// StartEval starts a prompt evaluation. With BacktestRunID it replays that
+// run's decision points on its bars; otherwise it fetches bars like a
+// backtest and samples every SampleEveryNBars bars.
+func (m *Manager) StartEval(ctx context.Context, cfg *EvalConfig) (string, error) {
+	if cfg.EvalID == "" {
+		cfg.EvalID = fmt.Sprintf("eval_%d", time.Now().UnixNano())
+	}
+
+	var source *Runner
+	if cfg.BacktestRunID != "" {
+		m.mu.RLock()
+		source = m.runners[cfg.BacktestRunID]
+		m.mu.RUnlock()
+		if source == nil {
+			return "", fmt.Errorf("backtest %s not found", cfg.BacktestRunID)
+		}
+		// Inherit what the run was configured with
+		run := source.GetMetadata().Config
+		if len(cfg.Symbols) == 0 {
+			cfg.Symbols = run.Symbols
+		}
+		if cfg.DecisionTimeframe == "" {
+			cfg.DecisionTimeframe = run.DecisionTimeframe
+		}
+		if cfg.Language == "" {
+			cfg.Language = run.Language
+		}
+		if cfg.InitialBalance <= 0 {
+			cfg.InitialBalance = run.InitialBalance
+		}
+		cfg.StartTS, cfg.EndTS = run.StartTS, run.EndTS
+	}
+	if err := cfg.Validate(); err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	if _, exists := m.evals[cfg.EvalID]; exists {
+		m.mu.Unlock()
+		return "", fmt.Errorf("evaluation %s already exists", cfg.EvalID)
+	}
+	runner := NewEvalRunner(cfg, m.meter.Wrap(m.client, store.CallerBacktest, cfg.EvalID, ""))
+	m.evals[cfg.EvalID] = runner
+	m.mu.Unlock()
+
+	go func() {
+		if source != nil {
+			for symbol, klines := range source.GetKlines() {
+				runner.LoadKlines(symbol, klines)
+			}
+			runner.SetDecisionPoints(source.GetDecisionTimes())
+		} else {
+			for symbol, klines := range m.fetchKlines(ctx, cfg.EvalID, cfg.Symbols, cfg.DecisionTimeframe, cfg.StartTS, cfg.EndTS) {
+				runner.LoadKlines(symbol, klines)
+			}
+		}
+
+		if err := runner.Start(ctx); err != nil {
+			log.Printf("[Eval] %s failed: %v", cfg.EvalID, err)
+			return
+		}
+		report := runner.GetReport(false)
+		log.Printf("[Eval] %s completed, winner: %q", cfg.EvalID, report.Winner)
+	}()
+
+	return cfg.EvalID, nil
+}
+
+// StopEval cancels a running evaluation
+func (m *Manager) StopEval(evalID string) error {
+	m.mu.RLock()
+	runner, exists := m.evals[evalID]
+	m.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("evaluation %s not found", evalID)
+	}
+	runner.Stop()
+	return nil
+}
+
+// GetEval returns an evaluation's report with every decision point
+func (m *Manager) GetEval(evalID string) (*EvalReport, error) {
+	m.mu.RLock()
+	runner, exists := m.evals[evalID]
+	m.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("evaluation %s not found", evalID)
+	}
+	return runner.GetReport(true), nil
+}
+
+// ListEvals returns the evaluation reports without their decision points
+func (m *Manager) ListEvals() []*EvalReport {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	reports := make([]*EvalReport, 0, len(m.evals))
+	for _, runner := range m.evals {
+		reports = append(reports, runner.GetReport(false))
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].StartedAt.After(reports[j].StartedAt) })
+	return reports
+}
@@ -106,6 +106,28 @@ func (r *Runner) GetTrades() []TradeEvent {
 	return r.trades
 }
 
+// GetKlines returns the loaded klines by symbol
+func (r *Runner) GetKlines() map[string][]Kline {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	klines := make(map[string][]Kline, len(r.klines))
+	for symbol, k := range r.klines {
+		klines[symbol] = k
+	}
+	return klines
+}
+
+// GetDecisionTimes returns the bar close times the run made decisions at
+func (r *Runner) GetDecisionTimes() []int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	times := make([]int64, 0, len(r.decisions))
+	for _, d := range r.decisions {
+		times = append(times, d.Timestamp)
+	}
+	return times
+}
+
 // GetMetrics calculates and returns performance metrics
 func (r *Runner) GetMetrics() *Metrics {
 	r.mu.RLock()
@@ -235,6 +257,8 @@ func (r *Runner) loop(ctx context.Context) error {
 				CoTTrace:     fullDecision.CoTTrace,
 				Decisions:    fullDecision.Decisions,
 				DurationMs:   fullDecision.AIRequestDurationMs,
+				ParsePath:    fullDecision.ParsePath,
+				Prompts:      fullDecision.Prompts,
 			}
 			if err != nil {
 				decisionLog.Error = err.Error()
@@ -382,6 +406,7 @@ func (r *Runner) buildDecisionContext(ts int64, priceMap map[string]float64) *de
 		AltcoinLeverage: r.config.AltcoinLeverage,
 		BTCETHPosRatio:  r.config.BTCETHPosRatio,
 		AltcoinPosRatio: r.config.AltcoinPosRatio,
+		PromptVariant:   r.config.PromptVariant,
 	}
 }
 
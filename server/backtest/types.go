@@ -51,6 +51,7 @@ type Config struct {
 	CacheAI              bool       `json:"cache_ai"`
 	ReplayOnly           bool       `json:"replay_only"`
 	Language             string     `json:"language"`
+	PromptVariant        string     `json:"prompt_variant,omitempty"`
 }
 
 // DefaultConfig returns a default backtest configuration
@@ -189,6 +190,8 @@ type DecisionLog struct {
 	CoTTrace        string               `json:"cot_trace"`
 	Decisions       []decision.Decision  `json:"decisions"`
 	DurationMs      int64                `json:"duration_ms"`
+	ParsePath       string               `json:"parse_path,omitempty"`
+	Prompts         []decision.PromptRef `json:"prompts,omitempty"`
 	Error           string               `json:"error,omitempty"`
 }
 
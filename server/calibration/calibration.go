@@ -0,0 +1,157 @@
+// Package calibration checks the AI's self-reported confidence against the
+// trades it opened. A trader's journaled closed positions are bucketed by
+// entry confidence into a reliability curve per model; the curve turns a
+// stated confidence into a calibrated win probability and suggests the
+// confidence at which the model's trades start paying off.
+package calibration
+
+import (
+	"math"
+	"sort"
+
+	"auto-trader-ahh/store"
+)
+
+const (
+	// DefaultBucketWidth is the width of a confidence bucket in points
+	DefaultBucketWidth = 10
+
+	// prior is how many trades a bucket needs before its hit rate outweighs
+	// the stated confidence, as in the debate's calibrated consensus
+	prior = 10.0
+)
+
+// Bucket is the result of trades opened within a confidence range
+type Bucket struct {
+	Min           int     `json:"min"` // Inclusive
+	Max           int     `json:"max"` // Exclusive, except for the top bucket
+	Trades        int     `json:"trades"`
+	Wins          int     `json:"wins"`
+	HitRate       float64 `json:"hit_rate"`       // Winning trades in %
+	AvgConfidence float64 `json:"avg_confidence"` // Stated confidence, 0-100
+	AvgPnL        float64 `json:"avg_pnl"`
+}
+
+// Curve is one model's reliability curve: hit rate by stated confidence
+type Curve struct {
+	AIModel       string   `json:"ai_model"`
+	Trades        int      `json:"trades"`
+	Wins          int      `json:"wins"`
+	HitRate       float64  `json:"hit_rate"`
+	AvgConfidence float64  `json:"avg_confidence"`
+	BrierScore    float64  `json:"brier_score"` // Mean (confidence - win)^2, lower is better calibrated
+	Buckets       []Bucket `json:"buckets"`     // Non-empty buckets, lowest confidence first
+}
+
+// Build groups closed positions by the model that opened them and buckets
+// them by entry confidence. Positions without a recorded confidence or
+// without a result are skipped. Curves with the most trades come first.
+func Build(positions []store.TraderPosition, width int) []*Curve {
+	if width <= 0 || width > 100 {
+		width = DefaultBucketWidth
+	}
+
+	curves := make(map[string]*Curve)
+	buckets := make(map[string]map[int]*Bucket)
+	for _, pos := range positions {
+		if pos.Confidence <= 0 || pos.CloseReason == store.CloseReasonUntracked {
+			continue
+		}
+		c, ok := curves[pos.AIModel]
+		if !ok {
+			c = &Curve{AIModel: pos.AIModel}
+			curves[pos.AIModel] = c
+			buckets[pos.AIModel] = make(map[int]*Bucket)
+		}
+
+		confidence := math.Min(float64(pos.Confidence), 100)
+		lo := int(confidence) / width * width
+		if lo >= 100 {
+			lo = 100 - width
+		}
+		b, ok := buckets[pos.AIModel][lo]
+		if !ok {
+			b = &Bucket{Min: lo, Max: lo + width}
+			buckets[pos.AIModel][lo] = b
+		}
+
+		won := 0.0
+		if pos.RealizedPnL > 0 {
+			won = 1
+			c.Wins++
+			b.Wins++
+		}
+		c.Trades++
+		c.AvgConfidence += confidence
+		c.BrierScore += math.Pow(confidence/100-won, 2)
+		b.Trades++
+		b.AvgConfidence += confidence
+		b.AvgPnL += pos.RealizedPnL
+	}
+
+	result := make([]*Curve, 0, len(curves))
+	for model, c := range curves {
+		c.HitRate = float64(c.Wins) / float64(c.Trades) * 100
+		c.AvgConfidence /= float64(c.Trades)
+		c.BrierScore /= float64(c.Trades)
+		for _, b := range buckets[model] {
+			b.HitRate = float64(b.Wins) / float64(b.Trades) * 100
+			b.AvgConfidence /= float64(b.Trades)
+			b.AvgPnL /= float64(b.Trades)
+			c.Buckets = append(c.Buckets, *b)
+		}
+		sort.Slice(c.Buckets, func(i, j int) bool { return c.Buckets[i].Min < c.Buckets[j].Min })
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Trades != result[j].Trades {
+			return result[i].Trades > result[j].Trades
+		}
+		return result[i].AIModel < result[j].AIModel
+	})
+	return result
+}
+
+// Find returns the curve for a model, or nil
+func Find(curves []*Curve, model string) *Curve {
+	for _, c := range curves {
+		if c.AIModel == model {
+			return c
+		}
+	}
+	return nil
+}
+
+// Probability returns the calibrated win probability, 0-100, for a stated
+// confidence: the hit rate of its bucket, blended with the stated value
+// while the bucket has few trades
+func (c *Curve) Probability(confidence float64) float64 {
+	for _, b := range c.Buckets {
+		top := b.Max >= 100 && confidence >= float64(b.Min)
+		if top || (confidence >= float64(b.Min) && confidence < float64(b.Max)) {
+			n := float64(b.Trades)
+			return (n*b.HitRate + prior*confidence) / (n + prior)
+		}
+	}
+	return confidence
+}
+
+// Threshold returns the lowest bucket floor at which the trades opened at
+// that confidence or above won at least targetHitRate % of the time, over at
+// least minTrades trades. ok is false when no floor qualifies.
+func (c *Curve) Threshold(targetHitRate float64, minTrades int) (threshold int, ok bool) {
+	for i, b := range c.Buckets {
+		trades, wins := 0, 0
+		for _, above := range c.Buckets[i:] {
+			trades += above.Trades
+			wins += above.Wins
+		}
+		if trades < minTrades || trades == 0 {
+			return 0, false
+		}
+		if float64(wins)/float64(trades)*100 >= targetHitRate {
+			return b.Min, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,98 @@
+package calibration
+
+import (
+	"math"
+	"testing"
+
+	"auto-trader-ahh/store"
+)
+
+// trade builds a closed position opened at confidence by model
+func trade(model string, confidence int, pnl float64) store.TraderPosition {
+	return store.TraderPosition{AIModel: model, Confidence: confidence, RealizedPnL: pnl, CloseReason: store.CloseReasonAI}
+}
+
+func TestBuild(t *testing.T) {
+	positions := []store.TraderPosition{
+		trade("a", 72, 10), trade("a", 75, -5), trade("a", 78, -5),
+		trade("a", 91, 10), trade("a", 100, 20),
+		trade("b", 80, 5),
+		trade("a", 0, 5), // Opened without a recorded confidence
+		{AIModel: "a", Confidence: 95, CloseReason: store.CloseReasonUntracked},
+	}
+
+	curves := Build(positions, 10)
+	if len(curves) != 2 || curves[0].AIModel != "a" {
+		t.Fatalf("Build() = %+v, want model a first", curves)
+	}
+
+	a := curves[0]
+	if a.Trades != 5 || a.Wins != 3 || len(a.Buckets) != 2 {
+		t.Fatalf("curve a = %+v", a)
+	}
+	if b := a.Buckets[0]; b.Min != 70 || b.Max != 80 || b.Trades != 3 || b.Wins != 1 || math.Abs(b.AvgConfidence-75) > 1e-9 {
+		t.Errorf("70-80 bucket = %+v", b)
+	}
+	// Confidence 100 lands in the top bucket
+	if b := a.Buckets[1]; b.Min != 90 || b.Trades != 2 || b.HitRate != 100 {
+		t.Errorf("90-100 bucket = %+v", b)
+	}
+	if Find(curves, "b") == nil || Find(curves, "c") != nil {
+		t.Errorf("Find() didn't match by model")
+	}
+}
+
+func TestCurve_Probability(t *testing.T) {
+	c := &Curve{Buckets: []Bucket{
+		{Min: 70, Max: 80, Trades: 10, HitRate: 30},
+		{Min: 90, Max: 100, Trades: 30, HitRate: 80},
+	}}
+
+	tests := []struct {
+		name       string
+		confidence float64
+		want       float64
+	}{
+		{"half-trusted bucket", 75, (10*30 + 10*75) / 20.0},
+		{"well-populated bucket", 90, (30*80 + 10*90) / 40.0},
+		{"top of the range", 100, (30*80 + 10*100) / 40.0},
+		{"no trades at this confidence", 85, 85},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Probability(tt.confidence); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Probability(%v) = %v, want %v", tt.confidence, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurve_Threshold(t *testing.T) {
+	c := &Curve{Buckets: []Bucket{
+		{Min: 60, Max: 70, Trades: 10, Wins: 2},
+		{Min: 70, Max: 80, Trades: 10, Wins: 5},
+		{Min: 80, Max: 90, Trades: 5, Wins: 4},
+	}}
+
+	tests := []struct {
+		name      string
+		target    float64
+		minTrades int
+		want      int
+		wantOK    bool
+	}{
+		{"every trade qualifies", 40, 1, 60, true},
+		{"skip the losing bucket", 55, 1, 70, true}, // 9 of 15 at 70 and above
+		{"only the top bucket", 70, 1, 80, true},
+		{"top bucket too thin", 70, 10, 0, false},
+		{"target out of reach", 90, 1, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.Threshold(tt.target, tt.minTrades)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Threshold() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
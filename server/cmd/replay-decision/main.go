@@ -0,0 +1,215 @@
+// Command replay-decision re-runs a recorded AI decision offline: the
+// recorded response goes back through the decision parser and validator
+// under the strategy version the trader ran at the time. No AI or exchange
+// calls are made. Traders record their AI calls when record_ai_calls is set.
+//
+//	replay-decision -trader <id> -symbol BTCUSDT -at "2026-10-18 03:12"
+//	replay-decision -id 1234
+//	replay-decision -trader <id> -list 20
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"auto-trader-ahh/config"
+	"auto-trader-ahh/mcp"
+	"auto-trader-ahh/store"
+	"auto-trader-ahh/trader"
+)
+
+func main() {
+	dataDir := flag.String("data", "data", "Data directory holding trading.db")
+	id := flag.Int64("id", 0, "Recording ID to replay")
+	traderID := flag.String("trader", "", "Trader whose recordings to search")
+	symbol := flag.String("symbol", "", "Only recordings whose prompt mentions this symbol")
+	at := flag.String("at", "", "Replay the latest recording at or before this local time (YYYY-MM-DD HH:MM or RFC3339)")
+	list := flag.Int("list", 0, "List this many matching recordings instead of replaying")
+	version := flag.Int("strategy-version", 0, "Validate under this strategy version instead of the one in force at the time")
+	asJSON := flag.Bool("json", false, "Print the result as JSON")
+	showPrompt := flag.Bool("prompt", false, "Print the recorded prompt")
+	flag.Parse()
+
+	log.SetFlags(0)
+	if *id == 0 && *traderID == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := store.Init(*dataDir); err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	recordings := store.NewAIRecordingStore()
+
+	filter := store.AIRecordingFilter{CallerID: *traderID, Contains: *symbol}
+	if *at != "" {
+		t, err := parseTime(*at)
+		if err != nil {
+			log.Fatal(err)
+		}
+		filter.Before = t
+	}
+
+	if *list > 0 {
+		recs, err := recordings.List(filter, *list)
+		if err != nil {
+			log.Fatalf("Failed to list recordings: %v", err)
+		}
+		for _, r := range recs {
+			status := "ok"
+			if r.Error != "" {
+				status = "error"
+			}
+			fmt.Printf("%6d  %s  %-8s %-40s %s  %s\n", r.ID, r.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+				r.CallerID, r.Model, r.PromptHash[:12], status)
+		}
+		return
+	}
+
+	var rec *store.AIRecording
+	var err error
+	if *id > 0 {
+		rec, err = recordings.Get(*id)
+	} else {
+		var recs []*store.AIRecording
+		if recs, err = recordings.List(filter, 1); err == nil {
+			if len(recs) == 0 {
+				log.Fatal("No matching recording. Is record_ai_calls enabled for the trader?")
+			}
+			rec = recs[0]
+		}
+	}
+	if err != nil {
+		log.Fatalf("Failed to load recording: %v", err)
+	}
+
+	recording, err := trader.DecodeRecording(rec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	strategy, err := strategyAt(rec.CallerID, rec.CreatedAt, *version)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	result, err := trader.ReplayDecision(recording, strategy, config.Load())
+	if err != nil {
+		log.Fatalf("Replay failed: %v", err)
+	}
+
+	if *asJSON {
+		out, _ := json.MarshalIndent(map[string]interface{}{
+			"recording": rec,
+			"strategy":  strategy,
+			"result":    result,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+	printResult(rec, recording, strategy, result, *showPrompt)
+}
+
+// strategyAt returns the trader's strategy as it was at a point in time
+func strategyAt(traderID string, t time.Time, version int) (*store.Strategy, error) {
+	if traderID == "" {
+		return nil, nil
+	}
+	tr, err := store.NewTraderStore().Get(traderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trader %s: %w", traderID, err)
+	}
+	if tr.StrategyID == "" {
+		return nil, nil
+	}
+
+	strategies := store.NewStrategyStore()
+	if version > 0 {
+		v, err := strategies.GetVersion(tr.StrategyID, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load strategy version %d: %w", version, err)
+		}
+		return versionStrategy(v), nil
+	}
+
+	versions, err := strategies.ListVersions(tr.StrategyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load strategy versions: %w", err)
+	}
+	for _, v := range versions { // Newest first
+		if !v.CreatedAt.After(t) {
+			return versionStrategy(v), nil
+		}
+	}
+	return strategies.Get(tr.StrategyID)
+}
+
+func versionStrategy(v *store.StrategyVersion) *store.Strategy {
+	return &store.Strategy{
+		ID:          v.StrategyID,
+		Name:        v.Name,
+		Description: v.Description,
+		Config:      v.Config,
+		Version:     v.Version,
+	}
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			if layout == "2006-01-02" {
+				t = t.AddDate(0, 0, 1).Add(-time.Second) // End of the day
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use YYYY-MM-DD HH:MM or RFC3339", s)
+}
+
+func printResult(rec *store.AIRecording, recording *mcp.Recording, strategy *store.Strategy, result *trader.ReplayResult, showPrompt bool) {
+	fmt.Printf("Recording #%d  %s  trader %s\n", rec.ID, rec.CreatedAt.Local().Format("2006-01-02 15:04:05"), rec.CallerID)
+	fmt.Printf("Model:     %s/%s  (prompt %s)\n", rec.Provider, rec.Model, rec.PromptHash[:12])
+	if strategy != nil {
+		fmt.Printf("Strategy:  %s v%d\n", strategy.Name, strategy.Version)
+	} else {
+		fmt.Println("Strategy:  none (engine defaults)")
+	}
+
+	if showPrompt && recording.Request != nil {
+		for _, m := range recording.Request.Messages {
+			fmt.Printf("\n--- %s prompt ---\n%s\n", m.Role, m.Content)
+		}
+	}
+	fmt.Printf("\n--- Response ---\n%s\n\n", recording.Response.Content)
+
+	fmt.Printf("Format:    %s\n", result.Format)
+	if result.ParseError != "" {
+		fmt.Printf("Parse:     FAILED: %s\n", result.ParseError)
+		return
+	}
+	fmt.Printf("Parse:     ok (%s)\n", result.ParsePath)
+
+	if d := result.Decision; d != nil {
+		fmt.Printf("Decision:  %s %s  confidence %.0f%%  SL %.2f%%  TP %.2f%%\n",
+			d.Action, d.Symbol, d.Confidence, d.StopLossPct, d.TakeProfitPct)
+	}
+	if full := result.FullDecision; full != nil {
+		for _, d := range full.Decisions {
+			fmt.Printf("Decision:  %s %s  confidence %d%%  lev %dx  size $%.2f  SL %.4f  TP %.4f\n",
+				d.Action, d.Symbol, d.Confidence, d.Leverage, d.PositionSizeUSD, d.StopLoss, d.TakeProfit)
+		}
+	}
+
+	if len(result.Rejections) > 0 {
+		fmt.Printf("Validator: REJECTED\n  - %s\n", strings.Join(result.Rejections, "\n  - "))
+	} else {
+		fmt.Println("Validator: passed")
+	}
+	fmt.Printf("Executes:  %v (live-only checks such as open positions and multi-timeframe confirmation are not replayed)\n",
+		result.WouldExecute)
+}
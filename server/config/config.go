@@ -23,9 +23,15 @@ type Config struct {
 	MaxPositionPct  float64 // Max % of balance per position
 	TradingInterval int     // Minutes between AI decisions
 
+	// Market Data
+	MarketStreamEnabled bool // Use WebSocket kline/markPrice streams (REST fallback)
+
 	// Server
 	APIPort string
 
+	// Prompt templates (<dir>/<variant>/<name>[.<lang>][.vN].tmpl)
+	PromptsDir string
+
 	// Authentication
 	AccessPasskey string
 }
@@ -51,9 +57,15 @@ func Load() *Config {
 		MaxPositionPct:  getEnvFloat("MAX_POSITION_PCT", 10.0),
 		TradingInterval: getEnvInt("TRADING_INTERVAL", 5),
 
+		// Market Data
+		MarketStreamEnabled: getEnvBool("MARKET_STREAM_ENABLED", true),
+
 		// Server
 		APIPort: getEnv("API_PORT", "8080"),
 
+		// Prompt templates
+		PromptsDir: getEnv("PROMPTS_DIR", "prompts"),
+
 		// Authentication
 		AccessPasskey: getEnv("ACCESS_PASSKEY", ""),
 	}
@@ -0,0 +1,228 @@
+package debate
+
+import (
+	"fmt"
+	"sort"
+
+	"auto-trader-ahh/store"
+)
+
+const (
+	calibrationPrior = 10.0 // Resolved outcomes needed before the track record outweighs stated confidence
+	uninformedBrier  = 0.25 // Brier score of a coin flip, assumed until outcomes resolve
+)
+
+// validConsensusMode reports whether mode is one of the Consensus* modes ("" = default)
+func validConsensusMode(mode string) error {
+	switch mode {
+	case "", ConsensusConfidence, ConsensusAccuracy, ConsensusCalibrated, ConsensusMedian, ConsensusUnanimous:
+		return nil
+	}
+	return fmt.Errorf("unknown consensus mode: %s", mode)
+}
+
+// participantKey identifies a participant's track record across sessions
+func participantKey(modelID string, personality Personality) string {
+	return modelID + "|" + string(personality)
+}
+
+// buildConsensus combines votes according to mode and reports the weight each voter carried
+func buildConsensus(mode string, votes []*Vote, stats map[string]*store.DebateParticipantStats) ([]*Decision, []*ParticipantWeight) {
+	weights := make(map[string]*ParticipantWeight)
+	ordered := make([]*ParticipantWeight, 0, len(votes))
+	for _, v := range votes {
+		key := participantKey(v.AIModelID, v.Personality)
+		if _, seen := weights[key]; seen {
+			continue
+		}
+		w := participantWeight(mode, v, stats[key])
+		weights[key] = w
+		ordered = append(ordered, w)
+	}
+	weightOf := func(v *Vote) float64 {
+		return weights[participantKey(v.AIModelID, v.Personality)].Weight
+	}
+
+	var decisions []*Decision
+	switch mode {
+	case ConsensusAccuracy:
+		decisions = weightedConsensus(votes, func(v *Vote, _ *Decision) float64 {
+			return weightOf(v)
+		})
+	case ConsensusCalibrated:
+		decisions = weightedConsensus(votes, func(v *Vote, d *Decision) float64 {
+			return calibratedProbability(d.Confidence, stats[participantKey(v.AIModelID, v.Personality)]) * weightOf(v)
+		})
+	case ConsensusMedian:
+		decisions = medianConsensus(votes)
+	case ConsensusUnanimous:
+		decisions = unanimousConsensus(votes)
+	default:
+		decisions = weightedConsensus(votes, func(_ *Vote, d *Decision) float64 {
+			return float64(d.Confidence) / 100.0
+		})
+	}
+	return decisions, ordered
+}
+
+// participantWeight derives a voter's multiplier from its track record:
+// accuracy uses the Laplace-smoothed hit rate (0.5 without history),
+// calibrated uses 1 - Brier score, other modes weigh everyone equally.
+func participantWeight(mode string, v *Vote, st *store.DebateParticipantStats) *ParticipantWeight {
+	w := &ParticipantWeight{
+		AIModelID:   v.AIModelID,
+		AIModelName: v.AIModelName,
+		Personality: v.Personality,
+		Weight:      1,
+		BrierScore:  uninformedBrier,
+	}
+	if st != nil && st.Predictions > 0 {
+		w.Predictions = st.Predictions
+		w.HitRate = st.HitRate
+		w.BrierScore = st.BrierScore
+		w.PnLContribution = st.PnLContribution
+	}
+
+	switch mode {
+	case ConsensusAccuracy:
+		hits := w.HitRate * float64(w.Predictions)
+		w.Weight = (hits + 1) / (float64(w.Predictions) + 2)
+	case ConsensusCalibrated:
+		w.Weight = 1 - w.BrierScore
+	}
+	return w
+}
+
+// calibratedProbability blends a stated confidence with the participant's
+// realized hit rate, trusting the record more as outcomes accumulate
+func calibratedProbability(confidence int, st *store.DebateParticipantStats) float64 {
+	p := float64(confidence) / 100.0
+	if st == nil || st.Predictions == 0 {
+		return p
+	}
+	n := float64(st.Predictions)
+	return (n*st.HitRate + calibrationPrior*p) / (n + calibrationPrior)
+}
+
+// actionStance places actions on a bearish..bullish scale for median consensus
+var actionStance = map[string]int{
+	"open_short":  -2,
+	"close_long":  -1,
+	"hold":        0,
+	"wait":        0,
+	"close_short": 1,
+	"open_long":   2,
+}
+
+// medianConsensus takes the median stance per symbol with every voter weighted
+// equally. An even split between different stances resolves to the more
+// cautious one (towards hold), so one outlier can't drag the panel.
+func medianConsensus(votes []*Vote) []*Decision {
+	bySymbol := make(map[string][]*Decision)
+	for _, vote := range votes {
+		for _, d := range vote.Decisions {
+			if _, ok := actionStance[d.Action]; !ok || d.Confidence < minConfidenceThreshold {
+				continue
+			}
+			bySymbol[d.Symbol] = append(bySymbol[d.Symbol], d)
+		}
+	}
+
+	var results []*Decision
+	for symbol, decisions := range bySymbol {
+		stances := make([]int, len(decisions))
+		for i, d := range decisions {
+			stances[i] = actionStance[d.Action]
+		}
+		sort.Ints(stances)
+
+		n := len(stances)
+		median := stances[n/2]
+		if n%2 == 0 {
+			lo, hi := stances[n/2-1], stances[n/2]
+			switch {
+			case lo == hi:
+				median = lo
+			case lo < 0 && hi > 0:
+				median = 0
+			case abs(lo) < abs(hi):
+				median = lo
+			default:
+				median = hi
+			}
+		}
+
+		// The most common action at the median stance (hold vs wait, ties alphabetical)
+		counts := make(map[string]int)
+		var supporters []*Decision
+		for _, d := range decisions {
+			if actionStance[d.Action] == median {
+				counts[d.Action]++
+				supporters = append(supporters, d)
+			}
+		}
+		if len(supporters) == 0 {
+			continue // Split panel with no one at the middle ground, no consensus
+		}
+		action := ""
+		for a, c := range counts {
+			if action == "" || c > counts[action] || (c == counts[action] && a < action) {
+				action = a
+			}
+		}
+
+		var backing []*Decision
+		for _, d := range supporters {
+			if d.Action == action {
+				backing = append(backing, d)
+			}
+		}
+		results = append(results, mergeDecisions(symbol, action, backing))
+	}
+	return results
+}
+
+// unanimousConsensus only decides a symbol when every vote picks the same
+// action for it with at least the minimum confidence
+func unanimousConsensus(votes []*Vote) []*Decision {
+	if len(votes) == 0 {
+		return nil
+	}
+
+	bySymbol := make(map[string][]*Decision)
+	for _, vote := range votes {
+		seen := make(map[string]bool)
+		for _, d := range vote.Decisions {
+			if seen[d.Symbol] {
+				continue // Only a voter's first decision per symbol counts
+			}
+			seen[d.Symbol] = true
+			bySymbol[d.Symbol] = append(bySymbol[d.Symbol], d)
+		}
+	}
+
+	var results []*Decision
+	for symbol, decisions := range bySymbol {
+		if len(decisions) != len(votes) {
+			continue // Someone abstained
+		}
+		unanimous := true
+		for _, d := range decisions {
+			if d.Action != decisions[0].Action || d.Confidence < minConfidenceThreshold {
+				unanimous = false
+				break
+			}
+		}
+		if unanimous {
+			results = append(results, mergeDecisions(symbol, decisions[0].Action, decisions))
+		}
+	}
+	return results
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
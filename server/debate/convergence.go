@@ -0,0 +1,108 @@
+package debate
+
+import "fmt"
+
+// endRound summarizes a finished round and decides whether the remaining
+// rounds are worth their tokens and cost. Voting always runs afterwards.
+func (e *Engine) endRound(session *SessionWithDetails, round, roundStartTokens int, roundStartCost float64) *RoundSummary {
+	e.mu.RLock()
+	agreement := roundAgreement(session.Messages, session.Participants, session.Symbols, session.CycleCount, round)
+	used := session.TokensUsed
+	threshold := session.ConvergenceThreshold
+	budget := session.TokenBudget
+	cost, costBudget := session.CostUsedUSD, session.CostBudgetUSD
+	e.mu.RUnlock()
+
+	summary := &RoundSummary{Round: round, Agreement: agreement, TokensUsed: used, CostUsedUSD: cost}
+	if round >= session.MaxRounds {
+		return summary
+	}
+
+	// Another round should cost about as much as this one did
+	roundTokens := used - roundStartTokens
+	roundCost := cost - roundStartCost
+	switch {
+	case threshold > 0 && len(session.Participants) > 1 && agreement >= threshold:
+		summary.SkipReason = fmt.Sprintf("converged: %.0f%% agreement on every symbol", agreement*100)
+	case budget > 0 && used+roundTokens > budget:
+		summary.SkipReason = fmt.Sprintf("token budget: %d of %d used, next round needs ~%d", used, budget, roundTokens)
+	case costBudget > 0 && cost+roundCost > costBudget:
+		summary.SkipReason = fmt.Sprintf("cost budget: $%.4f of $%.2f used, next round needs ~$%.4f", cost, costBudget, roundCost)
+	}
+
+	if summary.SkipReason != "" {
+		e.mu.Lock()
+		session.StopReason = summary.SkipReason
+		e.mu.Unlock()
+	}
+	return summary
+}
+
+// roundAgreement returns the lowest, across symbols, share of participants
+// whose latest message in the round takes the most common stance (bullish,
+// bearish or flat). Participants that failed to answer or skipped a symbol
+// count against agreement.
+func roundAgreement(messages []*Message, participants []*Participant, symbols []string, cycle, round int) float64 {
+	if len(participants) == 0 || len(symbols) == 0 {
+		return 0
+	}
+
+	// Latest message per participant in this round
+	latest := make(map[string]*Message)
+	for _, msg := range messages {
+		if msg.Cycle == cycle && msg.Round == round {
+			latest[participantKey(msg.AIModelID, msg.Personality)] = msg
+		}
+	}
+
+	agreement := 1.0
+	for _, symbol := range symbols {
+		counts := make(map[int]int)
+		for _, msg := range latest {
+			if stance, ok := symbolStance(msg.Decisions, symbol); ok {
+				counts[stance]++
+			}
+		}
+		top := 0
+		for _, n := range counts {
+			if n > top {
+				top = n
+			}
+		}
+		if share := float64(top) / float64(len(participants)); share < agreement {
+			agreement = share
+		}
+	}
+	return agreement
+}
+
+// symbolStance returns the direction (-1, 0, 1) a participant takes on a
+// symbol, using a blanket "ALL" decision when there's none for the symbol
+func symbolStance(decisions []*Decision, symbol string) (int, bool) {
+	var fallback *Decision
+	for _, d := range decisions {
+		if d.Symbol == symbol {
+			return stanceDirection(d.Action)
+		}
+		if d.Symbol == "ALL" && fallback == nil {
+			fallback = d
+		}
+	}
+	if fallback != nil {
+		return stanceDirection(fallback.Action)
+	}
+	return 0, false
+}
+
+func stanceDirection(action string) (int, bool) {
+	stance, ok := actionStance[action]
+	switch {
+	case !ok:
+		return 0, false
+	case stance > 0:
+		return 1, true
+	case stance < 0:
+		return -1, true
+	}
+	return 0, true
+}
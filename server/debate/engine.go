@@ -2,16 +2,18 @@ package debate
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"auto-trader-ahh/decision"
 	"auto-trader-ahh/mcp"
+	"auto-trader-ahh/store"
+	"auto-trader-ahh/usage"
 )
 
 // MarketContextProvider is a function that provides fresh market context
@@ -23,22 +25,29 @@ type TradeExecutor func(session *Session, decisions []*Decision) error
 
 // Engine runs debate sessions
 type Engine struct {
-	sessions            map[string]*SessionWithDetails
-	clients             map[string]mcp.AIClient // provider -> client
-	eventChan           map[string]chan *Event  // sessionID -> event channel
-	cancels             map[string]context.CancelFunc
-	mu                  sync.RWMutex
-	marketCtxProvider   MarketContextProvider
-	tradeExecutor       TradeExecutor
+	sessions          map[string]*SessionWithDetails
+	clients           map[string]mcp.AIClient // provider -> client
+	fallbackProvider  string                  // used when a participant's provider isn't configured
+	eventChan         map[string]chan *Event  // sessionID -> event channel
+	cancels           map[string]context.CancelFunc
+	mu                sync.RWMutex
+	marketCtxProvider MarketContextProvider
+	tradeExecutor     TradeExecutor
+	store             *store.DebateStore
+	meter             *usage.Meter        // Records participant calls; nil disables accounting
+	prices            *store.AIUsageStore // Prices calls against cost budgets; nil prices them at 0
 }
 
 // NewEngine creates a new debate engine
 func NewEngine() *Engine {
 	return &Engine{
-		sessions:  make(map[string]*SessionWithDetails),
-		clients:   make(map[string]mcp.AIClient),
-		eventChan: make(map[string]chan *Event),
-		cancels:   make(map[string]context.CancelFunc),
+		sessions:         make(map[string]*SessionWithDetails),
+		clients:          make(map[string]mcp.AIClient),
+		fallbackProvider: mcp.ProviderOpenRouter,
+		eventChan:        make(map[string]chan *Event),
+		cancels:          make(map[string]context.CancelFunc),
+		store:            store.NewDebateStore(),
+		prices:           store.NewAIUsageStore(),
 	}
 }
 
@@ -49,6 +58,23 @@ func (e *Engine) RegisterClient(provider string, client mcp.AIClient) {
 	e.clients[provider] = client
 }
 
+// SetClients replaces all provider clients. fallback names the provider used for
+// participants whose own provider is missing or failing ("" disables fallback).
+func (e *Engine) SetClients(clients map[string]mcp.AIClient, fallback string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.clients = clients
+	e.fallbackProvider = fallback
+}
+
+// SetUsageMeter records every participant call and enforces the AI budget
+// of the trader a session runs for
+func (e *Engine) SetUsageMeter(meter *usage.Meter) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.meter = meter
+}
+
 // SetMarketContextProvider sets the function to get fresh market context
 func (e *Engine) SetMarketContextProvider(provider MarketContextProvider) {
 	e.mu.Lock()
@@ -65,8 +91,33 @@ func (e *Engine) SetTradeExecutor(executor TradeExecutor) {
 
 // CreateSession creates a new debate session
 func (e *Engine) CreateSession(req *CreateSessionRequest) (*SessionWithDetails, error) {
+	if req.AutoExecute && req.TraderID == "" {
+		return nil, fmt.Errorf("auto_execute requires a trader_id")
+	}
+	if err := validConsensusMode(req.ConsensusMode); err != nil {
+		return nil, err
+	}
+	if req.ConvergenceThreshold < 0 || req.ConvergenceThreshold > 1 {
+		return nil, fmt.Errorf("convergence_threshold must be between 0 and 1")
+	}
+	if req.TokenBudget < 0 {
+		return nil, fmt.Errorf("token_budget can't be negative")
+	}
+	if req.CostBudgetUSD < 0 {
+		return nil, fmt.Errorf("cost_budget_usd can't be negative")
+	}
+
+	// Resolve personalities (built-in or custom) before taking the lock
+	profiles := make([]*PersonalityProfile, len(req.Participants))
+	for i, p := range req.Participants {
+		profile, err := e.lookupProfile(p.Personality)
+		if err != nil {
+			return nil, err
+		}
+		profiles[i] = profile
+	}
+
 	e.mu.Lock()
-	defer e.mu.Unlock()
 
 	session := &SessionWithDetails{
 		Session: Session{
@@ -83,6 +134,10 @@ func (e *Engine) CreateSession(req *CreateSessionRequest) (*SessionWithDetails,
 			CreatedAt:            time.Now(),
 			AutoCycle:            req.AutoCycle,
 			CycleIntervalMinutes: req.CycleIntervalMinutes,
+			ConsensusMode:        req.ConsensusMode,
+			ConvergenceThreshold: req.ConvergenceThreshold,
+			TokenBudget:          req.TokenBudget,
+			CostBudgetUSD:        req.CostBudgetUSD,
 			BinanceAPIKey:        req.BinanceAPIKey,
 			BinanceSecretKey:     req.BinanceSecretKey,
 			BinanceTestnet:       req.BinanceTestnet,
@@ -101,6 +156,9 @@ func (e *Engine) CreateSession(req *CreateSessionRequest) (*SessionWithDetails,
 	if session.CycleIntervalMinutes <= 0 {
 		session.CycleIntervalMinutes = 5 // Default 5 minutes between cycles
 	}
+	if session.ConsensusMode == "" {
+		session.ConsensusMode = ConsensusConfidence
+	}
 
 	// Add participants
 	for i, p := range req.Participants {
@@ -111,15 +169,37 @@ func (e *Engine) CreateSession(req *CreateSessionRequest) (*SessionWithDetails,
 			AIModelName: p.AIModelName,
 			Provider:    p.Provider,
 			Personality: p.Personality,
-			Color:       PersonalityColors[p.Personality],
+			Color:       profiles[i].Color,
 			SpeakOrder:  i + 1,
 			CreatedAt:   time.Now(),
 		}
 		session.Participants = append(session.Participants, participant)
 	}
 
+	if req.Judge != nil && req.Judge.AIModelID != "" {
+		session.Judge = &Participant{
+			ID:          fmt.Sprintf("judge_%d", time.Now().UnixNano()),
+			SessionID:   session.ID,
+			AIModelID:   req.Judge.AIModelID,
+			AIModelName: req.Judge.AIModelName,
+			Provider:    req.Judge.Provider,
+			Personality: PersonalityJudge,
+			Color:       PersonalityColors[PersonalityJudge],
+			CreatedAt:   time.Now(),
+		}
+		if session.Judge.AIModelName == "" {
+			session.Judge.AIModelName = session.Judge.AIModelID
+		}
+	}
+
 	e.sessions[session.ID] = session
 	e.eventChan[session.ID] = make(chan *Event, 100)
+	e.mu.Unlock()
+
+	e.persistSession(session)
+	for _, p := range session.Participants {
+		e.persistEntry(store.DebateParticipants, session.ID, p.ID, 0, p)
+	}
 
 	return session, nil
 }
@@ -180,6 +260,7 @@ func (e *Engine) Start(ctx context.Context, sessionID string, marketCtx *MarketC
 	ctx, cancel := context.WithCancel(ctx)
 	e.cancels[sessionID] = cancel
 	e.mu.Unlock()
+	e.persistSession(session)
 
 	// Run debate in background
 	go func() {
@@ -192,6 +273,7 @@ func (e *Engine) Start(ctx context.Context, sessionID string, marketCtx *MarketC
 				session.Status = StatusCancelled
 				session.Error = err.Error()
 				e.mu.Unlock()
+				e.persistSession(session)
 				e.sendEvent(sessionID, &Event{
 					Type:      "error",
 					SessionID: sessionID,
@@ -205,6 +287,73 @@ func (e *Engine) Start(ctx context.Context, sessionID string, marketCtx *MarketC
 	return nil
 }
 
+// maxTraderPanels caps the debate-mode transcripts kept in memory per trader
+const maxTraderPanels = 20
+
+// RunPanel runs a single debate synchronously and returns the finished session.
+// Used by traders in debate decision mode: the session is linked to
+// req.TraderID, never auto-executes or cycles (the trader owns scheduling and
+// execution), and is listed with the other sessions so its transcript can be viewed.
+func (e *Engine) RunPanel(ctx context.Context, req *CreateSessionRequest, marketCtx *MarketContext) (*SessionWithDetails, error) {
+	if len(req.Participants) == 0 {
+		return nil, fmt.Errorf("debate panel has no participants")
+	}
+
+	panelReq := *req
+	panelReq.AutoExecute = false
+	panelReq.AutoCycle = false
+	session, err := e.CreateSession(&panelReq)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	session.Source = SourceTrader
+	session.Status = StatusRunning
+	session.StartedAt = time.Now()
+	e.mu.Unlock()
+	e.persistSession(session)
+	e.prunePanels(session.TraderID)
+
+	if err := e.runDebate(ctx, session, marketCtx); err != nil {
+		e.mu.Lock()
+		session.Status = StatusCancelled
+		session.Error = err.Error()
+		e.mu.Unlock()
+		e.persistSession(session)
+		return session, err
+	}
+	return session, nil
+}
+
+// prunePanels drops the oldest debate-mode sessions of a trader beyond maxTraderPanels
+func (e *Engine) prunePanels(traderID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var panels []*SessionWithDetails
+	for _, s := range e.sessions {
+		if s.Source == SourceTrader && s.TraderID == traderID {
+			panels = append(panels, s)
+		}
+	}
+	if len(panels) <= maxTraderPanels {
+		return
+	}
+
+	sort.Slice(panels, func(i, j int) bool { return panels[i].CreatedAt.Before(panels[j].CreatedAt) })
+	for _, s := range panels[:len(panels)-maxTraderPanels] {
+		delete(e.sessions, s.ID)
+		delete(e.eventChan, s.ID)
+		delete(e.cancels, s.ID)
+		if e.store != nil {
+			if err := e.store.DeleteSession(s.ID); err != nil {
+				log.Printf("[Debate] Failed to delete pruned session %s: %v", s.ID, err)
+			}
+		}
+	}
+}
+
 // runAutoCycle runs the debate in continuous cycles
 func (e *Engine) runAutoCycle(ctx context.Context, session *SessionWithDetails, initialMarketCtx *MarketContext) {
 	log.Printf("[Debate] Starting auto-cycle for session %s (interval: %d minutes)", session.ID, session.CycleIntervalMinutes)
@@ -234,6 +383,21 @@ func (e *Engine) runAutoCycle(ctx context.Context, session *SessionWithDetails,
 			}
 		}
 
+		// A resumed session has no initial context; wait for the next cycle if the provider failed
+		if marketCtx == nil {
+			log.Printf("[Debate] No market context for session %s, retrying next cycle", session.ID)
+			e.mu.Lock()
+			session.NextCycleAt = time.Now().Add(cycleInterval)
+			e.mu.Unlock()
+			e.persistSession(session)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(cycleInterval):
+			}
+			continue
+		}
+
 		// Reset session state for new cycle
 		e.mu.Lock()
 		session.Status = StatusRunning
@@ -257,6 +421,7 @@ func (e *Engine) runAutoCycle(ctx context.Context, session *SessionWithDetails,
 		})
 
 		log.Printf("[Debate] Starting cycle #%d for session %s", session.CycleCount, session.ID)
+		e.persistSession(session)
 
 		// Run the debate
 		if err := e.runDebate(ctx, session, marketCtx); err != nil {
@@ -294,6 +459,7 @@ func (e *Engine) runAutoCycle(ctx context.Context, session *SessionWithDetails,
 		session.NextCycleAt = time.Now().Add(cycleInterval)
 		session.Status = StatusCompleted // Mark as completed between cycles
 		e.mu.Unlock()
+		e.persistSession(session)
 
 		log.Printf("[Debate] Cycle #%d complete. Next cycle at %s", session.CycleCount, session.NextCycleAt.Format("15:04:05"))
 
@@ -319,13 +485,13 @@ func (e *Engine) executeDecisions(session *SessionWithDetails) {
 		return
 	}
 
-	// Check if Binance credentials are configured
-	if session.BinanceAPIKey == "" || session.BinanceSecretKey == "" {
-		log.Printf("[Debate] No Binance credentials configured for session, skipping execution")
+	// Decisions are executed by the linked trader's engine
+	if session.TraderID == "" {
+		log.Printf("[Debate] No trader linked to session, skipping execution")
 		e.sendEvent(session.ID, &Event{
 			Type:      "execution_error",
 			SessionID: session.ID,
-			Data:      "No Binance API credentials configured. Please add your API key and secret to enable auto-execution.",
+			Data:      "No trader linked. Select a running trader to enable auto-execution.",
 			Timestamp: time.Now(),
 		})
 		return
@@ -333,7 +499,19 @@ func (e *Engine) executeDecisions(session *SessionWithDetails) {
 
 	log.Printf("[Debate] Executing %d decisions from cycle #%d", len(session.FinalDecisions), session.CycleCount)
 
-	if err := executor(&session.Session, session.FinalDecisions); err != nil {
+	err := executor(&session.Session, session.FinalDecisions)
+
+	for _, d := range session.FinalDecisions {
+		if d.Executed {
+			e.MarkExecuted(session.ID, session.CycleCount, d.Symbol, session.TraderID)
+		}
+	}
+
+	// Record execution results with the cycle's decisions
+	e.persistDecisions(session)
+	e.persistSession(session)
+
+	if err != nil {
 		log.Printf("[Debate] Trade execution error: %v", err)
 		e.sendEvent(session.ID, &Event{
 			Type:      "execution_error",
@@ -342,11 +520,7 @@ func (e *Engine) executeDecisions(session *SessionWithDetails) {
 			Timestamp: time.Now(),
 		})
 	} else {
-		// Mark decisions as executed
-		for _, d := range session.FinalDecisions {
-			d.Executed = true
-			d.ExecutedAt = time.Now()
-		}
+		// Executed/Error are set per decision by the executor
 		e.sendEvent(session.ID, &Event{
 			Type:      "execution_complete",
 			SessionID: session.ID,
@@ -359,44 +533,62 @@ func (e *Engine) executeDecisions(session *SessionWithDetails) {
 // Stop cancels a running debate
 func (e *Engine) Stop(sessionID string) error {
 	e.mu.Lock()
-	defer e.mu.Unlock()
-
 	cancel, exists := e.cancels[sessionID]
 	if !exists {
+		e.mu.Unlock()
 		return fmt.Errorf("session not found: %s", sessionID)
 	}
 
 	cancel()
 
-	if session, ok := e.sessions[sessionID]; ok {
+	session, ok := e.sessions[sessionID]
+	if ok {
 		session.Status = StatusCancelled
 	}
+	e.mu.Unlock()
 
+	if ok {
+		e.persistSession(session)
+	}
 	return nil
 }
 
 // runDebate executes the debate process
 func (e *Engine) runDebate(ctx context.Context, session *SessionWithDetails, marketCtx *MarketContext) error {
+	// Votes are scored when the trader's position closes; drop untraded ones
+	e.pruneOutcomes()
+
 	lang := decision.LangEnglish
 	if session.Language == "zh-CN" {
 		lang = decision.LangChinese
 	}
 
-	// Build base prompts
+	// Build base prompts from the session's prompt variant
 	promptBuilder := decision.NewPromptBuilder(lang)
-	baseSystemPrompt := promptBuilder.BuildSystemPrompt()
+	promptBuilder.SetVariant(session.PromptVariant)
 
 	decisionCtx := &decision.Context{
 		CurrentTime:     marketCtx.CurrentTime,
 		Account:         marketCtx.Account,
 		Positions:       marketCtx.Positions,
 		MarketDataMap:   marketCtx.MarketData,
+		Lessons:         marketCtx.Lessons,
 		BTCETHLeverage:  20,
 		AltcoinLeverage: 10,
 		BTCETHPosRatio:  0.3,
 		AltcoinPosRatio: 0.15,
 	}
-	userPrompt := promptBuilder.BuildUserPrompt(decisionCtx)
+	baseSystemPrompt, userPrompt, prompts := promptBuilder.Build(decisionCtx)
+	log.Printf("[Debate] Session %s prompts: %s, %s", session.ID, prompts[0], prompts[1])
+
+	e.mu.Lock()
+	session.TokensUsed = 0
+	session.CostUsedUSD = 0
+	session.StopReason = ""
+	session.Prompts = prompts
+	session.SystemPrompt = baseSystemPrompt
+	session.UserPrompt = userPrompt
+	e.mu.Unlock()
 
 	// Run debate rounds
 	for round := 1; round <= session.MaxRounds; round++ {
@@ -406,7 +598,10 @@ func (e *Engine) runDebate(ctx context.Context, session *SessionWithDetails, mar
 		default:
 		}
 
+		e.mu.Lock()
 		session.CurrentRound = round
+		roundStartTokens, roundStartCost := session.TokensUsed, session.CostUsedUSD
+		e.mu.Unlock()
 		e.sendEvent(session.ID, &Event{
 			Type:      "round_start",
 			SessionID: session.ID,
@@ -420,28 +615,16 @@ func (e *Engine) runDebate(ctx context.Context, session *SessionWithDetails, mar
 			systemPrompt := e.buildDebateSystemPrompt(baseSystemPrompt, participant, round, session.MaxRounds)
 			debateUserPrompt := e.buildDebateUserPrompt(userPrompt, session.Messages, participant, round)
 
-			// Get AI client
-			client := e.clients[participant.Provider]
-			if client == nil {
-				// Try default client
-				for _, c := range e.clients {
-					client = c
-					break
-				}
-			}
-			if client == nil {
-				return fmt.Errorf("no AI client available for %s", participant.Provider)
-			}
-
 			// Call AI
-			response, err := client.CallWithMessages(systemPrompt, debateUserPrompt)
+			response, err := e.callParticipant(participant, systemPrompt, debateUserPrompt)
 			if err != nil {
 				log.Printf("AI call failed for %s: %v", participant.AIModelName, err)
 				continue
 			}
 
 			// Parse decisions
-			decisions, confidence := parseDecisions(response)
+			decisions, confidence, parsePath := parseDecisions(response)
+			log.Printf("[Debate] %s round %d parsed via %s path", participant.AIModelName, round, parsePath)
 
 			// Create message
 			msgType := "analysis"
@@ -452,21 +635,24 @@ func (e *Engine) runDebate(ctx context.Context, session *SessionWithDetails, mar
 			msg := &Message{
 				ID:          fmt.Sprintf("msg_%d", time.Now().UnixNano()),
 				SessionID:   session.ID,
+				Cycle:       session.CycleCount,
 				Round:       round,
 				AIModelID:   participant.AIModelID,
 				AIModelName: participant.AIModelName,
 				Provider:    participant.Provider,
 				Personality: participant.Personality,
 				MessageType: msgType,
-				Content:     response,
+				Content:     transcriptText(response),
 				Decisions:   decisions,
 				Confidence:  confidence,
+				ParsePath:   parsePath,
 				CreatedAt:   time.Now(),
 			}
 
 			e.mu.Lock()
 			session.Messages = append(session.Messages, msg)
 			e.mu.Unlock()
+			e.persistEntry(store.DebateMessages, session.ID, msg.ID, msg.Cycle, msg)
 
 			e.sendEvent(session.ID, &Event{
 				Type:      "message",
@@ -480,12 +666,18 @@ func (e *Engine) runDebate(ctx context.Context, session *SessionWithDetails, mar
 			time.Sleep(time.Duration(session.IntervalMinutes) * time.Minute / time.Duration(len(session.Participants)))
 		}
 
+		summary := e.endRound(session, round, roundStartTokens, roundStartCost)
 		e.sendEvent(session.ID, &Event{
 			Type:      "round_end",
 			SessionID: session.ID,
 			Round:     round,
+			Data:      summary,
 			Timestamp: time.Now(),
 		})
+		if summary.SkipReason != "" {
+			log.Printf("[Debate] Session %s skipping to vote after round %d/%d: %s", session.ID, round, session.MaxRounds, summary.SkipReason)
+			break
+		}
 	}
 
 	// Voting phase
@@ -507,18 +699,48 @@ func (e *Engine) runDebate(ctx context.Context, session *SessionWithDetails, mar
 	e.mu.Unlock()
 
 	// Determine consensus
-	finalDecisions := e.determineConsensus(votes)
+	mode := session.ConsensusMode
+	if mode == "" {
+		mode = ConsensusConfidence
+	}
+	finalDecisions, weights := buildConsensus(mode, votes, e.participantStats())
+
+	// Optional judge rules on the whole debate; its verdict replaces the
+	// arithmetic consensus only if it passes validation
+	var verdict *Verdict
+	if session.Judge != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		e.sendEvent(session.ID, &Event{
+			Type:      "judge_start",
+			SessionID: session.ID,
+			Timestamp: time.Now(),
+		})
+		verdict = e.runJudge(session, baseSystemPrompt, userPrompt, marketCtx)
+		if verdict.Accepted {
+			log.Printf("[Debate] ⚖️ Judge ruling accepted for session %s (%d decisions)", session.ID, len(verdict.Decisions))
+			finalDecisions = verdict.Decisions
+		} else {
+			log.Printf("[Debate] ⚖️ Judge ruling rejected for session %s, using arithmetic consensus: %s", session.ID, verdict.Error)
+		}
+	}
+	e.recordOutcomes(session, votes, finalDecisions, marketCtx)
 
 	e.mu.Lock()
 	session.FinalDecisions = finalDecisions
+	session.ConsensusWeights = weights
+	session.Verdict = verdict
 	session.Status = StatusCompleted
 	session.CompletedAt = time.Now()
 	e.mu.Unlock()
+	e.persistDecisions(session)
+	e.persistSession(session)
 
 	e.sendEvent(session.ID, &Event{
 		Type:      "consensus",
 		SessionID: session.ID,
-		Data:      finalDecisions,
+		Data:      &ConsensusResult{Mode: mode, Weights: weights, Decisions: finalDecisions, Verdict: verdict},
 		Timestamp: time.Now(),
 	})
 
@@ -527,8 +749,7 @@ func (e *Engine) runDebate(ctx context.Context, session *SessionWithDetails, mar
 
 // buildDebateSystemPrompt builds personality-enhanced system prompt (NOFX-style exact copy)
 func (e *Engine) buildDebateSystemPrompt(basePrompt string, participant *Participant, round, maxRounds int) string {
-	personality := GetPersonalityDescription(participant.Personality)
-	emoji := PersonalityEmojis[participant.Personality]
+	profile := e.profile(participant.Personality)
 
 	debateInstructions := fmt.Sprintf(`You are a professional quantitative trading AI assistant participating in a multi-AI market debate.
 
@@ -625,7 +846,7 @@ Your chain of thought analysis...
 
 ---
 
-`, round, maxRounds, emoji, participant.Personality, personality)
+`, round, maxRounds, profile.Emoji, profile.Name, profile.roleInstructions())
 
 	return debateInstructions + basePrompt
 }
@@ -642,8 +863,8 @@ func (e *Engine) buildDebateUserPrompt(basePrompt string, messages []*Message, p
 
 	for _, msg := range messages {
 		if msg.Round < round {
-			emoji := PersonalityEmojis[msg.Personality]
-			sb.WriteString(fmt.Sprintf("### %s %s (%s)\n", emoji, msg.AIModelName, msg.Personality))
+			profile := e.profile(msg.Personality)
+			sb.WriteString(fmt.Sprintf("### %s %s (%s)\n", profile.Emoji, msg.AIModelName, profile.Name))
 			// Include summary, not full content
 			if len(msg.Content) > 500 {
 				sb.WriteString(msg.Content[:500] + "...\n\n")
@@ -683,17 +904,6 @@ Your final analysis summarizing the key points from the debate...
 `
 
 	for _, participant := range session.Participants {
-		client := e.clients[participant.Provider]
-		if client == nil {
-			for _, c := range e.clients {
-				client = c
-				break
-			}
-		}
-		if client == nil {
-			continue
-		}
-
 		// Build vote context with all messages
 		fullPrompt := userPrompt + "\n\n## Debate Summary\n\n"
 		for _, msg := range session.Messages {
@@ -701,26 +911,30 @@ Your final analysis summarizing the key points from the debate...
 		}
 		fullPrompt += votePrompt
 
-		response, err := client.CallWithMessages(systemPrompt, fullPrompt)
+		response, err := e.callParticipant(participant, systemPrompt, fullPrompt)
 		if err != nil {
 			log.Printf("Vote failed for %s: %v", participant.AIModelName, err)
 			continue
 		}
 
-		decisions, _ := parseDecisions(response)
+		decisions, _, parsePath := parseDecisions(response)
+		log.Printf("[Debate] %s vote parsed via %s path", participant.AIModelName, parsePath)
 
 		vote := &Vote{
 			ID:          fmt.Sprintf("vote_%d", time.Now().UnixNano()),
 			SessionID:   session.ID,
+			Cycle:       session.CycleCount,
 			AIModelID:   participant.AIModelID,
 			AIModelName: participant.AIModelName,
 			Personality: participant.Personality,
 			Decisions:   decisions,
 			Reasoning:   extractReasoning(response),
+			ParsePath:   parsePath,
 			CreatedAt:   time.Now(),
 		}
 
 		votes = append(votes, vote)
+		e.persistEntry(store.DebateVotes, session.ID, vote.ID, vote.Cycle, vote)
 
 		e.sendEvent(session.ID, &Event{
 			Type:      "vote",
@@ -733,24 +947,27 @@ Your final analysis summarizing the key points from the debate...
 	return votes, nil
 }
 
-// determineConsensus determines the final consensus from votes
+// determineConsensus determines the final consensus from votes, weighting each
+// decision by its self-reported confidence
 func (e *Engine) determineConsensus(votes []*Vote) []*Decision {
+	decisions, _ := buildConsensus(ConsensusConfidence, votes, nil)
+	return decisions
+}
+
+// minConfidenceThreshold is the minimum confidence for a decision to count in consensus
+const minConfidenceThreshold = 50
+
+// weightedConsensus picks the highest-scoring action per symbol, where each
+// decision adds weight(vote, decision) to its action's score
+func weightedConsensus(votes []*Vote, weight func(*Vote, *Decision) float64) []*Decision {
 	type actionData struct {
-		score       float64
-		totalConf   int
-		totalLev    int
-		totalPos    float64 // PositionPct sum
-		totalPosUSD float64 // PositionSizeUSD sum - preserve absolute sizes
-		totalSL     float64
-		totalTP     float64
-		count       int
-		reasons     []string
+		score     float64
+		decisions []*Decision
 	}
 
 	symbolActions := make(map[string]map[string]*actionData)
 
 	// Aggregate votes - filter out low confidence decisions
-	const minConfidenceThreshold = 50 // Minimum confidence to include in consensus
 	for _, vote := range votes {
 		for _, d := range vote.Decisions {
 			// Skip low confidence decisions - they shouldn't influence consensus
@@ -768,20 +985,8 @@ func (e *Engine) determineConsensus(votes []*Vote) []*Decision {
 			}
 
 			ad := symbolActions[d.Symbol][d.Action]
-			// Weight by confidence - higher confidence = more influence
-			weight := float64(d.Confidence) / 100.0
-
-			ad.score += weight
-			ad.totalConf += d.Confidence
-			ad.totalLev += d.Leverage
-			ad.totalPos += d.PositionPct
-			ad.totalPosUSD += d.PositionSizeUSD // Preserve absolute position size
-			ad.totalSL += d.StopLoss
-			ad.totalTP += d.TakeProfit
-			ad.count++
-			if d.Reasoning != "" {
-				ad.reasons = append(ad.reasons, d.Reasoning)
-			}
+			ad.score += weight(vote, d)
+			ad.decisions = append(ad.decisions, d)
 		}
 	}
 
@@ -800,42 +1005,61 @@ func (e *Engine) determineConsensus(votes []*Vote) []*Decision {
 			}
 		}
 
-		if winningData == nil || winningData.count == 0 {
+		if winningData == nil || len(winningData.decisions) == 0 {
 			continue
 		}
 
-		// Calculate averages
-		avgConf := winningData.totalConf / winningData.count
-		avgLev := winningData.totalLev / winningData.count
-		avgPos := winningData.totalPos / float64(winningData.count)
-		avgPosUSD := winningData.totalPosUSD / float64(winningData.count)
-		avgSL := winningData.totalSL / float64(winningData.count)
-		avgTP := winningData.totalTP / float64(winningData.count)
-
-		// Apply defaults only if no position info provided
-		if avgLev <= 0 {
-			avgLev = 5
-		}
-		if avgPos <= 0 && avgPosUSD <= 0 {
-			avgPos = 0.2 // Default 20% only if neither provided
-		}
+		results = append(results, mergeDecisions(symbol, winningAction, winningData.decisions))
+	}
 
-		decision := &Decision{
-			Symbol:          symbol,
-			Action:          winningAction,
-			Confidence:      avgConf,
-			Leverage:        avgLev,
-			PositionPct:     avgPos,
-			PositionSizeUSD: avgPosUSD, // Preserve absolute position size in consensus
-			StopLoss:        avgSL,
-			TakeProfit:      avgTP,
-			Reasoning:       strings.Join(winningData.reasons, "; "),
+	return results
+}
+
+// mergeDecisions averages the decisions backing a winning action into one consensus decision
+func mergeDecisions(symbol, action string, decisions []*Decision) *Decision {
+	var totalConf, totalLev, count int
+	var totalPos, totalPosUSD, totalSL, totalTP float64
+	var reasons []string
+	for _, d := range decisions {
+		totalConf += d.Confidence
+		totalLev += d.Leverage
+		totalPos += d.PositionPct
+		totalPosUSD += d.PositionSizeUSD // Preserve absolute position size
+		totalSL += d.StopLoss
+		totalTP += d.TakeProfit
+		count++
+		if d.Reasoning != "" {
+			reasons = append(reasons, d.Reasoning)
 		}
+	}
 
-		results = append(results, decision)
+	// Calculate averages
+	avgConf := totalConf / count
+	avgLev := totalLev / count
+	avgPos := totalPos / float64(count)
+	avgPosUSD := totalPosUSD / float64(count)
+	avgSL := totalSL / float64(count)
+	avgTP := totalTP / float64(count)
+
+	// Apply defaults only if no position info provided
+	if avgLev <= 0 {
+		avgLev = 5
+	}
+	if avgPos <= 0 && avgPosUSD <= 0 {
+		avgPos = 0.2 // Default 20% only if neither provided
 	}
 
-	return results
+	return &Decision{
+		Symbol:          symbol,
+		Action:          action,
+		Confidence:      avgConf,
+		Leverage:        avgLev,
+		PositionPct:     avgPos,
+		PositionSizeUSD: avgPosUSD, // Preserve absolute position size in consensus
+		StopLoss:        avgSL,
+		TakeProfit:      avgTP,
+		Reasoning:       strings.Join(reasons, "; "),
+	}
 }
 
 // sendEvent sends an event to subscribers
@@ -853,161 +1077,56 @@ func (e *Engine) sendEvent(sessionID string, event *Event) {
 	}
 }
 
-// Pre-compiled regex patterns for better performance (NOFX-style)
-var (
-	// Safe regex: precisely match ```json code blocks
-	reJSONFence      = regexp.MustCompile(`(?is)` + "```json\\s*(\\[\\s*\\{.*?\\}\\s*\\])\\s*```")
-	reJSONArray      = regexp.MustCompile(`(?is)\[\s*\{.*?\}\s*\]`)
-	reArrayHead      = regexp.MustCompile(`^\[\s*\{`)
-	reInvisibleRunes = regexp.MustCompile("[\u200B\u200C\u200D\uFEFF]")
-
-	// XML tag extraction (supports any characters in reasoning chain)
-	reReasoningTag = regexp.MustCompile(`(?s)<reasoning>(.*?)</reasoning>`)
-	reDecisionTag  = regexp.MustCompile(`(?s)<decision>(.*?)</decision>`)
-	reFinalVoteTag = regexp.MustCompile(`(?s)<final_vote>\s*(.*?)\s*</final_vote>`)
-)
-
-// fixMissingQuotes replaces curly/smart quotes and Chinese punctuation (NOFX-style)
-func fixMissingQuotes(s string) string {
-	// Smart quotes
-	s = strings.ReplaceAll(s, "\u201c", "\"") // "
-	s = strings.ReplaceAll(s, "\u201d", "\"") // "
-	s = strings.ReplaceAll(s, "\u2018", "'")  // '
-	s = strings.ReplaceAll(s, "\u2019", "'")  // '
-
-	// Chinese punctuation
-	s = strings.ReplaceAll(s, "［", "[")
-	s = strings.ReplaceAll(s, "］", "]")
-	s = strings.ReplaceAll(s, "｛", "{")
-	s = strings.ReplaceAll(s, "｝", "}")
-	s = strings.ReplaceAll(s, "：", ":")
-	s = strings.ReplaceAll(s, "，", ",")
-
-	// Chinese brackets
-	s = strings.ReplaceAll(s, "【", "[")
-	s = strings.ReplaceAll(s, "】", "]")
-	s = strings.ReplaceAll(s, "〔", "[")
-	s = strings.ReplaceAll(s, "〕", "]")
-	s = strings.ReplaceAll(s, "、", ",")
-
-	// Full-width space
-	s = strings.ReplaceAll(s, "　", " ")
-
-	return s
-}
+// reReasoningTag extracts the reasoning chain (supports any characters)
+var reReasoningTag = regexp.MustCompile(`(?s)<reasoning>(.*?)</reasoning>`)
 
-// removeInvisibleRunes removes invisible Unicode characters
-func removeInvisibleRunes(s string) string {
-	return reInvisibleRunes.ReplaceAllString(s, "")
+// rawDecision is a decision as read from an AI response
+type rawDecision = struct {
+	Symbol          string  `json:"symbol"`
+	Action          string  `json:"action"`
+	Confidence      int     `json:"confidence"`
+	Leverage        int     `json:"leverage"`
+	PositionPct     float64 `json:"position_pct"`
+	PositionSizeUSD float64 `json:"position_size_usd"`
+	StopLoss        float64 `json:"stop_loss"`
+	TakeProfit      float64 `json:"take_profit"`
+	Reasoning       string  `json:"reasoning"`
 }
 
-// parseDecisions extracts decisions from AI response (NOFX-style robust parsing)
-func parseDecisions(response string) ([]*Decision, int) {
-	// Step 1: Clean up the response
-	s := removeInvisibleRunes(response)
-	s = strings.TrimSpace(s)
-	s = fixMissingQuotes(s)
-
-	var jsonPart string
-
-	// Step 2: Try to extract from <decision> tag first (NOFX style)
-	if match := reDecisionTag.FindStringSubmatch(s); match != nil && len(match) > 1 {
-		jsonPart = strings.TrimSpace(match[1])
-		log.Printf("✓ Extracted JSON using <decision> tag")
-	} else if match := reFinalVoteTag.FindStringSubmatch(s); match != nil && len(match) > 1 {
-		jsonPart = strings.TrimSpace(match[1])
-		log.Printf("✓ Extracted JSON using <final_vote> tag")
-	} else {
-		jsonPart = s
-		log.Printf("⚠️  <decision> tag not found, searching JSON in full text")
-	}
-
-	// Apply quote fixes again
-	jsonPart = fixMissingQuotes(jsonPart)
-
-	// Step 3: Try to extract from ```json code fence
-	if m := reJSONFence.FindStringSubmatch(jsonPart); m != nil && len(m) > 1 {
-		jsonContent := strings.TrimSpace(m[1])
-		jsonContent = fixMissingQuotes(jsonContent)
-
-		var rawDecisions []struct {
-			Symbol          string  `json:"symbol"`
-			Action          string  `json:"action"`
-			Confidence      int     `json:"confidence"`
-			Leverage        int     `json:"leverage"`
-			PositionPct     float64 `json:"position_pct"`
-			PositionSizeUSD float64 `json:"position_size_usd"`
-			StopLoss        float64 `json:"stop_loss"`
-			TakeProfit      float64 `json:"take_profit"`
-			Reasoning       string  `json:"reasoning"`
-		}
-
-		if err := json.Unmarshal([]byte(jsonContent), &rawDecisions); err == nil && len(rawDecisions) > 0 {
-			return convertRawDecisions(rawDecisions)
-		}
-		log.Printf("⚠️  JSON parse error from code fence: %v", jsonContent[:min(100, len(jsonContent))])
-	}
-
-	// Step 4: Try to find raw JSON array
-	jsonContent := strings.TrimSpace(reJSONArray.FindString(jsonPart))
-	if jsonContent == "" {
-		// Fallback: Safe wait mode
-		log.Printf("⚠️  [SafeFallback] AI didn't output JSON decision, entering safe wait mode")
-
-		cotSummary := jsonPart
-		if len(cotSummary) > 240 {
-			cotSummary = cotSummary[:240] + "..."
-		}
-
+// parseDecisions extracts decisions from an AI response with the shared
+// decision parser. It returns the average confidence and the parse path.
+func parseDecisions(response string) ([]*Decision, int, string) {
+	parsed, parsePath, err := decision.ParseDecisions(response)
+	if err != nil {
+		log.Printf("⚠️  JSON parse error: %v", err)
 		return []*Decision{{
 			Symbol:     "ALL",
 			Action:     "wait",
 			Confidence: 50,
-			Reasoning:  fmt.Sprintf("Model didn't output structured JSON decision, entering safe wait; summary: %s", cotSummary),
-		}}, 50
-	}
-
-	jsonContent = fixMissingQuotes(jsonContent)
-
-	var rawDecisions []struct {
-		Symbol          string  `json:"symbol"`
-		Action          string  `json:"action"`
-		Confidence      int     `json:"confidence"`
-		Leverage        int     `json:"leverage"`
-		PositionPct     float64 `json:"position_pct"`
-		PositionSizeUSD float64 `json:"position_size_usd"`
-		StopLoss        float64 `json:"stop_loss"`
-		TakeProfit      float64 `json:"take_profit"`
-		Reasoning       string  `json:"reasoning"`
+			Reasoning:  fmt.Sprintf("JSON parsing failed: %v", err),
+		}}, 50, parsePath
 	}
 
-	if err := json.Unmarshal([]byte(jsonContent), &rawDecisions); err != nil {
-		log.Printf("⚠️  JSON parse error: %v", err)
-		log.Printf("JSON content: %s", jsonContent[:min(200, len(jsonContent))])
-
+	if parsePath == decision.ParsePathFallback {
+		log.Printf("⚠️  [SafeFallback] AI didn't output JSON decision, entering safe wait mode")
 		return []*Decision{{
 			Symbol:     "ALL",
 			Action:     "wait",
 			Confidence: 50,
-			Reasoning:  fmt.Sprintf("JSON parsing failed: %v", err),
-		}}, 50
+			Reasoning:  parsed.Decisions[0].Reasoning,
+		}}, 50, parsePath
 	}
 
-	return convertRawDecisions(rawDecisions)
+	raw := make([]rawDecision, 0, len(parsed.Decisions))
+	for _, d := range parsed.Decisions {
+		raw = append(raw, rawDecision(d))
+	}
+	decisions, avgConf := convertRawDecisions(raw)
+	return decisions, avgConf, parsePath
 }
 
 // convertRawDecisions converts raw parsed decisions to Decision structs
-func convertRawDecisions(rawDecisions []struct {
-	Symbol          string  `json:"symbol"`
-	Action          string  `json:"action"`
-	Confidence      int     `json:"confidence"`
-	Leverage        int     `json:"leverage"`
-	PositionPct     float64 `json:"position_pct"`
-	PositionSizeUSD float64 `json:"position_size_usd"`
-	StopLoss        float64 `json:"stop_loss"`
-	TakeProfit      float64 `json:"take_profit"`
-	Reasoning       string  `json:"reasoning"`
-}) ([]*Decision, int) {
+func convertRawDecisions(rawDecisions []rawDecision) ([]*Decision, int) {
 	var decisions []*Decision
 	totalConf := 0
 
@@ -1047,15 +1166,12 @@ func convertRawDecisions(rawDecisions []struct {
 	return decisions, avgConf
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
 // extractReasoning extracts reasoning from response (NOFX-style)
 func extractReasoning(response string) string {
+	if parsed, err := decision.ParseStructured(response); err == nil {
+		return strings.TrimSpace(parsed.Reasoning)
+	}
+
 	// Try <reasoning> tag first
 	if match := reReasoningTag.FindStringSubmatch(response); match != nil && len(match) > 1 {
 		log.Printf("✓ Extracted reasoning chain using <reasoning> tag")
@@ -1081,6 +1197,15 @@ func extractReasoning(response string) string {
 	return strings.TrimSpace(response)
 }
 
+// transcriptText renders a schema-constrained reply in the tagged format
+// the rest of the transcript uses; other replies are kept as they are
+func transcriptText(response string) string {
+	if parsed, err := decision.ParseStructured(response); err == nil {
+		return parsed.Text()
+	}
+	return response
+}
+
 // summarizeMessage creates a brief summary of a message
 func summarizeMessage(content string) string {
 	// Extract reasoning if available
@@ -1,7 +1,15 @@
 package debate
 
 import (
+	"errors"
+	"math"
+	"strings"
 	"testing"
+	"time"
+
+	"auto-trader-ahh/decision"
+	"auto-trader-ahh/mcp"
+	"auto-trader-ahh/store"
 )
 
 func TestDetermineConsensus_ConfidenceThreshold(t *testing.T) {
@@ -391,3 +399,514 @@ func TestConvertRawDecisions_DefaultsWhenNothingProvided(t *testing.T) {
 		t.Errorf("Expected default PositionPct 0.2, got %f", d.PositionPct)
 	}
 }
+
+// stubClient records the model it was called with
+type stubClient struct {
+	provider string
+	err      error
+	models   []string
+}
+
+func (c *stubClient) SetAPIKey(apiKey, customURL, customModel string) {}
+func (c *stubClient) SetTimeout(timeout time.Duration)                {}
+func (c *stubClient) GetProvider() string                             { return c.provider }
+func (c *stubClient) GetModel() string                                { return "" }
+func (c *stubClient) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	return c.provider, c.err
+}
+func (c *stubClient) CallStream(req *mcp.Request, handler mcp.ChunkHandler) (*mcp.Response, error) {
+	return c.CallWithRequest(req)
+}
+func (c *stubClient) CallWithRequest(req *mcp.Request) (*mcp.Response, error) {
+	c.models = append(c.models, req.Model)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &mcp.Response{Content: c.provider}, nil
+}
+
+func TestCallParticipant_RoutesByProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  string
+		modelID   string
+		native    error // errNoClient leaves the provider unregistered
+		fallback  string
+		wantFrom  string
+		wantModel string
+		wantErr   bool
+	}{
+		{
+			name:      "native provider gets unprefixed model",
+			provider:  "anthropic",
+			modelID:   "anthropic/claude-sonnet-4",
+			fallback:  "openrouter",
+			wantFrom:  "anthropic",
+			wantModel: "claude-sonnet-4",
+		},
+		{
+			name:      "unconfigured provider falls back with prefixed model",
+			provider:  "openai",
+			modelID:   "gpt-4o-mini",
+			native:    errNoClient,
+			fallback:  "openrouter",
+			wantFrom:  "openrouter",
+			wantModel: "openai/gpt-4o-mini",
+		},
+		{
+			name:      "failing native call retries via fallback",
+			provider:  "anthropic",
+			modelID:   "claude-sonnet-4",
+			native:    errors.New("503 overloaded"),
+			fallback:  "openrouter",
+			wantFrom:  "openrouter",
+			wantModel: "anthropic/claude-sonnet-4",
+		},
+		{
+			name:     "fallback disabled surfaces the error",
+			provider: "openai",
+			modelID:  "gpt-4o-mini",
+			native:   errNoClient,
+			fallback: "",
+			wantErr:  true,
+		},
+		{
+			name:      "openrouter participants keep their model id",
+			provider:  "openrouter",
+			modelID:   "deepseek/deepseek-v3.2",
+			fallback:  "openrouter",
+			wantFrom:  "openrouter",
+			wantModel: "deepseek/deepseek-v3.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := &stubClient{provider: "openrouter"}
+			clients := map[string]mcp.AIClient{"openrouter": router}
+			native := &stubClient{provider: tt.provider, err: tt.native}
+			if tt.provider != "openrouter" && tt.native != errNoClient {
+				clients[tt.provider] = native
+			}
+
+			e := NewEngine()
+			e.SetClients(clients, tt.fallback)
+
+			got, err := e.callParticipant(&Participant{Provider: tt.provider, AIModelID: tt.modelID}, "sys", "user")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got response from %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.wantFrom {
+				t.Errorf("answered by %q, want %q", got, tt.wantFrom)
+			}
+
+			answered := router
+			if tt.wantFrom != "openrouter" {
+				answered = native
+			}
+			if n := len(answered.models); n == 0 || answered.models[n-1] != tt.wantModel {
+				t.Errorf("called with models %v, want %q", answered.models, tt.wantModel)
+			}
+		})
+	}
+}
+
+var errNoClient = errors.New("no client")
+
+func TestBuildConsensus_Modes(t *testing.T) {
+	// An overconfident bull with a poor record against two modest, accurate bears
+	votes := []*Vote{
+		{AIModelID: "m1", Personality: "bull", Decisions: []*Decision{{Symbol: "BTCUSDT", Action: "open_long", Confidence: 95}}},
+		{AIModelID: "m2", Personality: "bear", Decisions: []*Decision{{Symbol: "BTCUSDT", Action: "open_short", Confidence: 55}}},
+		{AIModelID: "m3", Personality: "analyst", Decisions: []*Decision{{Symbol: "BTCUSDT", Action: "wait", Confidence: 60}}},
+	}
+	stats := map[string]*store.DebateParticipantStats{
+		"m1|bull":    {Predictions: 20, Hits: 4, HitRate: 0.2, BrierScore: 0.6},
+		"m2|bear":    {Predictions: 20, Hits: 16, HitRate: 0.8, BrierScore: 0.1},
+		"m3|analyst": {Predictions: 20, Hits: 14, HitRate: 0.7, BrierScore: 0.15},
+	}
+
+	tests := []struct {
+		mode       string
+		wantAction string // "" = no consensus
+	}{
+		{ConsensusConfidence, "open_long"},
+		{ConsensusAccuracy, "open_short"},
+		{ConsensusCalibrated, "open_short"},
+		{ConsensusMedian, "wait"},
+		{ConsensusUnanimous, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			decisions, weights := buildConsensus(tt.mode, votes, stats)
+			if len(weights) != 3 {
+				t.Errorf("expected a weight per voter, got %d", len(weights))
+			}
+			if tt.wantAction == "" {
+				if len(decisions) != 0 {
+					t.Errorf("expected no consensus, got %s", decisions[0].Action)
+				}
+				return
+			}
+			if len(decisions) != 1 || decisions[0].Action != tt.wantAction {
+				t.Fatalf("expected %s, got %+v", tt.wantAction, decisions)
+			}
+		})
+	}
+}
+
+func TestBuildConsensus_UnanimousAgreement(t *testing.T) {
+	votes := []*Vote{
+		{AIModelID: "m1", Decisions: []*Decision{{Symbol: "ETHUSDT", Action: "open_long", Confidence: 70, Leverage: 4}}},
+		{AIModelID: "m2", Decisions: []*Decision{{Symbol: "ETHUSDT", Action: "open_long", Confidence: 80, Leverage: 6}}},
+	}
+	decisions, _ := buildConsensus(ConsensusUnanimous, votes, nil)
+	if len(decisions) != 1 || decisions[0].Action != "open_long" || decisions[0].Leverage != 5 {
+		t.Fatalf("expected averaged open_long, got %+v", decisions)
+	}
+}
+
+func TestAccuracyWeight_NoHistoryIsNeutral(t *testing.T) {
+	w := participantWeight(ConsensusAccuracy, &Vote{AIModelID: "new"}, nil)
+	if w.Weight != 0.5 {
+		t.Errorf("expected 0.5 without history, got %.2f", w.Weight)
+	}
+}
+
+func TestScoreOutcome(t *testing.T) {
+	tests := []struct {
+		name      string
+		outcome   store.DebateOutcome
+		exitPrice float64
+		pnlShare  float64
+		wantHit   bool
+		wantPnL   float64
+		wantBrier float64
+	}{
+		{
+			name:      "long voter right on executed long",
+			outcome:   store.DebateOutcome{Action: "open_long", ExecutedAction: "open_long", Confidence: 80, Leverage: 5, EntryPrice: 100},
+			exitPrice: 102,
+			pnlShare:  25,
+			wantHit:   true,
+			wantPnL:   25,
+			wantBrier: 0.04,
+		},
+		{
+			name:      "short voter right against executed long gets no PnL",
+			outcome:   store.DebateOutcome{Action: "open_short", ExecutedAction: "open_long", Confidence: 60, Leverage: 5, EntryPrice: 100},
+			exitPrice: 98,
+			pnlShare:  -12,
+			wantHit:   true,
+			wantPnL:   0,
+			wantBrier: 0.16,
+		},
+		{
+			name:      "wait voter right when price is flat",
+			outcome:   store.DebateOutcome{Action: "wait", ExecutedAction: "open_short", Confidence: 50, Leverage: 2, EntryPrice: 100},
+			exitPrice: 100.2,
+			pnlShare:  1.5,
+			wantHit:   true,
+			wantPnL:   0,
+			wantBrier: 0.25,
+		},
+		{
+			name:      "short voter wrong on executed short",
+			outcome:   store.DebateOutcome{Action: "open_short", ExecutedAction: "open_short", Confidence: 90, Leverage: 3, EntryPrice: 100},
+			exitPrice: 101,
+			pnlShare:  -7.5,
+			wantHit:   false,
+			wantPnL:   -7.5,
+			wantBrier: 0.81,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := tt.outcome
+			scoreOutcome(&o, tt.exitPrice, tt.pnlShare)
+			if o.Hit != tt.wantHit {
+				t.Errorf("hit = %v, want %v", o.Hit, tt.wantHit)
+			}
+			if math.Abs(o.PnLContribution-tt.wantPnL) > 1e-9 {
+				t.Errorf("pnl contribution = %.4f, want %.4f", o.PnLContribution, tt.wantPnL)
+			}
+			if math.Abs(o.Brier-tt.wantBrier) > 1e-9 {
+				t.Errorf("brier = %.4f, want %.4f", o.Brier, tt.wantBrier)
+			}
+		})
+	}
+}
+
+func TestValidateVerdict(t *testing.T) {
+	marketCtx := &MarketContext{MarketData: map[string]*decision.MarketData{
+		"BTCUSDT": {Symbol: "BTCUSDT", Price: 45000},
+	}}
+	marketCtx.Account.TotalEquity = 10000
+	symbols := []string{"BTCUSDT", "ETHUSDT"}
+
+	tests := []struct {
+		name      string
+		decisions []*Decision
+		wantErr   bool
+	}{
+		{
+			name: "coherent long and a wait",
+			decisions: []*Decision{
+				{Symbol: "BTCUSDT", Action: "open_long", Leverage: 5, PositionSizeUSD: 1000, StopLoss: 40000, TakeProfit: 52000},
+				{Symbol: "ETHUSDT", Action: "wait"},
+			},
+		},
+		{
+			name: "position pct is converted using equity",
+			decisions: []*Decision{
+				{Symbol: "BTCUSDT", Action: "open_short", Leverage: 5, PositionPct: 0.1, StopLoss: 52000, TakeProfit: 40000},
+			},
+		},
+		{
+			name: "stop loss on the wrong side (averaged long and short)",
+			decisions: []*Decision{
+				{Symbol: "BTCUSDT", Action: "open_long", Leverage: 5, PositionSizeUSD: 1000, StopLoss: 50000, TakeProfit: 46000},
+			},
+			wantErr: true,
+		},
+		{
+			name: "leverage above the limit",
+			decisions: []*Decision{
+				{Symbol: "ETHUSDT", Action: "open_long", Leverage: 50, PositionSizeUSD: 1000, StopLoss: 2000, TakeProfit: 3200},
+			},
+			wantErr: true,
+		},
+		{
+			name: "take profit already behind the current price",
+			decisions: []*Decision{
+				{Symbol: "BTCUSDT", Action: "open_long", Leverage: 5, PositionSizeUSD: 1000, StopLoss: 40000, TakeProfit: 44000},
+			},
+			wantErr: true,
+		},
+		{
+			name:      "symbol that was not debated",
+			decisions: []*Decision{{Symbol: "SOLUSDT", Action: "close_long"}},
+			wantErr:   true,
+		},
+		{
+			name: "two rulings on one symbol",
+			decisions: []*Decision{
+				{Symbol: "BTCUSDT", Action: "wait"},
+				{Symbol: "BTCUSDT", Action: "hold"},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "no decisions",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVerdict(tt.decisions, symbols, marketCtx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateVerdict() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizePersonality(t *testing.T) {
+	tests := []struct {
+		name     string
+		p        store.DebatePersonality
+		wantErr  bool
+		wantRole string
+	}{
+		{
+			name:     "defaults applied",
+			p:        store.DebatePersonality{ID: "macro", Name: "Macro", Description: "Reads macro flows."},
+			wantRole: "Reads macro flows.",
+		},
+		{
+			name: "bias and risk add instructions",
+			p: store.DebatePersonality{
+				ID: "degen", Name: "Degen", Description: "Chases breakouts.",
+				PromptFragment: "Focus on volume spikes.", Bias: BiasBullish, RiskTolerance: RiskAggressive,
+			},
+			wantRole: "Chases breakouts.\n\nFocus on volume spikes.\n\nDirectional bias: lean bullish.",
+		},
+		{
+			name:    "missing name",
+			p:       store.DebatePersonality{ID: "x", Description: "d"},
+			wantErr: true,
+		},
+		{
+			name:    "missing description",
+			p:       store.DebatePersonality{ID: "x", Name: "X"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid bias",
+			p:       store.DebatePersonality{ID: "x", Name: "X", Description: "d", Bias: "sideways"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.p
+			err := normalizePersonality(&p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizePersonality() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if p.Color == "" || p.Emoji == "" {
+				t.Errorf("expected default color and emoji, got %q %q", p.Color, p.Emoji)
+			}
+			if role := customProfile(&p).roleInstructions(); !strings.HasPrefix(role, tt.wantRole) {
+				t.Errorf("roleInstructions() = %q, want prefix %q", role, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestEndRound_SkipReasons(t *testing.T) {
+	participants := []*Participant{
+		{AIModelID: "a", Personality: PersonalityBull},
+		{AIModelID: "b", Personality: PersonalityBear},
+		{AIModelID: "c", Personality: PersonalityAnalyst},
+	}
+	msg := func(model string, personality Personality, actions ...string) *Message {
+		m := &Message{AIModelID: model, Personality: personality, Cycle: 1, Round: 1}
+		for i, a := range actions {
+			m.Decisions = append(m.Decisions, &Decision{Symbol: []string{"BTCUSDT", "ETHUSDT"}[i], Action: a})
+		}
+		return m
+	}
+
+	tests := []struct {
+		name          string
+		messages      []*Message
+		threshold     float64
+		budget        int
+		startTokens   int
+		usedTokens    int
+		costBudget    float64
+		startCost     float64
+		usedCost      float64
+		wantAgreement float64
+		wantSkip      bool
+	}{
+		{
+			name: "unanimous long and short stances converge",
+			messages: []*Message{
+				msg("a", PersonalityBull, "open_long", "open_short"),
+				msg("b", PersonalityBear, "open_long", "close_long"),
+				msg("c", PersonalityAnalyst, "open_long", "open_short"),
+			},
+			threshold:     1,
+			wantAgreement: 1,
+			wantSkip:      true,
+		},
+		{
+			name: "one dissenter blocks unanimous threshold",
+			messages: []*Message{
+				msg("a", PersonalityBull, "open_long", "wait"),
+				msg("b", PersonalityBear, "open_short", "wait"),
+				msg("c", PersonalityAnalyst, "open_long", "hold"),
+			},
+			threshold:     1,
+			wantAgreement: 2.0 / 3,
+		},
+		{
+			name: "missing participant counts against agreement",
+			messages: []*Message{
+				msg("a", PersonalityBull, "open_long", "wait"),
+				msg("b", PersonalityBear, "open_long", "wait"),
+			},
+			threshold:     0.6,
+			wantAgreement: 2.0 / 3,
+			wantSkip:      true,
+		},
+		{
+			name: "disabled threshold never converges",
+			messages: []*Message{
+				msg("a", PersonalityBull, "wait", "wait"),
+				msg("b", PersonalityBear, "wait", "wait"),
+				msg("c", PersonalityAnalyst, "wait", "wait"),
+			},
+			wantAgreement: 1,
+		},
+		{
+			name:          "next round would exceed token budget",
+			messages:      []*Message{msg("a", PersonalityBull, "open_long", "wait")},
+			threshold:     1,
+			budget:        10000,
+			usedTokens:    6000,
+			wantAgreement: 1.0 / 3,
+			wantSkip:      true,
+		},
+		{
+			name:          "budget left for another round",
+			messages:      []*Message{msg("a", PersonalityBull, "open_long", "wait")},
+			budget:        10000,
+			startTokens:   2000,
+			usedTokens:    6000,
+			wantAgreement: 1.0 / 3,
+		},
+		{
+			name:          "next round would exceed cost budget",
+			messages:      []*Message{msg("a", PersonalityBull, "open_long", "wait")},
+			costBudget:    0.10,
+			usedCost:      0.06,
+			wantAgreement: 1.0 / 3,
+			wantSkip:      true,
+		},
+		{
+			name:          "cost budget left for another round",
+			messages:      []*Message{msg("a", PersonalityBull, "open_long", "wait")},
+			costBudget:    0.10,
+			startCost:     0.03,
+			usedCost:      0.06,
+			wantAgreement: 1.0 / 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine()
+			session := &SessionWithDetails{
+				Session: Session{
+					Symbols:              []string{"BTCUSDT", "ETHUSDT"},
+					MaxRounds:            3,
+					CycleCount:           1,
+					ConvergenceThreshold: tt.threshold,
+					TokenBudget:          tt.budget,
+					TokensUsed:           tt.usedTokens,
+					CostBudgetUSD:        tt.costBudget,
+					CostUsedUSD:          tt.usedCost,
+				},
+				Participants: participants,
+				Messages:     tt.messages,
+			}
+
+			summary := e.endRound(session, 1, tt.startTokens, tt.startCost)
+			if math.Abs(summary.Agreement-tt.wantAgreement) > 1e-9 {
+				t.Errorf("agreement = %v, want %v", summary.Agreement, tt.wantAgreement)
+			}
+			if (summary.SkipReason != "") != tt.wantSkip {
+				t.Errorf("skip reason = %q, wantSkip %v", summary.SkipReason, tt.wantSkip)
+			}
+			if summary.SkipReason != session.StopReason {
+				t.Errorf("session stop reason = %q, want %q", session.StopReason, summary.SkipReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package debate
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"auto-trader-ahh/decision"
+)
+
+// judgePrompt asks the judge for one decision per symbol with a written rationale
+const judgePrompt = `
+## JUDGE'S RULING
+
+You are the judge of this debate. You did not argue; read the full transcript
+and the final votes above, weigh the arguments on their merits rather than by
+headcount or stated confidence, and rule on each symbol.
+
+Output exactly one decision per symbol. Each decision must be internally
+coherent: stop loss and take profit on the correct side of price for its
+direction, leverage and position size within the limits of the trading rules.
+Prefer "wait" over a trade you cannot justify.
+
+<reasoning>
+Your rationale: which arguments were decisive, which you rejected and why...
+</reasoning>
+
+<decision>
+` + "```json" + `
+[
+  {"symbol": "BTCUSDT", "action": "open_long", "confidence": 75, "leverage": 5, "position_size_usd": 1000, "stop_loss": 42000, "take_profit": 48000, "reasoning": "Ruling for this symbol"}
+]
+` + "```" + `
+</decision>
+`
+
+// runJudge has the session's judge rule on the debate. The verdict is only
+// accepted if every decision passes decision.ValidateDecision.
+func (e *Engine) runJudge(session *SessionWithDetails, systemPrompt, userPrompt string, marketCtx *MarketContext) *Verdict {
+	verdict := &Verdict{CreatedAt: time.Now()}
+
+	e.mu.RLock()
+	judge := session.Judge
+	messages := session.Messages
+	votes := session.Votes
+	e.mu.RUnlock()
+
+	response, err := e.callParticipant(judge, e.buildJudgeSystemPrompt(systemPrompt), buildJudgeUserPrompt(userPrompt, messages, votes))
+	if err != nil {
+		verdict.Error = fmt.Sprintf("judge call failed: %v", err)
+		return verdict
+	}
+
+	parsed, _, parsePath := parseDecisions(response)
+	verdict.ParsePath = parsePath
+	decisions := make([]*Decision, 0, len(parsed))
+	for _, d := range parsed {
+		if d.Symbol == "ALL" && decision.IsPassiveAction(d.Action) {
+			continue // A blanket "wait" is the same as no ruling
+		}
+		decisions = append(decisions, d)
+	}
+	verdict.Decisions = decisions
+	verdict.Rationale = extractReasoning(response)
+
+	if err := validateVerdict(decisions, session.Symbols, marketCtx); err != nil {
+		verdict.Error = err.Error()
+		return verdict
+	}
+	verdict.Accepted = true
+	return verdict
+}
+
+// buildJudgeSystemPrompt frames the base trading rules for the judge's seat
+func (e *Engine) buildJudgeSystemPrompt(basePrompt string) string {
+	return fmt.Sprintf("You are %s %s\n\n%s", PersonalityEmojis[PersonalityJudge],
+		GetPersonalityDescription(PersonalityJudge), basePrompt)
+}
+
+// buildJudgeUserPrompt gives the judge the market data, the full transcript and every vote
+func buildJudgeUserPrompt(userPrompt string, messages []*Message, votes []*Vote) string {
+	var sb strings.Builder
+	sb.WriteString(userPrompt)
+
+	sb.WriteString("\n\n## Debate Transcript\n\n")
+	for _, msg := range messages {
+		sb.WriteString(fmt.Sprintf("### Round %d - %s (%s)\n\n%s\n\n", msg.Round, msg.AIModelName, msg.Personality, msg.Content))
+	}
+
+	sb.WriteString("## Final Votes\n\n")
+	for _, vote := range votes {
+		sb.WriteString(fmt.Sprintf("**%s (%s)**: %s\n", vote.AIModelName, vote.Personality, vote.Reasoning))
+		for _, d := range vote.Decisions {
+			sb.WriteString(fmt.Sprintf("- %s %s (confidence %d%%, %dx, SL %.4f, TP %.4f)\n",
+				d.Symbol, d.Action, d.Confidence, d.Leverage, d.StopLoss, d.TakeProfit))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(judgePrompt)
+	return sb.String()
+}
+
+// validateVerdict checks the judge ruled once on each debated symbol with
+// decisions that pass the same validation as single-model decisions
+func validateVerdict(decisions []*Decision, symbols []string, marketCtx *MarketContext) error {
+	if len(decisions) == 0 {
+		return fmt.Errorf("judge returned no decisions")
+	}
+
+	cfg := decision.DefaultValidationConfig()
+	if marketCtx != nil && marketCtx.Account.TotalEquity > 0 {
+		cfg.AccountEquity = marketCtx.Account.TotalEquity
+	}
+	// The validator estimates entry as the SL/TP midpoint, which always yields
+	// 1:1. Check the sides against the live price instead; the trader enforces
+	// its strategy's risk/reward with real prices at execution.
+	cfg.MinRiskReward = 0
+
+	debated := make(map[string]bool, len(symbols))
+	for _, s := range symbols {
+		debated[s] = true
+	}
+	ruled := make(map[string]bool, len(decisions))
+	for _, d := range decisions {
+		if !debated[d.Symbol] {
+			return fmt.Errorf("judge ruled on %s, which was not debated", d.Symbol)
+		}
+		if ruled[d.Symbol] {
+			return fmt.Errorf("judge ruled on %s more than once", d.Symbol)
+		}
+		ruled[d.Symbol] = true
+
+		candidate := decision.Decision{
+			Symbol:          d.Symbol,
+			Action:          d.Action,
+			Leverage:        d.Leverage,
+			PositionSizeUSD: d.PositionSizeUSD,
+			StopLoss:        d.StopLoss,
+			TakeProfit:      d.TakeProfit,
+			Confidence:      d.Confidence,
+			Reasoning:       d.Reasoning,
+		}
+		if candidate.PositionSizeUSD <= 0 && d.PositionPct > 0 {
+			candidate.PositionSizeUSD = d.PositionPct * cfg.AccountEquity
+		}
+		if err := decision.ValidateDecision(&candidate, cfg); err != nil {
+			return fmt.Errorf("judge decision for %s rejected: %w", d.Symbol, err)
+		}
+		if marketCtx != nil && decision.IsOpeningAction(d.Action) {
+			if md := marketCtx.MarketData[d.Symbol]; md != nil && md.Price > 0 {
+				if err := checkBracketSides(d, md.Price); err != nil {
+					return fmt.Errorf("judge decision for %s rejected: %w", d.Symbol, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkBracketSides rejects stop loss / take profit on the wrong side of the current price
+func checkBracketSides(d *Decision, price float64) error {
+	if d.Action == decision.ActionOpenLong && !(d.StopLoss < price && price < d.TakeProfit) {
+		return fmt.Errorf("long needs stop loss %.4f < price %.4f < take profit %.4f", d.StopLoss, price, d.TakeProfit)
+	}
+	if d.Action == decision.ActionOpenShort && !(d.TakeProfit < price && price < d.StopLoss) {
+		return fmt.Errorf("short needs take profit %.4f < price %.4f < stop loss %.4f", d.TakeProfit, price, d.StopLoss)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package debate
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"auto-trader-ahh/store"
+)
+
+const (
+	outcomeRetention = 24 * time.Hour // Unexecuted outcomes are dropped after this
+	holdBand         = 0.005          // A hold/wait call is right if price moved less than 0.5%
+)
+
+// Performance returns every participant's (model + personality) resolved track record
+func (e *Engine) Performance() ([]*store.DebateParticipantStats, error) {
+	if e.store == nil {
+		return nil, nil
+	}
+	return e.store.ParticipantStats()
+}
+
+// MarkExecuted records that a session cycle's consensus on symbol was traded
+// by a trader, so the participants' votes on it are scored once the trader's
+// position closes (see ResolvePosition)
+func (e *Engine) MarkExecuted(sessionID string, cycle int, symbol, traderID string) {
+	if e.store == nil {
+		return
+	}
+	if err := e.store.MarkOutcomesExecuted(sessionID, cycle, symbol, traderID); err != nil {
+		log.Printf("[Debate] Failed to mark outcomes executed for %s %s: %v", sessionID, symbol, err)
+	}
+}
+
+// participantStats loads track records keyed by participantKey
+func (e *Engine) participantStats() map[string]*store.DebateParticipantStats {
+	stats := make(map[string]*store.DebateParticipantStats)
+	all, err := e.Performance()
+	if err != nil {
+		log.Printf("[Debate] Failed to load participant performance: %v", err)
+		return stats
+	}
+	for _, st := range all {
+		stats[participantKey(st.AIModelID, Personality(st.Personality))] = st
+	}
+	return stats
+}
+
+// recordOutcomes stores every vote on a symbol the consensus wants to trade,
+// with the current price as entry. They are scored only if marked executed.
+func (e *Engine) recordOutcomes(session *SessionWithDetails, votes []*Vote, decisions []*Decision, marketCtx *MarketContext) {
+	if e.store == nil || marketCtx == nil {
+		return
+	}
+
+	var outcomes []*store.DebateOutcome
+	for _, final := range decisions {
+		if actionStance[final.Action] == 0 {
+			continue // Hold/wait consensus trades nothing
+		}
+		md := marketCtx.MarketData[final.Symbol]
+		if md == nil || md.Price <= 0 {
+			continue
+		}
+		for _, vote := range votes {
+			for _, d := range vote.Decisions {
+				if d.Symbol != final.Symbol {
+					continue
+				}
+				outcomes = append(outcomes, &store.DebateOutcome{
+					SessionID:      session.ID,
+					Cycle:          session.CycleCount,
+					Symbol:         final.Symbol,
+					AIModelID:      vote.AIModelID,
+					AIModelName:    vote.AIModelName,
+					Personality:    string(vote.Personality),
+					Action:         d.Action,
+					Confidence:     d.Confidence,
+					ExecutedAction: final.Action,
+					Leverage:       final.Leverage,
+					EntryPrice:     md.Price,
+				})
+				break // One decision per voter and symbol
+			}
+		}
+	}
+	if len(outcomes) == 0 {
+		return
+	}
+	if err := e.store.SaveOutcomes(outcomes); err != nil {
+		log.Printf("[Debate] Failed to record outcomes for session %s: %v", session.ID, err)
+	}
+}
+
+// pruneOutcomes drops votes whose consensus was never traded
+func (e *Engine) pruneOutcomes() {
+	if e.store == nil {
+		return
+	}
+	if err := e.store.PruneOutcomes(time.Now().Add(-outcomeRetention)); err != nil {
+		log.Printf("[Debate] Failed to prune outcomes: %v", err)
+	}
+}
+
+// outcomeCycle identifies a session cycle's consensus
+type outcomeCycle struct {
+	sessionID string
+	cycle     int
+}
+
+// ResolvePosition scores a trader's executed votes on symbol when its
+// position on side ("long" or "short") closes at exitPrice. The cycles whose
+// consensus opened or added to the position split its realized PnL, and each
+// backer of such a cycle's action is credited the cycle's share. Votes on the
+// cycles that closed it are scored on the exit price alone.
+func (e *Engine) ResolvePosition(traderID, symbol, side string, exitPrice, realizedPnL float64) {
+	if e.store == nil || exitPrice <= 0 {
+		return
+	}
+	pending, err := e.store.ListPendingOutcomes(traderID, symbol, time.Now())
+	if err != nil {
+		log.Printf("[Debate] Failed to load pending outcomes for %s: %v", symbol, err)
+		return
+	}
+
+	openAction, closeAction := "open_long", "close_long"
+	if side == "short" {
+		openAction, closeAction = "open_short", "close_short"
+	}
+	var legOutcomes []*store.DebateOutcome
+	openers := make(map[outcomeCycle]bool)
+	for _, o := range pending {
+		switch o.ExecutedAction {
+		case openAction:
+			openers[outcomeCycle{o.SessionID, o.Cycle}] = true
+		case closeAction:
+		default:
+			continue // The other side's position
+		}
+		legOutcomes = append(legOutcomes, o)
+	}
+	if len(legOutcomes) == 0 {
+		return
+	}
+
+	share := 0.0
+	if len(openers) > 0 {
+		share = realizedPnL / float64(len(openers))
+	}
+	resolved := 0
+	for _, o := range legOutcomes {
+		pnlShare := 0.0
+		if openers[outcomeCycle{o.SessionID, o.Cycle}] {
+			pnlShare = share
+		}
+		scoreOutcome(o, exitPrice, pnlShare)
+		if err := e.store.ResolveOutcome(o); err != nil {
+			log.Printf("[Debate] Failed to resolve outcome %d: %v", o.ID, err)
+			continue
+		}
+		resolved++
+	}
+	log.Printf("[Debate] Scored %d participant outcomes on %s's closed %s %s (PnL $%.2f)",
+		resolved, traderID, symbol, side, realizedPnL)
+}
+
+// scoreOutcome fills in the result of a vote given the exit price and its
+// cycle's share of the position's realized PnL. Directional calls hit when
+// price moved their way; hold/wait calls hit when it stayed within holdBand.
+// The PnL share is credited to the participants who voted for the executed
+// action.
+func scoreOutcome(o *store.DebateOutcome, exitPrice, pnlShare float64) {
+	move := (exitPrice - o.EntryPrice) / o.EntryPrice
+
+	if stance := actionStance[o.Action]; stance == 0 {
+		o.Hit = math.Abs(move) < holdBand
+	} else {
+		o.Hit = float64(stance)*move > 0
+	}
+
+	leverage := float64(o.Leverage)
+	if leverage <= 0 {
+		leverage = 1
+	}
+	direction := math.Copysign(1, float64(actionStance[o.ExecutedAction]))
+	o.ExitPrice = exitPrice
+	o.ReturnPct = direction * move * leverage * 100
+	o.PnLContribution = 0
+	if o.Action == o.ExecutedAction {
+		o.PnLContribution = pnlShare
+	}
+
+	p := float64(o.Confidence) / 100.0
+	outcome := 0.0
+	if o.Hit {
+		outcome = 1
+	}
+	o.Brier = (p - outcome) * (p - outcome)
+}
@@ -0,0 +1,59 @@
+package debate
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"auto-trader-ahh/store"
+)
+
+func TestResolvePosition_SplitsRealizedPnL(t *testing.T) {
+	if err := store.Init(t.TempDir()); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer store.Close()
+
+	e := NewEngine()
+	vote := func(session string, cycle int, model, action, executed string) *store.DebateOutcome {
+		return &store.DebateOutcome{SessionID: session, Cycle: cycle, Symbol: "BTCUSDT", AIModelID: model, Personality: "analyst",
+			Action: action, Confidence: 70, ExecutedAction: executed, Leverage: 2, EntryPrice: 100, CreatedAt: time.Now().Add(-time.Hour)}
+	}
+	outcomes := []*store.DebateOutcome{
+		vote("s1", 1, "a", "open_long", "open_long"),   // Opened the long
+		vote("s1", 1, "b", "wait", "open_long"),        // Didn't back it
+		vote("s2", 1, "a", "open_long", "open_long"),   // Added to it
+		vote("s2", 2, "a", "close_long", "close_long"), // Closed it
+		vote("s3", 1, "a", "open_short", "open_short"), // The short leg
+		vote("s4", 1, "a", "open_long", "open_long"),   // Another trader's
+	}
+	if err := e.store.SaveOutcomes(outcomes); err != nil {
+		t.Fatalf("SaveOutcomes() error = %v", err)
+	}
+	for _, o := range outcomes {
+		trader := "t1"
+		if o.SessionID == "s4" {
+			trader = "t2"
+		}
+		e.MarkExecuted(o.SessionID, o.Cycle, o.Symbol, trader)
+	}
+
+	e.ResolvePosition("t1", "BTCUSDT", "long", 103, 30)
+
+	stats, err := e.Performance()
+	if err != nil {
+		t.Fatalf("Performance() error = %v", err)
+	}
+	got := make(map[string]*store.DebateParticipantStats)
+	for _, st := range stats {
+		got[st.AIModelID] = st
+	}
+	// a: s1#1 and s2#1 split the $30 and the close is scored without PnL;
+	// the short and t2's long stay pending
+	if a := got["a"]; a == nil || a.Predictions != 3 || math.Abs(a.PnLContribution-30) > 1e-9 {
+		t.Errorf("stats[a] = %+v, want 3 predictions and $30", a)
+	}
+	if b := got["b"]; b == nil || b.Predictions != 1 || b.PnLContribution != 0 {
+		t.Errorf("stats[b] = %+v, want 1 prediction and no PnL", b)
+	}
+}
@@ -0,0 +1,228 @@
+package debate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"auto-trader-ahh/store"
+)
+
+// persistSession saves the session row: settings, status, cycle state and final decisions
+func (e *Engine) persistSession(session *SessionWithDetails) {
+	if e.store == nil {
+		return
+	}
+
+	e.mu.RLock()
+	_, exists := e.sessions[session.ID]
+	snapshot := session.Session
+	data, err := json.Marshal(&snapshot)
+	e.mu.RUnlock()
+	if !exists {
+		return // Deleted while a debate was still running
+	}
+	if err != nil {
+		log.Printf("[Debate] Failed to encode session %s: %v", snapshot.ID, err)
+		return
+	}
+
+	if err := e.store.SaveSession(&store.DebateSession{
+		ID:          snapshot.ID,
+		Name:        snapshot.Name,
+		Status:      string(snapshot.Status),
+		TraderID:    snapshot.TraderID,
+		Source:      snapshot.Source,
+		AutoCycle:   snapshot.AutoCycle,
+		CycleCount:  snapshot.CycleCount,
+		NextCycleAt: snapshot.NextCycleAt,
+		Data:        string(data),
+		CreatedAt:   snapshot.CreatedAt,
+	}); err != nil {
+		log.Printf("[Debate] Failed to persist session %s: %v", snapshot.ID, err)
+	}
+}
+
+// persistEntry saves a participant, message or vote
+func (e *Engine) persistEntry(table, sessionID, id string, cycle int, v interface{}) {
+	e.mu.RLock()
+	_, exists := e.sessions[sessionID]
+	e.mu.RUnlock()
+	if e.store == nil || !exists {
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("[Debate] Failed to encode %s %s: %v", table, id, err)
+		return
+	}
+	if err := e.store.SaveEntry(table, &store.DebateEntry{
+		ID:        id,
+		SessionID: sessionID,
+		Cycle:     cycle,
+		Data:      string(data),
+	}); err != nil {
+		log.Printf("[Debate] Failed to persist %s %s: %v", table, id, err)
+	}
+}
+
+// persistDecisions saves the current cycle's final decisions with their execution results
+func (e *Engine) persistDecisions(session *SessionWithDetails) {
+	if e.store == nil {
+		return
+	}
+
+	e.mu.RLock()
+	cycle := session.CycleCount
+	records := make([]*store.DebateDecision, 0, len(session.FinalDecisions))
+	for _, d := range session.FinalDecisions {
+		records = append(records, &store.DebateDecision{
+			Symbol:          d.Symbol,
+			Action:          d.Action,
+			Confidence:      d.Confidence,
+			Leverage:        d.Leverage,
+			PositionPct:     d.PositionPct,
+			PositionSizeUSD: d.PositionSizeUSD,
+			StopLoss:        d.StopLoss,
+			TakeProfit:      d.TakeProfit,
+			Reasoning:       d.Reasoning,
+			Executed:        d.Executed,
+			ExecutedAt:      d.ExecutedAt,
+			OrderID:         d.OrderID,
+			Error:           d.Error,
+		})
+	}
+	e.mu.RUnlock()
+
+	if err := e.store.SaveDecisions(session.ID, cycle, records); err != nil {
+		log.Printf("[Debate] Failed to persist decisions for session %s cycle %d: %v", session.ID, cycle, err)
+	}
+}
+
+// History returns a session's persisted decisions across all cycles, newest cycle first
+func (e *Engine) History(sessionID string) ([]*store.DebateDecision, error) {
+	if _, err := e.GetSession(sessionID); err != nil {
+		return nil, err
+	}
+	if e.store == nil {
+		return nil, fmt.Errorf("debate persistence not configured")
+	}
+	return e.store.ListDecisions(sessionID, -1)
+}
+
+// Delete stops a session if it is running and removes it with its history
+func (e *Engine) Delete(sessionID string) error {
+	e.mu.Lock()
+	if _, exists := e.sessions[sessionID]; !exists {
+		e.mu.Unlock()
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	if cancel, ok := e.cancels[sessionID]; ok {
+		cancel()
+	}
+	delete(e.sessions, sessionID)
+	delete(e.eventChan, sessionID)
+	delete(e.cancels, sessionID)
+	e.mu.Unlock()
+
+	if e.store == nil {
+		return nil
+	}
+	return e.store.DeleteSession(sessionID)
+}
+
+// Restore reloads persisted sessions with the participants, messages and votes
+// of their latest cycle. Auto-cycle sessions that weren't stopped resume at
+// their NextCycleAt; other sessions interrupted mid-debate are marked cancelled.
+func (e *Engine) Restore(ctx context.Context) error {
+	if e.store == nil {
+		return nil
+	}
+
+	records, err := e.store.ListSessions()
+	if err != nil {
+		return fmt.Errorf("failed to load debate sessions: %w", err)
+	}
+
+	resumed := 0
+	for _, rec := range records {
+		var session Session
+		if err := json.Unmarshal([]byte(rec.Data), &session); err != nil {
+			log.Printf("[Debate] Skipping unreadable session %s: %v", rec.ID, err)
+			continue
+		}
+
+		details := &SessionWithDetails{
+			Session:      session,
+			Participants: loadEntries[*Participant](e.store, store.DebateParticipants, rec.ID, -1),
+			Messages:     loadEntries[*Message](e.store, store.DebateMessages, rec.ID, session.CycleCount),
+			Votes:        loadEntries[*Vote](e.store, store.DebateVotes, rec.ID, session.CycleCount),
+		}
+
+		resume := session.AutoCycle && session.Status != StatusCancelled
+		switch {
+		case resume:
+			details.Status = StatusCompleted // Between cycles until the next one starts
+		case session.Status == StatusRunning || session.Status == StatusVoting:
+			details.Status = StatusCancelled
+			details.Error = "interrupted by server restart"
+		}
+
+		e.mu.Lock()
+		e.sessions[details.ID] = details
+		e.eventChan[details.ID] = make(chan *Event, 100)
+		e.mu.Unlock()
+		e.persistSession(details)
+
+		if resume {
+			e.resumeAutoCycle(ctx, details)
+			resumed++
+		}
+	}
+
+	log.Printf("[Debate] Restored %d sessions (%d auto-cycle resumed)", len(records), resumed)
+	return nil
+}
+
+// resumeAutoCycle restarts a restored auto-cycle session, waiting for its NextCycleAt
+func (e *Engine) resumeAutoCycle(ctx context.Context, session *SessionWithDetails) {
+	ctx, cancel := context.WithCancel(ctx)
+	e.mu.Lock()
+	e.cancels[session.ID] = cancel
+	nextCycleAt := session.NextCycleAt
+	e.mu.Unlock()
+
+	go func() {
+		if wait := time.Until(nextCycleAt); wait > 0 {
+			log.Printf("[Debate] Resuming auto-cycle session %s at %s", session.ID, nextCycleAt.Format("15:04:05"))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+		e.runAutoCycle(ctx, session, nil)
+	}()
+}
+
+// loadEntries decodes persisted participants, messages or votes, skipping unreadable rows
+func loadEntries[T any](s *store.DebateStore, table, sessionID string, cycle int) []T {
+	items := make([]T, 0)
+	entries, err := s.ListEntries(table, sessionID, cycle)
+	if err != nil {
+		log.Printf("[Debate] Failed to load %s for session %s: %v", table, sessionID, err)
+		return items
+	}
+	for _, entry := range entries {
+		var item T
+		if err := json.Unmarshal([]byte(entry.Data), &item); err != nil {
+			log.Printf("[Debate] Skipping unreadable %s %s: %v", table, entry.ID, err)
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
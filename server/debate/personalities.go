@@ -0,0 +1,293 @@
+package debate
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"auto-trader-ahh/store"
+)
+
+// PersonalityProfile is what prompts and the UI need to know about a
+// personality, built-in or user-defined
+type PersonalityProfile struct {
+	ID             Personality `json:"id"`
+	Name           string      `json:"name"`
+	Description    string      `json:"description"`
+	PromptFragment string      `json:"prompt_fragment,omitempty"`
+	Color          string      `json:"color"`
+	Emoji          string      `json:"emoji"`
+	Bias           string      `json:"bias"`
+	RiskTolerance  string      `json:"risk_tolerance"`
+	Builtin        bool        `json:"builtin"`
+}
+
+// Bias and risk tolerance settings of custom personalities
+const (
+	BiasNeutral = "neutral"
+	BiasBullish = "bullish"
+	BiasBearish = "bearish"
+
+	RiskConservative = "conservative"
+	RiskModerate     = "moderate"
+	RiskAggressive   = "aggressive"
+)
+
+// builtinPersonalities lists the personalities participants can pick, in display order
+var builtinPersonalities = []struct {
+	id   Personality
+	name string
+	bias string
+	risk string
+}{
+	{PersonalityBull, "Bull", BiasBullish, RiskAggressive},
+	{PersonalityBear, "Bear", BiasBearish, RiskConservative},
+	{PersonalityAnalyst, "Analyst", BiasNeutral, RiskModerate},
+	{PersonalityContrarian, "Contrarian", BiasNeutral, RiskModerate},
+	{PersonalityRiskManager, "Risk Manager", BiasNeutral, RiskConservative},
+}
+
+var rePersonalitySlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// builtinProfile returns the profile of a built-in personality (including the judge)
+func builtinProfile(p Personality) (*PersonalityProfile, bool) {
+	for _, b := range builtinPersonalities {
+		if b.id == p {
+			return &PersonalityProfile{
+				ID:            b.id,
+				Name:          b.name,
+				Description:   GetPersonalityDescription(b.id),
+				Color:         PersonalityColors[b.id],
+				Emoji:         PersonalityEmojis[b.id],
+				Bias:          b.bias,
+				RiskTolerance: b.risk,
+				Builtin:       true,
+			}, true
+		}
+	}
+	if p == PersonalityJudge {
+		return &PersonalityProfile{
+			ID:            PersonalityJudge,
+			Name:          "Judge",
+			Description:   GetPersonalityDescription(PersonalityJudge),
+			Color:         PersonalityColors[PersonalityJudge],
+			Emoji:         PersonalityEmojis[PersonalityJudge],
+			Bias:          BiasNeutral,
+			RiskTolerance: RiskModerate,
+			Builtin:       true,
+		}, true
+	}
+	return nil, false
+}
+
+// customProfile converts a stored personality
+func customProfile(p *store.DebatePersonality) *PersonalityProfile {
+	return &PersonalityProfile{
+		ID:             Personality(p.ID),
+		Name:           p.Name,
+		Description:    p.Description,
+		PromptFragment: p.PromptFragment,
+		Color:          p.Color,
+		Emoji:          p.Emoji,
+		Bias:           p.Bias,
+		RiskTolerance:  p.RiskTolerance,
+	}
+}
+
+// lookupProfile resolves a built-in or custom personality
+func (e *Engine) lookupProfile(p Personality) (*PersonalityProfile, error) {
+	if profile, ok := builtinProfile(p); ok {
+		return profile, nil
+	}
+	if e.store == nil {
+		return nil, fmt.Errorf("unknown personality: %s", p)
+	}
+	custom, err := e.store.GetPersonality(string(p))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load personality %s: %w", p, err)
+	}
+	if custom == nil {
+		return nil, fmt.Errorf("unknown personality: %s", p)
+	}
+	return customProfile(custom), nil
+}
+
+// profile resolves a personality for prompts. Personalities deleted after a
+// session was created fall back to a neutral analyst role.
+func (e *Engine) profile(p Personality) *PersonalityProfile {
+	profile, err := e.lookupProfile(p)
+	if err != nil {
+		log.Printf("[Debate] %v, using default role", err)
+		return &PersonalityProfile{
+			ID:            p,
+			Name:          string(p),
+			Description:   GetPersonalityDescription(p),
+			Bias:          BiasNeutral,
+			RiskTolerance: RiskModerate,
+		}
+	}
+	return profile
+}
+
+// Personalities lists the built-in personalities followed by the custom ones
+func (e *Engine) Personalities() ([]*PersonalityProfile, error) {
+	profiles := make([]*PersonalityProfile, 0, len(builtinPersonalities))
+	for _, b := range builtinPersonalities {
+		profile, _ := builtinProfile(b.id)
+		profiles = append(profiles, profile)
+	}
+	if e.store == nil {
+		return profiles, nil
+	}
+
+	custom, err := e.store.ListPersonalities()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range custom {
+		profiles = append(profiles, customProfile(p))
+	}
+	return profiles, nil
+}
+
+// CreatePersonality adds a custom personality. Its ID is derived from the name
+// unless given, and can't shadow a built-in or existing personality.
+func (e *Engine) CreatePersonality(p *store.DebatePersonality) (*PersonalityProfile, error) {
+	if e.store == nil {
+		return nil, fmt.Errorf("debate persistence not configured")
+	}
+	if p.ID == "" {
+		p.ID = strings.Trim(rePersonalitySlug.ReplaceAllString(strings.ToLower(p.Name), "_"), "_")
+	}
+	if err := normalizePersonality(p); err != nil {
+		return nil, err
+	}
+	if _, ok := builtinProfile(Personality(p.ID)); ok {
+		return nil, fmt.Errorf("personality %s is built in", p.ID)
+	}
+	existing, err := e.store.GetPersonality(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("personality %s already exists", p.ID)
+	}
+
+	if err := e.store.SavePersonality(p); err != nil {
+		return nil, err
+	}
+	return customProfile(p), nil
+}
+
+// UpdatePersonality replaces a custom personality's settings. Running sessions
+// pick up the change from their next prompt.
+func (e *Engine) UpdatePersonality(id string, p *store.DebatePersonality) (*PersonalityProfile, error) {
+	if e.store == nil {
+		return nil, fmt.Errorf("debate persistence not configured")
+	}
+	if _, ok := builtinProfile(Personality(id)); ok {
+		return nil, fmt.Errorf("built-in personality %s can't be changed", id)
+	}
+	existing, err := e.store.GetPersonality(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("personality not found: %s", id)
+	}
+
+	p.ID = id
+	p.CreatedAt = existing.CreatedAt
+	if err := normalizePersonality(p); err != nil {
+		return nil, err
+	}
+	if err := e.store.SavePersonality(p); err != nil {
+		return nil, err
+	}
+	return customProfile(p), nil
+}
+
+// DeletePersonality removes a custom personality. Sessions still using it fall
+// back to a neutral role.
+func (e *Engine) DeletePersonality(id string) error {
+	if e.store == nil {
+		return fmt.Errorf("debate persistence not configured")
+	}
+	if _, ok := builtinProfile(Personality(id)); ok {
+		return fmt.Errorf("built-in personality %s can't be deleted", id)
+	}
+	existing, err := e.store.GetPersonality(id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("personality not found: %s", id)
+	}
+	return e.store.DeletePersonality(id)
+}
+
+// normalizePersonality validates a custom personality and fills in defaults
+func normalizePersonality(p *store.DebatePersonality) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return fmt.Errorf("personality name is required")
+	}
+	if p.ID == "" {
+		return fmt.Errorf("personality name must contain letters or digits")
+	}
+	if strings.TrimSpace(p.Description) == "" {
+		return fmt.Errorf("personality description is required")
+	}
+
+	switch p.Bias {
+	case "":
+		p.Bias = BiasNeutral
+	case BiasNeutral, BiasBullish, BiasBearish:
+	default:
+		return fmt.Errorf("invalid bias: %s (use neutral, bullish or bearish)", p.Bias)
+	}
+	switch p.RiskTolerance {
+	case "":
+		p.RiskTolerance = RiskModerate
+	case RiskConservative, RiskModerate, RiskAggressive:
+	default:
+		return fmt.Errorf("invalid risk tolerance: %s (use conservative, moderate or aggressive)", p.RiskTolerance)
+	}
+
+	if p.Color == "" {
+		p.Color = "#94A3B8" // Slate
+	}
+	if p.Emoji == "" {
+		p.Emoji = "🎭"
+	}
+	return nil
+}
+
+// roleInstructions renders a profile's role, extra instructions and biases for the system prompt
+func (p *PersonalityProfile) roleInstructions() string {
+	var sb strings.Builder
+	sb.WriteString(p.Description)
+	if p.PromptFragment != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(strings.TrimSpace(p.PromptFragment))
+	}
+
+	// Built-in descriptions already carry their bias
+	if p.Builtin {
+		return sb.String()
+	}
+	switch p.Bias {
+	case BiasBullish:
+		sb.WriteString("\n\nDirectional bias: lean bullish. Look hardest for long setups, but defer to the data.")
+	case BiasBearish:
+		sb.WriteString("\n\nDirectional bias: lean bearish. Look hardest for short setups and downside risks, but defer to the data.")
+	}
+	switch p.RiskTolerance {
+	case RiskConservative:
+		sb.WriteString("\nRisk tolerance: conservative. Prefer low leverage, smaller positions and tight stops; waiting is a valid call.")
+	case RiskAggressive:
+		sb.WriteString("\nRisk tolerance: aggressive. Use higher leverage and larger positions when conviction is high, within the trading rules.")
+	}
+	return sb.String()
+}
@@ -0,0 +1,132 @@
+package debate
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"auto-trader-ahh/decision"
+	"auto-trader-ahh/mcp"
+	"auto-trader-ahh/store"
+)
+
+// callParticipant sends the prompts to the participant's model on its own
+// provider, retrying through the fallback provider when that provider isn't
+// configured or the native call fails.
+func (e *Engine) callParticipant(participant *Participant, systemPrompt, userPrompt string) (string, error) {
+	e.mu.RLock()
+	client := e.clients[participant.Provider]
+	fallbackName := e.fallbackProvider
+	fallback := e.clients[fallbackName]
+	meter := e.meter
+	traderID := ""
+	if session, ok := e.sessions[participant.SessionID]; ok {
+		traderID = session.TraderID
+	}
+	e.mu.RUnlock()
+
+	if meter != nil {
+		if err := meter.CheckBudget(traderID); err != nil {
+			return "", err
+		}
+		observe := meter.Observer(store.CallerDebate, participant.SessionID, traderID)
+		if client != nil {
+			client = mcp.NewMeteredClient(client, observe, nil)
+		}
+		if fallback != nil {
+			fallback = mcp.NewMeteredClient(fallback, observe, nil)
+		}
+	}
+
+	if participant.Provider == fallbackName || mcp.IsLocalProvider(participant.Provider) {
+		fallback = nil // Already the fallback, or a local model OpenRouter can't serve
+	}
+
+	if client != nil {
+		model := mcp.NativeModelID(participant.Provider, participant.AIModelID)
+		resp, err := callModel(client, model, systemPrompt, userPrompt)
+		if err == nil {
+			e.addUsage(participant.SessionID, usageModel(client, model, resp), resp.Usage)
+			return resp.Content, nil
+		}
+		if fallback == nil {
+			return "", err
+		}
+		log.Printf("[Debate] %s failed on %s, retrying via %s: %v", participant.AIModelName, participant.Provider, fallbackName, err)
+	}
+
+	if fallback == nil {
+		return "", fmt.Errorf("no AI client configured for provider %q", participant.Provider)
+	}
+	model := fallbackModelID(participant.Provider, participant.AIModelID)
+	resp, err := callModel(fallback, model, systemPrompt, userPrompt)
+	if err != nil {
+		return "", err
+	}
+	e.addUsage(participant.SessionID, usageModel(fallback, model, resp), resp.Usage)
+	return resp.Content, nil
+}
+
+// usageModel names the model a call is priced as, like the usage meter: the
+// one that answered, else the one requested, else the client's default
+func usageModel(client mcp.AIClient, requested string, resp *mcp.Response) string {
+	if resp.Model != "" {
+		return resp.Model
+	}
+	if requested != "" {
+		return requested
+	}
+	return client.GetModel()
+}
+
+// addUsage charges a call's tokens, and its cost from the price table, to
+// the session's current cycle
+func (e *Engine) addUsage(sessionID, model string, u mcp.Usage) {
+	e.mu.RLock()
+	_, ok := e.sessions[sessionID]
+	prices := e.prices
+	e.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	cost := 0.0
+	if prices != nil {
+		var err error
+		if cost, err = prices.Cost(model, u.PromptTokens, u.CompletionTokens); err != nil {
+			log.Printf("[Debate] Failed to price %s call for session %s: %v", model, sessionID, err)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if session, ok := e.sessions[sessionID]; ok {
+		session.TokensUsed += u.TotalTokens
+		session.CostUsedUSD += cost
+	}
+}
+
+// callModel makes a single chat call with an explicit model (empty uses the
+// client's default). Replies are constrained to the shared decision schema
+// where the provider supports it.
+func callModel(client mcp.AIClient, model, systemPrompt, userPrompt string) (*mcp.Response, error) {
+	return client.CallWithRequest(&mcp.Request{
+		Model: model,
+		Messages: []mcp.Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Temperature:    0.7,
+		MaxTokens:      4096,
+		ResponseSchema: decision.ResponseSchema(),
+	})
+}
+
+// fallbackModelID adds the vendor prefix OpenRouter expects to native model names
+// ("claude-sonnet-4" on anthropic -> "anthropic/claude-sonnet-4")
+func fallbackModelID(provider, model string) string {
+	if provider == "" || provider == mcp.ProviderOpenRouter || model == "" || strings.Contains(model, "/") {
+		return model
+	}
+	return provider + "/" + model
+}
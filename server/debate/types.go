@@ -17,6 +17,49 @@ const (
 	StatusCancelled Status = "cancelled"
 )
 
+// SourceTrader marks sessions run by a trader in debate decision mode
+const SourceTrader = "trader"
+
+// Consensus modes decide how votes are combined into final decisions
+const (
+	ConsensusConfidence = "confidence" // Weight by self-reported confidence (default)
+	ConsensusAccuracy   = "accuracy"   // Weight by historical hit rate
+	ConsensusCalibrated = "calibrated" // Confidence corrected by track record and Brier score
+	ConsensusMedian     = "median"     // Median stance on a short..long scale, equal weights
+	ConsensusUnanimous  = "unanimous"  // Act only when every voter picks the same action
+)
+
+// ParticipantWeight is the weight a participant carried in a consensus and why
+type ParticipantWeight struct {
+	AIModelID       string      `json:"ai_model_id"`
+	AIModelName     string      `json:"ai_model_name"`
+	Personality     Personality `json:"personality"`
+	Weight          float64     `json:"weight"`      // Multiplier on each of the participant's votes
+	Predictions     int         `json:"predictions"` // Resolved outcomes behind the weight
+	HitRate         float64     `json:"hit_rate"`
+	BrierScore      float64     `json:"brier_score"`
+	PnLContribution float64     `json:"pnl_contribution"` // Realized PnL (USDT) credited to the participant's backed votes
+}
+
+// Verdict is a judge's synthesized decision for a cycle. When it fails
+// validation the arithmetic consensus is used instead (Accepted = false).
+type Verdict struct {
+	Decisions []*Decision `json:"decisions"`
+	Rationale string      `json:"rationale"`
+	Accepted  bool        `json:"accepted"`
+	Error     string      `json:"error,omitempty"`
+	ParsePath string      `json:"parse_path,omitempty"` // How the judge's reply was parsed
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// ConsensusResult is the payload of the consensus event
+type ConsensusResult struct {
+	Mode      string               `json:"mode"`
+	Weights   []*ParticipantWeight `json:"weights"`
+	Decisions []*Decision          `json:"decisions"`
+	Verdict   *Verdict             `json:"verdict,omitempty"`
+}
+
 // Personality represents AI personality types
 type Personality string
 
@@ -26,6 +69,7 @@ const (
 	PersonalityAnalyst     Personality = "analyst"      // Neutral, data-driven
 	PersonalityContrarian  Personality = "contrarian"   // Challenges majority opinion
 	PersonalityRiskManager Personality = "risk_manager" // Focuses on position sizing
+	PersonalityJudge       Personality = "judge"        // Moderator, rules on the debate instead of arguing
 )
 
 // PersonalityColors maps personalities to UI colors
@@ -35,6 +79,7 @@ var PersonalityColors = map[Personality]string{
 	PersonalityAnalyst:     "#3B82F6", // Blue
 	PersonalityContrarian:  "#F59E0B", // Amber
 	PersonalityRiskManager: "#8B5CF6", // Purple
+	PersonalityJudge:       "#64748B", // Slate
 }
 
 // PersonalityEmojis maps personalities to emojis
@@ -44,6 +89,7 @@ var PersonalityEmojis = map[Personality]string{
 	PersonalityAnalyst:     "📊",
 	PersonalityContrarian:  "🔄",
 	PersonalityRiskManager: "🛡️",
+	PersonalityJudge:       "⚖️",
 }
 
 // GetPersonalityDescription returns the description for a personality
@@ -57,6 +103,8 @@ func GetPersonalityDescription(p Personality) string {
 		return "Data Analyst - You are neutral and purely data-driven. Present technical analysis without bias. Let the indicators speak for themselves."
 	case PersonalityContrarian:
 		return "Contrarian - You challenge majority opinions and look for overlooked opportunities. Question consensus views and find alternative interpretations of the data."
+	case PersonalityJudge:
+		return "Judge - You moderate the debate. Weigh every argument and vote on its merits and synthesize one coherent, executable decision per symbol."
 	case PersonalityRiskManager:
 		return "Risk Manager - You focus on position sizing, stop losses, and capital preservation. Evaluate risk/reward ratios and warn about potential downsides."
 	default:
@@ -83,8 +131,30 @@ type Session struct {
 	StartedAt       time.Time    `json:"started_at"`
 	CompletedAt     time.Time    `json:"completed_at"`
 	Error           string       `json:"error,omitempty"`
+	Source          string       `json:"source,omitempty"` // "" = user session, "trader" = a trader's debate-mode cycle
+	ConsensusMode   string       `json:"consensus_mode"`
+	ConsensusWeights []*ParticipantWeight `json:"consensus_weights,omitempty"` // Weights used for the latest consensus
+	Judge           *Participant `json:"judge,omitempty"`   // Optional moderator that synthesizes the final decision
+	Verdict         *Verdict     `json:"verdict,omitempty"` // The judge's ruling on the latest cycle
+
+	// Early termination: rounds stop once participants agree or the budget runs out
+	ConvergenceThreshold float64 `json:"convergence_threshold"` // Share of participants that must agree per symbol, 0 = always run MaxRounds
+	TokenBudget          int     `json:"token_budget"`          // Max tokens per cycle across rounds, 0 = unlimited
+	TokensUsed           int     `json:"tokens_used"`           // Tokens spent by the latest cycle
+	CostBudgetUSD        float64 `json:"cost_budget_usd"`       // Max USD per cycle across rounds, priced from the AI price table, 0 = unlimited
+	CostUsedUSD          float64 `json:"cost_used_usd"`         // USD spent by the latest cycle
+	StopReason           string  `json:"stop_reason,omitempty"` // Why the latest cycle skipped rounds
+
+	// Prompt template versions used by the latest cycle, in system, user order
+	Prompts []decision.PromptRef `json:"prompts,omitempty"`
 
-	// Binance credentials for trade execution
+	// Base prompts of the latest cycle, before personalities and the
+	// transcript are added. Kept for the trader's decision audit.
+	SystemPrompt string `json:"-"`
+	UserPrompt   string `json:"-"`
+
+	// Deprecated: auto-execution goes through the linked trader (TraderID).
+	// Kept so existing clients and sessions still decode.
 	BinanceAPIKey    string `json:"binance_api_key,omitempty"`
 	BinanceSecretKey string `json:"-"` // Never expose in JSON responses
 	BinanceTestnet   bool   `json:"binance_testnet"`
@@ -113,6 +183,7 @@ type Participant struct {
 type Message struct {
 	ID          string       `json:"id"`
 	SessionID   string       `json:"session_id"`
+	Cycle       int          `json:"cycle"`
 	Round       int          `json:"round"`
 	AIModelID   string       `json:"ai_model_id"`
 	AIModelName string       `json:"ai_model_name"`
@@ -122,6 +193,7 @@ type Message struct {
 	Content     string       `json:"content"`
 	Decisions   []*Decision  `json:"decisions"`
 	Confidence  int          `json:"confidence"`
+	ParsePath   string       `json:"parse_path,omitempty"` // schema, lenient or fallback
 	CreatedAt   time.Time    `json:"created_at"`
 }
 
@@ -129,11 +201,13 @@ type Message struct {
 type Vote struct {
 	ID          string       `json:"id"`
 	SessionID   string       `json:"session_id"`
+	Cycle       int          `json:"cycle"`
 	AIModelID   string       `json:"ai_model_id"`
 	AIModelName string       `json:"ai_model_name"`
 	Personality Personality  `json:"personality"`
 	Decisions   []*Decision  `json:"decisions"`
 	Reasoning   string       `json:"reasoning"`
+	ParsePath   string       `json:"parse_path,omitempty"`
 	CreatedAt   time.Time    `json:"created_at"`
 }
 
@@ -175,7 +249,12 @@ type CreateSessionRequest struct {
 	Participants         []CreateParticipantRequest  `json:"participants"`
 	AutoCycle            bool                        `json:"auto_cycle"`
 	CycleIntervalMinutes int                         `json:"cycle_interval_minutes"`
-	// Binance credentials for trade execution
+	ConsensusMode        string                      `json:"consensus_mode"` // See Consensus* modes, default confidence
+	Judge                *CreateParticipantRequest   `json:"judge,omitempty"`
+	ConvergenceThreshold float64                     `json:"convergence_threshold"` // 0 disables early termination, 1 = unanimous
+	TokenBudget          int                         `json:"token_budget"`          // Tokens per cycle, 0 = unlimited
+	CostBudgetUSD        float64                     `json:"cost_budget_usd"`       // USD per cycle, 0 = unlimited
+	// Deprecated: ignored for execution, decisions go to TraderID
 	BinanceAPIKey    string `json:"binance_api_key"`
 	BinanceSecretKey string `json:"binance_secret_key"`
 	BinanceTestnet   bool   `json:"binance_testnet"`
@@ -198,10 +277,20 @@ type Event struct {
 	Timestamp time.Time   `json:"timestamp"`
 }
 
+// RoundSummary is the data of a round_end event
+type RoundSummary struct {
+	Round       int     `json:"round"`
+	Agreement   float64 `json:"agreement"`             // Lowest per-symbol share of participants sharing a stance
+	TokensUsed  int     `json:"tokens_used"`           // Tokens spent so far this cycle
+	CostUsedUSD float64 `json:"cost_used_usd"`         // USD spent so far this cycle
+	SkipReason  string  `json:"skip_reason,omitempty"` // Set when the remaining rounds are skipped
+}
+
 // MarketContext provides market data for debate
 type MarketContext struct {
 	CurrentTime string                    `json:"current_time"`
 	Account     decision.AccountInfo      `json:"account"`
 	Positions   []decision.PositionInfo   `json:"positions"`
 	MarketData  map[string]*decision.MarketData `json:"market_data"`
+	Lessons     []decision.Lesson         `json:"lessons,omitempty"` // Reflection lessons for the panel's prompt
 }
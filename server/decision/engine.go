@@ -14,6 +14,7 @@ type Engine struct {
 	promptBuilder *PromptBuilder
 	validationCfg *ValidationConfig
 	lang          Language
+	model         string // Overrides the client's model when set
 }
 
 // NewEngine creates a new decision engine
@@ -26,6 +27,21 @@ func NewEngine(client mcp.AIClient, lang Language) *Engine {
 	}
 }
 
+// SetClient replaces the AI client used for decisions
+func (e *Engine) SetClient(client mcp.AIClient) {
+	e.client = client
+}
+
+// SetModel overrides the client's model for decisions; empty uses the client's
+func (e *Engine) SetModel(model string) {
+	e.model = model
+}
+
+// SetPromptVariant selects the prompt variant for contexts that don't set one
+func (e *Engine) SetPromptVariant(variant string) {
+	e.promptBuilder.SetVariant(variant)
+}
+
 // SetValidationConfig sets custom validation configuration
 func (e *Engine) SetValidationConfig(cfg *ValidationConfig) {
 	e.validationCfg = cfg
@@ -46,21 +62,26 @@ func (e *Engine) MakeDecision(ctx *Context) (*FullDecision, error) {
 	e.UpdateValidationFromContext(ctx)
 
 	// Build prompts
-	systemPrompt := e.promptBuilder.BuildSystemPrompt()
-	userPrompt := e.promptBuilder.BuildUserPrompt(ctx)
+	systemPrompt, userPrompt, prompts := e.promptBuilder.Build(ctx)
 
 	// Call AI
 	start := time.Now()
 
+	model := e.client.GetModel()
+	if e.model != "" {
+		model = e.model
+	}
+
 	req := &mcp.Request{
-		Model: e.client.GetModel(),
+		Model: model,
 		Messages: []mcp.Message{
 			{Role: "system", Content: systemPrompt},
 			{Role: "user", Content: userPrompt},
 		},
-		Temperature: 0.7,
-		MaxTokens:   4096,
-		Stream:      true,
+		Temperature:    0.7,
+		MaxTokens:      4096,
+		Stream:         true,
+		ResponseSchema: ResponseSchema(),
 	}
 
 	log.Printf("[Decision] Requesting AI (streaming)... ")
@@ -92,10 +113,15 @@ func (e *Engine) MakeDecision(ctx *Context) (*FullDecision, error) {
 		log.Printf("WARNING: Decision parsing/validation error: %v", parseErr)
 		// Still return what we parsed, but with the error
 	}
+	log.Printf("[Decision] Parsed %d decisions via %s path", len(fullDecision.Decisions), fullDecision.ParsePath)
 
 	// Fill in metadata
 	fullDecision.SystemPrompt = systemPrompt
 	fullDecision.UserPrompt = userPrompt
+	fullDecision.Prompts = prompts
+	for _, l := range ctx.Lessons {
+		fullDecision.Lessons = append(fullDecision.Lessons, l.ID)
+	}
 	fullDecision.RawResponse = response
 	fullDecision.Timestamp = time.Now()
 	fullDecision.AIRequestDurationMs = duration.Milliseconds()
@@ -11,6 +11,7 @@ import (
 var (
 	reReasoningTag   = regexp.MustCompile(`(?s)<reasoning>(.*?)</reasoning>`)
 	reDecisionTag    = regexp.MustCompile(`(?s)<decision>(.*?)</decision>`)
+	reFinalVoteTag   = regexp.MustCompile(`(?s)<final_vote>(.*?)</final_vote>`)
 	reJSONFence      = regexp.MustCompile("(?s)```(?:json)?\\s*([\\s\\S]*?)```")
 	reJSONArray      = regexp.MustCompile(`(?s)\[[\s\S]*\]`)
 	reArrayHead      = regexp.MustCompile(`^\s*\[\s*\{`)
@@ -18,28 +19,35 @@ var (
 	reInvisibleRunes = regexp.MustCompile(`[\x00-\x08\x0B\x0C\x0E-\x1F\x7F]`)
 )
 
-// ParseFullDecisionResponse parses AI response into decisions
+// ParseFullDecisionResponse parses AI response into decisions. FullDecision
+// records the parse path taken.
 func ParseFullDecisionResponse(aiResponse string, cfg *ValidationConfig) (*FullDecision, error) {
-	cotTrace := extractCoTTrace(aiResponse)
-
-	decisions, err := extractDecisions(aiResponse)
+	parsed, parsePath, err := ParseDecisions(aiResponse)
+	cotTrace := parsed.Reasoning
 	if err != nil {
 		return &FullDecision{
 			CoTTrace:  cotTrace,
 			Decisions: []Decision{},
+			ParsePath: parsePath,
 		}, fmt.Errorf("failed to extract decisions: %w", err)
 	}
+	if cfg == nil {
+		cfg = DefaultValidationConfig()
+	}
+	decisions := engineDecisions(parsed.Decisions, cfg.AccountEquity)
 
 	if err := ValidateDecisions(decisions, cfg); err != nil {
 		return &FullDecision{
 			CoTTrace:  cotTrace,
 			Decisions: decisions,
+			ParsePath: parsePath,
 		}, fmt.Errorf("decision validation failed: %w", err)
 	}
 
 	return &FullDecision{
 		CoTTrace:  cotTrace,
 		Decisions: decisions,
+		ParsePath: parsePath,
 	}, nil
 }
 
@@ -64,8 +72,10 @@ func extractCoTTrace(response string) string {
 	return strings.TrimSpace(response)
 }
 
-// extractDecisions extracts JSON decisions from AI response
-func extractDecisions(response string) ([]Decision, error) {
+// extractDecisions is the lenient parser: it recovers a JSON decision array
+// from tags, code fences or free text. fallback is set when no array was
+// found and the result is a safe wait.
+func extractDecisions(response string) (decisions []StructuredDecision, fallback bool, err error) {
 	s := removeInvisibleRunes(response)
 	s = strings.TrimSpace(s)
 	s = fixMissingQuotes(s)
@@ -75,6 +85,8 @@ func extractDecisions(response string) ([]Decision, error) {
 	// Try <decision> tag first
 	if match := reDecisionTag.FindStringSubmatch(s); len(match) > 1 {
 		jsonPart = strings.TrimSpace(match[1])
+	} else if match := reFinalVoteTag.FindStringSubmatch(s); len(match) > 1 {
+		jsonPart = strings.TrimSpace(match[1])
 	} else {
 		jsonPart = s
 	}
@@ -88,14 +100,13 @@ func extractDecisions(response string) ([]Decision, error) {
 		jsonContent = fixMissingQuotes(jsonContent)
 
 		if err := validateJSONFormat(jsonContent); err != nil {
-			return nil, fmt.Errorf("JSON format validation failed: %w\nJSON: %s", err, truncate(jsonContent, 200))
+			return nil, false, fmt.Errorf("JSON format validation failed: %w\nJSON: %s", err, truncate(jsonContent, 200))
 		}
 
-		var decisions []Decision
 		if err := json.Unmarshal([]byte(jsonContent), &decisions); err != nil {
-			return nil, fmt.Errorf("JSON parsing failed: %w\nJSON: %s", err, truncate(jsonContent, 200))
+			return nil, false, fmt.Errorf("JSON parsing failed: %w\nJSON: %s", err, truncate(jsonContent, 200))
 		}
-		return decisions, nil
+		return decisions, false, nil
 	}
 
 	// Fallback to raw array extraction
@@ -107,27 +118,26 @@ func extractDecisions(response string) ([]Decision, error) {
 			cotSummary = cotSummary[:240] + "..."
 		}
 
-		fallbackDecision := Decision{
+		fallbackDecision := StructuredDecision{
 			Symbol:    "ALL",
 			Action:    ActionWait,
 			Reasoning: fmt.Sprintf("Model didn't output structured JSON decision, entering safe wait; summary: %s", cotSummary),
 		}
-		return []Decision{fallbackDecision}, nil
+		return []StructuredDecision{fallbackDecision}, true, nil
 	}
 
 	jsonContent = compactArrayOpen(jsonContent)
 	jsonContent = fixMissingQuotes(jsonContent)
 
 	if err := validateJSONFormat(jsonContent); err != nil {
-		return nil, fmt.Errorf("JSON format validation failed: %w\nJSON: %s", err, truncate(jsonContent, 200))
+		return nil, false, fmt.Errorf("JSON format validation failed: %w\nJSON: %s", err, truncate(jsonContent, 200))
 	}
 
-	var decisions []Decision
 	if err := json.Unmarshal([]byte(jsonContent), &decisions); err != nil {
-		return nil, fmt.Errorf("JSON parsing failed: %w\nJSON: %s", err, truncate(jsonContent, 200))
+		return nil, false, fmt.Errorf("JSON parsing failed: %w\nJSON: %s", err, truncate(jsonContent, 200))
 	}
 
-	return decisions, nil
+	return decisions, false, nil
 }
 
 // fixMissingQuotes fixes common quote and bracket issues from AI output
@@ -0,0 +1,116 @@
+package decision
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDecisions_Paths(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   string
+		wantPath   string
+		wantSymbol string
+		wantAction string
+		wantErr    bool
+	}{
+		{
+			name:       "schema-constrained object",
+			response:   `{"reasoning":"Trend up","decisions":[{"symbol":"BTCUSDT","action":"open_long","confidence":80,"leverage":5,"position_pct":0,"position_size_usd":1000,"stop_loss":60000,"take_profit":70000,"reasoning":"Breakout"}]}`,
+			wantPath:   ParsePathSchema,
+			wantSymbol: "BTCUSDT",
+			wantAction: ActionOpenLong,
+		},
+		{
+			name:       "tagged reply",
+			response:   "<reasoning>Flat</reasoning>\n<decision>\n```json\n[{\"symbol\":\"ETHUSDT\",\"action\":\"hold\",\"reasoning\":\"Range\"}]\n```\n</decision>",
+			wantPath:   ParsePathLenient,
+			wantSymbol: "ETHUSDT",
+			wantAction: ActionHold,
+		},
+		{
+			name:       "final vote tag",
+			response:   `<final_vote>[{"symbol":"SOLUSDT","action":"open_short","confidence":70}]</final_vote>`,
+			wantPath:   ParsePathLenient,
+			wantSymbol: "SOLUSDT",
+			wantAction: ActionOpenShort,
+		},
+		{
+			name:       "object with an unknown action falls back to the lenient parser",
+			response:   `{"reasoning":"x","decisions":[{"symbol":"BTCUSDT","action":"buy"}]}`,
+			wantPath:   ParsePathLenient,
+			wantSymbol: "BTCUSDT",
+			wantAction: "buy",
+		},
+		{
+			name:       "prose only",
+			response:   "The market is unclear, I'd stay out for now.",
+			wantPath:   ParsePathFallback,
+			wantSymbol: "ALL",
+			wantAction: ActionWait,
+		},
+		{
+			name:     "broken array",
+			response: `<decision>[{"symbol":"BTCUSDT","action":"open_long","stop_loss":60~61000}]</decision>`,
+			wantPath: ParsePathLenient,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, path, err := ParseDecisions(tt.response)
+			if path != tt.wantPath {
+				t.Errorf("path = %q, want %q", path, tt.wantPath)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(parsed.Decisions) != 1 {
+				t.Fatalf("got %d decisions, want 1", len(parsed.Decisions))
+			}
+			if d := parsed.Decisions[0]; d.Symbol != tt.wantSymbol || d.Action != tt.wantAction {
+				t.Errorf("decision = %s %s, want %s %s", d.Action, d.Symbol, tt.wantAction, tt.wantSymbol)
+			}
+		})
+	}
+}
+
+func TestParseFullDecisionResponse_ReportsPath(t *testing.T) {
+	response := `{"reasoning":"Waiting for a retest","decisions":[{"symbol":"BTCUSDT","action":"wait","confidence":60,"leverage":0,"position_pct":0,"position_size_usd":0,"stop_loss":0,"take_profit":0,"reasoning":"No setup"}]}`
+
+	full, err := ParseFullDecisionResponse(response, DefaultValidationConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if full.ParsePath != ParsePathSchema || full.CoTTrace != "Waiting for a retest" {
+		t.Errorf("got path %q, CoT %q", full.ParsePath, full.CoTTrace)
+	}
+
+	// Sizing by position_pct converts to USD of equity and passes validation.
+	// Absolute SL/TP are checked against their midpoint, so R:R is 1:1.
+	pctOnly := `{"reasoning":"Trend up","decisions":[{"symbol":"BTCUSDT","action":"open_long","confidence":80,"leverage":5,"position_pct":0.1,"position_size_usd":0,"stop_loss":60000,"take_profit":70000,"reasoning":"Breakout"}]}`
+	cfg := DefaultValidationConfig()
+	cfg.MinRiskReward = 1
+	full, err = ParseFullDecisionResponse(pctOnly, cfg)
+	if err != nil {
+		t.Fatalf("position_pct reply: unexpected error: %v", err)
+	}
+	if got := full.Decisions[0].PositionSizeUSD; got != 1000 {
+		t.Errorf("position_pct 0.1 of 10000 equity = $%.2f, want $1000", got)
+	}
+
+	// The rendered text goes back through the lenient parser unchanged
+	parsed, _ := ParseStructured(response)
+	again, path, err := ParseDecisions(parsed.Text())
+	if err != nil || path != ParsePathLenient || again.Decisions[0] != parsed.Decisions[0] ||
+		!strings.Contains(again.Reasoning, "Waiting for a retest") {
+		t.Errorf("Text() round trip = %+v via %q (err %v)", again, path, err)
+	}
+}
@@ -0,0 +1,365 @@
+package decision
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+)
+
+// Built-in prompt templates, the "default" variant
+//
+//go:embed prompts
+var builtinPrompts embed.FS
+
+// DefaultPromptVariant is the variant used when none is selected, and the
+// one every lookup falls back to
+const DefaultPromptVariant = "default"
+
+// Prompt template names
+const (
+	PromptSystem             = "system"               // Decision engine system prompt
+	PromptUser               = "user"                 // Decision engine user prompt (PromptData)
+	PromptSignalSystem       = "signal_system"        // Single-symbol signal system prompt
+	PromptSignalSimpleSystem = "signal_simple_system" // Minimal single-symbol signal system prompt
+	PromptSignalUser         = "signal_user"          // Single-symbol signal user prompt (SignalPromptData)
+	PromptReflectionSystem   = "reflection_system"    // Closed-trade lesson summarizer system prompt
+	PromptReflectionUser     = "reflection_user"      // Closed-trade lesson summarizer user prompt (ReflectionPromptData)
+)
+
+// Prompt template sources
+const (
+	PromptSourceBuiltin = "builtin"
+	PromptSourceFile    = "file"
+	PromptSourceDB      = "db"
+)
+
+// PromptTemplate is one version of a named prompt template
+type PromptTemplate struct {
+	Variant  string `json:"variant"`
+	Name     string `json:"name"`
+	Language string `json:"language,omitempty"` // Empty for the English/default text
+	Version  int    `json:"version"`
+	Source   string `json:"source"`
+	Hash     string `json:"hash"`
+	Body     string `json:"body"`
+
+	tmpl *template.Template
+}
+
+// Ref identifies the exact template version
+func (t *PromptTemplate) Ref() PromptRef {
+	return PromptRef{
+		Name:     t.Name,
+		Variant:  t.Variant,
+		Language: t.Language,
+		Version:  t.Version,
+		Source:   t.Source,
+		Hash:     t.Hash,
+	}
+}
+
+// PromptRef records which template version rendered a prompt
+type PromptRef struct {
+	Name     string `json:"name"`
+	Variant  string `json:"variant"`
+	Language string `json:"language,omitempty"`
+	Version  int    `json:"version"`
+	Source   string `json:"source"`
+	Hash     string `json:"hash"`
+}
+
+// String formats the ref as variant/name[.lang]@vN
+func (r PromptRef) String() string {
+	name := r.Name
+	if r.Language != "" {
+		name += "." + r.Language
+	}
+	return fmt.Sprintf("%s/%s@v%d", r.Variant, name, r.Version)
+}
+
+// PromptData is the data for the system and user templates
+type PromptData struct {
+	Context     *Context
+	ContextText string // Context formatted for the AI in the template's language
+}
+
+// SignalPromptData is the data for the single-symbol signal templates
+type SignalPromptData struct {
+	Symbol     string
+	MarketData string
+	Simple     bool
+	Turbo      bool
+}
+
+// ReflectionPromptData is the data for the reflection templates
+type ReflectionPromptData struct {
+	Lessons []Lesson // Stats of each symbol/direction/setup; Summary is the stats summary
+}
+
+// NewPromptTemplate parses a template body
+func NewPromptTemplate(variant, name, language string, version int, source, body string) (*PromptTemplate, error) {
+	if variant == "" || name == "" {
+		return nil, fmt.Errorf("variant and name are required")
+	}
+	if version < 1 {
+		return nil, fmt.Errorf("version must be at least 1")
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s/%s: %w", variant, name, err)
+	}
+
+	sum := sha256.Sum256([]byte(body))
+	return &PromptTemplate{
+		Variant:  variant,
+		Name:     name,
+		Language: language,
+		Version:  version,
+		Source:   source,
+		Hash:     hex.EncodeToString(sum[:])[:12],
+		Body:     body,
+		tmpl:     tmpl,
+	}, nil
+}
+
+// PromptRegistry holds the latest version of every prompt template by
+// variant, name and language
+type PromptRegistry struct {
+	mu        sync.RWMutex
+	templates map[string]*PromptTemplate
+}
+
+// NewPromptRegistry creates a registry holding the built-in templates
+func NewPromptRegistry() *PromptRegistry {
+	r := &PromptRegistry{templates: make(map[string]*PromptTemplate)}
+	sub, err := fs.Sub(builtinPrompts, "prompts")
+	if err == nil {
+		err = r.loadFS(sub, PromptSourceBuiltin)
+	}
+	if err != nil {
+		// The templates are compiled in, so this is a build problem
+		panic(fmt.Sprintf("built-in prompt templates: %v", err))
+	}
+	return r
+}
+
+func promptKey(variant, name, language string) string {
+	return variant + "/" + name + "/" + language
+}
+
+// Add registers a template. It replaces the current one for the same
+// variant, name and language unless that one has a higher version.
+func (r *PromptRegistry) Add(t *PromptTemplate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := promptKey(t.Variant, t.Name, t.Language)
+	if cur, ok := r.templates[key]; ok && cur.Version > t.Version {
+		return
+	}
+	r.templates[key] = t
+}
+
+// LoadDir loads templates laid out as <dir>/<variant>/<name>[.<lang>][.vN].tmpl.
+// Files without a version suffix are version 1. A missing directory is not
+// an error.
+func (r *PromptRegistry) LoadDir(dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	return r.loadFS(os.DirFS(dir), PromptSourceFile)
+}
+
+func (r *PromptRegistry) loadFS(fsys fs.FS, source string) error {
+	return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".tmpl" {
+			return nil
+		}
+
+		parts := strings.Split(path, "/")
+		if len(parts) != 2 {
+			return nil
+		}
+		name, language, version, ok := parsePromptFilename(parts[1])
+		if !ok {
+			log.Printf("[Prompt] Skipping %s: expected <name>[.<lang>][.vN].tmpl", path)
+			return nil
+		}
+
+		body, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		// Editors add a final newline; the prompts never end with one
+		body = bytes.TrimSuffix(body, []byte("\n"))
+
+		t, err := NewPromptTemplate(parts[0], name, language, version, source, string(body))
+		if err != nil {
+			return err
+		}
+		r.Add(t)
+		return nil
+	})
+}
+
+// parsePromptFilename splits <name>[.<lang>][.vN].tmpl
+func parsePromptFilename(filename string) (name, language string, version int, ok bool) {
+	parts := strings.Split(strings.TrimSuffix(filename, ".tmpl"), ".")
+	version = 1
+
+	if last := parts[len(parts)-1]; len(parts) > 1 && strings.HasPrefix(last, "v") {
+		n, err := strconv.Atoi(last[1:])
+		if err != nil || n < 1 {
+			return "", "", 0, false
+		}
+		version = n
+		parts = parts[:len(parts)-1]
+	}
+
+	switch len(parts) {
+	case 1:
+		name = parts[0]
+	case 2:
+		name, language = parts[0], parts[1]
+	default:
+		return "", "", 0, false
+	}
+	return name, language, version, name != ""
+}
+
+// Get finds the template for a variant, name and language, falling back to
+// the English text and then to the default variant
+func (r *PromptRegistry) Get(variant, name, language string) (*PromptTemplate, bool) {
+	if variant == "" {
+		variant = DefaultPromptVariant
+	}
+	if language == string(LangEnglish) {
+		language = ""
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range []string{variant, DefaultPromptVariant} {
+		if language != "" {
+			if t, ok := r.templates[promptKey(v, name, language)]; ok {
+				return t, true
+			}
+		}
+		if t, ok := r.templates[promptKey(v, name, "")]; ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Render executes a template and returns the text with the version used
+func (r *PromptRegistry) Render(variant, name, language string, data interface{}) (string, PromptRef, error) {
+	t, ok := r.Get(variant, name, language)
+	if !ok {
+		return "", PromptRef{}, fmt.Errorf("no prompt template %q", name)
+	}
+
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return "", t.Ref(), fmt.Errorf("failed to render %s: %w", t.Ref(), err)
+	}
+	return buf.String(), t.Ref(), nil
+}
+
+// RenderOrDefault renders a template, falling back to the built-in default
+// variant if it fails so a bad custom template can't stop trading
+func (r *PromptRegistry) RenderOrDefault(variant, name, language string, data interface{}) (string, PromptRef) {
+	prompt, ref, err := r.Render(variant, name, language, data)
+	if err == nil {
+		return prompt, ref
+	}
+
+	log.Printf("[Prompt] %v, using the built-in default", err)
+	prompt, ref, err = builtinRegistry().Render(DefaultPromptVariant, name, language, data)
+	if err != nil {
+		// Built-in templates are tested; this only happens on a broken build
+		panic(fmt.Sprintf("built-in prompt %s: %v", name, err))
+	}
+	return prompt, ref
+}
+
+// List returns the current templates sorted by variant, name and language
+func (r *PromptRegistry) List() []*PromptTemplate {
+	r.mu.RLock()
+	list := make([]*PromptTemplate, 0, len(r.templates))
+	for _, t := range r.templates {
+		list = append(list, t)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Variant != b.Variant {
+			return a.Variant < b.Variant
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Language < b.Language
+	})
+	return list
+}
+
+// Variants returns the registered variant names
+func (r *PromptRegistry) Variants() []string {
+	seen := make(map[string]bool)
+	var variants []string
+	for _, t := range r.List() {
+		if !seen[t.Variant] {
+			seen[t.Variant] = true
+			variants = append(variants, t.Variant)
+		}
+	}
+	return variants
+}
+
+var (
+	defaultPromptRegistry atomic.Pointer[PromptRegistry]
+
+	builtinOnce sync.Once
+	builtin     *PromptRegistry
+)
+
+// builtinRegistry holds only the built-in templates, the last resort when a
+// custom template fails to render
+func builtinRegistry() *PromptRegistry {
+	builtinOnce.Do(func() { builtin = NewPromptRegistry() })
+	return builtin
+}
+
+// DefaultPromptRegistry returns the process-wide registry, built-in
+// templates only until SetDefaultPromptRegistry installs another
+func DefaultPromptRegistry() *PromptRegistry {
+	if r := defaultPromptRegistry.Load(); r != nil {
+		return r
+	}
+	defaultPromptRegistry.CompareAndSwap(nil, NewPromptRegistry())
+	return defaultPromptRegistry.Load()
+}
+
+// SetDefaultPromptRegistry replaces the process-wide registry
+func SetDefaultPromptRegistry(r *PromptRegistry) {
+	defaultPromptRegistry.Store(r)
+}
@@ -0,0 +1,118 @@
+package decision
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePromptFilename(t *testing.T) {
+	tests := []struct {
+		filename     string
+		wantName     string
+		wantLanguage string
+		wantVersion  int
+		wantOK       bool
+	}{
+		{"system.tmpl", "system", "", 1, true},
+		{"system.zh-CN.tmpl", "system", "zh-CN", 1, true},
+		{"user.v3.tmpl", "user", "", 3, true},
+		{"user.zh-CN.v2.tmpl", "user", "zh-CN", 2, true},
+		{"user.v0.tmpl", "", "", 0, false},
+		{"user.vx.tmpl", "", "", 0, false},
+		{"a.b.c.tmpl", "", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			name, language, version, ok := parsePromptFilename(tt.filename)
+			if name != tt.wantName || language != tt.wantLanguage || version != tt.wantVersion || ok != tt.wantOK {
+				t.Errorf("got (%q, %q, %d, %v), want (%q, %q, %d, %v)", name, language, version, ok,
+					tt.wantName, tt.wantLanguage, tt.wantVersion, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPromptRegistry_LoadDirAndRender(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"default/system.v2.tmpl":    "Custom default system\n",
+		"scalper/system.tmpl":       "Scalper v1",
+		"scalper/system.v2.tmpl":    "Scalper v2 for {{.ContextText}}",
+		"scalper/system.zh-CN.tmpl": "剥头皮",
+		"broken/user.tmpl":          "{{.Missing}}",
+	}
+	for path, body := range files {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := NewPromptRegistry()
+	if err := r.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		variant  string
+		template string
+		language Language
+		want     string
+		wantRef  string
+	}{
+		{"highest version wins", "scalper", PromptSystem, LangEnglish, "Scalper v2 for BTC", "scalper/system@v2"},
+		{"language specific", "scalper", PromptSystem, LangChinese, "剥头皮", "scalper/system.zh-CN@v1"},
+		{"file overrides built-in default", "", PromptSystem, LangEnglish, "Custom default system", "default/system@v2"},
+		{"unknown variant falls back to default", "missing", PromptSystem, LangEnglish, "Custom default system", "default/system@v2"},
+		{"missing template falls back to default variant", "scalper", PromptSignalUser, LangEnglish, "Analyze and decide:\n\nBTC", "default/signal_user@v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]interface{}{"ContextText": "BTC", "MarketData": "BTC", "Simple": true, "Turbo": false}
+			got, ref, err := r.Render(tt.variant, tt.template, string(tt.language), data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want || ref.String() != tt.wantRef {
+				t.Errorf("Render() = %q (%s), want %q (%s)", got, ref, tt.want, tt.wantRef)
+			}
+		})
+	}
+
+	// A template that fails to render is replaced by the built-in default
+	got, ref := r.RenderOrDefault("broken", PromptUser, "", PromptData{ContextText: "CTX"})
+	if ref.Source != PromptSourceBuiltin || !strings.HasPrefix(got, "CTX\n\n---\n\n## Decision Steps") {
+		t.Errorf("RenderOrDefault() = %q (%s from %s), want the built-in user prompt", got[:20], ref, ref.Source)
+	}
+}
+
+func TestPromptBuilder_BuildRecordsVariant(t *testing.T) {
+	r := NewPromptRegistry()
+	tmpl, err := NewPromptTemplate("terse", PromptSystem, "", 4, PromptSourceDB, "Be terse.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Add(tmpl)
+
+	pb := NewPromptBuilder(LangEnglish)
+	pb.SetRegistry(r)
+
+	// The context's variant overrides the builder's
+	pb.SetVariant("unused")
+	system, user, refs := pb.Build(&Context{PromptVariant: "terse"})
+	if system != "Be terse." || !strings.Contains(user, "## Decision Steps") {
+		t.Errorf("Build() system = %q, user = %q", system, user[:40])
+	}
+	if len(refs) != 2 || refs[0].String() != "terse/system@v4" || refs[0].Source != PromptSourceDB ||
+		refs[1].String() != "default/user@v1" || refs[0].Hash == "" {
+		t.Errorf("Build() refs = %+v", refs)
+	}
+}
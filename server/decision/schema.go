@@ -0,0 +1,190 @@
+package decision
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"auto-trader-ahh/mcp"
+)
+
+// DecisionSchemaName names the shared decision schema
+const DecisionSchemaName = "trading_decisions"
+
+// SignalSchemaName names the single-symbol signal schema
+const SignalSchemaName = "trading_signal"
+
+// Parse paths, reported for every AI call whose decisions are parsed
+const (
+	ParsePathSchema   = "schema"   // Schema-constrained JSON, decoded strictly
+	ParsePathLenient  = "lenient"  // Recovered from free text by the lenient parser
+	ParsePathFallback = "fallback" // No decisions found; safe wait
+)
+
+// StructuredDecision is one decision as the shared schema describes it. It
+// carries the fields of both the decision engine and the debate engine.
+type StructuredDecision struct {
+	Symbol          string  `json:"symbol"`
+	Action          string  `json:"action"`
+	Confidence      int     `json:"confidence"`
+	Leverage        int     `json:"leverage"`
+	PositionPct     float64 `json:"position_pct"`
+	PositionSizeUSD float64 `json:"position_size_usd"`
+	StopLoss        float64 `json:"stop_loss"`
+	TakeProfit      float64 `json:"take_profit"`
+	Reasoning       string  `json:"reasoning"`
+}
+
+// StructuredResponse is the object the shared schema constrains output to
+type StructuredResponse struct {
+	Reasoning string               `json:"reasoning"`
+	Decisions []StructuredDecision `json:"decisions"`
+}
+
+// ResponseSchema returns the shared decision schema for mcp requests
+func ResponseSchema() *mcp.ResponseSchema {
+	number := func(desc string) map[string]interface{} {
+		return map[string]interface{}{"type": "number", "description": desc}
+	}
+	integer := func(desc string) map[string]interface{} {
+		return map[string]interface{}{"type": "integer", "description": desc}
+	}
+
+	item := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"symbol": map[string]interface{}{"type": "string", "description": "Trading pair, or ALL for a blanket wait"},
+			"action": map[string]interface{}{
+				"type": "string",
+				"enum": []string{ActionOpenLong, ActionOpenShort, ActionCloseLong, ActionCloseShort, ActionHold, ActionWait},
+			},
+			"confidence":        integer("Confidence 0-100"),
+			"leverage":          integer("Leverage for opening actions, otherwise 0"),
+			"position_pct":      number("Position size as a fraction of equity (0-1), 0 when position_size_usd is given"),
+			"position_size_usd": number("Position size in USD, 0 when position_pct is given"),
+			"stop_loss":         number("Stop loss price for opening actions, otherwise 0"),
+			"take_profit":       number("Take profit price for opening actions, otherwise 0"),
+			"reasoning":         map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"symbol", "action", "confidence", "leverage", "position_pct", "position_size_usd",
+			"stop_loss", "take_profit", "reasoning"},
+		"additionalProperties": false,
+	}
+
+	return &mcp.ResponseSchema{
+		Name:        DecisionSchemaName,
+		Description: "Submit your chain of thought and one decision per symbol",
+		Schema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"reasoning": map[string]interface{}{"type": "string", "description": "Chain of thought behind the decisions"},
+				"decisions": map[string]interface{}{"type": "array", "items": item},
+			},
+			"required":             []string{"reasoning", "decisions"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+// SignalResponseSchema returns the schema for the single-model trading loop's
+// per-symbol signal, limited to the given actions. It is deliberately not
+// the shared schema: the signal prompts ask for one symbol's BUY/SELL/HOLD/
+// CLOSE with SL/TP as percentages and an optional limit entry price, which is
+// what the loop executes. The shared schema carries several symbols' leg
+// actions with absolute SL/TP prices and sizes for the decision and debate
+// engines.
+func SignalResponseSchema(actions ...string) *mcp.ResponseSchema {
+	number := func(desc string) map[string]interface{} {
+		return map[string]interface{}{"type": "number", "description": desc}
+	}
+	return &mcp.ResponseSchema{
+		Name:        SignalSchemaName,
+		Description: "Submit the trading decision for the symbol",
+		Schema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"action":          map[string]interface{}{"type": "string", "enum": actions},
+				"symbol":          map[string]interface{}{"type": "string", "description": "Exact symbol from the data"},
+				"confidence":      number("Confidence 0-100"),
+				"reasoning":       map[string]interface{}{"type": "string"},
+				"stop_loss_pct":   number("Stop loss as a percentage, e.g. 2.0"),
+				"take_profit_pct": number("Take profit as a percentage, e.g. 6.0"),
+				"entry_price":     number("Limit entry price, 0 for a market entry"),
+			},
+			"required":             []string{"action", "symbol", "confidence", "reasoning", "stop_loss_pct", "take_profit_pct", "entry_price"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+// ParseStructured strictly decodes a schema-constrained response. It fails
+// on anything but a bare JSON object with at least one well-formed decision.
+func ParseStructured(response string) (*StructuredResponse, error) {
+	s := strings.TrimSpace(response)
+	if !strings.HasPrefix(s, "{") {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+
+	var parsed StructuredResponse
+	if err := json.Unmarshal([]byte(s), &parsed); err != nil {
+		return nil, err
+	}
+	if len(parsed.Decisions) == 0 {
+		return nil, fmt.Errorf("no decisions")
+	}
+	for i, d := range parsed.Decisions {
+		if d.Symbol == "" {
+			return nil, fmt.Errorf("decision %d has no symbol", i+1)
+		}
+		if !ValidActions[d.Action] {
+			return nil, fmt.Errorf("decision %d has invalid action %q", i+1, d.Action)
+		}
+	}
+	return &parsed, nil
+}
+
+// ParseDecisions reads decisions from an AI response: schema-constrained
+// JSON first, then the lenient parser. It returns the parse path taken; on
+// the fallback path the only decision is a safe wait for ALL.
+func ParseDecisions(response string) (*StructuredResponse, string, error) {
+	if parsed, err := ParseStructured(response); err == nil {
+		return parsed, ParsePathSchema, nil
+	}
+
+	decisions, fallback, err := extractDecisions(response)
+	parsed := &StructuredResponse{Reasoning: extractCoTTrace(response), Decisions: decisions}
+	if fallback {
+		return parsed, ParsePathFallback, err
+	}
+	return parsed, ParsePathLenient, err
+}
+
+// Text renders a structured response in the tagged format the prompts ask
+// for, for transcripts and logs
+func (r *StructuredResponse) Text() string {
+	decisions, _ := json.MarshalIndent(r.Decisions, "", "  ")
+	return fmt.Sprintf("<reasoning>\n%s\n</reasoning>\n\n<decision>\n%s\n</decision>", strings.TrimSpace(r.Reasoning), decisions)
+}
+
+// engineDecisions converts structured decisions to the decision engine's
+// type. Sizes given as position_pct are converted to USD of equity.
+func engineDecisions(decisions []StructuredDecision, equity float64) []Decision {
+	out := make([]Decision, 0, len(decisions))
+	for _, d := range decisions {
+		sizeUSD := d.PositionSizeUSD
+		if sizeUSD <= 0 && d.PositionPct > 0 {
+			sizeUSD = d.PositionPct * equity
+		}
+		out = append(out, Decision{
+			Symbol:          d.Symbol,
+			Action:          d.Action,
+			Leverage:        d.Leverage,
+			PositionSizeUSD: sizeUSD,
+			StopLoss:        d.StopLoss,
+			TakeProfit:      d.TakeProfit,
+			Confidence:      d.Confidence,
+			Reasoning:       d.Reasoning,
+		})
+	}
+	return out
+}
@@ -39,13 +39,16 @@ type Decision struct {
 
 // FullDecision is the complete AI response with chain of thought
 type FullDecision struct {
-	SystemPrompt        string     `json:"system_prompt"`
-	UserPrompt          string     `json:"user_prompt"`
-	CoTTrace            string     `json:"cot_trace"` // Chain of thought
-	Decisions           []Decision `json:"decisions"`
-	RawResponse         string     `json:"raw_response"`
-	Timestamp           time.Time  `json:"timestamp"`
-	AIRequestDurationMs int64      `json:"ai_request_duration_ms,omitempty"`
+	SystemPrompt        string      `json:"system_prompt"`
+	UserPrompt          string      `json:"user_prompt"`
+	CoTTrace            string      `json:"cot_trace"` // Chain of thought
+	Decisions           []Decision  `json:"decisions"`
+	RawResponse         string      `json:"raw_response"`
+	Timestamp           time.Time   `json:"timestamp"`
+	AIRequestDurationMs int64       `json:"ai_request_duration_ms,omitempty"`
+	ParsePath           string      `json:"parse_path,omitempty"` // schema, lenient or fallback
+	Prompts             []PromptRef `json:"prompts,omitempty"`    // Template versions that rendered the prompts
+	Lessons             []int64     `json:"lessons,omitempty"`    // IDs of the reflection lessons in the prompt
 }
 
 // PositionInfo represents current trading position
@@ -107,6 +110,21 @@ type RecentOrder struct {
 	HoldDuration string  `json:"hold_duration"` // Hold duration, e.g. "2h30m"
 }
 
+// Lesson is what the trader's closed trades taught about one symbol,
+// direction and setup
+type Lesson struct {
+	ID         int64   `json:"id"`
+	Symbol     string  `json:"symbol"`      // Trading pair
+	Side       string  `json:"side"`        // long/short
+	Setup      string  `json:"setup"`       // Entry setup, e.g. with_trend
+	Trades     int     `json:"trades"`      // Closed trades behind the lesson
+	WinRate    float64 `json:"win_rate"`    // Win rate (%)
+	AvgPnLPct  float64 `json:"avg_pnl_pct"` // Average price move captured (%)
+	StopOuts   int     `json:"stop_outs"`   // Trades closed by the stop loss
+	LossStreak int     `json:"loss_streak"` // Most recent trades lost in a row
+	Summary    string  `json:"summary"`     // The lesson itself
+}
+
 // MarketData represents market data for a symbol
 type MarketData struct {
 	Symbol        string    `json:"symbol"`
@@ -144,6 +162,7 @@ type Context struct {
 	PromptVariant   string                   `json:"prompt_variant,omitempty"`
 	TradingStats    *TradingStats            `json:"trading_stats,omitempty"`
 	RecentOrders    []RecentOrder            `json:"recent_orders,omitempty"`
+	Lessons         []Lesson                 `json:"lessons,omitempty"` // Bounded digest of reflection lessons
 	MarketDataMap   map[string]*MarketData   `json:"-"`
 	MultiTFMarket   map[string]map[string]*MarketData `json:"-"` // symbol -> timeframe -> data
 	BTCETHLeverage  int                      `json:"-"`
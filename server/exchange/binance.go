@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -27,20 +28,40 @@ type BinanceClient struct {
 	secretKey        string
 	baseURL          string
 	httpClient       *http.Client
-	serverTimeOffset int64 // Offset between local time and Binance server time (in ms)
+	serverTimeOffset int64        // Offset between local time and Binance server time (in ms)
+	limiter          *RateLimiter // Shared per-host request weight limiter
+	hedgeMode        atomic.Bool  // Account uses dual-side positions (orders need positionSide)
 
 	// Symbol precision cache (fetched from exchange)
 	symbolInfo map[string]*SymbolInfo
 }
 
-// SymbolInfo holds precision info for a trading symbol
+// SymbolInfo holds precision and order filter info for a trading symbol
 type SymbolInfo struct {
 	Symbol            string
 	QuantityPrecision int
 	PricePrecision    int
-	MinQty            float64
-	StepSize          float64
 	Status            string
+
+	// PRICE_FILTER
+	MinPrice float64
+	MaxPrice float64
+	TickSize float64
+
+	// LOT_SIZE (limit orders) and MARKET_LOT_SIZE (market orders)
+	MinQty         float64
+	MaxQty         float64
+	StepSize       float64
+	MarketMinQty   float64
+	MarketMaxQty   float64
+	MarketStepSize float64
+
+	// MIN_NOTIONAL (quantity * price, USDT)
+	MinNotional float64
+
+	// PERCENT_PRICE (limit price must be within mark * [down, up])
+	MultiplierUp   float64
+	MultiplierDown float64
 }
 
 type AccountInfo struct {
@@ -81,6 +102,15 @@ type Ticker struct {
 	Time   int64   `json:"time"`
 }
 
+// BookTicker is the best bid/ask on the order book
+type BookTicker struct {
+	Symbol   string  `json:"symbol"`
+	BidPrice float64 `json:"bidPrice,string"`
+	BidQty   float64 `json:"bidQty,string"`
+	AskPrice float64 `json:"askPrice,string"`
+	AskQty   float64 `json:"askQty,string"`
+}
+
 type Kline struct {
 	OpenTime  int64
 	Open      float64
@@ -105,6 +135,7 @@ func NewBinanceClient(apiKey, secretKey string, testnet bool) *BinanceClient {
 			Timeout: 30 * time.Second,
 		},
 		serverTimeOffset: 0,
+		limiter:          GetRateLimiter(baseURL),
 		symbolInfo:       make(map[string]*SymbolInfo),
 	}
 
@@ -134,9 +165,14 @@ func (c *BinanceClient) startPeriodicTimeSync() {
 	log.Printf("[Binance] Periodic time sync started (every 15 minutes)")
 }
 
-// fetchExchangeInfo fetches symbol precision info from Binance
+// fetchExchangeInfo fetches symbol precision and filter info from Binance
 func (c *BinanceClient) fetchExchangeInfo() {
-	resp, err := c.httpClient.Get(c.baseURL + "/fapi/v1/exchangeInfo")
+	req, err := http.NewRequest("GET", c.baseURL+"/fapi/v1/exchangeInfo", nil)
+	if err != nil {
+		log.Printf("[Binance] Failed to fetch exchange info: %v", err)
+		return
+	}
+	resp, err := c.send(req, 1, PriorityMarketData, false)
 	if err != nil {
 		log.Printf("[Binance] Failed to fetch exchange info: %v", err)
 		return
@@ -153,13 +189,9 @@ func (c *BinanceClient) fetchExchangeInfo() {
 		Symbols []struct {
 			Symbol            string `json:"symbol"`
 			Status            string `json:"status"`
-			QuantityPrecision int    `json:"quantityPrecision"`
-			PricePrecision    int    `json:"pricePrecision"`
-			Filters           []struct {
-				FilterType string `json:"filterType"`
-				MinQty     string `json:"minQty"`
-				StepSize   string `json:"stepSize"`
-			} `json:"filters"`
+			QuantityPrecision int              `json:"quantityPrecision"`
+			PricePrecision    int              `json:"pricePrecision"`
+			Filters           []exchangeFilter `json:"filters"`
 		} `json:"symbols"`
 	}
 
@@ -176,14 +208,8 @@ func (c *BinanceClient) fetchExchangeInfo() {
 			PricePrecision:    s.PricePrecision,
 		}
 
-		// Extract LOT_SIZE filter for min qty and step size
-		for _, f := range s.Filters {
-			if f.FilterType == "LOT_SIZE" {
-				info.MinQty = parseFloat(f.MinQty)
-				info.StepSize = parseFloat(f.StepSize)
-				break
-			}
-		}
+		// Extract order filters (tick/step size, qty limits, min notional, percent price)
+		info.applyFilters(s.Filters)
 
 		c.symbolInfo[s.Symbol] = info
 	}
@@ -195,7 +221,12 @@ func (c *BinanceClient) fetchExchangeInfo() {
 func (c *BinanceClient) syncServerTime() {
 	localTime := time.Now().UnixMilli()
 
-	resp, err := c.httpClient.Get(c.baseURL + "/fapi/v1/time")
+	req, err := http.NewRequest("GET", c.baseURL+"/fapi/v1/time", nil)
+	if err != nil {
+		log.Printf("[Binance] Failed to sync server time: %v", err)
+		return
+	}
+	resp, err := c.send(req, 1, PriorityAccount, false)
 	if err != nil {
 		log.Printf("[Binance] Failed to sync server time: %v", err)
 		return
@@ -227,6 +258,26 @@ func (c *BinanceClient) sign(params url.Values) string {
 	return signature
 }
 
+// send executes a request through the shared rate limiter and feeds the
+// response headers back into it
+func (c *BinanceClient) send(req *http.Request, weight int, priority RequestPriority, isOrder bool) (*http.Response, error) {
+	if err := c.limiter.Acquire(req.Context(), priority, weight, c.apiKey, isOrder); err != nil {
+		return nil, fmt.Errorf("rate limiter: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	c.limiter.Update(c.apiKey, resp)
+	return resp, nil
+}
+
+// RateLimiterStats returns the state of the limiter this client uses
+func (c *BinanceClient) RateLimiterStats() RateLimiterStats {
+	return c.limiter.Stats()
+}
+
 func (c *BinanceClient) doRequest(ctx context.Context, method, endpoint string, params url.Values, signed bool) ([]byte, error) {
 	var reqURL string
 	var body io.Reader
@@ -256,7 +307,8 @@ func (c *BinanceClient) doRequest(ctx context.Context, method, endpoint string,
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	}
 
-	resp, err := c.httpClient.Do(req)
+	priority, isOrder := requestPriority(method, endpoint)
+	resp, err := c.send(req, requestWeight(method, endpoint, params), priority, isOrder)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -538,30 +590,53 @@ func (c *BinanceClient) roundToStepSize(symbol string, quantity float64) float64
 
 // PlaceOrder places a new order
 func (c *BinanceClient) PlaceOrder(ctx context.Context, symbol, side, orderType string, quantity float64, price float64, reduceOnly bool) (*Order, error) {
+	timeInForce := ""
+	if orderType == "LIMIT" {
+		timeInForce = "GTC"
+	}
+	return c.placeOrder(ctx, symbol, side, orderType, quantity, price, reduceOnly, timeInForce)
+}
+
+// PlaceLimitOrder places an opening LIMIT order with the given time in force:
+// GTC, IOC, FOK or GTX (post-only - expires instead of taking liquidity)
+func (c *BinanceClient) PlaceLimitOrder(ctx context.Context, symbol, side string, quantity, price float64, timeInForce string) (*Order, error) {
+	return c.placeOrder(ctx, symbol, side, "LIMIT", quantity, price, false, timeInForce)
+}
+
+func (c *BinanceClient) placeOrder(ctx context.Context, symbol, side, orderType string, quantity float64, price float64, reduceOnly bool, timeInForce string) (*Order, error) {
 	params := url.Values{}
 	params.Set("symbol", symbol)
 	params.Set("side", side)      // BUY or SELL
 	params.Set("type", orderType) // MARKET or LIMIT
 
-	// Round quantity to step size for proper precision
-	quantity = c.roundToStepSize(symbol, quantity)
+	// Validate against symbol filters (rounds quantity to step size, price to tick size)
+	quantity, price, err := c.ValidateOrder(symbol, orderType, quantity, price, 0, reduceOnly)
+	if err != nil {
+		log.Printf("[Binance] Order rejected locally: %v", err)
+		return nil, err
+	}
 
 	// Use proper precision for the symbol
 	qtyPrecision := c.getQuantityPrecision(symbol)
 	qtyStr := strconv.FormatFloat(quantity, 'f', qtyPrecision, 64)
 	params.Set("quantity", qtyStr)
 
+	priceStr := "MARKET"
 	if orderType == "LIMIT" {
 		pricePrecision := c.getPricePrecision(symbol)
-		params.Set("price", strconv.FormatFloat(price, 'f', pricePrecision, 64))
-		params.Set("timeInForce", "GTC")
+		priceStr = strconv.FormatFloat(price, 'f', pricePrecision, 64)
+		params.Set("price", priceStr)
+		params.Set("timeInForce", timeInForce)
 	}
 
-	if reduceOnly {
+	// Hedge mode: positionSide identifies the leg, reduceOnly is not accepted
+	if positionSide := c.positionSideFor(side, reduceOnly); positionSide != "" {
+		params.Set("positionSide", positionSide)
+	} else if reduceOnly {
 		params.Set("reduceOnly", "true")
 	}
 
-	log.Printf("[Binance] Placing %s %s order: %s %s @ %s (reduceOnly=%v)", orderType, side, symbol, qtyStr, "MARKET", reduceOnly)
+	log.Printf("[Binance] Placing %s %s order: %s %s @ %s (reduceOnly=%v)", orderType, side, symbol, qtyStr, priceStr, reduceOnly)
 
 	body, err := c.doRequest(ctx, "POST", "/fapi/v1/order", params, true)
 	if err != nil {
@@ -593,6 +668,58 @@ func (c *BinanceClient) ClosePosition(ctx context.Context, symbol string, positi
 	return c.PlaceOrder(ctx, symbol, side, "MARKET", quantity, 0, true)
 }
 
+// GetPositionMode returns true if the account is in hedge (dual-side) mode
+func (c *BinanceClient) GetPositionMode(ctx context.Context) (bool, error) {
+	body, err := c.doRequest(ctx, "GET", "/fapi/v1/positionSide/dual", url.Values{}, true)
+	if err != nil {
+		return false, err
+	}
+
+	var result struct {
+		DualSidePosition bool `json:"dualSidePosition"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return false, fmt.Errorf("failed to parse position mode: %w", err)
+	}
+	return result.DualSidePosition, nil
+}
+
+// SetPositionMode switches the account between hedge (dual-side) and one-way mode.
+// Binance rejects the change while any position or open order exists.
+func (c *BinanceClient) SetPositionMode(ctx context.Context, dualSide bool) error {
+	params := url.Values{}
+	params.Set("dualSidePosition", strconv.FormatBool(dualSide))
+
+	_, err := c.doRequest(ctx, "POST", "/fapi/v1/positionSide/dual", params, true)
+	if err != nil && strings.Contains(err.Error(), "-4059") {
+		return nil // "No need to change position side"
+	}
+	return err
+}
+
+// SetHedgeMode tells the client which position mode the account uses.
+// In hedge mode every order carries positionSide and reduceOnly is omitted.
+func (c *BinanceClient) SetHedgeMode(enabled bool) {
+	c.hedgeMode.Store(enabled)
+}
+
+// IsHedgeMode returns true if orders are placed with positionSide
+func (c *BinanceClient) IsHedgeMode() bool {
+	return c.hedgeMode.Load()
+}
+
+// positionSideFor returns the positionSide for an order in hedge mode ("" in one-way mode).
+// Opening orders: BUY -> LONG, SELL -> SHORT. Closing orders: SELL -> LONG, BUY -> SHORT.
+func (c *BinanceClient) positionSideFor(side string, closing bool) string {
+	if !c.IsHedgeMode() {
+		return ""
+	}
+	if (side == "BUY") != closing {
+		return "LONG"
+	}
+	return "SHORT"
+}
+
 // CancelAllOrders cancels all open orders for a symbol
 func (c *BinanceClient) CancelAllOrders(ctx context.Context, symbol string) error {
 	params := url.Values{}
@@ -613,6 +740,15 @@ func (c *BinanceClient) PlaceStopLoss(ctx context.Context, symbol, side string,
 	params.Set("type", "STOP_MARKET")
 	params.Set("algoType", "CONDITIONAL")
 	params.Set("closePosition", "true") // Close entire position when triggered
+	if positionSide := c.positionSideFor(side, true); positionSide != "" {
+		params.Set("positionSide", positionSide)
+	}
+
+	// Round trigger price to tick size and check PRICE_FILTER bounds
+	stopPrice, err := c.ValidateTriggerPrice(symbol, stopPrice)
+	if err != nil {
+		return nil, err
+	}
 
 	// Set trigger price with proper precision (renamed from stopPrice for algo orders)
 	pricePrecision := c.getPricePrecision(symbol)
@@ -663,6 +799,15 @@ func (c *BinanceClient) PlaceTakeProfit(ctx context.Context, symbol, side string
 	params.Set("type", "TAKE_PROFIT_MARKET")
 	params.Set("algoType", "CONDITIONAL")
 	params.Set("closePosition", "true") // Close entire position when triggered
+	if positionSide := c.positionSideFor(side, true); positionSide != "" {
+		params.Set("positionSide", positionSide)
+	}
+
+	// Round trigger price to tick size and check PRICE_FILTER bounds
+	stopPrice, err := c.ValidateTriggerPrice(symbol, stopPrice)
+	if err != nil {
+		return nil, err
+	}
 
 	// Set trigger price with proper precision (renamed from stopPrice for algo orders)
 	pricePrecision := c.getPricePrecision(symbol)
@@ -773,6 +918,69 @@ func (c *BinanceClient) CancelAlgoOrder(ctx context.Context, symbol string, algo
 	return nil
 }
 
+// AlgoOrder is an open conditional order placed via /fapi/v1/algoOrder
+type AlgoOrder struct {
+	AlgoID       int64  `json:"algoId"`
+	Symbol       string `json:"symbol"`
+	Side         string `json:"side"`
+	PositionSide string `json:"positionSide"`
+	OrderType    string `json:"orderType"` // e.g. STOP_MARKET, TAKE_PROFIT_MARKET
+	AlgoStatus   string `json:"algoStatus"`
+}
+
+// GetOpenAlgoOrders returns the open algo orders (SL/TP) for a symbol. They
+// are not listed by GetOpenOrders.
+func (c *BinanceClient) GetOpenAlgoOrders(ctx context.Context, symbol string) ([]AlgoOrder, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+
+	body, err := c.doRequest(ctx, "GET", "/fapi/v1/openAlgoOrders", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []AlgoOrder
+	if err := json.Unmarshal(body, &orders); err != nil {
+		return nil, fmt.Errorf("failed to parse algo orders: %w", err)
+	}
+	return orders, nil
+}
+
+// GetOrder returns the current state of an order
+func (c *BinanceClient) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("orderId", strconv.FormatInt(orderID, 10))
+
+	body, err := c.doRequest(ctx, "GET", "/fapi/v1/order", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var order Order
+	if err := json.Unmarshal(body, &order); err != nil {
+		return nil, fmt.Errorf("failed to parse order: %w", err)
+	}
+	return &order, nil
+}
+
+// GetBookTicker returns the best bid/ask for a symbol
+func (c *BinanceClient) GetBookTicker(ctx context.Context, symbol string) (*BookTicker, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+
+	body, err := c.doRequest(ctx, "GET", "/fapi/v1/ticker/bookTicker", params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var book BookTicker
+	if err := json.Unmarshal(body, &book); err != nil {
+		return nil, fmt.Errorf("failed to parse book ticker: %w", err)
+	}
+	return &book, nil
+}
+
 // GetOpenOrders returns all open orders for a symbol
 func (c *BinanceClient) GetOpenOrders(ctx context.Context, symbol string) ([]Order, error) {
 	params := url.Values{}
@@ -47,11 +47,19 @@ func (c *BinanceClient) doSAPIRequest(ctx context.Context, method, endpoint stri
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	}
 
+	// SAPI has its own weight budget, tracked by a separate limiter
+	limiter := GetRateLimiter(baseURL)
+	priority, isOrder := requestPriority(method, endpoint)
+	if err := limiter.Acquire(ctx, priority, 1, c.apiKey, isOrder); err != nil {
+		return nil, fmt.Errorf("rate limiter: %w", err)
+	}
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	limiter.Update(c.apiKey, resp)
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
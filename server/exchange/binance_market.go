@@ -27,7 +27,7 @@ func (c *BinanceClient) Get24hTicker(ctx context.Context) ([]Ticker24h, error) {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(req, 40, PriorityMarketData, false)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -52,7 +52,7 @@ func (c *BinanceClient) GetTickerStats(ctx context.Context, symbol string) (*Tic
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(req, 1, PriorityMarketData, false)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -346,3 +346,98 @@ func TestQuantityPrecision(t *testing.T) {
 		})
 	}
 }
+
+// TestPositionSideFor tests positionSide selection for hedge-mode orders
+func TestPositionSideFor(t *testing.T) {
+	c := &BinanceClient{}
+	if got := c.positionSideFor("BUY", false); got != "" {
+		t.Errorf("one-way mode should not set positionSide, got %q", got)
+	}
+
+	c.SetHedgeMode(true)
+	tests := []struct {
+		side    string
+		closing bool
+		want    string
+	}{
+		{"BUY", false, "LONG"},
+		{"SELL", false, "SHORT"},
+		{"SELL", true, "LONG"},
+		{"BUY", true, "SHORT"},
+	}
+	for _, tt := range tests {
+		if got := c.positionSideFor(tt.side, tt.closing); got != tt.want {
+			t.Errorf("positionSideFor(%s, closing=%v) = %q, want %q", tt.side, tt.closing, got, tt.want)
+		}
+	}
+}
+
+// TestSymbolFilterValidation tests order validation against exchange filters
+func TestSymbolFilterValidation(t *testing.T) {
+	info := &SymbolInfo{Symbol: "SOLUSDT"}
+	info.applyFilters([]exchangeFilter{
+		{FilterType: "PRICE_FILTER", MinPrice: "0.4200", MaxPrice: "6857", TickSize: "0.0100"},
+		{FilterType: "LOT_SIZE", MinQty: "1", MaxQty: "1000000", StepSize: "1"},
+		{FilterType: "MARKET_LOT_SIZE", MinQty: "1", MaxQty: "5000", StepSize: "1"},
+		{FilterType: "MIN_NOTIONAL", Notional: "5"},
+		{FilterType: "PERCENT_PRICE", MultiplierUp: "1.0500", MultiplierDown: "0.9500"},
+	})
+
+	tests := []struct {
+		name       string
+		orderType  string
+		quantity   float64
+		price      float64
+		markPrice  float64
+		reduceOnly bool
+		wantQty    float64
+		wantPrice  float64
+		wantFilter string
+	}{
+		{"market rounds down to step", "MARKET", 12.9, 150, 0, false, 12, 0, ""},
+		{"market capped at MARKET_LOT_SIZE max", "MARKET", 8000, 150, 0, false, 5000, 0, ""},
+		{"reduce-only above max rejected", "MARKET", 8000, 0, 0, true, 0, 0, "MARKET_LOT_SIZE"},
+		{"below min qty", "MARKET", 0.5, 150, 0, false, 0, 0, "MARKET_LOT_SIZE"},
+		{"limit price rounded to tick", "LIMIT", 2, 150.126, 150, false, 2, 150.13, ""},
+		{"limit outside percent price", "LIMIT", 2, 160, 150, false, 0, 0, "PERCENT_PRICE"},
+		{"limit below min price", "LIMIT", 10, 0.3, 0, false, 0, 0, "PRICE_FILTER"},
+		{"notional too small", "LIMIT", 1, 4.5, 0, false, 0, 0, "MIN_NOTIONAL"},
+		{"reduce-only exempt from notional", "LIMIT", 1, 4.5, 0, true, 1, 4.5, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qty, price, err := info.ValidateOrder(tt.orderType, tt.quantity, tt.price, tt.markPrice, tt.reduceOnly)
+			if tt.wantFilter != "" {
+				filterErr, ok := err.(*FilterError)
+				if !ok || filterErr.Filter != tt.wantFilter {
+					t.Fatalf("expected %s rejection, got %v", tt.wantFilter, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if qty != tt.wantQty || (tt.orderType == "LIMIT" && price != tt.wantPrice) {
+				t.Errorf("got qty=%v price=%v, want qty=%v price=%v", qty, price, tt.wantQty, tt.wantPrice)
+			}
+		})
+	}
+}
+
+// TestFloorToStep tests step rounding without float drift
+func TestFloorToStep(t *testing.T) {
+	tests := []struct {
+		v, step, want float64
+	}{
+		{0.3, 0.1, 0.3},
+		{0.0019, 0.001, 0.001},
+		{1.23456, 0.01, 1.23},
+		{17, 5, 15},
+	}
+	for _, tt := range tests {
+		if got := floorToStep(tt.v, tt.step); got != tt.want {
+			t.Errorf("floorToStep(%v, %v) = %v, want %v", tt.v, tt.step, got, tt.want)
+		}
+	}
+}
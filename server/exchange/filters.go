@@ -0,0 +1,174 @@
+package exchange
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// FilterError describes why an order violates a Binance symbol filter.
+// Returned before submission so the reason can be logged with the decision.
+type FilterError struct {
+	Symbol string
+	Filter string // PRICE_FILTER, LOT_SIZE, MARKET_LOT_SIZE, MIN_NOTIONAL, PERCENT_PRICE
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Symbol, e.Filter, e.Reason)
+}
+
+// exchangeFilter is a raw filter entry from /fapi/v1/exchangeInfo
+type exchangeFilter struct {
+	FilterType     string `json:"filterType"`
+	MinPrice       string `json:"minPrice"`
+	MaxPrice       string `json:"maxPrice"`
+	TickSize       string `json:"tickSize"`
+	MinQty         string `json:"minQty"`
+	MaxQty         string `json:"maxQty"`
+	StepSize       string `json:"stepSize"`
+	Notional       string `json:"notional"`
+	MultiplierUp   string `json:"multiplierUp"`
+	MultiplierDown string `json:"multiplierDown"`
+}
+
+// applyFilters copies the filters Binance enforces on order submission into info
+func (info *SymbolInfo) applyFilters(filters []exchangeFilter) {
+	for _, f := range filters {
+		switch f.FilterType {
+		case "PRICE_FILTER":
+			info.MinPrice = parseFloat(f.MinPrice)
+			info.MaxPrice = parseFloat(f.MaxPrice)
+			info.TickSize = parseFloat(f.TickSize)
+		case "LOT_SIZE":
+			info.MinQty = parseFloat(f.MinQty)
+			info.MaxQty = parseFloat(f.MaxQty)
+			info.StepSize = parseFloat(f.StepSize)
+		case "MARKET_LOT_SIZE":
+			info.MarketMinQty = parseFloat(f.MinQty)
+			info.MarketMaxQty = parseFloat(f.MaxQty)
+			info.MarketStepSize = parseFloat(f.StepSize)
+		case "MIN_NOTIONAL":
+			info.MinNotional = parseFloat(f.Notional)
+		case "PERCENT_PRICE":
+			info.MultiplierUp = parseFloat(f.MultiplierUp)
+			info.MultiplierDown = parseFloat(f.MultiplierDown)
+		}
+	}
+}
+
+// ValidateOrder checks an order against the symbol's filters and returns the
+// adjusted quantity and price. Quantity is rounded down to the step size and
+// opening orders above the max quantity are capped; price is rounded to the
+// tick size. For MARKET orders price is only a reference for the notional
+// check (0 skips it). markPrice enables the PERCENT_PRICE check for LIMIT
+// orders (0 skips it). Reduce-only orders are exempt from MIN_NOTIONAL.
+func (info *SymbolInfo) ValidateOrder(orderType string, quantity, price, markPrice float64, reduceOnly bool) (float64, float64, error) {
+	minQty, maxQty, stepSize, lotFilter := info.MinQty, info.MaxQty, info.StepSize, "LOT_SIZE"
+	if orderType == "MARKET" && info.MarketStepSize > 0 {
+		minQty, maxQty, stepSize, lotFilter = info.MarketMinQty, info.MarketMaxQty, info.MarketStepSize, "MARKET_LOT_SIZE"
+	}
+
+	// Quantity: step size, then min/max
+	quantity = floorToStep(quantity, stepSize)
+	if maxQty > 0 && quantity > maxQty {
+		if reduceOnly {
+			return 0, 0, info.reject(lotFilter, "quantity %s above maximum %s", formatNum(quantity), formatNum(maxQty))
+		}
+		quantity = floorToStep(maxQty, stepSize)
+	}
+	if quantity <= 0 || quantity < minQty {
+		return 0, 0, info.reject(lotFilter, "quantity %s below minimum %s", formatNum(quantity), formatNum(minQty))
+	}
+
+	if orderType != "MARKET" && price > 0 {
+		var err error
+		if price, err = info.ValidatePrice(price); err != nil {
+			return 0, 0, err
+		}
+
+		if markPrice > 0 && info.MultiplierUp > 0 {
+			upper, lower := markPrice*info.MultiplierUp, markPrice*info.MultiplierDown
+			if price > upper || price < lower {
+				return 0, 0, info.reject("PERCENT_PRICE", "price %s outside allowed range %s - %s (mark %s)",
+					formatNum(price), formatNum(lower), formatNum(upper), formatNum(markPrice))
+			}
+		}
+	}
+
+	if !reduceOnly && price > 0 && info.MinNotional > 0 && quantity*price < info.MinNotional {
+		return 0, 0, info.reject("MIN_NOTIONAL", "notional $%.2f below minimum $%.2f", quantity*price, info.MinNotional)
+	}
+
+	return quantity, price, nil
+}
+
+// ValidatePrice rounds a limit or trigger price to the tick size and checks the price bounds
+func (info *SymbolInfo) ValidatePrice(price float64) (float64, error) {
+	if info.TickSize > 0 {
+		price = roundToStep(price, info.TickSize)
+	}
+	if price <= 0 || (info.MinPrice > 0 && price < info.MinPrice) {
+		return 0, info.reject("PRICE_FILTER", "price %s below minimum %s", formatNum(price), formatNum(info.MinPrice))
+	}
+	if info.MaxPrice > 0 && price > info.MaxPrice {
+		return 0, info.reject("PRICE_FILTER", "price %s above maximum %s", formatNum(price), formatNum(info.MaxPrice))
+	}
+	return price, nil
+}
+
+func (info *SymbolInfo) reject(filter, format string, args ...any) *FilterError {
+	return &FilterError{Symbol: info.Symbol, Filter: filter, Reason: fmt.Sprintf(format, args...)}
+}
+
+// GetSymbolInfo returns the cached exchange filters for a symbol
+func (c *BinanceClient) GetSymbolInfo(symbol string) (*SymbolInfo, bool) {
+	info, ok := c.symbolInfo[symbol]
+	return info, ok
+}
+
+// ValidateOrder checks an order against the symbol's filters (see SymbolInfo.ValidateOrder).
+// Without cached exchange info the quantity is only rounded to the known precision.
+func (c *BinanceClient) ValidateOrder(symbol, orderType string, quantity, price, markPrice float64, reduceOnly bool) (float64, float64, error) {
+	if info, ok := c.symbolInfo[symbol]; ok {
+		return info.ValidateOrder(orderType, quantity, price, markPrice, reduceOnly)
+	}
+	return c.roundToStepSize(symbol, quantity), price, nil
+}
+
+// ValidateTriggerPrice rounds an SL/TP trigger price to the tick size and checks the price bounds
+func (c *BinanceClient) ValidateTriggerPrice(symbol string, price float64) (float64, error) {
+	if info, ok := c.symbolInfo[symbol]; ok {
+		return info.ValidatePrice(price)
+	}
+	return price, nil
+}
+
+// floorToStep rounds v down to a multiple of step. A small epsilon absorbs
+// float error so that e.g. 0.3/0.1 doesn't floor to 2 steps.
+func floorToStep(v, step float64) float64 {
+	if step <= 0 {
+		return v
+	}
+	steps := math.Floor(v/step + 1e-9)
+	return roundDecimals(steps*step, step)
+}
+
+// roundToStep rounds v to the nearest multiple of step
+func roundToStep(v, step float64) float64 {
+	return roundDecimals(math.Round(v/step)*step, step)
+}
+
+// roundDecimals trims float noise to the number of decimals in step
+func roundDecimals(v, step float64) float64 {
+	decimals := 0
+	for s := step; s < 1 && decimals < 12; s *= 10 {
+		decimals++
+	}
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'f', decimals, 64), 64)
+	return rounded
+}
+
+func formatNum(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
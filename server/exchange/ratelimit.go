@@ -0,0 +1,490 @@
+package exchange
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Binance Futures default limits (see /fapi/v1/exchangeInfo rateLimits)
+const (
+	DefaultWeightLimit1m = 2400 // REQUEST_WEIGHT per IP per minute
+	DefaultOrderLimit10s = 300  // ORDERS per account per 10 seconds
+	DefaultOrderLimit1m  = 1200 // ORDERS per account per minute
+
+	// Market data may only use this share of the weight budget so orders
+	// always have headroom, even when many engines scan the market at once
+	marketDataWeightShare = 0.8
+
+	defaultRetryAfter429 = 60 * time.Second
+	defaultRetryAfter418 = 2 * time.Minute
+)
+
+// RequestPriority orders queued requests; lower values are served first
+type RequestPriority int
+
+const (
+	PriorityOrder      RequestPriority = iota // Order placement / cancellation
+	PriorityAccount                           // Account, positions, trade history
+	PriorityMarketData                        // Klines, tickers, exchange info
+)
+
+func (p RequestPriority) String() string {
+	switch p {
+	case PriorityOrder:
+		return "order"
+	case PriorityAccount:
+		return "account"
+	default:
+		return "market_data"
+	}
+}
+
+// RateLimiterStats is a snapshot of limiter state
+type RateLimiterStats struct {
+	Host           string         `json:"host"`
+	UsedWeight     int            `json:"used_weight"`
+	WeightLimit    int            `json:"weight_limit"`
+	OrderCount10s  map[string]int `json:"order_count_10s"` // key: masked API key
+	OrderCount1m   map[string]int `json:"order_count_1m"`
+	Queued         map[string]int `json:"queued"` // key: priority
+	BackoffUntil   time.Time      `json:"backoff_until,omitempty"`
+	TotalRequests  int64          `json:"total_requests"`
+	ThrottledWaits int64          `json:"throttled_waits"` // Requests that had to wait for budget
+	RateLimited429 int64          `json:"rate_limited_429"`
+	IPBanned418    int64          `json:"ip_banned_418"`
+}
+
+type orderCounter struct {
+	count10s  int
+	count1m   int
+	window10s time.Time
+	window1m  time.Time
+}
+
+type limiterWaiter struct {
+	priority  RequestPriority
+	weight    int
+	account   string
+	isOrder   bool
+	seq       int64
+	ready     chan struct{}
+	cancelled bool
+	index     int
+}
+
+// waiterQueue is a priority queue of waiters (priority, then FIFO)
+type waiterQueue []*limiterWaiter
+
+func (q waiterQueue) Len() int { return len(q) }
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority < q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+func (q *waiterQueue) Push(x interface{}) {
+	w := x.(*limiterWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+func (q *waiterQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return w
+}
+
+// RateLimiter enforces Binance request weight and order count limits.
+// One limiter exists per API host and is shared by every BinanceClient in the
+// process, since weight limits apply per IP. Usage is tracked locally and
+// corrected from the X-MBX-USED-WEIGHT-* / X-MBX-ORDER-COUNT-* headers.
+type RateLimiter struct {
+	host          string
+	weightLimit   int
+	orderLimit10s int
+	orderLimit1m  int
+
+	mu           sync.Mutex
+	usedWeight   int
+	weightWindow time.Time
+	orders       map[string]*orderCounter // key: API key
+	backoffUntil time.Time
+	queue        waiterQueue
+	seq          int64
+	signal       chan struct{}
+
+	totalRequests  int64
+	throttledWaits int64
+	rateLimited429 int64
+	ipBanned418    int64
+}
+
+var (
+	rateLimiters   = make(map[string]*RateLimiter)
+	rateLimitersMu sync.Mutex
+)
+
+// GetRateLimiter returns the shared limiter for an API base URL
+func GetRateLimiter(baseURL string) *RateLimiter {
+	host := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+
+	if l, ok := rateLimiters[host]; ok {
+		return l
+	}
+	l := newRateLimiter(host)
+	rateLimiters[host] = l
+	go l.run()
+	return l
+}
+
+// GetRateLimiterStats returns stats for every limiter in use
+func GetRateLimiterStats() []RateLimiterStats {
+	rateLimitersMu.Lock()
+	limiters := make([]*RateLimiter, 0, len(rateLimiters))
+	for _, l := range rateLimiters {
+		limiters = append(limiters, l)
+	}
+	rateLimitersMu.Unlock()
+
+	stats := make([]RateLimiterStats, 0, len(limiters))
+	for _, l := range limiters {
+		stats = append(stats, l.Stats())
+	}
+	return stats
+}
+
+func newRateLimiter(host string) *RateLimiter {
+	return &RateLimiter{
+		host:          host,
+		weightLimit:   DefaultWeightLimit1m,
+		orderLimit10s: DefaultOrderLimit10s,
+		orderLimit1m:  DefaultOrderLimit1m,
+		orders:        make(map[string]*orderCounter),
+		signal:        make(chan struct{}, 1),
+	}
+}
+
+// Acquire blocks until the request fits in the current budget.
+// Higher priority requests are always served first.
+func (l *RateLimiter) Acquire(ctx context.Context, priority RequestPriority, weight int, account string, isOrder bool) error {
+	l.mu.Lock()
+	l.seq++
+	w := &limiterWaiter{
+		priority: priority,
+		weight:   weight,
+		account:  account,
+		isOrder:  isOrder,
+		seq:      l.seq,
+		ready:    make(chan struct{}),
+	}
+	heap.Push(&l.queue, w)
+	l.grantLocked(time.Now())
+
+	select {
+	case <-w.ready:
+		l.mu.Unlock()
+		return nil
+	default:
+	}
+	l.throttledWaits++
+	l.mu.Unlock()
+
+	l.wake()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		w.cancelled = true
+		l.mu.Unlock()
+		l.wake()
+		return ctx.Err()
+	}
+}
+
+// Update corrects local usage from response headers and applies back-off
+// on 429 (rate limited) and 418 (IP banned) responses.
+func (l *RateLimiter) Update(account string, resp *http.Response) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer l.wake()
+
+	l.rollWindowsLocked(now)
+
+	if v := headerInt(resp.Header, "X-MBX-USED-WEIGHT-1M"); v >= 0 {
+		l.usedWeight = v
+	}
+	if account != "" {
+		oc := l.orderCounterLocked(account, now)
+		if v := headerInt(resp.Header, "X-MBX-ORDER-COUNT-10S"); v >= 0 {
+			oc.count10s = v
+		}
+		if v := headerInt(resp.Header, "X-MBX-ORDER-COUNT-1M"); v >= 0 {
+			oc.count1m = v
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusTeapot:
+		retryAfter := time.Duration(headerInt(resp.Header, "Retry-After")) * time.Second
+		if retryAfter <= 0 {
+			retryAfter = defaultRetryAfter429
+			if resp.StatusCode == http.StatusTeapot {
+				retryAfter = defaultRetryAfter418
+			}
+		}
+		if resp.StatusCode == http.StatusTeapot {
+			l.ipBanned418++
+		} else {
+			l.rateLimited429++
+		}
+		if until := now.Add(retryAfter); until.After(l.backoffUntil) {
+			l.backoffUntil = until
+		}
+		log.Printf("[Binance] ⚠️ Rate limited by %s (status %d), backing off for %v", l.host, resp.StatusCode, retryAfter)
+	}
+}
+
+// Stats returns a snapshot of the limiter state
+func (l *RateLimiter) Stats() RateLimiterStats {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rollWindowsLocked(now)
+
+	stats := RateLimiterStats{
+		Host:           l.host,
+		UsedWeight:     l.usedWeight,
+		WeightLimit:    l.weightLimit,
+		OrderCount10s:  make(map[string]int),
+		OrderCount1m:   make(map[string]int),
+		Queued:         make(map[string]int),
+		TotalRequests:  l.totalRequests,
+		ThrottledWaits: l.throttledWaits,
+		RateLimited429: l.rateLimited429,
+		IPBanned418:    l.ipBanned418,
+	}
+	if now.Before(l.backoffUntil) {
+		stats.BackoffUntil = l.backoffUntil
+	}
+	for account := range l.orders {
+		oc := l.orderCounterLocked(account, now)
+		stats.OrderCount10s[maskAPIKey(account)] = oc.count10s
+		stats.OrderCount1m[maskAPIKey(account)] = oc.count1m
+	}
+	for _, w := range l.queue {
+		if !w.cancelled {
+			stats.Queued[w.priority.String()]++
+		}
+	}
+	return stats
+}
+
+// run grants queued requests as budget becomes available
+func (l *RateLimiter) run() {
+	for {
+		l.mu.Lock()
+		wait := l.grantLocked(time.Now())
+		l.mu.Unlock()
+
+		if wait <= 0 {
+			<-l.signal
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-l.signal:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// grantLocked releases waiters in priority order while the budget allows.
+// Returns how long to wait before retrying, or 0 if the queue is empty.
+func (l *RateLimiter) grantLocked(now time.Time) time.Duration {
+	l.rollWindowsLocked(now)
+
+	for l.queue.Len() > 0 {
+		w := l.queue[0]
+		if w.cancelled {
+			heap.Pop(&l.queue)
+			continue
+		}
+
+		if now.Before(l.backoffUntil) {
+			return l.backoffUntil.Sub(now)
+		}
+
+		limit := l.weightLimit
+		if w.priority != PriorityOrder {
+			limit = int(float64(limit) * marketDataWeightShare)
+		}
+		if l.usedWeight > 0 && l.usedWeight+w.weight > limit {
+			return l.weightWindow.Add(time.Minute).Sub(now)
+		}
+
+		if w.isOrder {
+			oc := l.orderCounterLocked(w.account, now)
+			if oc.count10s >= l.orderLimit10s {
+				return oc.window10s.Add(10 * time.Second).Sub(now)
+			}
+			if oc.count1m >= l.orderLimit1m {
+				return oc.window1m.Add(time.Minute).Sub(now)
+			}
+			oc.count10s++
+			oc.count1m++
+		}
+
+		heap.Pop(&l.queue)
+		l.usedWeight += w.weight
+		l.totalRequests++
+		close(w.ready)
+	}
+	return 0
+}
+
+// rollWindowsLocked resets the weight counter when a new minute starts
+// (Binance uses fixed calendar windows)
+func (l *RateLimiter) rollWindowsLocked(now time.Time) {
+	window := now.Truncate(time.Minute)
+	if !window.Equal(l.weightWindow) {
+		l.weightWindow = window
+		l.usedWeight = 0
+	}
+}
+
+func (l *RateLimiter) orderCounterLocked(account string, now time.Time) *orderCounter {
+	oc, ok := l.orders[account]
+	if !ok {
+		oc = &orderCounter{}
+		l.orders[account] = oc
+	}
+	if w := now.Truncate(10 * time.Second); !w.Equal(oc.window10s) {
+		oc.window10s = w
+		oc.count10s = 0
+	}
+	if w := now.Truncate(time.Minute); !w.Equal(oc.window1m) {
+		oc.window1m = w
+		oc.count1m = 0
+	}
+	return oc
+}
+
+func (l *RateLimiter) wake() {
+	select {
+	case l.signal <- struct{}{}:
+	default:
+	}
+}
+
+// headerInt parses an integer header, returning -1 if absent or invalid
+func headerInt(h http.Header, key string) int {
+	v := h.Get(key)
+	if v == "" {
+		return -1
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func maskAPIKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:4] + "..." + key[len(key)-4:]
+}
+
+// requestWeight returns the documented request weight of a Futures endpoint
+func requestWeight(method, endpoint string, params url.Values) int {
+	hasSymbol := params.Get("symbol") != ""
+
+	switch endpoint {
+	case "/fapi/v1/klines":
+		limit, _ := strconv.Atoi(params.Get("limit"))
+		switch {
+		case limit > 0 && limit < 100:
+			return 1
+		case limit > 0 && limit < 500:
+			return 2
+		case limit == 0 || limit <= 1000:
+			return 5
+		default:
+			return 10
+		}
+	case "/fapi/v1/ticker/24hr":
+		if hasSymbol {
+			return 1
+		}
+		return 40
+	case "/fapi/v1/ticker/price", "/fapi/v2/ticker/price":
+		if hasSymbol {
+			return 1
+		}
+		return 2
+	case "/fapi/v1/ticker/bookTicker":
+		if hasSymbol {
+			return 2
+		}
+		return 5
+	case "/fapi/v1/openOrders", "/fapi/v1/openAlgoOrders":
+		if hasSymbol {
+			return 1
+		}
+		return 40
+	case "/fapi/v2/account", "/fapi/v3/account", "/fapi/v2/positionRisk", "/fapi/v3/positionRisk", "/fapi/v1/userTrades":
+		return 5
+	case "/fapi/v1/income":
+		return 30
+	case "/fapi/v1/exchangeInfo":
+		return 1
+	}
+	return 1
+}
+
+// requestPriority classifies an endpoint. isOrder is true for requests that
+// count towards the per-account order limits.
+func requestPriority(method, endpoint string) (priority RequestPriority, isOrder bool) {
+	switch endpoint {
+	case "/fapi/v1/order", "/fapi/v1/algoOrder", "/fapi/v1/batchOrders":
+		return PriorityOrder, method == http.MethodPost
+	case "/fapi/v1/allOpenOrders", "/fapi/v1/leverage", "/fapi/v1/positionSide/dual", "/fapi/v1/marginType":
+		return PriorityOrder, false
+	}
+
+	if strings.Contains(endpoint, "account") || strings.Contains(endpoint, "position") ||
+		strings.Contains(endpoint, "Orders") || strings.Contains(endpoint, "userTrades") ||
+		strings.Contains(endpoint, "income") || strings.HasPrefix(endpoint, "/sapi/") {
+		return PriorityAccount, false
+	}
+	return PriorityMarketData, false
+}
@@ -0,0 +1,120 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRequestWeight(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		params   url.Values
+		want     int
+	}{
+		{"klines small", "/fapi/v1/klines", url.Values{"limit": {"50"}}, 1},
+		{"klines 100", "/fapi/v1/klines", url.Values{"limit": {"100"}}, 2},
+		{"klines default", "/fapi/v1/klines", url.Values{}, 5},
+		{"klines 1500", "/fapi/v1/klines", url.Values{"limit": {"1500"}}, 10},
+		{"24hr all symbols", "/fapi/v1/ticker/24hr", url.Values{}, 40},
+		{"24hr single symbol", "/fapi/v1/ticker/24hr", url.Values{"symbol": {"BTCUSDT"}}, 1},
+		{"account", "/fapi/v2/account", url.Values{}, 5},
+		{"income", "/fapi/v1/income", url.Values{}, 30},
+		{"order", "/fapi/v1/order", url.Values{}, 1},
+		{"open algo orders single symbol", "/fapi/v1/openAlgoOrders", url.Values{"symbol": {"BTCUSDT"}}, 1},
+		{"open algo orders all symbols", "/fapi/v1/openAlgoOrders", url.Values{}, 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestWeight("GET", tt.endpoint, tt.params); got != tt.want {
+				t.Errorf("requestWeight() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestPriority(t *testing.T) {
+	tests := []struct {
+		method      string
+		endpoint    string
+		wantPrio    RequestPriority
+		wantIsOrder bool
+	}{
+		{"POST", "/fapi/v1/order", PriorityOrder, true},
+		{"DELETE", "/fapi/v1/order", PriorityOrder, false},
+		{"POST", "/fapi/v1/algoOrder", PriorityOrder, true},
+		{"DELETE", "/fapi/v1/allOpenOrders", PriorityOrder, false},
+		{"GET", "/fapi/v2/positionRisk", PriorityAccount, false},
+		{"GET", "/fapi/v1/openOrders", PriorityAccount, false},
+		{"GET", "/fapi/v1/openAlgoOrders", PriorityAccount, false},
+		{"GET", "/fapi/v1/klines", PriorityMarketData, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.endpoint, func(t *testing.T) {
+			prio, isOrder := requestPriority(tt.method, tt.endpoint)
+			if prio != tt.wantPrio || isOrder != tt.wantIsOrder {
+				t.Errorf("requestPriority() = (%v, %v), want (%v, %v)", prio, isOrder, tt.wantPrio, tt.wantIsOrder)
+			}
+		})
+	}
+}
+
+func TestRateLimiterReservesHeadroomForOrders(t *testing.T) {
+	l := newRateLimiter("test")
+	now := time.Now()
+	l.rollWindowsLocked(now)
+	l.usedWeight = int(float64(l.weightLimit)*marketDataWeightShare) - 1
+
+	data := &limiterWaiter{priority: PriorityMarketData, weight: 5, ready: make(chan struct{})}
+	l.queue = waiterQueue{}
+	l.queue.Push(data)
+
+	if wait := l.grantLocked(now); wait <= 0 {
+		t.Fatalf("market data request should wait when above its weight share")
+	}
+
+	order := &limiterWaiter{priority: PriorityOrder, weight: 1, isOrder: true, account: "k", seq: 1, ready: make(chan struct{})}
+	l.queue = waiterQueue{}
+	l.queue.Push(order)
+
+	if wait := l.grantLocked(now); wait != 0 {
+		t.Fatalf("order should be granted from reserved headroom, wait = %v", wait)
+	}
+	select {
+	case <-order.ready:
+	default:
+		t.Errorf("order waiter not released")
+	}
+}
+
+func TestRateLimiterBacksOffOn429(t *testing.T) {
+	l := newRateLimiter("test")
+	go l.run()
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "30")
+	resp.Header.Set("X-MBX-USED-WEIGHT-1M", "1234")
+	l.Update("key", resp)
+
+	stats := l.Stats()
+	if stats.UsedWeight != 1234 {
+		t.Errorf("UsedWeight = %d, want 1234 from header", stats.UsedWeight)
+	}
+	if stats.RateLimited429 != 1 {
+		t.Errorf("RateLimited429 = %d, want 1", stats.RateLimited429)
+	}
+	if time.Until(stats.BackoffUntil) < 25*time.Second {
+		t.Errorf("BackoffUntil = %v, want ~30s from now", stats.BackoffUntil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.Acquire(ctx, PriorityOrder, 1, "key", true); err == nil {
+		t.Errorf("Acquire() should block during back-off")
+	}
+}
@@ -74,6 +74,10 @@ func main() {
 	log.Println("  - GET  /api/health                 - Health check")
 	log.Println("  - GET  /api/strategies             - List strategies")
 	log.Println("  - POST /api/strategies             - Create strategy")
+	log.Println("  - GET  /api/strategies/{id}/versions - Version history, diff and rollback")
+	log.Println("  - GET  /api/strategies/{id}/export - Export strategy as JSON or YAML")
+	log.Println("  - POST /api/strategies/import      - Import strategy from JSON or YAML")
+	log.Println("  - GET  /api/strategy-templates     - List built-in strategy templates")
 	log.Println("  - GET  /api/traders                - List traders")
 	log.Println("  - POST /api/traders                - Create trader")
 	log.Println("  - POST /api/traders/{id}/start     - Start trader")
@@ -81,10 +85,29 @@ func main() {
 	log.Println("  - GET  /api/status?trader_id=x     - Get trader status")
 	log.Println("  - GET  /api/positions?trader_id=x  - Get positions")
 	log.Println("  - GET  /api/decisions?trader_id=x  - Get decisions")
+	log.Println("  - GET  /api/decisions/audit?trader_id=x&symbol=&block_reason= - Per-symbol decision audits")
+	log.Println("  - GET  /api/decisions/audit/{id}     - Decision audit with prompts and raw output")
+	log.Println("  - GET  /api/decisions/audit/block-reasons?trader_id=x - Blocked decisions by guard")
+	log.Println("  - GET  /api/lessons?trader_id=x    - Lessons learned from closed trades")
+	log.Println("  - GET  /api/calibration?trader_id=x - AI confidence vs. trade outcome per model")
 	log.Println("  - GET  /api/backtest               - List backtests")
 	log.Println("  - POST /api/backtest/start         - Start backtest")
+	log.Println("  - POST /api/backtest/eval          - Compare two prompt variants/models on historical bars")
+	log.Println("  - GET  /api/backtest/eval/{id}     - Prompt evaluation report")
 	log.Println("  - GET  /api/debate/sessions        - List debates")
 	log.Println("  - POST /api/debate/sessions        - Create debate")
+	log.Println("  - GET  /api/debate/sessions/{id}/history - Debate decisions per cycle")
+	log.Println("  - GET  /api/debate/performance     - Participant hit rates and PnL")
+	log.Println("  - GET  /api/debate/personalities   - Built-in and custom personalities")
+	log.Println("  - GET  /api/ai/models?provider=x   - Models served by an AI provider (e.g. ollama)")
+	log.Println("  - GET  /api/ai/usage?group_by=x    - AI spend by trader, day, model or caller")
+	log.Println("  - GET  /api/ai/budgets             - Trader AI spend vs monthly budget")
+	log.Println("  - PUT  /api/ai/prices              - Set a model's price per million tokens")
+	log.Println("  - GET  /api/prompts                - Prompt templates and variants in use")
+	log.Println("  - POST /api/prompts                - Save a new prompt template version")
+	log.Println("  - POST /api/prompts/reload         - Reload prompt templates from disk and DB")
+	log.Println("  - GET  /api/market/stream          - Market stream status")
+	log.Println("  - GET  /api/exchange/rate-limits   - Binance rate limiter stats")
 	log.Println()
 	log.Println("Press Ctrl+C to stop")
 	fmt.Println()
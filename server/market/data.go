@@ -29,6 +29,10 @@ type MarketData struct {
 
 type DataProvider struct {
 	binance *exchange.BinanceClient
+
+	// Optional shared WebSocket cache; REST is used when nil or cold
+	stream      *Stream
+	streamOwner string
 }
 
 func NewDataProvider(binance *exchange.BinanceClient) *DataProvider {
@@ -37,6 +41,13 @@ func NewDataProvider(binance *exchange.BinanceClient) *DataProvider {
 	}
 }
 
+// SetStream attaches a shared market stream. Symbols requested through this
+// provider are watched on behalf of owner.
+func (d *DataProvider) SetStream(stream *Stream, owner string) {
+	d.stream = stream
+	d.streamOwner = owner
+}
+
 // GetMarketData fetches and analyzes market data for a symbol (default config)
 func (d *DataProvider) GetMarketData(ctx context.Context, symbol string) (*MarketData, error) {
 	return d.GetMarketDataWithConfig(ctx, symbol, "5m", 100)
@@ -44,20 +55,39 @@ func (d *DataProvider) GetMarketData(ctx context.Context, symbol string) (*Marke
 
 // GetMarketDataWithConfig fetches market data with custom timeframe and count
 func (d *DataProvider) GetMarketDataWithConfig(ctx context.Context, symbol, timeframe string, count int) (*MarketData, error) {
-	// Get klines
-	klines, err := d.binance.GetKlines(ctx, symbol, timeframe, count)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get klines: %w", err)
+	// Prefer streamed candles; fall back to REST when the cache is cold or the stream is down
+	var klines []exchange.Kline
+	fromStream := false
+	if d.stream != nil {
+		d.stream.Watch(d.streamOwner, symbol, timeframe)
+		klines, fromStream = d.stream.Klines(symbol, timeframe, count)
+	}
+
+	if !fromStream {
+		var err error
+		klines, err = d.binance.GetKlines(ctx, symbol, timeframe, count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get klines: %w", err)
+		}
+		if d.stream != nil {
+			d.stream.Seed(symbol, timeframe, klines)
+		}
 	}
 
 	if len(klines) < 26 {
 		return nil, fmt.Errorf("not enough kline data")
 	}
 
-	// Get current price
-	ticker, err := d.binance.GetTicker(ctx, symbol)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get ticker: %w", err)
+	// Get current price (the live candle's close when streaming)
+	var currentPrice float64
+	if fromStream {
+		currentPrice = klines[len(klines)-1].Close
+	} else {
+		ticker, err := d.binance.GetTicker(ctx, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ticker: %w", err)
+		}
+		currentPrice = ticker.Price
 	}
 
 	// Calculate indicators
@@ -100,7 +130,7 @@ func (d *DataProvider) GetMarketDataWithConfig(ctx context.Context, symbol, time
 
 	return &MarketData{
 		Symbol:         symbol,
-		CurrentPrice:   ticker.Price,
+		CurrentPrice:   currentPrice,
 		Klines:         klines,
 		EMA9:           ema9,
 		EMA21:          ema21,
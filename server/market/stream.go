@@ -0,0 +1,551 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"auto-trader-ahh/exchange"
+)
+
+const (
+	BinanceFuturesStreamURL = "wss://fstream.binance.com"
+	BinanceTestnetStreamURL = "wss://stream.binancefuture.com"
+)
+
+const (
+	streamReadTimeout   = 60 * time.Second // markPrice@1s guarantees traffic well inside this
+	streamDialTimeout   = 10 * time.Second
+	streamMaxBackoff    = 30 * time.Second
+	candleStaleAfter    = 30 * time.Second // Serve cached candles only if updated recently
+	markPriceStaleAfter = 5 * time.Second
+)
+
+// PriceUpdate is pushed to listeners on every mark price tick
+type PriceUpdate struct {
+	Symbol    string
+	MarkPrice float64
+	Time      time.Time
+}
+
+type candleSeries struct {
+	klines  []exchange.Kline
+	updated time.Time
+}
+
+type markPrice struct {
+	price   float64
+	updated time.Time
+}
+
+// Stream maintains a single Binance Futures WebSocket connection subscribed to
+// kline and markPrice streams for every symbol watched by any engine.
+// Candles and mark prices are cached in memory and shared between engines;
+// callers fall back to REST whenever the cache is cold or the stream is down.
+type Stream struct {
+	baseURL string
+
+	mu          sync.RWMutex
+	owners      map[string]map[string]bool // stream name -> owner IDs
+	subscribed  map[string]bool            // streams active on the current connection
+	candles     map[string]*candleSeries   // key: "SYMBOL|interval"
+	markPrices  map[string]markPrice
+	listeners   map[string]chan PriceUpdate // key: owner ID
+	conn        *wsConn
+	connected   bool
+	connectedAt time.Time
+	lastMessage time.Time
+	reconnects  int
+	requestID   int64
+
+	running bool
+	wakeCh  chan struct{}
+	stopCh  chan struct{}
+}
+
+// NewStream creates a market stream for mainnet or testnet
+func NewStream(testnet bool) *Stream {
+	baseURL := BinanceFuturesStreamURL
+	if testnet {
+		baseURL = BinanceTestnetStreamURL
+	}
+
+	return &Stream{
+		baseURL:    baseURL,
+		owners:     make(map[string]map[string]bool),
+		subscribed: make(map[string]bool),
+		candles:    make(map[string]*candleSeries),
+		markPrices: make(map[string]markPrice),
+		listeners:  make(map[string]chan PriceUpdate),
+		wakeCh:     make(chan struct{}, 1),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start launches the connection loop. It connects lazily once something is watched.
+func (s *Stream) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+	s.running = true
+	go s.run()
+}
+
+// Stop closes the connection and stops reconnecting
+func (s *Stream) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stopCh)
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// Watch subscribes the owner to the mark price stream of a symbol and to the
+// kline streams of the given intervals. Calling it repeatedly is cheap.
+func (s *Stream) Watch(owner, symbol string, intervals ...string) {
+	names := []string{markPriceStreamName(symbol)}
+	for _, interval := range intervals {
+		names = append(names, klineStreamName(symbol, interval))
+	}
+
+	var toSubscribe []string
+
+	s.mu.Lock()
+	added := false
+	for _, name := range names {
+		set, ok := s.owners[name]
+		if !ok {
+			set = make(map[string]bool)
+			s.owners[name] = set
+			added = true
+		}
+		set[owner] = true
+		if s.connected && !s.subscribed[name] {
+			s.subscribed[name] = true
+			toSubscribe = append(toSubscribe, name)
+		}
+	}
+	conn := s.conn
+	s.mu.Unlock()
+
+	if len(toSubscribe) > 0 && conn != nil {
+		s.sendSubscription(conn, "SUBSCRIBE", toSubscribe)
+	}
+	if added {
+		s.wake()
+	}
+}
+
+// Release drops every subscription held by the owner and closes its listener.
+// Streams no other owner needs are unsubscribed and their cache discarded.
+func (s *Stream) Release(owner string) {
+	var toUnsubscribe []string
+
+	s.mu.Lock()
+	for name, set := range s.owners {
+		if !set[owner] {
+			continue
+		}
+		delete(set, owner)
+		if len(set) > 0 {
+			continue
+		}
+		delete(s.owners, name)
+		if s.subscribed[name] {
+			delete(s.subscribed, name)
+			toUnsubscribe = append(toUnsubscribe, name)
+		}
+		if symbol, interval, ok := parseKlineStreamName(name); ok {
+			delete(s.candles, candleKey(symbol, interval))
+		}
+	}
+	if ch, ok := s.listeners[owner]; ok {
+		close(ch)
+		delete(s.listeners, owner)
+	}
+	conn := s.conn
+	s.mu.Unlock()
+
+	if len(toUnsubscribe) > 0 && conn != nil {
+		s.sendSubscription(conn, "UNSUBSCRIBE", toUnsubscribe)
+	}
+}
+
+// Listen returns a channel receiving mark price updates for all watched symbols.
+// Updates are dropped when the listener falls behind.
+func (s *Stream) Listen(owner string) <-chan PriceUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ch, ok := s.listeners[owner]; ok {
+		return ch
+	}
+	ch := make(chan PriceUpdate, 64)
+	s.listeners[owner] = ch
+	return ch
+}
+
+// Klines returns the last count cached candles if the stream is live and the
+// cache is warm. ok=false means the caller should fetch from REST.
+func (s *Stream) Klines(symbol, interval string, count int) ([]exchange.Kline, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.connected {
+		return nil, false
+	}
+	series, ok := s.candles[candleKey(symbol, interval)]
+	if !ok || len(series.klines) < count || time.Since(series.updated) > candleStaleAfter {
+		return nil, false
+	}
+
+	out := make([]exchange.Kline, count)
+	copy(out, series.klines[len(series.klines)-count:])
+	return out, true
+}
+
+// Seed stores REST candles so subsequent stream updates can extend them.
+// Candles for streams nobody watches are ignored.
+func (s *Stream) Seed(symbol, interval string, klines []exchange.Kline) {
+	if len(klines) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, watched := s.owners[klineStreamName(symbol, interval)]; !watched {
+		return
+	}
+
+	cached := make([]exchange.Kline, len(klines))
+	copy(cached, klines)
+	s.candles[candleKey(symbol, interval)] = &candleSeries{
+		klines:  cached,
+		updated: time.Now(),
+	}
+}
+
+// MarkPrice returns the latest streamed mark price if it is fresh
+func (s *Stream) MarkPrice(symbol string) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	mp, ok := s.markPrices[strings.ToUpper(symbol)]
+	if !ok || !s.connected || time.Since(mp.updated) > markPriceStaleAfter {
+		return 0, false
+	}
+	return mp.price, true
+}
+
+// IsConnected reports whether the WebSocket is currently up
+func (s *Stream) IsConnected() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.connected
+}
+
+// Stats returns connection and cache statistics
+func (s *Stream) Stats() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return map[string]interface{}{
+		"connected":     s.connected,
+		"connected_at":  s.connectedAt,
+		"last_message":  s.lastMessage,
+		"reconnects":    s.reconnects,
+		"streams":       len(s.owners),
+		"cached_series": len(s.candles),
+		"mark_prices":   len(s.markPrices),
+	}
+}
+
+// run keeps the connection alive, reconnecting with exponential backoff
+func (s *Stream) run() {
+	backoff := time.Second
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+
+		streams := s.streamNames()
+		if len(streams) == 0 {
+			select {
+			case <-s.stopCh:
+				return
+			case <-s.wakeCh:
+				continue
+			}
+		}
+
+		connectedAt := time.Now()
+		err := s.connectAndRead(streams)
+		s.markDisconnected()
+
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+
+		// A connection that stayed up for a while resets the backoff
+		if time.Since(connectedAt) > time.Minute {
+			backoff = time.Second
+		}
+		log.Printf("[MarketStream] Disconnected: %v (reconnecting in %v, using REST meanwhile)", err, backoff)
+
+		select {
+		case <-s.stopCh:
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > streamMaxBackoff {
+			backoff = streamMaxBackoff
+		}
+	}
+}
+
+// connectAndRead opens a combined stream connection and processes messages until it fails
+func (s *Stream) connectAndRead(streams []string) error {
+	url := s.baseURL + "/stream?streams=" + strings.Join(streams, "/")
+	conn, err := dialWebSocket(url, streamDialTimeout)
+	if err != nil {
+		return err
+	}
+
+	// Subscribe anything watched between building the URL and connecting
+	var missing []string
+	s.mu.Lock()
+	s.conn = conn
+	s.connected = true
+	s.connectedAt = time.Now()
+	s.lastMessage = time.Now()
+	s.subscribed = make(map[string]bool)
+	s.candles = make(map[string]*candleSeries) // Seeds taken while offline may have gaps
+	for _, name := range streams {
+		s.subscribed[name] = true
+	}
+	for name := range s.owners {
+		if !s.subscribed[name] {
+			s.subscribed[name] = true
+			missing = append(missing, name)
+		}
+	}
+	s.mu.Unlock()
+
+	log.Printf("[MarketStream] 📡 Connected to %s (%d streams)", s.baseURL, len(streams))
+
+	if len(missing) > 0 {
+		s.sendSubscription(conn, "SUBSCRIBE", missing)
+	}
+
+	for {
+		msg, err := conn.ReadMessage(time.Now().Add(streamReadTimeout))
+		if err != nil {
+			return err
+		}
+		s.handleMessage(msg)
+	}
+}
+
+// markDisconnected resets connection state. Candle caches are dropped because
+// they may now have gaps; the next REST fetch re-seeds them.
+func (s *Stream) markDisconnected() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.conn = nil
+	s.connected = false
+	s.subscribed = make(map[string]bool)
+	s.candles = make(map[string]*candleSeries)
+	s.reconnects++
+}
+
+func (s *Stream) sendSubscription(conn *wsConn, method string, streams []string) {
+	s.mu.Lock()
+	s.requestID++
+	id := s.requestID
+	s.mu.Unlock()
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"method": method,
+		"params": streams,
+		"id":     id,
+	})
+	if err := conn.WriteText(payload); err != nil {
+		log.Printf("[MarketStream] %s failed: %v", method, err)
+	}
+}
+
+func (s *Stream) wake() {
+	select {
+	case s.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Stream) streamNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.owners))
+	for name := range s.owners {
+		names = append(names, name)
+	}
+	return names
+}
+
+// streamEnvelope is the combined stream wrapper: {"stream": "...", "data": {...}}
+type streamEnvelope struct {
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type streamEvent struct {
+	EventType string `json:"e"`
+	EventTime int64  `json:"E"`
+	Symbol    string `json:"s"`
+	MarkPrice string `json:"p"`
+	Kline     *struct {
+		OpenTime  int64  `json:"t"`
+		CloseTime int64  `json:"T"`
+		Interval  string `json:"i"`
+		Open      string `json:"o"`
+		Close     string `json:"c"`
+		High      string `json:"h"`
+		Low       string `json:"l"`
+		Volume    string `json:"v"`
+	} `json:"k"`
+}
+
+func (s *Stream) handleMessage(msg []byte) {
+	var env streamEnvelope
+	if err := json.Unmarshal(msg, &env); err != nil || env.Stream == "" {
+		return // Subscription acks and unknown payloads
+	}
+
+	var evt streamEvent
+	if err := json.Unmarshal(env.Data, &evt); err != nil {
+		return
+	}
+
+	now := time.Now()
+
+	switch evt.EventType {
+	case "markPriceUpdate":
+		price, err := strconv.ParseFloat(evt.MarkPrice, 64)
+		if err != nil || price <= 0 {
+			return
+		}
+		update := PriceUpdate{Symbol: evt.Symbol, MarkPrice: price, Time: now}
+
+		s.mu.Lock()
+		s.lastMessage = now
+		s.markPrices[evt.Symbol] = markPrice{price: price, updated: now}
+		for _, ch := range s.listeners {
+			select {
+			case ch <- update:
+			default:
+			}
+		}
+		s.mu.Unlock()
+
+	case "kline":
+		if evt.Kline == nil {
+			return
+		}
+		k := exchange.Kline{
+			OpenTime:  evt.Kline.OpenTime,
+			Open:      parseStreamFloat(evt.Kline.Open),
+			High:      parseStreamFloat(evt.Kline.High),
+			Low:       parseStreamFloat(evt.Kline.Low),
+			Close:     parseStreamFloat(evt.Kline.Close),
+			Volume:    parseStreamFloat(evt.Kline.Volume),
+			CloseTime: evt.Kline.CloseTime,
+		}
+
+		s.mu.Lock()
+		s.lastMessage = now
+		key := candleKey(evt.Symbol, evt.Kline.Interval)
+		if series, ok := s.candles[key]; ok {
+			if applyKline(series, k, now) {
+				delete(s.candles, key) // Gap detected, re-seed from REST
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// applyKline merges a streamed candle into the series.
+// Returns true if a gap was detected and the series must be re-seeded.
+func applyKline(series *candleSeries, k exchange.Kline, now time.Time) bool {
+	n := len(series.klines)
+	if n == 0 {
+		return true
+	}
+	last := series.klines[n-1]
+
+	switch {
+	case k.OpenTime == last.OpenTime:
+		series.klines[n-1] = k
+	case k.OpenTime == last.CloseTime+1:
+		// New candle opened - slide the window to keep the seeded length
+		series.klines = append(series.klines[1:], k)
+	case k.OpenTime > last.CloseTime+1:
+		return true
+	default:
+		return false // Stale update for an older candle
+	}
+
+	series.updated = now
+	return false
+}
+
+func markPriceStreamName(symbol string) string {
+	return strings.ToLower(symbol) + "@markPrice@1s"
+}
+
+func klineStreamName(symbol, interval string) string {
+	return strings.ToLower(symbol) + "@kline_" + interval
+}
+
+// parseKlineStreamName extracts symbol and interval from "btcusdt@kline_5m"
+func parseKlineStreamName(name string) (symbol, interval string, ok bool) {
+	parts := strings.SplitN(name, "@kline_", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.ToUpper(parts[0]), parts[1], true
+}
+
+func candleKey(symbol, interval string) string {
+	return fmt.Sprintf("%s|%s", strings.ToUpper(symbol), interval)
+}
+
+func parseStreamFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
@@ -0,0 +1,95 @@
+package market
+
+import (
+	"testing"
+	"time"
+
+	"auto-trader-ahh/exchange"
+)
+
+func testSeries() *candleSeries {
+	return &candleSeries{
+		klines: []exchange.Kline{
+			{OpenTime: 0, CloseTime: 299999, Close: 100},
+			{OpenTime: 300000, CloseTime: 599999, Close: 101},
+			{OpenTime: 600000, CloseTime: 899999, Close: 102},
+		},
+	}
+}
+
+func TestApplyKline(t *testing.T) {
+	tests := []struct {
+		name      string
+		kline     exchange.Kline
+		wantReset bool
+		wantLen   int
+		wantFirst int64
+		wantClose float64
+	}{
+		{"update live candle", exchange.Kline{OpenTime: 600000, CloseTime: 899999, Close: 103}, false, 3, 0, 103},
+		{"next candle slides window", exchange.Kline{OpenTime: 900000, CloseTime: 1199999, Close: 104}, false, 3, 300000, 104},
+		{"gap forces reseed", exchange.Kline{OpenTime: 1200000, CloseTime: 1499999, Close: 105}, true, 3, 0, 102},
+		{"stale candle ignored", exchange.Kline{OpenTime: 300000, CloseTime: 599999, Close: 99}, false, 3, 0, 102},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := testSeries()
+			reset := applyKline(series, tt.kline, time.Now())
+
+			if reset != tt.wantReset {
+				t.Errorf("applyKline() reset = %v, want %v", reset, tt.wantReset)
+			}
+			if len(series.klines) != tt.wantLen {
+				t.Errorf("len = %d, want %d", len(series.klines), tt.wantLen)
+			}
+			if series.klines[0].OpenTime != tt.wantFirst {
+				t.Errorf("first open time = %d, want %d", series.klines[0].OpenTime, tt.wantFirst)
+			}
+			if last := series.klines[len(series.klines)-1].Close; last != tt.wantClose {
+				t.Errorf("last close = %.0f, want %.0f", last, tt.wantClose)
+			}
+		})
+	}
+}
+
+func TestStreamNames(t *testing.T) {
+	if got := klineStreamName("BTCUSDT", "5m"); got != "btcusdt@kline_5m" {
+		t.Errorf("klineStreamName() = %s", got)
+	}
+	if got := markPriceStreamName("ETHUSDT"); got != "ethusdt@markPrice@1s" {
+		t.Errorf("markPriceStreamName() = %s", got)
+	}
+
+	symbol, interval, ok := parseKlineStreamName("btcusdt@kline_15m")
+	if !ok || symbol != "BTCUSDT" || interval != "15m" {
+		t.Errorf("parseKlineStreamName() = %s, %s, %v", symbol, interval, ok)
+	}
+	if _, _, ok := parseKlineStreamName("btcusdt@markPrice@1s"); ok {
+		t.Errorf("parseKlineStreamName() should reject mark price stream")
+	}
+}
+
+func TestStreamKlinesFallback(t *testing.T) {
+	s := NewStream(true)
+	s.Watch("trader-1", "BTCUSDT", "5m")
+	s.Seed("BTCUSDT", "5m", testSeries().klines)
+
+	// Not connected: callers must use REST
+	if _, ok := s.Klines("BTCUSDT", "5m", 3); ok {
+		t.Errorf("expected cache miss while disconnected")
+	}
+
+	s.connected = true
+	if klines, ok := s.Klines("BTCUSDT", "5m", 2); !ok || len(klines) != 2 || klines[1].Close != 102 {
+		t.Errorf("expected last 2 cached candles, got %v (ok=%v)", klines, ok)
+	}
+	if _, ok := s.Klines("BTCUSDT", "5m", 10); ok {
+		t.Errorf("expected cache miss when fewer candles cached than requested")
+	}
+
+	s.Release("trader-1")
+	if _, ok := s.Klines("BTCUSDT", "5m", 2); ok {
+		t.Errorf("expected cache cleared after last owner released")
+	}
+}
@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"    // Calls flow normally
+	BreakerOpen     = "open"      // Calls are skipped until the cool-down ends
+	BreakerHalfOpen = "half_open" // One probe call decides whether to close again
+)
+
+// BreakerConfig controls when a backend's circuit opens
+type BreakerConfig struct {
+	Window           int           // Number of recent calls the failure rate is measured over
+	MinCalls         int           // Calls needed in the window before the circuit can open
+	FailureRate      float64       // Fraction of failed calls (0-1) that opens the circuit
+	LatencyThreshold time.Duration // Successful calls slower than this count as failures (0 = off)
+	OpenDuration     time.Duration // How long the circuit stays open before a half-open probe
+}
+
+// DefaultBreakerConfig returns the breaker settings used when none are given
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		Window:           10,
+		MinCalls:         3,
+		FailureRate:      0.5,
+		LatencyThreshold: 120 * time.Second,
+		OpenDuration:     60 * time.Second,
+	}
+}
+
+// BreakerStats is a snapshot of one backend's circuit
+type BreakerStats struct {
+	Backend     string    `json:"backend"`
+	State       string    `json:"state"`
+	Calls       int       `json:"calls"`        // Calls in the current window
+	Failures    int       `json:"failures"`     // Failed or slow calls in the current window
+	OpenedAt    time.Time `json:"opened_at"`    // Zero unless open or half-open
+	LastFailure string    `json:"last_failure"` // Most recent error, if any
+}
+
+// circuitBreaker tracks a rolling window of call outcomes for one backend
+type circuitBreaker struct {
+	cfg BreakerConfig
+	now func() time.Time
+
+	mu          sync.Mutex
+	state       string
+	outcomes    []bool // true = failure, oldest first
+	openedAt    time.Time
+	probing     bool // A half-open probe is in flight
+	lastFailure string
+}
+
+func newCircuitBreaker(cfg BreakerConfig) *circuitBreaker {
+	defaults := DefaultBreakerConfig()
+	if cfg.Window <= 0 {
+		cfg.Window = defaults.Window
+	}
+	if cfg.MinCalls <= 0 {
+		cfg.MinCalls = defaults.MinCalls
+	}
+	if cfg.FailureRate <= 0 || cfg.FailureRate > 1 {
+		cfg.FailureRate = defaults.FailureRate
+	}
+	if cfg.OpenDuration <= 0 {
+		cfg.OpenDuration = defaults.OpenDuration
+	}
+	return &circuitBreaker{cfg: cfg, now: time.Now, state: BreakerClosed}
+}
+
+// allow reports whether a call may go to the backend. Once the cool-down
+// ends, an open circuit lets exactly one probe through.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.OpenDuration {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record stores the outcome of an allowed call
+func (b *circuitBreaker) record(err error, latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	failed := err != nil
+	if !failed && b.cfg.LatencyThreshold > 0 && latency > b.cfg.LatencyThreshold {
+		failed = true
+		b.lastFailure = "slow response: " + latency.Round(time.Millisecond).String()
+	} else if err != nil {
+		b.lastFailure = err.Error()
+	}
+
+	if b.state == BreakerHalfOpen {
+		b.probing = false
+		if failed {
+			b.trip()
+		} else {
+			b.state = BreakerClosed
+			b.outcomes = nil
+		}
+		return
+	}
+
+	b.outcomes = append(b.outcomes, failed)
+	if len(b.outcomes) > b.cfg.Window {
+		b.outcomes = b.outcomes[len(b.outcomes)-b.cfg.Window:]
+	}
+	if len(b.outcomes) >= b.cfg.MinCalls && b.failureRate() >= b.cfg.FailureRate {
+		b.trip()
+	}
+}
+
+func (b *circuitBreaker) trip() {
+	b.state = BreakerOpen
+	b.openedAt = b.now()
+	b.outcomes = nil
+}
+
+func (b *circuitBreaker) failureRate() float64 {
+	if len(b.outcomes) == 0 {
+		return 0
+	}
+	return float64(b.failures()) / float64(len(b.outcomes))
+}
+
+func (b *circuitBreaker) failures() int {
+	n := 0
+	for _, failed := range b.outcomes {
+		if failed {
+			n++
+		}
+	}
+	return n
+}
+
+func (b *circuitBreaker) stats(backend string) BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := BreakerStats{
+		Backend:     backend,
+		State:       b.state,
+		Calls:       len(b.outcomes),
+		Failures:    b.failures(),
+		LastFailure: b.lastFailure,
+	}
+	if b.state != BreakerClosed {
+		s.OpenedAt = b.openedAt
+	}
+	return s
+}
@@ -165,6 +165,23 @@ func (c *Client) CallStream(req *Request, handler ChunkHandler) (*Response, erro
 	}
 	req.Stream = true
 
+	// Anthropic streams tool input as partial JSON; a schema-constrained
+	// answer is only usable whole, so it arrives as one chunk
+	if req.ResponseSchema != nil && c.config.Provider == ProviderAnthropic {
+		streamReq := *req
+		streamReq.Stream = false
+		resp, err := c.CallWithRequest(&streamReq)
+		if err != nil {
+			return nil, err
+		}
+		if handler != nil && resp.Content != "" {
+			if err := handler(resp.Content); err != nil {
+				return nil, err
+			}
+		}
+		return resp, nil
+	}
+
 	var lastErr error
 	for attempt := 1; attempt <= c.config.MaxRetries; attempt++ {
 		resp, err := c.doCallStream(req, handler)
@@ -196,6 +213,8 @@ func (c *Client) doCall(req *Request) (*Response, error) {
 	switch c.config.Provider {
 	case ProviderAnthropic:
 		httpReq, err = c.buildAnthropicRequest(req)
+	case ProviderOllama:
+		httpReq, err = c.buildOllamaRequest(req)
 	default:
 		// OpenAI-compatible (OpenRouter, OpenAI, DeepSeek, etc.)
 		httpReq, err = c.buildOpenAIRequest(req)
@@ -226,6 +245,8 @@ func (c *Client) doCall(req *Request) (*Response, error) {
 	switch c.config.Provider {
 	case ProviderAnthropic:
 		resp, err = c.parseAnthropicResponse(body)
+	case ProviderOllama:
+		resp, err = c.parseOllamaResponse(body)
 	default:
 		resp, err = c.parseOpenAIResponse(body)
 	}
@@ -258,6 +279,8 @@ func (c *Client) doCallStream(req *Request, handler ChunkHandler) (*Response, er
 	switch c.config.Provider {
 	case ProviderAnthropic:
 		httpReq, err = c.buildAnthropicRequest(req)
+	case ProviderOllama:
+		httpReq, err = c.buildOllamaRequest(req)
 	default:
 		// OpenAI-compatible (OpenRouter, OpenAI, DeepSeek, etc.)
 		httpReq, err = c.buildOpenAIRequest(req)
@@ -282,6 +305,19 @@ func (c *Client) doCallStream(req *Request, handler ChunkHandler) (*Response, er
 		return nil, fmt.Errorf("API error (status %d): %s", httpResp.StatusCode, string(body))
 	}
 
+	// Ollama streams newline-delimited JSON rather than SSE
+	if c.config.Provider == ProviderOllama {
+		resp, err := c.readOllamaStream(httpResp.Body, handler)
+		if err != nil {
+			return nil, err
+		}
+		resp.Duration = time.Since(start)
+		resp.Timestamp = time.Now()
+		resp.Model = req.Model
+		resp.Provider = c.config.Provider
+		return resp, nil
+	}
+
 	// Handle streaming response
 	reader := bufio.NewReader(httpResp.Body)
 	var fullContent strings.Builder
@@ -362,6 +398,9 @@ func (c *Client) buildOpenAIRequest(req *Request) (*http.Request, error) {
 	if len(req.Stop) > 0 {
 		payload["stop"] = req.Stop
 	}
+	if req.ResponseSchema != nil && SupportsResponseSchema(c.config.Provider) {
+		payload["response_format"] = req.ResponseSchema.OpenAIResponseFormat()
+	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -375,7 +414,9 @@ func (c *Client) buildOpenAIRequest(req *Request) (*http.Request, error) {
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	if c.config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
 
 	// OpenRouter-specific headers
 	if c.config.Provider == ProviderOpenRouter {
@@ -419,6 +460,11 @@ func (c *Client) buildAnthropicRequest(req *Request) (*http.Request, error) {
 	if len(req.Stop) > 0 {
 		payload["stop_sequences"] = req.Stop
 	}
+	if req.ResponseSchema != nil {
+		// Forcing the tool call makes its input the schema-constrained answer
+		payload["tools"] = []map[string]interface{}{anthropicTool(req.ResponseSchema)}
+		payload["tool_choice"] = map[string]interface{}{"type": "tool", "name": req.ResponseSchema.Name}
+	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -482,8 +528,9 @@ func (c *Client) parseOpenAIResponse(body []byte) (*Response, error) {
 func (c *Client) parseAnthropicResponse(body []byte) (*Response, error) {
 	var result struct {
 		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
+			Type  string          `json:"type"`
+			Text  string          `json:"text"`
+			Input json.RawMessage `json:"input"` // tool_use blocks
 		} `json:"content"`
 		Usage struct {
 			InputTokens  int `json:"input_tokens"`
@@ -502,12 +549,16 @@ func (c *Client) parseAnthropicResponse(body []byte) (*Response, error) {
 		return nil, fmt.Errorf("API error: %s", result.Error.Message)
 	}
 
+	// A tool_use block is the schema-constrained answer and wins over text
 	var content string
 	for _, c := range result.Content {
-		if c.Type == "text" {
-			content = c.Text
+		if c.Type == "tool_use" {
+			content = string(c.Input)
 			break
 		}
+		if c.Type == "text" && content == "" {
+			content = c.Text
+		}
 	}
 
 	return &Response{
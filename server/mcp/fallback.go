@@ -0,0 +1,223 @@
+package mcp
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BackendConfig describes one provider/model in a fallback chain
+type BackendConfig struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	APIKey   string `json:"-"`
+	BaseURL  string `json:"base_url,omitempty"` // Provider default when empty
+}
+
+// NewBackendClient creates a client for one backend of a fallback chain. It
+// makes a single attempt per call: the chain does the retrying, so a
+// degraded backend fails over instead of backing off.
+func NewBackendClient(b BackendConfig) *Client {
+	opts := []Option{
+		WithProvider(b.Provider),
+		WithAPIKey(b.APIKey),
+		WithModel(b.Model),
+		WithMaxRetries(1),
+	}
+	if b.BaseURL != "" {
+		opts = append(opts, WithBaseURL(b.BaseURL))
+	}
+	return NewClient(opts...)
+}
+
+// FallbackClient is an AIClient over an ordered list of backends. Each call
+// goes to the first backend whose circuit is closed (or due a half-open
+// probe) and fails over down the list on error. Response.Backend reports
+// which backend served the call.
+type FallbackClient struct {
+	mu       sync.RWMutex
+	backends []*fallbackBackend
+}
+
+type fallbackBackend struct {
+	client  AIClient
+	breaker *circuitBreaker
+}
+
+func (b *fallbackBackend) name() string {
+	return b.client.GetProvider() + "/" + b.client.GetModel()
+}
+
+// NewFallbackClient chains clients in priority order, each behind its own
+// circuit breaker
+func NewFallbackClient(cfg BreakerConfig, clients ...AIClient) *FallbackClient {
+	f := &FallbackClient{}
+	for _, c := range clients {
+		f.backends = append(f.backends, &fallbackBackend{client: c, breaker: newCircuitBreaker(cfg)})
+	}
+	return f
+}
+
+// NewFallbackClientFromConfigs builds a chain from backend configs
+func NewFallbackClientFromConfigs(cfg BreakerConfig, backends ...BackendConfig) *FallbackClient {
+	clients := make([]AIClient, 0, len(backends))
+	for _, b := range backends {
+		clients = append(clients, NewBackendClient(b))
+	}
+	return NewFallbackClient(cfg, clients...)
+}
+
+// SetAPIKey implements AIClient; it configures the primary backend
+func (f *FallbackClient) SetAPIKey(apiKey, customURL, customModel string) {
+	if primary := f.primary(); primary != nil {
+		primary.client.SetAPIKey(apiKey, customURL, customModel)
+	}
+}
+
+// SetTimeout implements AIClient; it applies to every backend
+func (f *FallbackClient) SetTimeout(timeout time.Duration) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for _, b := range f.backends {
+		b.client.SetTimeout(timeout)
+	}
+}
+
+// GetProvider implements AIClient; it reports the primary backend
+func (f *FallbackClient) GetProvider() string {
+	if primary := f.primary(); primary != nil {
+		return primary.client.GetProvider()
+	}
+	return ""
+}
+
+// GetModel implements AIClient; it reports the primary backend
+func (f *FallbackClient) GetModel() string {
+	if primary := f.primary(); primary != nil {
+		return primary.client.GetModel()
+	}
+	return ""
+}
+
+// CallWithMessages implements AIClient
+func (f *FallbackClient) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	resp, err := f.CallWithRequest(&Request{
+		Messages: []Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Temperature: 0.7,
+		MaxTokens:   4096,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+// CallWithRequest implements AIClient. A model set on the request only
+// applies to the primary backend; fallbacks always use their own model.
+func (f *FallbackClient) CallWithRequest(req *Request) (*Response, error) {
+	return f.call(req, func(b *fallbackBackend, r *Request) (*Response, error) {
+		return b.client.CallWithRequest(r)
+	})
+}
+
+// CallStream implements AIClient. Once a backend has streamed a chunk the
+// call can no longer fail over, since the handler has already seen output.
+func (f *FallbackClient) CallStream(req *Request, handler ChunkHandler) (*Response, error) {
+	streamed := false
+	tracked := func(chunk string) error {
+		streamed = true
+		if handler != nil {
+			return handler(chunk)
+		}
+		return nil
+	}
+	return f.call(req, func(b *fallbackBackend, r *Request) (*Response, error) {
+		resp, err := b.client.CallStream(r, tracked)
+		if err != nil && streamed {
+			return nil, &streamStartedError{err: err}
+		}
+		return resp, err
+	})
+}
+
+// streamStartedError stops failover after a partial stream
+type streamStartedError struct{ err error }
+
+func (e *streamStartedError) Error() string { return e.err.Error() }
+func (e *streamStartedError) Unwrap() error { return e.err }
+
+func (f *FallbackClient) call(req *Request, do func(*fallbackBackend, *Request) (*Response, error)) (*Response, error) {
+	f.mu.RLock()
+	backends := f.backends
+	f.mu.RUnlock()
+
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("no AI backends configured")
+	}
+
+	var skipped, failures []string
+	var lastErr error
+	for i, b := range backends {
+		name := b.name()
+		if !b.breaker.allow() {
+			skipped = append(skipped, name)
+			continue
+		}
+
+		r := *req
+		if i > 0 || r.Model == "" {
+			r.Model = b.client.GetModel()
+		}
+
+		start := time.Now()
+		resp, err := do(b, &r)
+		b.breaker.record(err, time.Since(start))
+
+		if err == nil {
+			resp.Backend = name
+			resp.FailedOver = append(skipped, failures...)
+			if len(resp.FailedOver) > 0 {
+				log.Printf("[AI] Served by fallback %s (unavailable: %s)", name, strings.Join(resp.FailedOver, ", "))
+			}
+			return resp, nil
+		}
+
+		log.Printf("[AI] Backend %s failed: %v", name, err)
+		if _, partial := err.(*streamStartedError); partial {
+			return nil, fmt.Errorf("%s failed mid-stream: %w", name, err)
+		}
+		failures = append(failures, name)
+		lastErr = err
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("all AI backends failed (tried %s): %w", strings.Join(failures, ", "), lastErr)
+	}
+	return nil, fmt.Errorf("all AI backends unavailable: circuits open for %s", strings.Join(skipped, ", "))
+}
+
+// Stats reports each backend's circuit, in priority order
+func (f *FallbackClient) Stats() []BreakerStats {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	stats := make([]BreakerStats, 0, len(f.backends))
+	for _, b := range f.backends {
+		stats = append(stats, b.breaker.stats(b.name()))
+	}
+	return stats
+}
+
+func (f *FallbackClient) primary() *fallbackBackend {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if len(f.backends) == 0 {
+		return nil
+	}
+	return f.backends[0]
+}
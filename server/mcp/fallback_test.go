@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClient is an AIClient whose calls fail while down is set
+type fakeClient struct {
+	provider, model string
+	down            bool
+	calls           int
+}
+
+func (c *fakeClient) SetAPIKey(apiKey, customURL, customModel string) {}
+func (c *fakeClient) SetTimeout(timeout time.Duration)                {}
+func (c *fakeClient) GetProvider() string                             { return c.provider }
+func (c *fakeClient) GetModel() string                                { return c.model }
+
+func (c *fakeClient) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	resp, err := c.CallWithRequest(&Request{})
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+func (c *fakeClient) CallWithRequest(req *Request) (*Response, error) {
+	c.calls++
+	if c.down {
+		return nil, errors.New("API error (status 503): unavailable")
+	}
+	return &Response{Content: "ok", Provider: c.provider, Model: req.Model}, nil
+}
+
+func (c *fakeClient) CallStream(req *Request, handler ChunkHandler) (*Response, error) {
+	return c.CallWithRequest(req)
+}
+
+func TestFallbackClient_FailoverAndRecovery(t *testing.T) {
+	primary := &fakeClient{provider: ProviderOpenRouter, model: "m1"}
+	secondary := &fakeClient{provider: ProviderDeepSeek, model: "deepseek-chat"}
+
+	cfg := BreakerConfig{Window: 4, MinCalls: 2, FailureRate: 0.6, OpenDuration: time.Minute}
+	f := NewFallbackClient(cfg, primary, secondary)
+	now := time.Now()
+	for _, b := range f.backends {
+		b.breaker.now = func() time.Time { return now }
+	}
+
+	steps := []struct {
+		name         string
+		primaryDown  bool
+		advance      time.Duration
+		wantBackend  string
+		wantState    string // Primary circuit after the call
+		wantPrimCall int    // Cumulative calls that reached the primary
+	}{
+		{"healthy", false, 0, "openrouter/m1", BreakerClosed, 1},
+		{"first failure fails over", true, 0, "deepseek/deepseek-chat", BreakerClosed, 2},
+		{"failure rate trips circuit", true, 0, "deepseek/deepseek-chat", BreakerOpen, 3},
+		{"open circuit is skipped", true, 0, "deepseek/deepseek-chat", BreakerOpen, 3},
+		{"failed probe reopens", true, time.Minute, "deepseek/deepseek-chat", BreakerOpen, 4},
+		{"successful probe closes", false, time.Minute, "openrouter/m1", BreakerClosed, 5},
+	}
+
+	for _, step := range steps {
+		primary.down = step.primaryDown
+		now = now.Add(step.advance)
+
+		resp, err := f.CallWithRequest(&Request{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if resp.Backend != step.wantBackend {
+			t.Errorf("%s: served by %s, want %s", step.name, resp.Backend, step.wantBackend)
+		}
+		if got := f.Stats()[0].State; got != step.wantState {
+			t.Errorf("%s: primary circuit %s, want %s", step.name, got, step.wantState)
+		}
+		if primary.calls != step.wantPrimCall {
+			t.Errorf("%s: primary called %d times, want %d", step.name, primary.calls, step.wantPrimCall)
+		}
+	}
+
+	secondary.down = true
+	primary.down = true
+	if _, err := f.CallWithRequest(&Request{}); err == nil {
+		t.Error("expected an error when every backend fails")
+	}
+}
+
+func TestCircuitBreaker_SlowCallsCountAsFailures(t *testing.T) {
+	b := newCircuitBreaker(BreakerConfig{Window: 2, MinCalls: 2, FailureRate: 1, LatencyThreshold: time.Second})
+
+	b.record(nil, 2*time.Second)
+	b.record(nil, 3*time.Second)
+
+	if got := b.stats("x").State; got != BreakerOpen {
+		t.Errorf("state = %s, want %s", got, BreakerOpen)
+	}
+	if b.allow() {
+		t.Error("open circuit allowed a call before the cool-down")
+	}
+}
@@ -0,0 +1,208 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// buildOllamaRequest builds a request for Ollama's /api/chat endpoint
+func (c *Client) buildOllamaRequest(req *Request) (*http.Request, error) {
+	options := map[string]interface{}{}
+	if req.Temperature > 0 {
+		options["temperature"] = req.Temperature
+	}
+	if req.MaxTokens > 0 {
+		options["num_predict"] = req.MaxTokens
+	}
+	if req.TopP > 0 {
+		options["top_p"] = req.TopP
+	}
+	if len(req.Stop) > 0 {
+		options["stop"] = req.Stop
+	}
+
+	payload := map[string]interface{}{
+		"model":    req.Model,
+		"messages": req.Messages,
+		"stream":   req.Stream,
+	}
+	if len(options) > 0 {
+		payload["options"] = options
+	}
+	if req.ResponseSchema != nil {
+		payload["format"] = req.ResponseSchema.Schema
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequest("POST", strings.TrimSuffix(c.config.BaseURL, "/")+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.config.APIKey) // For Ollama behind an auth proxy
+	}
+	return httpReq, nil
+}
+
+// ollamaChunk is one /api/chat response object; streaming sends one per line
+type ollamaChunk struct {
+	Message struct {
+		Content string `json:"content"`
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	Error           string `json:"error"`
+}
+
+func (ch *ollamaChunk) usage() Usage {
+	return Usage{
+		PromptTokens:     ch.PromptEvalCount,
+		CompletionTokens: ch.EvalCount,
+		TotalTokens:      ch.PromptEvalCount + ch.EvalCount,
+	}
+}
+
+// parseOllamaResponse parses a non-streaming Ollama chat response
+func (c *Client) parseOllamaResponse(body []byte) (*Response, error) {
+	var result ollamaChunk
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("API error: %s", result.Error)
+	}
+	return &Response{
+		Content: result.Message.Content,
+		Usage:   result.usage(),
+	}, nil
+}
+
+// readOllamaStream reads Ollama's newline-delimited JSON stream. Token counts
+// arrive on the final ("done") line.
+func (c *Client) readOllamaStream(body io.Reader, handler ChunkHandler) (*Response, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	resp := &Response{}
+	var fullContent strings.Builder
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			continue // Skip invalid JSON
+		}
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("stream error: %s", chunk.Error)
+		}
+
+		if content := chunk.Message.Content; content != "" {
+			fullContent.WriteString(content)
+			if handler != nil {
+				if err := handler(content); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if chunk.Done {
+			resp.Usage = chunk.usage()
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("stream read error: %w", err)
+	}
+
+	resp.Content = fullContent.String()
+	return resp, nil
+}
+
+// ListModels returns the models the provider serves. Supported for Ollama
+// (/api/tags) and OpenAI-compatible servers (/models), sorted by name.
+func (c *Client) ListModels() ([]string, error) {
+	baseURL := strings.TrimSuffix(c.config.BaseURL, "/")
+	url := baseURL + "/models"
+	if c.config.Provider == ProviderOllama {
+		url = baseURL + "/api/tags"
+	} else if c.config.Provider == ProviderAnthropic {
+		return nil, fmt.Errorf("model listing is not supported for %s", c.config.Provider)
+	}
+
+	httpReq, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if c.config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (status %d): %s", httpResp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"` // Ollama
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"` // OpenAI-compatible
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	models := make([]string, 0, len(result.Models)+len(result.Data))
+	for _, m := range result.Models {
+		models = append(models, m.Name)
+	}
+	for _, m := range result.Data {
+		models = append(models, m.ID)
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+// NewOllamaClient creates a client for an Ollama server (default base URL when empty)
+func NewOllamaClient(baseURL, model string) *Client {
+	opts := []Option{WithProvider(ProviderOllama), WithModel(model)}
+	if baseURL != "" {
+		opts = append(opts, WithBaseURL(baseURL))
+	}
+	return NewClient(opts...)
+}
+
+// NewLocalClient creates a client for a self-hosted OpenAI-compatible server
+// such as llama.cpp or vLLM. The API key is optional.
+func NewLocalClient(baseURL, apiKey, model string) *Client {
+	opts := []Option{WithProvider(ProviderLocal), WithAPIKey(apiKey), WithModel(model)}
+	if baseURL != "" {
+		opts = append(opts, WithBaseURL(baseURL))
+	}
+	return NewClient(opts...)
+}
@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeLocalServer serves the Ollama and OpenAI-compatible endpoints the client uses
+func fakeLocalServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/chat":
+			var req struct {
+				Model  string `json:"model"`
+				Stream bool   `json:"stream"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			if !req.Stream {
+				fmt.Fprintf(w, `{"message":{"content":"hello from %s"},"done":true,"prompt_eval_count":5,"eval_count":3}`, req.Model)
+				return
+			}
+			fmt.Fprintln(w, `{"message":{"content":"hel"},"done":false}`)
+			fmt.Fprintln(w, `{"message":{"content":"lo"},"done":false}`)
+			fmt.Fprintln(w, `{"message":{"content":""},"done":true,"prompt_eval_count":5,"eval_count":2}`)
+		case "/api/tags":
+			fmt.Fprint(w, `{"models":[{"name":"qwen2.5:7b"},{"name":"llama3.1:8b"}]}`)
+		case "/v1/models":
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("unexpected Authorization header without an API key")
+			}
+			fmt.Fprint(w, `{"data":[{"id":"Meta-Llama-3-8B-Instruct"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestOllamaClient(t *testing.T) {
+	srv := fakeLocalServer(t)
+	defer srv.Close()
+	client := NewOllamaClient(srv.URL, "llama3.1:8b")
+
+	resp, err := client.CallWithRequest(&Request{Messages: []Message{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("CallWithRequest() error = %v", err)
+	}
+	if resp.Content != "hello from llama3.1:8b" || resp.Usage.TotalTokens != 8 {
+		t.Errorf("CallWithRequest() = %q (%d tokens)", resp.Content, resp.Usage.TotalTokens)
+	}
+
+	var chunks []string
+	resp, err = client.CallStream(&Request{Messages: []Message{{Role: "user", Content: "hi"}}}, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CallStream() error = %v", err)
+	}
+	if resp.Content != "hello" || strings.Join(chunks, "|") != "hel|lo" || resp.Usage.CompletionTokens != 2 {
+		t.Errorf("CallStream() = %q, chunks %v, usage %+v", resp.Content, chunks, resp.Usage)
+	}
+}
+
+func TestListModels(t *testing.T) {
+	srv := fakeLocalServer(t)
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		client *Client
+		want   []string
+	}{
+		{"ollama", NewOllamaClient(srv.URL, ""), []string{"llama3.1:8b", "qwen2.5:7b"}},
+		{"openai-compatible", NewLocalClient(srv.URL+"/v1", "", ""), []string{"Meta-Llama-3-8B-Instruct"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.client.ListModels()
+			if err != nil {
+				t.Fatalf("ListModels() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListModels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import "time"
+
+// CallRecord describes one finished AI call, for usage accounting
+type CallRecord struct {
+	Provider string
+	Model    string
+	Usage    Usage
+	Latency  time.Duration
+	Err      error // nil on success
+}
+
+// CallObserver receives a record of every AI call
+type CallObserver func(rec CallRecord)
+
+// CallGate is checked before every AI call; a non-nil error blocks the call
+// (e.g. a spent budget)
+type CallGate func() error
+
+// MeteredClient wraps an AIClient, checking a gate before each call and
+// reporting every call to an observer. Wrapped around a FallbackClient it
+// records the backend that served the call, or the primary if all failed.
+type MeteredClient struct {
+	inner   AIClient
+	observe CallObserver
+	gate    CallGate
+}
+
+// NewMeteredClient wraps a client; observe and gate may be nil
+func NewMeteredClient(inner AIClient, observe CallObserver, gate CallGate) *MeteredClient {
+	return &MeteredClient{inner: inner, observe: observe, gate: gate}
+}
+
+// SetAPIKey implements AIClient
+func (m *MeteredClient) SetAPIKey(apiKey, customURL, customModel string) {
+	m.inner.SetAPIKey(apiKey, customURL, customModel)
+}
+
+// SetTimeout implements AIClient
+func (m *MeteredClient) SetTimeout(timeout time.Duration) {
+	m.inner.SetTimeout(timeout)
+}
+
+// GetProvider implements AIClient
+func (m *MeteredClient) GetProvider() string {
+	return m.inner.GetProvider()
+}
+
+// GetModel implements AIClient
+func (m *MeteredClient) GetModel() string {
+	return m.inner.GetModel()
+}
+
+// CallWithMessages implements AIClient
+func (m *MeteredClient) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	resp, err := m.CallWithRequest(&Request{
+		Messages: []Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Temperature: 0.7,
+		MaxTokens:   4096,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+// CallWithRequest implements AIClient
+func (m *MeteredClient) CallWithRequest(req *Request) (*Response, error) {
+	return m.call(req, func() (*Response, error) {
+		return m.inner.CallWithRequest(req)
+	})
+}
+
+// CallStream implements AIClient
+func (m *MeteredClient) CallStream(req *Request, handler ChunkHandler) (*Response, error) {
+	return m.call(req, func() (*Response, error) {
+		return m.inner.CallStream(req, handler)
+	})
+}
+
+func (m *MeteredClient) call(req *Request, do func() (*Response, error)) (*Response, error) {
+	if m.gate != nil {
+		if err := m.gate(); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	resp, err := do()
+	if m.observe == nil {
+		return resp, err
+	}
+
+	rec := CallRecord{
+		Provider: m.inner.GetProvider(),
+		Model:    req.Model,
+		Latency:  time.Since(start),
+		Err:      err,
+	}
+	if rec.Model == "" {
+		rec.Model = m.inner.GetModel()
+	}
+	if resp != nil {
+		rec.Usage = resp.Usage
+		if resp.Provider != "" {
+			rec.Provider = resp.Provider
+		}
+		if resp.Model != "" {
+			rec.Model = resp.Model
+		}
+	}
+	m.observe(rec)
+	return resp, err
+}
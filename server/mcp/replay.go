@@ -0,0 +1,294 @@
+package mcp
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNoRecording is returned by ReplayClient when a prompt was never recorded
+var ErrNoRecording = errors.New("no recording for prompt")
+
+// Recording is one recorded request/response pair
+type Recording struct {
+	Key        string    `json:"key"` // PromptHash of the request
+	Provider   string    `json:"provider"`
+	Model      string    `json:"model"`
+	Request    *Request  `json:"request"`
+	Response   *Response `json:"response,omitempty"`
+	Error      string    `json:"error,omitempty"` // Replayed as a failed call
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// PromptHash keys a request by its messages alone, so a recording still
+// matches when the model or sampling settings change
+func PromptHash(req *Request) string {
+	h := sha256.New()
+	for _, m := range req.Messages {
+		fmt.Fprintf(h, "%s\x00%s\x00", m.Role, m.Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cassette stores recordings
+type Cassette interface {
+	Save(rec *Recording) error
+	// Find returns the latest recording for a key, or nil if there is none
+	Find(key string) (*Recording, error)
+}
+
+// NewRecording builds the recording of a finished call
+func NewRecording(provider string, req *Request, resp *Response, err error) *Recording {
+	rec := &Recording{
+		Key:        PromptHash(req),
+		Provider:   provider,
+		Model:      req.Model,
+		Request:    req,
+		Response:   resp,
+		RecordedAt: time.Now(),
+	}
+	if resp != nil {
+		if resp.Provider != "" {
+			rec.Provider = resp.Provider
+		}
+		if resp.Model != "" {
+			rec.Model = resp.Model
+		}
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	return rec
+}
+
+// RecordingClient wraps an AIClient and saves every call, failed ones
+// included, to a cassette. A failed save never fails the live call.
+type RecordingClient struct {
+	inner    AIClient
+	cassette Cassette
+	onError  func(err error)
+}
+
+// NewRecordingClient wraps a client; onError (optional) receives save errors
+func NewRecordingClient(inner AIClient, cassette Cassette, onError func(err error)) *RecordingClient {
+	return &RecordingClient{inner: inner, cassette: cassette, onError: onError}
+}
+
+// SetAPIKey implements AIClient
+func (r *RecordingClient) SetAPIKey(apiKey, customURL, customModel string) {
+	r.inner.SetAPIKey(apiKey, customURL, customModel)
+}
+
+// SetTimeout implements AIClient
+func (r *RecordingClient) SetTimeout(timeout time.Duration) {
+	r.inner.SetTimeout(timeout)
+}
+
+// GetProvider implements AIClient
+func (r *RecordingClient) GetProvider() string {
+	return r.inner.GetProvider()
+}
+
+// GetModel implements AIClient
+func (r *RecordingClient) GetModel() string {
+	return r.inner.GetModel()
+}
+
+// CallWithMessages implements AIClient
+func (r *RecordingClient) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	resp, err := r.CallWithRequest(messagesRequest(systemPrompt, userPrompt))
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+// CallWithRequest implements AIClient
+func (r *RecordingClient) CallWithRequest(req *Request) (*Response, error) {
+	resp, err := r.inner.CallWithRequest(req)
+	r.save(req, resp, err)
+	return resp, err
+}
+
+// CallStream implements AIClient; the full streamed content is recorded
+func (r *RecordingClient) CallStream(req *Request, handler ChunkHandler) (*Response, error) {
+	resp, err := r.inner.CallStream(req, handler)
+	r.save(req, resp, err)
+	return resp, err
+}
+
+func (r *RecordingClient) save(req *Request, resp *Response, err error) {
+	rec := NewRecording(r.inner.GetProvider(), req, resp, err)
+	if rec.Model == "" {
+		rec.Model = r.inner.GetModel()
+	}
+	if saveErr := r.cassette.Save(rec); saveErr != nil && r.onError != nil {
+		r.onError(saveErr)
+	}
+}
+
+// ReplayClient is an AIClient that serves recorded responses by prompt hash
+// and never touches the network. Unrecorded prompts fail with ErrNoRecording.
+type ReplayClient struct {
+	cassette Cassette
+	provider string
+	model    string
+}
+
+// NewReplayClient serves calls from a cassette
+func NewReplayClient(cassette Cassette) *ReplayClient {
+	return &ReplayClient{cassette: cassette, provider: "replay"}
+}
+
+// SetAPIKey implements AIClient; only the model is used
+func (r *ReplayClient) SetAPIKey(apiKey, customURL, customModel string) {
+	if customModel != "" {
+		r.model = customModel
+	}
+}
+
+// SetTimeout implements AIClient
+func (r *ReplayClient) SetTimeout(timeout time.Duration) {}
+
+// GetProvider implements AIClient
+func (r *ReplayClient) GetProvider() string {
+	return r.provider
+}
+
+// GetModel implements AIClient
+func (r *ReplayClient) GetModel() string {
+	return r.model
+}
+
+// CallWithMessages implements AIClient
+func (r *ReplayClient) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	resp, err := r.CallWithRequest(messagesRequest(systemPrompt, userPrompt))
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+// CallWithRequest implements AIClient
+func (r *ReplayClient) CallWithRequest(req *Request) (*Response, error) {
+	key := PromptHash(req)
+	rec, err := r.cassette.Find(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recording: %w", err)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("%w (%s)", ErrNoRecording, key[:12])
+	}
+	if rec.Error != "" {
+		return nil, fmt.Errorf("recorded error: %s", rec.Error)
+	}
+	if rec.Response == nil {
+		return nil, fmt.Errorf("recording %s has no response", key[:12])
+	}
+
+	resp := *rec.Response
+	if resp.Provider == "" {
+		resp.Provider = rec.Provider
+	}
+	if resp.Model == "" {
+		resp.Model = rec.Model
+	}
+	return &resp, nil
+}
+
+// CallStream implements AIClient; the recorded content arrives as one chunk
+func (r *ReplayClient) CallStream(req *Request, handler ChunkHandler) (*Response, error) {
+	resp, err := r.CallWithRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if handler != nil && resp.Content != "" {
+		if err := handler(resp.Content); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// FileCassette keeps recordings in a JSON Lines file, one recording per
+// line. The file is append-only; the latest recording of a key wins.
+type FileCassette struct {
+	mu   sync.Mutex
+	path string
+	recs map[string]*Recording
+}
+
+// OpenFileCassette loads a cassette file, creating it on first save
+func OpenFileCassette(path string) (*FileCassette, error) {
+	c := &FileCassette{path: path, recs: make(map[string]*Recording)}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var rec Recording
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("cassette %s line %d: %w", path, line, err)
+		}
+		c.recs[rec.Key] = &rec
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	return c, nil
+}
+
+// Save implements Cassette
+func (c *FileCassette) Save(rec *Recording) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	c.recs[rec.Key] = rec
+	return nil
+}
+
+// Find implements Cassette
+func (c *FileCassette) Find(key string) (*Recording, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.recs[key], nil
+}
+
+// messagesRequest is the request CallWithMessages sends
+func messagesRequest(systemPrompt, userPrompt string) *Request {
+	return &Request{
+		Messages: []Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Temperature: 0.7,
+		MaxTokens:   4096,
+	}
+}
@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	cassette, err := OpenFileCassette(path)
+	if err != nil {
+		t.Fatalf("OpenFileCassette() error = %v", err)
+	}
+
+	live := NewScriptedClientSteps(
+		ScriptStep{Content: `{"action":"SELL","confidence":80}`},
+		ScriptStep{Err: errors.New("API error (status 503): unavailable")},
+	)
+	recorder := NewRecordingClient(live, cassette, func(err error) { t.Errorf("save failed: %v", err) })
+
+	if _, err := recorder.CallWithMessages("system", "BTCUSDT at 03:12"); err != nil {
+		t.Fatalf("recorded call error = %v", err)
+	}
+	if _, err := recorder.CallWithMessages("system", "ETHUSDT at 03:12"); err == nil {
+		t.Fatal("expected the scripted failure")
+	}
+
+	// Reopen from disk, as an offline reproduction would
+	reopened, err := OpenFileCassette(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	replay := NewReplayClient(reopened)
+
+	tests := []struct {
+		name    string
+		prompt  string
+		want    string
+		wantErr bool
+		noRec   bool
+	}{
+		{"recorded response", "BTCUSDT at 03:12", `{"action":"SELL","confidence":80}`, false, false},
+		{"recorded failure", "ETHUSDT at 03:12", "", true, false},
+		{"never recorded", "SOLUSDT at 03:12", "", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := replay.CallWithMessages("system", tt.prompt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.noRec && !errors.Is(err, ErrNoRecording) {
+				t.Errorf("error = %v, want ErrNoRecording", err)
+			}
+			if got != tt.want {
+				t.Errorf("content = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if live.Remaining() != 0 || len(live.Requests) != 2 {
+		t.Errorf("live client saw %d requests with %d replies left", len(live.Requests), live.Remaining())
+	}
+}
+
+func TestPromptHash_IgnoresSamplingSettings(t *testing.T) {
+	a := &Request{Model: "a", Temperature: 0.7, Messages: []Message{{Role: "user", Content: "hi"}}}
+	b := &Request{Model: "b", Temperature: 0.1, Messages: []Message{{Role: "user", Content: "hi"}}}
+	c := &Request{Messages: []Message{{Role: "system", Content: "hi"}}}
+
+	if PromptHash(a) != PromptHash(b) {
+		t.Error("hash changed with model and temperature")
+	}
+	if PromptHash(a) == PromptHash(c) {
+		t.Error("hash ignored the message role")
+	}
+}
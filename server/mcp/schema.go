@@ -0,0 +1,48 @@
+package mcp
+
+// ResponseSchema constrains a response to JSON matching a JSON Schema.
+// Providers that support it enforce the schema natively; the others ignore
+// it, so the prompt should still describe the expected format.
+type ResponseSchema struct {
+	Name        string                 `json:"name"` // [a-zA-Z0-9_-], used as the Anthropic tool name
+	Description string                 `json:"description,omitempty"`
+	Schema      map[string]interface{} `json:"schema"`
+}
+
+// SupportsResponseSchema reports whether requests to a provider carry the
+// response schema: OpenAI-compatible response_format (OpenAI, OpenRouter,
+// self-hosted servers), Anthropic tool use and Ollama's format field.
+// OpenRouter drops the parameter for models that can't honour it.
+func SupportsResponseSchema(provider string) bool {
+	switch provider {
+	case ProviderOpenAI, ProviderOpenRouter, ProviderAnthropic, ProviderOllama, ProviderLocal:
+		return true
+	}
+	return false
+}
+
+// OpenAIResponseFormat is the response_format value for the schema. Strict
+// mode needs every property required and no additional properties.
+func (s *ResponseSchema) OpenAIResponseFormat() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "json_schema",
+		"json_schema": map[string]interface{}{
+			"name":   s.Name,
+			"strict": true,
+			"schema": s.Schema,
+		},
+	}
+}
+
+// anthropicTool is the tool Anthropic is forced to call with the schema as
+// its input, which is how Anthropic models produce schema-constrained JSON
+func anthropicTool(s *ResponseSchema) map[string]interface{} {
+	tool := map[string]interface{}{
+		"name":         s.Name,
+		"input_schema": s.Schema,
+	}
+	if s.Description != "" {
+		tool["description"] = s.Description
+	}
+	return tool
+}
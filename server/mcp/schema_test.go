@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseSchemaPayloads(t *testing.T) {
+	schema := &ResponseSchema{
+		Name:   "answer",
+		Schema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{"ok": map[string]interface{}{"type": "boolean"}}},
+	}
+
+	tests := []struct {
+		provider    string
+		wantField   string // Payload field carrying the schema; empty when none should
+		wantContent string
+	}{
+		{ProviderOpenAI, "response_format", `{"ok":true}`},
+		{ProviderOpenRouter, "response_format", `{"ok":true}`},
+		{ProviderLocal, "response_format", `{"ok":true}`},
+		{ProviderOllama, "format", `{"ok":true}`},
+		{ProviderAnthropic, "tool_choice", `{"ok":true}`},
+		{ProviderDeepSeek, "", `{"ok":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			var payload map[string]interface{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&payload)
+				switch tt.provider {
+				case ProviderAnthropic:
+					fmt.Fprint(w, `{"content":[{"type":"text","text":"Sure."},{"type":"tool_use","name":"answer","input":{"ok":true}}]}`)
+				case ProviderOllama:
+					fmt.Fprint(w, `{"message":{"content":"{\"ok\":true}"},"done":true}`)
+				default:
+					fmt.Fprint(w, `{"choices":[{"message":{"content":"{\"ok\":true}"}}]}`)
+				}
+			}))
+			defer srv.Close()
+
+			client := NewClient(WithProvider(tt.provider), WithBaseURL(srv.URL), WithModel("m"), WithMaxRetries(1))
+			resp, err := client.CallWithRequest(&Request{
+				Messages:       []Message{{Role: "user", Content: "ok?"}},
+				MaxTokens:      100,
+				ResponseSchema: schema,
+			})
+			if err != nil {
+				t.Fatalf("CallWithRequest() error = %v", err)
+			}
+			if resp.Content != tt.wantContent {
+				t.Errorf("content = %q, want %q", resp.Content, tt.wantContent)
+			}
+
+			for _, field := range []string{"response_format", "format", "tool_choice"} {
+				if _, ok := payload[field]; ok != (field == tt.wantField) {
+					t.Errorf("payload has %s = %v, want only %q", field, ok, tt.wantField)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ScriptStep is one scripted reply: content, or an error when Err is set
+type ScriptStep struct {
+	Content string
+	Err     error
+}
+
+// ScriptedClient is an AIClient for unit tests. It answers calls with its
+// steps in order and keeps every request for assertions. Once the script
+// runs out, calls fail.
+type ScriptedClient struct {
+	mu       sync.Mutex
+	steps    []ScriptStep
+	next     int
+	provider string
+	model    string
+
+	Requests []*Request // Every request received, in order
+}
+
+// NewScriptedClient answers successive calls with the given contents
+func NewScriptedClient(contents ...string) *ScriptedClient {
+	steps := make([]ScriptStep, 0, len(contents))
+	for _, c := range contents {
+		steps = append(steps, ScriptStep{Content: c})
+	}
+	return NewScriptedClientSteps(steps...)
+}
+
+// NewScriptedClientSteps answers successive calls with the given steps,
+// which may include failures
+func NewScriptedClientSteps(steps ...ScriptStep) *ScriptedClient {
+	return &ScriptedClient{steps: steps, provider: "scripted", model: "scripted"}
+}
+
+// SetAPIKey implements AIClient; only the model is used
+func (s *ScriptedClient) SetAPIKey(apiKey, customURL, customModel string) {
+	if customModel != "" {
+		s.model = customModel
+	}
+}
+
+// SetTimeout implements AIClient
+func (s *ScriptedClient) SetTimeout(timeout time.Duration) {}
+
+// GetProvider implements AIClient
+func (s *ScriptedClient) GetProvider() string {
+	return s.provider
+}
+
+// GetModel implements AIClient
+func (s *ScriptedClient) GetModel() string {
+	return s.model
+}
+
+// CallWithMessages implements AIClient
+func (s *ScriptedClient) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	resp, err := s.CallWithRequest(messagesRequest(systemPrompt, userPrompt))
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+// CallWithRequest implements AIClient
+func (s *ScriptedClient) CallWithRequest(req *Request) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests = append(s.Requests, req)
+	if s.next >= len(s.steps) {
+		return nil, fmt.Errorf("scripted client: no reply left for call %d", len(s.Requests))
+	}
+	step := s.steps[s.next]
+	s.next++
+	if step.Err != nil {
+		return nil, step.Err
+	}
+
+	model := req.Model
+	if model == "" {
+		model = s.model
+	}
+	return &Response{
+		Content:   step.Content,
+		Provider:  s.provider,
+		Model:     model,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// CallStream implements AIClient; the reply arrives as one chunk
+func (s *ScriptedClient) CallStream(req *Request, handler ChunkHandler) (*Response, error) {
+	resp, err := s.CallWithRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if handler != nil && resp.Content != "" {
+		if err := handler(resp.Content); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// Remaining returns how many scripted replies are left
+func (s *ScriptedClient) Remaining() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.steps) - s.next
+}
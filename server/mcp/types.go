@@ -1,6 +1,9 @@
 package mcp
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Message represents a chat message
 type Message struct {
@@ -19,6 +22,10 @@ type Request struct {
 	PresencePenalty  float64   `json:"presence_penalty,omitempty"`
 	Stop             []string  `json:"stop,omitempty"`
 	Stream           bool      `json:"stream,omitempty"`
+
+	// Optional; constrains the response to JSON matching the schema on
+	// providers that support it (see SupportsResponseSchema)
+	ResponseSchema *ResponseSchema `json:"response_schema,omitempty"`
 }
 
 // Usage represents token usage information
@@ -36,6 +43,11 @@ type Response struct {
 	Provider  string
 	Duration  time.Duration
 	Timestamp time.Time
+
+	// Set by FallbackClient: the provider/model that served the call, and
+	// the backends that failed or had open circuits before it
+	Backend    string
+	FailedOver []string
 }
 
 // AIClient is the interface for AI providers
@@ -91,6 +103,8 @@ const (
 	ProviderDeepSeek   = "deepseek"
 	ProviderGoogle     = "google"
 	ProviderQwen       = "qwen"
+	ProviderOllama     = "ollama" // Ollama's native chat API
+	ProviderLocal      = "local"  // Self-hosted OpenAI-compatible server (llama.cpp, vLLM)
 )
 
 // Default base URLs
@@ -101,4 +115,21 @@ var DefaultBaseURLs = map[string]string{
 	ProviderDeepSeek:   "https://api.deepseek.com/v1",
 	ProviderGoogle:     "https://generativelanguage.googleapis.com/v1beta",
 	ProviderQwen:       "https://dashscope.aliyuncs.com/api/v1",
+	ProviderOllama:     "http://localhost:11434",
+	ProviderLocal:      "http://localhost:8000/v1",
+}
+
+// IsLocalProvider reports whether a provider runs on our own hardware. Local
+// providers need no API key and have no OpenRouter equivalent to fall back to.
+func IsLocalProvider(provider string) bool {
+	return provider == ProviderOllama || provider == ProviderLocal
+}
+
+// NativeModelID strips an OpenRouter-style vendor prefix ("openai/gpt-4o" -> "gpt-4o")
+// when the model is sent to that vendor's own endpoint
+func NativeModelID(provider, model string) string {
+	if provider == "" || provider == ProviderOpenRouter {
+		return model
+	}
+	return strings.TrimPrefix(model, provider+"/")
 }
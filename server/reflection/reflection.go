@@ -0,0 +1,264 @@
+// Package reflection turns a trader's closed trades into lessons for its
+// future prompts: results are grouped per symbol, direction and entry setup,
+// summarized from the stats or by an AI call, and stored so each cycle can
+// add a bounded digest to the decision context.
+package reflection
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"auto-trader-ahh/decision"
+	"auto-trader-ahh/mcp"
+	"auto-trader-ahh/store"
+)
+
+// Entry setups, see ClassifySetup
+const (
+	SetupWithTrend    = "with_trend"    // Entered in the direction of the EMA trend
+	SetupCounterTrend = "counter_trend" // Entered against the EMA trend
+	SetupExtended     = "extended"      // Entered after the move was stretched (RSI overbought/oversold)
+	SetupUnknown      = "unclassified"  // No indicator data at entry
+)
+
+// ClassifySetup buckets an entry by the primary timeframe's EMA9/EMA21 trend
+// and RSI when the position opened. A long above RSI 70 or a short below
+// RSI 30 is chasing, whatever the trend.
+func ClassifySetup(side string, emaFast, emaSlow, rsi float64) string {
+	long := side == "long"
+	if (long && rsi >= 70) || (!long && rsi > 0 && rsi <= 30) {
+		return SetupExtended
+	}
+	if emaFast == 0 || emaSlow == 0 {
+		return SetupUnknown
+	}
+	if (emaFast > emaSlow) == long {
+		return SetupWithTrend
+	}
+	return SetupCounterTrend
+}
+
+// Summarize groups closed positions, newest first, by symbol, side and
+// setup. Groups with fewer than minTrades trades are left out. Positions
+// closed without matching fills carry no result and are skipped.
+func Summarize(positions []store.TraderPosition, minTrades int) []*store.TradeLesson {
+	groups := make(map[string]*store.TradeLesson)
+	var order []string
+	streakOpen := make(map[string]bool)
+
+	for _, pos := range positions {
+		if pos.CloseReason == store.CloseReasonUntracked {
+			continue
+		}
+		setup := pos.Setup
+		if setup == "" {
+			setup = SetupUnknown
+		}
+		key := pos.Symbol + "/" + pos.Side + "/" + setup
+		l, ok := groups[key]
+		if !ok {
+			l = &store.TradeLesson{
+				Symbol:       pos.Symbol,
+				Side:         pos.Side,
+				Setup:        setup,
+				LastExitTime: pos.ExitTime,
+				Source:       store.LessonSourceStats,
+			}
+			groups[key] = l
+			order = append(order, key)
+			streakOpen[key] = true
+		}
+
+		l.Trades++
+		l.TotalPnL += pos.RealizedPnL
+		l.AvgPnLPct += movePct(pos)
+		if pos.RealizedPnL > 0 {
+			l.Wins++
+			streakOpen[key] = false
+		} else if streakOpen[key] {
+			l.LossStreak++
+		}
+		if pos.CloseReason == store.CloseReasonStopLoss {
+			l.StopOuts++
+		}
+	}
+
+	var lessons []*store.TradeLesson
+	for _, key := range order {
+		l := groups[key]
+		if l.Trades < minTrades {
+			continue
+		}
+		l.AvgPnLPct /= float64(l.Trades)
+		l.Summary = statsSummary(l)
+		lessons = append(lessons, l)
+	}
+	sort.SliceStable(lessons, func(i, j int) bool { return lessons[i].Trades > lessons[j].Trades })
+	return lessons
+}
+
+// movePct is the price move the position captured, in %
+func movePct(pos store.TraderPosition) float64 {
+	if pos.EntryPrice <= 0 || pos.ExitPrice <= 0 {
+		return 0
+	}
+	pct := (pos.ExitPrice - pos.EntryPrice) / pos.EntryPrice * 100
+	if pos.Side == "short" {
+		pct = -pct
+	}
+	return pct
+}
+
+// statsSummary writes a lesson from the numbers alone
+func statsSummary(l *store.TradeLesson) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d trades, %.0f%% won, avg %+.2f%%", l.Trades, l.WinRate(), l.AvgPnLPct))
+	if l.StopOuts > 0 {
+		sb.WriteString(fmt.Sprintf(", %d hit the stop loss", l.StopOuts))
+	}
+	if l.LossStreak >= 2 {
+		sb.WriteString(fmt.Sprintf(", the last %d lost in a row", l.LossStreak))
+	}
+	sb.WriteString(". ")
+
+	switch {
+	case l.LossStreak >= 3:
+		sb.WriteString("Avoid this setup until conditions clearly change.")
+	case l.WinRate() <= 35:
+		sb.WriteString("Mostly losing: require stronger confirmation before taking it again.")
+	case l.WinRate() >= 65 && l.AvgPnLPct > 0:
+		sb.WriteString("This has worked: keep taking it when it lines up.")
+	default:
+		sb.WriteString("Mixed results: no clear edge yet.")
+	}
+	return sb.String()
+}
+
+// ToDecision converts a stored lesson for the decision context
+func ToDecision(l *store.TradeLesson) decision.Lesson {
+	return decision.Lesson{
+		ID:         l.ID,
+		Symbol:     l.Symbol,
+		Side:       l.Side,
+		Setup:      l.Setup,
+		Trades:     l.Trades,
+		WinRate:    l.WinRate(),
+		AvgPnLPct:  l.AvgPnLPct,
+		StopOuts:   l.StopOuts,
+		LossStreak: l.LossStreak,
+		Summary:    l.Summary,
+	}
+}
+
+// Digest picks up to max lessons for a prompt: lessons on the given symbols
+// first, then the rest, each in the given order
+func Digest(lessons []*store.TradeLesson, symbols []string, max int) []decision.Lesson {
+	wanted := make(map[string]bool, len(symbols))
+	for _, s := range symbols {
+		wanted[s] = true
+	}
+
+	digest := make([]decision.Lesson, 0, max)
+	for _, onSymbol := range []bool{true, false} {
+		for _, l := range lessons {
+			if len(digest) >= max {
+				return digest
+			}
+			if wanted[l.Symbol] == onSymbol {
+				digest = append(digest, ToDecision(l))
+			}
+		}
+	}
+	return digest
+}
+
+// Reflector runs reflection passes for one trader
+type Reflector struct {
+	traderID  string
+	positions *store.PositionStore
+	lessons   *store.TradeLessonStore
+	client    mcp.AIClient // Writes the lessons when the config asks for it
+}
+
+// NewReflector creates a reflector for a trader
+func NewReflector(traderID string, client mcp.AIClient) *Reflector {
+	return &Reflector{
+		traderID:  traderID,
+		positions: store.NewPositionStore(),
+		lessons:   store.NewTradeLessonStore(),
+		client:    client,
+	}
+}
+
+// SetClient replaces the client used for AI summaries
+func (r *Reflector) SetClient(client mcp.AIClient) {
+	r.client = client
+}
+
+// Run summarizes the trader's recent closed trades and replaces its stored
+// lessons. If the AI summary fails, the stats summaries are kept.
+func (r *Reflector) Run(cfg store.ReflectionConfig) ([]*store.TradeLesson, error) {
+	positions, err := r.positions.GetClosedPositions(r.traderID, cfg.LookbackTrades)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load closed positions: %w", err)
+	}
+
+	lessons := Summarize(positions, cfg.MinTrades)
+	if cfg.LLMSummary && r.client != nil && len(lessons) > 0 {
+		if err := SummarizeWithAI(r.client, lessons); err != nil {
+			log.Printf("[Reflection] AI summary failed for %s, keeping the stats summaries: %v", r.traderID, err)
+		}
+	}
+
+	if err := r.lessons.Replace(r.traderID, lessons); err != nil {
+		return nil, fmt.Errorf("failed to save lessons: %w", err)
+	}
+	log.Printf("[Reflection] %s: %d lessons from %d closed trades", r.traderID, len(lessons), len(positions))
+	return lessons, nil
+}
+
+// SummarizeWithAI has the AI rewrite the lessons' summaries in one call.
+// Lessons the reply leaves out keep their stats summary.
+func SummarizeWithAI(client mcp.AIClient, lessons []*store.TradeLesson) error {
+	data := decision.ReflectionPromptData{Lessons: make([]decision.Lesson, len(lessons))}
+	for i, l := range lessons {
+		data.Lessons[i] = ToDecision(l)
+		data.Lessons[i].ID = int64(i + 1) // Stored IDs aren't assigned yet
+	}
+
+	registry := decision.DefaultPromptRegistry()
+	system, _ := registry.RenderOrDefault("", decision.PromptReflectionSystem, "", data)
+	user, _ := registry.RenderOrDefault("", decision.PromptReflectionUser, "", data)
+
+	resp, err := client.CallWithMessages(system, user)
+	if err != nil {
+		return err
+	}
+
+	start, end := strings.Index(resp, "{"), strings.LastIndex(resp, "}")
+	if start < 0 || end < start {
+		return fmt.Errorf("no JSON object in response")
+	}
+	var parsed struct {
+		Lessons []struct {
+			ID     int    `json:"id"`
+			Lesson string `json:"lesson"`
+		} `json:"lessons"`
+	}
+	if err := json.Unmarshal([]byte(resp[start:end+1]), &parsed); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	for _, p := range parsed.Lessons {
+		text := strings.TrimSpace(p.Lesson)
+		if p.ID < 1 || p.ID > len(lessons) || text == "" {
+			continue
+		}
+		lessons[p.ID-1].Summary = text
+		lessons[p.ID-1].Source = store.LessonSourceLLM
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package reflection
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"auto-trader-ahh/mcp"
+	"auto-trader-ahh/store"
+)
+
+func TestClassifySetup(t *testing.T) {
+	tests := []struct {
+		name    string
+		side    string
+		emaFast float64
+		emaSlow float64
+		rsi     float64
+		want    string
+	}{
+		{"long in an uptrend", "long", 101, 100, 55, SetupWithTrend},
+		{"short in an uptrend", "short", 101, 100, 55, SetupCounterTrend},
+		{"short in a downtrend", "short", 99, 100, 45, SetupWithTrend},
+		{"long chasing overbought", "long", 101, 100, 75, SetupExtended},
+		{"short chasing oversold", "short", 99, 100, 25, SetupExtended},
+		{"no indicators", "long", 0, 0, 0, SetupUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifySetup(tt.side, tt.emaFast, tt.emaSlow, tt.rsi); got != tt.want {
+				t.Errorf("ClassifySetup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// closed builds a closed position; exit is the price move in %
+func closed(symbol, side, setup string, pnl, exit float64, reason string) store.TraderPosition {
+	return store.TraderPosition{
+		Symbol:      symbol,
+		Side:        side,
+		Setup:       setup,
+		EntryPrice:  100,
+		ExitPrice:   100 + exit,
+		RealizedPnL: pnl,
+		CloseReason: reason,
+		ExitTime:    time.Now(),
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	// Newest first: five BTC shorts stopped out, a win before them
+	var positions []store.TraderPosition
+	for i := 0; i < 5; i++ {
+		positions = append(positions, closed("BTCUSDT", "short", SetupCounterTrend, -10, 1, store.CloseReasonStopLoss))
+	}
+	positions = append(positions,
+		closed("BTCUSDT", "short", SetupCounterTrend, 20, -2, store.CloseReasonTakeProfit),
+		closed("ETHUSDT", "long", SetupWithTrend, 5, 1, store.CloseReasonAI),
+		closed("ETHUSDT", "long", SetupWithTrend, 5, 1, store.CloseReasonTakeProfit),
+		closed("ETHUSDT", "long", SetupWithTrend, 0, 0, store.CloseReasonUntracked),
+		closed("SOLUSDT", "long", "", -1, -1, store.CloseReasonStopLoss),
+	)
+
+	lessons := Summarize(positions, 2)
+	if len(lessons) != 2 {
+		t.Fatalf("got %d lessons, want 2 (SOL has too few trades)", len(lessons))
+	}
+
+	btc := lessons[0]
+	if btc.Symbol != "BTCUSDT" || btc.Trades != 6 || btc.Wins != 1 || btc.StopOuts != 5 || btc.LossStreak != 5 {
+		t.Errorf("BTC lesson = %+v", btc)
+	}
+	if !strings.Contains(btc.Summary, "the last 5 lost in a row") || !strings.Contains(btc.Summary, "Avoid this setup") {
+		t.Errorf("BTC summary = %q", btc.Summary)
+	}
+
+	// The untracked close is left out
+	eth := lessons[1]
+	if eth.Trades != 2 || eth.WinRate() != 100 || eth.AvgPnLPct != 1 || !strings.Contains(eth.Summary, "keep taking it") {
+		t.Errorf("ETH lesson = %+v", eth)
+	}
+
+	digest := Digest(lessons, []string{"ETHUSDT"}, 1)
+	if len(digest) != 1 || digest[0].Symbol != "ETHUSDT" {
+		t.Errorf("Digest() = %+v, want only the ETH lesson", digest)
+	}
+}
+
+func TestSummarizeWithAI(t *testing.T) {
+	lessons := []*store.TradeLesson{
+		{Symbol: "BTCUSDT", Side: "short", Setup: SetupCounterTrend, Trades: 5, Summary: "stats", Source: store.LessonSourceStats},
+		{Symbol: "ETHUSDT", Side: "long", Setup: SetupWithTrend, Trades: 3, Summary: "stats", Source: store.LessonSourceStats},
+	}
+	client := mcp.NewScriptedClient("Here you go:\n" +
+		`{"lessons": [{"id": 1, "lesson": "Stop shorting BTC against the trend."}, {"id": 7, "lesson": "unknown"}]}`)
+
+	if err := SummarizeWithAI(client, lessons); err != nil {
+		t.Fatalf("SummarizeWithAI() error = %v", err)
+	}
+	if lessons[0].Summary != "Stop shorting BTC against the trend." || lessons[0].Source != store.LessonSourceLLM {
+		t.Errorf("lesson 1 = %+v", lessons[0])
+	}
+	if lessons[1].Summary != "stats" || lessons[1].Source != store.LessonSourceStats {
+		t.Errorf("lesson 2 was changed: %+v", lessons[1])
+	}
+	if prompt := client.Requests[0].Messages[1].Content; !strings.Contains(prompt, "id 1: BTCUSDT short, setup counter_trend: 5 trades") {
+		t.Errorf("user prompt = %q", prompt)
+	}
+}
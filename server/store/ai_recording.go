@@ -0,0 +1,166 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AIRecording is one recorded AI request/response pair, kept so a decision
+// can be reproduced offline. Request and Response hold the JSON-encoded
+// mcp.Request and mcp.Response.
+type AIRecording struct {
+	ID         int64     `json:"id"`
+	CallerType string    `json:"caller_type"` // trader | debate | backtest | system
+	CallerID   string    `json:"caller_id"`
+	PromptHash string    `json:"prompt_hash"`
+	Provider   string    `json:"provider"`
+	Model      string    `json:"model"`
+	Request    string    `json:"request"`
+	Response   string    `json:"response,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AIRecordingFilter narrows a recording search; empty fields match everything
+type AIRecordingFilter struct {
+	CallerID string
+	Before   time.Time // Recorded at or before
+	Contains string    // Substring of the request, e.g. a symbol
+}
+
+// AIRecordingStore persists AI recordings
+type AIRecordingStore struct{}
+
+func NewAIRecordingStore() *AIRecordingStore {
+	return &AIRecordingStore{}
+}
+
+// InitTables creates the recordings table
+func (s *AIRecordingStore) InitTables() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS ai_recordings (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		caller_type TEXT NOT NULL,
+		caller_id TEXT DEFAULT '',
+		prompt_hash TEXT NOT NULL,
+		provider TEXT DEFAULT '',
+		model TEXT DEFAULT '',
+		request TEXT NOT NULL,
+		response TEXT DEFAULT '',
+		error TEXT DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_ai_recordings_hash ON ai_recordings(prompt_hash);
+	CREATE INDEX IF NOT EXISTS idx_ai_recordings_caller ON ai_recordings(caller_id, created_at);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// Save stores a recording
+func (s *AIRecordingStore) Save(rec *AIRecording) error {
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now()
+	}
+	result, err := db.Exec(`
+		INSERT INTO ai_recordings (caller_type, caller_id, prompt_hash, provider, model, request, response, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rec.CallerType, rec.CallerID, rec.PromptHash, rec.Provider, rec.Model, rec.Request, rec.Response,
+		rec.Error, rec.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save AI recording: %w", err)
+	}
+	rec.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// Get returns a recording by ID
+func (s *AIRecordingStore) Get(id int64) (*AIRecording, error) {
+	return scanAIRecording(db.QueryRow(`
+		SELECT id, caller_type, caller_id, prompt_hash, provider, model, request, response, error, created_at
+		FROM ai_recordings WHERE id = ?
+	`, id))
+}
+
+// FindByHash returns the latest recording of a prompt, or nil if there is
+// none. callerID scopes the search when set.
+func (s *AIRecordingStore) FindByHash(callerID, hash string) (*AIRecording, error) {
+	query := `
+		SELECT id, caller_type, caller_id, prompt_hash, provider, model, request, response, error, created_at
+		FROM ai_recordings WHERE prompt_hash = ?`
+	args := []interface{}{hash}
+	if callerID != "" {
+		query += ` AND caller_id = ?`
+		args = append(args, callerID)
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT 1`
+
+	rec, err := scanAIRecording(db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rec, err
+}
+
+// List returns recordings matching a filter, newest first
+func (s *AIRecordingStore) List(f AIRecordingFilter, limit int) ([]*AIRecording, error) {
+	var conds []string
+	var args []interface{}
+	if f.CallerID != "" {
+		conds = append(conds, "caller_id = ?")
+		args = append(args, f.CallerID)
+	}
+	if !f.Before.IsZero() {
+		conds = append(conds, "created_at <= ?")
+		args = append(args, f.Before)
+	}
+	if f.Contains != "" {
+		conds = append(conds, "instr(request, ?) > 0")
+		args = append(args, f.Contains)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, limit)
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT id, caller_type, caller_id, prompt_hash, provider, model, request, response, error, created_at
+		FROM ai_recordings %s
+		ORDER BY created_at DESC, id DESC LIMIT ?
+	`, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recs := make([]*AIRecording, 0)
+	for rows.Next() {
+		rec, err := scanAIRecording(rows)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, rows.Err()
+}
+
+// DeleteBefore prunes recordings older than a point in time
+func (s *AIRecordingStore) DeleteBefore(t time.Time) (int64, error) {
+	result, err := db.Exec(`DELETE FROM ai_recordings WHERE created_at < ?`, t)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanAIRecording(row rowScanner) (*AIRecording, error) {
+	var rec AIRecording
+	if err := row.Scan(&rec.ID, &rec.CallerType, &rec.CallerID, &rec.PromptHash, &rec.Provider, &rec.Model,
+		&rec.Request, &rec.Response, &rec.Error, &rec.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
@@ -0,0 +1,313 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AI call caller types
+const (
+	CallerTrader   = "trader"
+	CallerDebate   = "debate"
+	CallerBacktest = "backtest"
+	CallerSystem   = "system" // API helpers such as pair recommendations
+)
+
+// AICall is one recorded AI request
+type AICall struct {
+	ID               int64     `json:"id"`
+	CallerType       string    `json:"caller_type"` // trader | debate | backtest | system
+	CallerID         string    `json:"caller_id"`   // Trader, debate session or backtest run ID
+	TraderID         string    `json:"trader_id"`   // Trader billed for the call, if any (debates run for a trader)
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	CostUSD          float64   `json:"cost_usd"` // From the price table at the time of the call
+	LatencyMs        int64     `json:"latency_ms"`
+	Success          bool      `json:"success"`
+	Error            string    `json:"error,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ModelPrice is the USD price per million tokens for a model
+type ModelPrice struct {
+	Model             string    `json:"model"`
+	PromptPerMTok     float64   `json:"prompt_per_mtok"`
+	CompletionPerMTok float64   `json:"completion_per_mtok"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// SpendRow is AI spend aggregated over one group (trader, day, model or caller)
+type SpendRow struct {
+	Key              string  `json:"key"`
+	Calls            int     `json:"calls"`
+	FailedCalls      int     `json:"failed_calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+	AvgLatencyMs     float64 `json:"avg_latency_ms"`
+}
+
+// SpendFilter narrows a spend query; empty fields match everything
+type SpendFilter struct {
+	TraderID   string
+	CallerType string
+	From       time.Time
+	To         time.Time
+}
+
+// AIUsageStore records AI calls and the model price table
+type AIUsageStore struct{}
+
+func NewAIUsageStore() *AIUsageStore {
+	return &AIUsageStore{}
+}
+
+// InitTables creates the AI call log and price table
+func (s *AIUsageStore) InitTables() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS ai_calls (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		caller_type TEXT NOT NULL,
+		caller_id TEXT DEFAULT '',
+		trader_id TEXT DEFAULT '',
+		provider TEXT DEFAULT '',
+		model TEXT DEFAULT '',
+		prompt_tokens INTEGER DEFAULT 0,
+		completion_tokens INTEGER DEFAULT 0,
+		cost_usd REAL DEFAULT 0,
+		latency_ms INTEGER DEFAULT 0,
+		success BOOLEAN DEFAULT 1,
+		error TEXT DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_ai_calls_trader ON ai_calls(trader_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_ai_calls_created ON ai_calls(created_at);
+
+	CREATE TABLE IF NOT EXISTS ai_model_prices (
+		model TEXT PRIMARY KEY,
+		prompt_per_mtok REAL NOT NULL DEFAULT 0,
+		completion_per_mtok REAL NOT NULL DEFAULT 0,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// Record prices and saves a call
+func (s *AIUsageStore) Record(call *AICall) error {
+	if call.CreatedAt.IsZero() {
+		call.CreatedAt = time.Now()
+	}
+	if call.CostUSD == 0 {
+		cost, err := s.Cost(call.Model, call.PromptTokens, call.CompletionTokens)
+		if err != nil {
+			return err
+		}
+		call.CostUSD = cost
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO ai_calls (caller_type, caller_id, trader_id, provider, model, prompt_tokens,
+			completion_tokens, cost_usd, latency_ms, success, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, call.CallerType, call.CallerID, call.TraderID, call.Provider, call.Model, call.PromptTokens,
+		call.CompletionTokens, call.CostUSD, call.LatencyMs, call.Success, call.Error, call.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record AI call: %w", err)
+	}
+	call.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// Cost prices a call from the price table. Models are matched exactly, then
+// without an OpenRouter-style vendor prefix ("deepseek/deepseek-chat" ->
+// "deepseek-chat"). Unpriced models cost 0.
+func (s *AIUsageStore) Cost(model string, promptTokens, completionTokens int) (float64, error) {
+	candidates := []string{model}
+	if i := strings.Index(model, "/"); i >= 0 {
+		candidates = append(candidates, model[i+1:])
+	}
+	for _, m := range candidates {
+		price, err := s.GetPrice(m)
+		if err != nil {
+			return 0, err
+		}
+		if price != nil {
+			return (float64(promptTokens)*price.PromptPerMTok + float64(completionTokens)*price.CompletionPerMTok) / 1e6, nil
+		}
+	}
+	return 0, nil
+}
+
+// Spend aggregates calls by "trader", "day", "model" or "caller", most
+// expensive first (by day: newest first)
+func (s *AIUsageStore) Spend(groupBy string, f SpendFilter) ([]*SpendRow, error) {
+	var key, order string
+	switch groupBy {
+	case "trader":
+		key, order = "trader_id", "cost_usd DESC"
+	case "day":
+		key, order = "date(created_at)", "key DESC"
+	case "model":
+		key, order = "model", "cost_usd DESC"
+	case "caller":
+		key, order = "caller_type || ':' || caller_id", "cost_usd DESC"
+	default:
+		return nil, fmt.Errorf("invalid group_by %q: use trader, day, model or caller", groupBy)
+	}
+
+	where, args := f.where()
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT %s AS key, COUNT(*), SUM(CASE WHEN success THEN 0 ELSE 1 END),
+			SUM(prompt_tokens), SUM(completion_tokens), SUM(cost_usd) AS cost_usd, AVG(latency_ms)
+		FROM ai_calls %s
+		GROUP BY key ORDER BY %s
+	`, key, where, order), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*SpendRow, 0)
+	for rows.Next() {
+		var r SpendRow
+		var k sql.NullString
+		if err := rows.Scan(&k, &r.Calls, &r.FailedCalls, &r.PromptTokens, &r.CompletionTokens,
+			&r.CostUSD, &r.AvgLatencyMs); err != nil {
+			return nil, err
+		}
+		r.Key = k.String
+		result = append(result, &r)
+	}
+	return result, rows.Err()
+}
+
+// TraderSpend returns a trader's AI spend since a point in time, including
+// debates run on its behalf
+func (s *AIUsageStore) TraderSpend(traderID string, since time.Time) (float64, error) {
+	var spend sql.NullFloat64
+	err := db.QueryRow(`
+		SELECT SUM(cost_usd) FROM ai_calls WHERE trader_id = ? AND created_at >= ?
+	`, traderID, since).Scan(&spend)
+	return spend.Float64, err
+}
+
+// RecentCalls returns the latest calls, newest first
+func (s *AIUsageStore) RecentCalls(f SpendFilter, limit int) ([]*AICall, error) {
+	where, args := f.where()
+	args = append(args, limit)
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT id, caller_type, caller_id, trader_id, provider, model, prompt_tokens, completion_tokens,
+			cost_usd, latency_ms, success, error, created_at
+		FROM ai_calls %s
+		ORDER BY created_at DESC, id DESC LIMIT ?
+	`, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	calls := make([]*AICall, 0)
+	for rows.Next() {
+		var c AICall
+		if err := rows.Scan(&c.ID, &c.CallerType, &c.CallerID, &c.TraderID, &c.Provider, &c.Model,
+			&c.PromptTokens, &c.CompletionTokens, &c.CostUSD, &c.LatencyMs, &c.Success, &c.Error,
+			&c.CreatedAt); err != nil {
+			return nil, err
+		}
+		calls = append(calls, &c)
+	}
+	return calls, rows.Err()
+}
+
+func (f SpendFilter) where() (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if f.TraderID != "" {
+		conds = append(conds, "trader_id = ?")
+		args = append(args, f.TraderID)
+	}
+	if f.CallerType != "" {
+		conds = append(conds, "caller_type = ?")
+		args = append(args, f.CallerType)
+	}
+	if !f.From.IsZero() {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, f.From)
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "created_at < ?")
+		args = append(args, f.To)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+// SetPrice adds or updates a model's price
+func (s *AIUsageStore) SetPrice(p *ModelPrice) error {
+	p.UpdatedAt = time.Now()
+	_, err := db.Exec(`
+		INSERT INTO ai_model_prices (model, prompt_per_mtok, completion_per_mtok, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(model) DO UPDATE SET
+			prompt_per_mtok = excluded.prompt_per_mtok,
+			completion_per_mtok = excluded.completion_per_mtok,
+			updated_at = excluded.updated_at
+	`, p.Model, p.PromptPerMTok, p.CompletionPerMTok, p.UpdatedAt)
+	return err
+}
+
+// GetPrice returns a model's price, or nil if it has none
+func (s *AIUsageStore) GetPrice(model string) (*ModelPrice, error) {
+	var p ModelPrice
+	err := db.QueryRow(`
+		SELECT model, prompt_per_mtok, completion_per_mtok, updated_at FROM ai_model_prices WHERE model = ?
+	`, model).Scan(&p.Model, &p.PromptPerMTok, &p.CompletionPerMTok, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListPrices returns the price table by model
+func (s *AIUsageStore) ListPrices() ([]*ModelPrice, error) {
+	rows, err := db.Query(`
+		SELECT model, prompt_per_mtok, completion_per_mtok, updated_at FROM ai_model_prices ORDER BY model ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make([]*ModelPrice, 0)
+	for rows.Next() {
+		var p ModelPrice
+		if err := rows.Scan(&p.Model, &p.PromptPerMTok, &p.CompletionPerMTok, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		prices = append(prices, &p)
+	}
+	return prices, rows.Err()
+}
+
+// DeletePrice removes a model from the price table
+func (s *AIUsageStore) DeletePrice(model string) error {
+	_, err := db.Exec(`DELETE FROM ai_model_prices WHERE model = ?`, model)
+	return err
+}
+
+// MonthStart returns the start of the calendar month containing t, the
+// period monthly AI budgets cover
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
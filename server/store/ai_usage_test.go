@@ -0,0 +1,96 @@
+package store
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAIUsageStore_CostAndSpend(t *testing.T) {
+	if err := Init(t.TempDir()); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Close()
+
+	s := NewAIUsageStore()
+	if err := s.SetPrice(&ModelPrice{Model: "deepseek-chat", PromptPerMTok: 0.27, CompletionPerMTok: 1.10}); err != nil {
+		t.Fatalf("SetPrice() error = %v", err)
+	}
+
+	costs := []struct {
+		model string
+		want  float64
+	}{
+		{"deepseek-chat", 0.27 + 1.10},
+		{"deepseek/deepseek-chat", 0.27 + 1.10}, // Vendor prefix stripped
+		{"unpriced-model", 0},
+	}
+	for _, tt := range costs {
+		got, err := s.Cost(tt.model, 1_000_000, 1_000_000)
+		if err != nil {
+			t.Fatalf("Cost(%s) error = %v", tt.model, err)
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Cost(%s) = %v, want %v", tt.model, got, tt.want)
+		}
+	}
+
+	month := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	calls := []*AICall{
+		{CallerType: CallerTrader, CallerID: "t1", TraderID: "t1", Model: "deepseek/deepseek-chat",
+			PromptTokens: 1_000_000, Success: true, CreatedAt: month.AddDate(0, 0, -1)},
+		{CallerType: CallerTrader, CallerID: "t1", TraderID: "t1", Model: "deepseek/deepseek-chat",
+			PromptTokens: 1_000_000, CompletionTokens: 1_000_000, Success: true, CreatedAt: month.Add(time.Hour)},
+		{CallerType: CallerDebate, CallerID: "d1", TraderID: "t1", Model: "deepseek-chat",
+			Success: false, Error: "timeout", CreatedAt: month.Add(2 * time.Hour)},
+		{CallerType: CallerBacktest, CallerID: "bt1", Model: "deepseek-chat",
+			CompletionTokens: 1_000_000, Success: true, CreatedAt: month.Add(3 * time.Hour)},
+	}
+	for _, c := range calls {
+		if err := s.Record(c); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	spent, err := s.TraderSpend("t1", month)
+	if err != nil {
+		t.Fatalf("TraderSpend() error = %v", err)
+	}
+	if want := 0.27 + 1.10; math.Abs(spent-want) > 1e-9 {
+		t.Errorf("TraderSpend() = %v, want %v (earlier months excluded)", spent, want)
+	}
+
+	rows, err := s.Spend("caller", SpendFilter{From: month})
+	if err != nil {
+		t.Fatalf("Spend() error = %v", err)
+	}
+	want := []struct {
+		key    string
+		calls  int
+		failed int
+	}{
+		{"trader:t1", 1, 0},
+		{"backtest:bt1", 1, 0},
+		{"debate:d1", 1, 1},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Spend() returned %d rows, want %d", len(rows), len(want))
+	}
+	for i, w := range want {
+		if rows[i].Key != w.key || rows[i].Calls != w.calls || rows[i].FailedCalls != w.failed {
+			t.Errorf("row %d = %+v, want key %s with %d calls (%d failed)", i, rows[i], w.key, w.calls, w.failed)
+		}
+	}
+
+	days, err := s.Spend("day", SpendFilter{TraderID: "t1"})
+	if err != nil {
+		t.Fatalf("Spend(day) error = %v", err)
+	}
+	if len(days) != 2 || days[0].Key != "2026-03-01" || days[1].Key != "2026-02-28" {
+		t.Errorf("Spend(day) = %+v, want 2026-03-01 then 2026-02-28", days)
+	}
+
+	if _, err := s.Spend("provider", SpendFilter{}); err == nil {
+		t.Error("Spend() accepted an invalid group_by")
+	}
+}
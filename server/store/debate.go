@@ -0,0 +1,372 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DebateSession is a persisted debate session. The full session is kept as
+// JSON in Data (the debate package owns its shape); the other columns are
+// for lookups on startup.
+type DebateSession struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	TraderID    string    `json:"trader_id"`
+	Source      string    `json:"source"`
+	AutoCycle   bool      `json:"auto_cycle"`
+	CycleCount  int       `json:"cycle_count"`
+	NextCycleAt time.Time `json:"next_cycle_at"`
+	Data        string    `json:"data"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// DebateEntry is a participant, message or vote of a debate cycle, stored as JSON
+type DebateEntry struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	Cycle     int       `json:"cycle"`
+	Data      string    `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DebateDecision is a consensus decision of a debate cycle and its execution result
+type DebateDecision struct {
+	ID              int64     `json:"id"`
+	SessionID       string    `json:"session_id"`
+	Cycle           int       `json:"cycle"`
+	Symbol          string    `json:"symbol"`
+	Action          string    `json:"action"`
+	Confidence      int       `json:"confidence"`
+	Leverage        int       `json:"leverage"`
+	PositionPct     float64   `json:"position_pct"`
+	PositionSizeUSD float64   `json:"position_size_usd"`
+	StopLoss        float64   `json:"stop_loss"`
+	TakeProfit      float64   `json:"take_profit"`
+	Reasoning       string    `json:"reasoning"`
+	Executed        bool      `json:"executed"`
+	ExecutedAt      time.Time `json:"executed_at,omitempty"`
+	OrderID         string    `json:"order_id,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Debate entry tables
+const (
+	DebateParticipants = "debate_participants"
+	DebateMessages     = "debate_messages"
+	DebateVotes        = "debate_votes"
+)
+
+// DebateStore handles debate persistence
+type DebateStore struct{}
+
+func NewDebateStore() *DebateStore {
+	return &DebateStore{}
+}
+
+// InitTables creates the debate tables
+func (s *DebateStore) InitTables() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS debate_sessions (
+		id TEXT PRIMARY KEY,
+		name TEXT,
+		status TEXT NOT NULL,
+		trader_id TEXT,
+		source TEXT DEFAULT '',
+		auto_cycle BOOLEAN DEFAULT 0,
+		cycle_count INTEGER DEFAULT 0,
+		next_cycle_at DATETIME,
+		data TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS debate_participants (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		cycle INTEGER DEFAULT 0,
+		data TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS debate_messages (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		cycle INTEGER DEFAULT 0,
+		data TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS debate_votes (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		cycle INTEGER DEFAULT 0,
+		data TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS debate_decisions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		session_id TEXT NOT NULL,
+		cycle INTEGER DEFAULT 0,
+		symbol TEXT NOT NULL,
+		action TEXT NOT NULL,
+		confidence INTEGER,
+		leverage INTEGER,
+		position_pct REAL,
+		position_size_usd REAL,
+		stop_loss REAL,
+		take_profit REAL,
+		reasoning TEXT,
+		executed BOOLEAN DEFAULT 0,
+		executed_at DATETIME,
+		order_id TEXT,
+		error TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS debate_outcomes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		session_id TEXT NOT NULL,
+		cycle INTEGER DEFAULT 0,
+		symbol TEXT NOT NULL,
+		ai_model_id TEXT NOT NULL,
+		ai_model_name TEXT,
+		personality TEXT NOT NULL,
+		action TEXT NOT NULL,
+		confidence INTEGER,
+		executed_action TEXT NOT NULL,
+		leverage INTEGER,
+		entry_price REAL NOT NULL,
+		executed BOOLEAN DEFAULT 0,
+		trader_id TEXT DEFAULT '',
+		resolved BOOLEAN DEFAULT 0,
+		exit_price REAL,
+		return_pct REAL,
+		hit BOOLEAN DEFAULT 0,
+		pnl_contribution REAL,
+		brier REAL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		resolved_at DATETIME
+	);
+
+	CREATE TABLE IF NOT EXISTS debate_personalities (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		description TEXT DEFAULT '',
+		prompt_fragment TEXT DEFAULT '',
+		color TEXT DEFAULT '',
+		emoji TEXT DEFAULT '',
+		bias TEXT DEFAULT 'neutral',
+		risk_tolerance TEXT DEFAULT 'moderate',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_debate_participants_session ON debate_participants(session_id);
+	CREATE INDEX IF NOT EXISTS idx_debate_messages_session ON debate_messages(session_id, cycle);
+	CREATE INDEX IF NOT EXISTS idx_debate_votes_session ON debate_votes(session_id, cycle);
+	CREATE INDEX IF NOT EXISTS idx_debate_decisions_session ON debate_decisions(session_id, cycle);
+	CREATE INDEX IF NOT EXISTS idx_debate_outcomes_pending ON debate_outcomes(trader_id, symbol, resolved);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// SaveSession inserts or updates a session
+func (s *DebateStore) SaveSession(session *DebateSession) error {
+	session.UpdatedAt = time.Now()
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = session.UpdatedAt
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO debate_sessions (id, name, status, trader_id, source, auto_cycle, cycle_count, next_cycle_at, data, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			status = excluded.status,
+			trader_id = excluded.trader_id,
+			source = excluded.source,
+			auto_cycle = excluded.auto_cycle,
+			cycle_count = excluded.cycle_count,
+			next_cycle_at = excluded.next_cycle_at,
+			data = excluded.data,
+			updated_at = excluded.updated_at
+	`, session.ID, session.Name, session.Status, session.TraderID, session.Source, session.AutoCycle,
+		session.CycleCount, session.NextCycleAt, session.Data, session.CreatedAt, session.UpdatedAt)
+	return err
+}
+
+// ListSessions returns all sessions, oldest first
+func (s *DebateStore) ListSessions() ([]*DebateSession, error) {
+	rows, err := db.Query(`
+		SELECT id, name, status, trader_id, source, auto_cycle, cycle_count, next_cycle_at, data, created_at, updated_at
+		FROM debate_sessions ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*DebateSession
+	for rows.Next() {
+		var d DebateSession
+		var traderID, source sql.NullString
+		var nextCycleAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.Name, &d.Status, &traderID, &source, &d.AutoCycle, &d.CycleCount,
+			&nextCycleAt, &d.Data, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+		d.TraderID = traderID.String
+		d.Source = source.String
+		d.NextCycleAt = nextCycleAt.Time
+		sessions = append(sessions, &d)
+	}
+	return sessions, rows.Err()
+}
+
+// DeleteSession removes a session with its participants, messages, votes and decisions.
+// Outcomes are kept: they are the participants' track record, not part of the session.
+func (s *DebateStore) DeleteSession(id string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{DebateParticipants, DebateMessages, DebateVotes, "debate_decisions"} {
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE session_id = ?", table), id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM debate_sessions WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveEntry inserts or replaces a participant, message or vote (table is one of the Debate* constants)
+func (s *DebateStore) SaveEntry(table string, entry *DebateEntry) error {
+	if err := checkDebateTable(table); err != nil {
+		return err
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	_, err := db.Exec(fmt.Sprintf(`
+		INSERT OR REPLACE INTO %s (id, session_id, cycle, data, created_at) VALUES (?, ?, ?, ?, ?)
+	`, table), entry.ID, entry.SessionID, entry.Cycle, entry.Data, entry.CreatedAt)
+	return err
+}
+
+// ListEntries returns a session's entries for one cycle (cycle < 0 = all cycles) in creation order
+func (s *DebateStore) ListEntries(table, sessionID string, cycle int) ([]*DebateEntry, error) {
+	if err := checkDebateTable(table); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT id, session_id, cycle, data, created_at FROM %s WHERE session_id = ?", table)
+	args := []interface{}{sessionID}
+	if cycle >= 0 {
+		query += " AND cycle = ?"
+		args = append(args, cycle)
+	}
+	query += " ORDER BY created_at ASC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*DebateEntry
+	for rows.Next() {
+		var e DebateEntry
+		if err := rows.Scan(&e.ID, &e.SessionID, &e.Cycle, &e.Data, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// SaveDecisions replaces the decisions of a session cycle. Called at consensus
+// and again once execution results are known.
+func (s *DebateStore) SaveDecisions(sessionID string, cycle int, decisions []*DebateDecision) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM debate_decisions WHERE session_id = ? AND cycle = ?", sessionID, cycle); err != nil {
+		return err
+	}
+
+	for _, d := range decisions {
+		var executedAt interface{}
+		if !d.ExecutedAt.IsZero() {
+			executedAt = d.ExecutedAt
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO debate_decisions (
+				session_id, cycle, symbol, action, confidence, leverage, position_pct, position_size_usd,
+				stop_loss, take_profit, reasoning, executed, executed_at, order_id, error, created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, sessionID, cycle, d.Symbol, d.Action, d.Confidence, d.Leverage, d.PositionPct, d.PositionSizeUSD,
+			d.StopLoss, d.TakeProfit, d.Reasoning, d.Executed, executedAt, d.OrderID, d.Error, time.Now()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListDecisions returns a session's decisions, newest cycle first (cycle < 0 = all cycles)
+func (s *DebateStore) ListDecisions(sessionID string, cycle int) ([]*DebateDecision, error) {
+	query := `
+		SELECT id, session_id, cycle, symbol, action, confidence, leverage, position_pct, position_size_usd,
+			stop_loss, take_profit, reasoning, executed, executed_at, order_id, error, created_at
+		FROM debate_decisions WHERE session_id = ?`
+	args := []interface{}{sessionID}
+	if cycle >= 0 {
+		query += " AND cycle = ?"
+		args = append(args, cycle)
+	}
+	query += " ORDER BY cycle DESC, id ASC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var decisions []*DebateDecision
+	for rows.Next() {
+		var d DebateDecision
+		var reasoning, orderID, errMsg sql.NullString
+		var executedAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.SessionID, &d.Cycle, &d.Symbol, &d.Action, &d.Confidence, &d.Leverage,
+			&d.PositionPct, &d.PositionSizeUSD, &d.StopLoss, &d.TakeProfit, &reasoning, &d.Executed,
+			&executedAt, &orderID, &errMsg, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		d.Reasoning = reasoning.String
+		d.ExecutedAt = executedAt.Time
+		d.OrderID = orderID.String
+		d.Error = errMsg.String
+		decisions = append(decisions, &d)
+	}
+	return decisions, rows.Err()
+}
+
+func checkDebateTable(table string) error {
+	switch table {
+	case DebateParticipants, DebateMessages, DebateVotes:
+		return nil
+	}
+	return fmt.Errorf("unknown debate table: %s", table)
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DebateOutcome is one participant's vote on a symbol whose consensus was
+// traded, scored once the trader's position on it closes
+type DebateOutcome struct {
+	ID              int64     `json:"id"`
+	SessionID       string    `json:"session_id"`
+	Cycle           int       `json:"cycle"`
+	Symbol          string    `json:"symbol"`
+	AIModelID       string    `json:"ai_model_id"`
+	AIModelName     string    `json:"ai_model_name"`
+	Personality     string    `json:"personality"`
+	Action          string    `json:"action"`          // The participant's vote
+	Confidence      int       `json:"confidence"`      // Self-reported, 0-100
+	ExecutedAction  string    `json:"executed_action"` // The consensus action that was traded
+	Leverage        int       `json:"leverage"`
+	EntryPrice      float64   `json:"entry_price"`
+	Executed        bool      `json:"executed"`
+	TraderID        string    `json:"trader_id"` // Trader that executed the consensus
+	Resolved        bool      `json:"resolved"`
+	ExitPrice       float64   `json:"exit_price"`       // The position's average exit price
+	ReturnPct       float64   `json:"return_pct"`       // Leveraged price move of the executed action
+	Hit             bool      `json:"hit"`              // Whether the participant's own call was right
+	PnLContribution float64   `json:"pnl_contribution"` // Share of the position's realized PnL (USDT) when the participant backed the executed action
+	Brier           float64   `json:"brier"`            // (confidence - hit)^2
+	CreatedAt       time.Time `json:"created_at"`
+	ResolvedAt      time.Time `json:"resolved_at,omitempty"`
+}
+
+// DebateParticipantStats is a participant's (model + personality) resolved track record
+type DebateParticipantStats struct {
+	AIModelID       string  `json:"ai_model_id"`
+	AIModelName     string  `json:"ai_model_name"`
+	Personality     string  `json:"personality"`
+	Predictions     int     `json:"predictions"`
+	Hits            int     `json:"hits"`
+	HitRate         float64 `json:"hit_rate"`
+	PnLContribution float64 `json:"pnl_contribution"` // Realized PnL (USDT) credited to the participant
+	BrierScore      float64 `json:"brier_score"`      // Mean, lower is better calibrated
+}
+
+// SaveOutcomes records participants' votes at consensus, before execution is known
+func (s *DebateStore) SaveOutcomes(outcomes []*DebateOutcome) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, o := range outcomes {
+		if o.CreatedAt.IsZero() {
+			o.CreatedAt = now
+		}
+		res, err := tx.Exec(`
+			INSERT INTO debate_outcomes (
+				session_id, cycle, symbol, ai_model_id, ai_model_name, personality, action, confidence,
+				executed_action, leverage, entry_price, executed, created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, o.SessionID, o.Cycle, o.Symbol, o.AIModelID, o.AIModelName, o.Personality, o.Action, o.Confidence,
+			o.ExecutedAction, o.Leverage, o.EntryPrice, o.Executed, o.CreatedAt)
+		if err != nil {
+			return err
+		}
+		o.ID, _ = res.LastInsertId()
+	}
+	return tx.Commit()
+}
+
+// MarkOutcomesExecuted flags a cycle's outcomes for a symbol as traded by a
+// trader, so they get scored when its position closes
+func (s *DebateStore) MarkOutcomesExecuted(sessionID string, cycle int, symbol, traderID string) error {
+	_, err := db.Exec(`
+		UPDATE debate_outcomes SET executed = 1, trader_id = ?
+		WHERE session_id = ? AND cycle = ? AND symbol = ?
+	`, traderID, sessionID, cycle, symbol)
+	return err
+}
+
+// ListPendingOutcomes returns a trader's executed, unresolved outcomes for a symbol recorded before the cutoff
+func (s *DebateStore) ListPendingOutcomes(traderID, symbol string, before time.Time) ([]*DebateOutcome, error) {
+	rows, err := db.Query(`
+		SELECT id, session_id, cycle, symbol, ai_model_id, ai_model_name, personality, action, confidence,
+			executed_action, leverage, entry_price, trader_id, created_at
+		FROM debate_outcomes
+		WHERE trader_id = ? AND symbol = ? AND executed = 1 AND resolved = 0 AND created_at <= ?
+		ORDER BY id ASC
+	`, traderID, symbol, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var outcomes []*DebateOutcome
+	for rows.Next() {
+		o := DebateOutcome{Executed: true}
+		var name sql.NullString
+		if err := rows.Scan(&o.ID, &o.SessionID, &o.Cycle, &o.Symbol, &o.AIModelID, &name, &o.Personality,
+			&o.Action, &o.Confidence, &o.ExecutedAction, &o.Leverage, &o.EntryPrice, &o.TraderID, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		o.AIModelName = name.String
+		outcomes = append(outcomes, &o)
+	}
+	return outcomes, rows.Err()
+}
+
+// ResolveOutcome stores the scored result of an outcome
+func (s *DebateStore) ResolveOutcome(o *DebateOutcome) error {
+	o.Resolved = true
+	if o.ResolvedAt.IsZero() {
+		o.ResolvedAt = time.Now()
+	}
+	_, err := db.Exec(`
+		UPDATE debate_outcomes SET
+			resolved = 1, exit_price = ?, return_pct = ?, hit = ?, pnl_contribution = ?, brier = ?, resolved_at = ?
+		WHERE id = ?
+	`, o.ExitPrice, o.ReturnPct, o.Hit, o.PnLContribution, o.Brier, o.ResolvedAt, o.ID)
+	return err
+}
+
+// PruneOutcomes deletes outcomes that were never executed and are older than the cutoff
+func (s *DebateStore) PruneOutcomes(before time.Time) error {
+	_, err := db.Exec(`DELETE FROM debate_outcomes WHERE executed = 0 AND created_at < ?`, before)
+	return err
+}
+
+// ParticipantStats aggregates resolved outcomes per model and personality
+func (s *DebateStore) ParticipantStats() ([]*DebateParticipantStats, error) {
+	rows, err := db.Query(`
+		SELECT ai_model_id, MAX(ai_model_name), personality, COUNT(*),
+			SUM(CASE WHEN hit THEN 1 ELSE 0 END), COALESCE(SUM(pnl_contribution), 0), COALESCE(AVG(brier), 0)
+		FROM debate_outcomes
+		WHERE resolved = 1
+		GROUP BY ai_model_id, personality
+		ORDER BY ai_model_id, personality
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*DebateParticipantStats
+	for rows.Next() {
+		var st DebateParticipantStats
+		var name sql.NullString
+		if err := rows.Scan(&st.AIModelID, &name, &st.Personality, &st.Predictions, &st.Hits,
+			&st.PnLContribution, &st.BrierScore); err != nil {
+			return nil, err
+		}
+		st.AIModelName = name.String
+		if st.Predictions > 0 {
+			st.HitRate = float64(st.Hits) / float64(st.Predictions)
+		}
+		stats = append(stats, &st)
+	}
+	return stats, rows.Err()
+}
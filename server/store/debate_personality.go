@@ -0,0 +1,89 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DebatePersonality is a user-defined debate persona. Participants reference
+// it by ID in place of a built-in personality.
+type DebatePersonality struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`     // The role, shown to the model and in the UI
+	PromptFragment string    `json:"prompt_fragment"` // Extra instructions added to the system prompt
+	Color          string    `json:"color"`
+	Emoji          string    `json:"emoji"`
+	Bias           string    `json:"bias"`           // bullish | bearish | neutral
+	RiskTolerance  string    `json:"risk_tolerance"` // conservative | moderate | aggressive
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// SavePersonality inserts or updates a custom personality
+func (s *DebateStore) SavePersonality(p *DebatePersonality) error {
+	p.UpdatedAt = time.Now()
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = p.UpdatedAt
+	}
+	_, err := db.Exec(`
+		INSERT INTO debate_personalities (id, name, description, prompt_fragment, color, emoji, bias, risk_tolerance, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			description = excluded.description,
+			prompt_fragment = excluded.prompt_fragment,
+			color = excluded.color,
+			emoji = excluded.emoji,
+			bias = excluded.bias,
+			risk_tolerance = excluded.risk_tolerance,
+			updated_at = excluded.updated_at
+	`, p.ID, p.Name, p.Description, p.PromptFragment, p.Color, p.Emoji, p.Bias, p.RiskTolerance, p.CreatedAt, p.UpdatedAt)
+	return err
+}
+
+// GetPersonality returns a custom personality, or nil if it doesn't exist
+func (s *DebateStore) GetPersonality(id string) (*DebatePersonality, error) {
+	var p DebatePersonality
+	err := db.QueryRow(`
+		SELECT id, name, description, prompt_fragment, color, emoji, bias, risk_tolerance, created_at, updated_at
+		FROM debate_personalities WHERE id = ?
+	`, id).Scan(&p.ID, &p.Name, &p.Description, &p.PromptFragment, &p.Color, &p.Emoji, &p.Bias,
+		&p.RiskTolerance, &p.CreatedAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListPersonalities returns all custom personalities by name
+func (s *DebateStore) ListPersonalities() ([]*DebatePersonality, error) {
+	rows, err := db.Query(`
+		SELECT id, name, description, prompt_fragment, color, emoji, bias, risk_tolerance, created_at, updated_at
+		FROM debate_personalities ORDER BY name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var personalities []*DebatePersonality
+	for rows.Next() {
+		var p DebatePersonality
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.PromptFragment, &p.Color, &p.Emoji, &p.Bias,
+			&p.RiskTolerance, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		personalities = append(personalities, &p)
+	}
+	return personalities, rows.Err()
+}
+
+// DeletePersonality removes a custom personality
+func (s *DebateStore) DeletePersonality(id string) error {
+	_, err := db.Exec(`DELETE FROM debate_personalities WHERE id = ?`, id)
+	return err
+}
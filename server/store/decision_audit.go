@@ -0,0 +1,370 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Guards checked between the AI's decision and the exchange, in the order
+// the engine runs them. A blocking guard's name is the audit's block reason.
+const (
+	GuardAIResponse     = "ai_response"     // The AI replied with a decision that parsed
+	GuardCopyTrading    = "copy_trading"    // Trader isn't in copy trading mode (external decisions)
+	GuardTradingPair    = "trading_pair"    // The symbol is one of the trader's pairs (external decisions)
+	GuardTradingPause   = "trading_pause"   // Trading isn't paused by the daily loss limit
+	GuardBrackets       = "brackets"        // Absolute SL/TP are on the right side of the price
+	GuardPositionLeg    = "position_leg"    // The action maps to a position leg
+	GuardConfidence     = "confidence"      // Confidence reaches the minimum
+	GuardMultiTF        = "multi_tf"        // Higher timeframe trend agrees with a new position
+	GuardMaxPositions   = "max_positions"   // Room for another position
+	GuardRiskReward     = "risk_reward"     // SL/TP meet the minimum risk/reward
+	GuardValueRatio     = "value_ratio"     // Position value within the equity ratio
+	GuardCalibratedSize = "calibrated_size" // Size scaled by calibrated confidence
+	GuardMinSize        = "min_size"        // Position reaches the exchange minimum
+	GuardSymbolFilters  = "symbol_filters"  // Quantity and SL/TP fit the symbol's filters
+	GuardPosition       = "position"        // No conflicting position, or one to close
+	GuardNoiseZone      = "noise_zone"      // A close isn't premature
+	GuardEntryOrder     = "entry_order"     // The entry order was placed (limit entries may time out)
+)
+
+// BlockReasonError marks a decision that no guard blocked but that failed,
+// e.g. the AI call or an order
+const BlockReasonError = "error"
+
+// Guard verdicts
+const (
+	VerdictPass   = "pass"
+	VerdictBlock  = "block"
+	VerdictAdjust = "adjust" // Passed after changing the order, e.g. a smaller size
+)
+
+// Order purposes in an audit
+const (
+	AuditOrderEntry      = "entry"
+	AuditOrderClose      = "close"
+	AuditOrderStopLoss   = "stop_loss"
+	AuditOrderTakeProfit = "take_profit"
+)
+
+// GuardVerdict is one guard's result for a decision
+type GuardVerdict struct {
+	Guard   string `json:"guard"`
+	Verdict string `json:"verdict"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// AuditOrder is an order placed for a decision
+type AuditOrder struct {
+	Purpose  string  `json:"purpose"`
+	OrderID  int64   `json:"order_id"`
+	Side     string  `json:"side"`
+	Type     string  `json:"type"`
+	Quantity float64 `json:"quantity"`
+	Price    float64 `json:"price"`
+	Status   string  `json:"status,omitempty"`
+}
+
+// DecisionAudit is the full record of one symbol's decision in a cycle: what
+// the AI was given, what it answered, every guard's verdict and the orders
+// that resulted. A nil audit records nothing.
+type DecisionAudit struct {
+	ID                int64     `json:"id"`
+	TraderID          string    `json:"trader_id"`
+	DecisionID        int64     `json:"decision_id"` // The cycle's row in decisions
+	Symbol            string    `json:"symbol"`
+	Timestamp         time.Time `json:"timestamp"`
+	Source            string    `json:"source"`   // single_model, debate or the external caller
+	AIModel           string    `json:"ai_model"` // Model, or "debate"
+	DebateSessionID   string    `json:"debate_session_id,omitempty"`
+	StrategyVersionID int64     `json:"strategy_version_id"`
+
+	// Inputs
+	SystemPrompt   string          `json:"system_prompt,omitempty"`
+	UserPrompt     string          `json:"user_prompt,omitempty"`
+	Prompts        json.RawMessage `json:"prompts,omitempty"` // Template versions that rendered the prompts
+	MarketSnapshot string          `json:"market_snapshot,omitempty"`
+
+	// AI output
+	RawOutput            string          `json:"raw_output,omitempty"`
+	ParsePath            string          `json:"parse_path,omitempty"`
+	Decision             json.RawMessage `json:"decision,omitempty"` // The decision as acted on
+	Action               string          `json:"action"`
+	Confidence           float64         `json:"confidence"`
+	CalibratedConfidence float64         `json:"calibrated_confidence,omitempty"`
+
+	// Result
+	Guards      []GuardVerdict `json:"guards"`
+	BlockReason string         `json:"block_reason,omitempty"` // First guard that blocked
+	Error       string         `json:"error,omitempty"`
+	Executed    bool           `json:"executed"`
+	Orders      []AuditOrder   `json:"orders"`
+	RealizedPnL float64        `json:"realized_pnl"`
+}
+
+// Pass records a guard that let the decision through
+func (a *DecisionAudit) Pass(guard, format string, args ...interface{}) {
+	a.addGuard(guard, VerdictPass, format, args...)
+}
+
+// Adjust records a guard that let the decision through after changing it
+func (a *DecisionAudit) Adjust(guard, format string, args ...interface{}) {
+	a.addGuard(guard, VerdictAdjust, format, args...)
+}
+
+// Block records a guard that stopped the decision
+func (a *DecisionAudit) Block(guard, format string, args ...interface{}) {
+	if a != nil && a.BlockReason == "" {
+		a.BlockReason = guard
+	}
+	a.addGuard(guard, VerdictBlock, format, args...)
+}
+
+func (a *DecisionAudit) addGuard(guard, verdict, format string, args ...interface{}) {
+	if a == nil {
+		return
+	}
+	a.Guards = append(a.Guards, GuardVerdict{Guard: guard, Verdict: verdict, Detail: fmt.Sprintf(format, args...)})
+}
+
+// AddOrder records an order placed for the decision
+func (a *DecisionAudit) AddOrder(o AuditOrder) {
+	if a == nil {
+		return
+	}
+	a.Orders = append(a.Orders, o)
+}
+
+// AuditFilter selects decision audits. Zero fields match everything.
+type AuditFilter struct {
+	TraderID    string
+	Symbol      string
+	BlockReason string // A guard name, or "none" for decisions nothing blocked
+	Executed    *bool
+	From        time.Time
+	To          time.Time
+}
+
+func (f AuditFilter) where() (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if f.TraderID != "" {
+		conds = append(conds, "trader_id = ?")
+		args = append(args, f.TraderID)
+	}
+	if f.Symbol != "" {
+		conds = append(conds, "symbol = ?")
+		args = append(args, f.Symbol)
+	}
+	if f.BlockReason == "none" {
+		conds = append(conds, "block_reason = ''")
+	} else if f.BlockReason != "" {
+		conds = append(conds, "block_reason = ?")
+		args = append(args, f.BlockReason)
+	}
+	if f.Executed != nil {
+		conds = append(conds, "executed = ?")
+		args = append(args, *f.Executed)
+	}
+	if !f.From.IsZero() {
+		conds = append(conds, "timestamp >= ?")
+		args = append(args, f.From)
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "timestamp < ?")
+		args = append(args, f.To)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+// DecisionAuditStore persists decision audits
+type DecisionAuditStore struct{}
+
+func NewDecisionAuditStore() *DecisionAuditStore {
+	return &DecisionAuditStore{}
+}
+
+// InitTables creates the decision audit table
+func (s *DecisionAuditStore) InitTables() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS decision_audits (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		trader_id TEXT NOT NULL,
+		decision_id INTEGER NOT NULL DEFAULT 0,
+		symbol TEXT NOT NULL,
+		timestamp DATETIME NOT NULL,
+		source TEXT NOT NULL DEFAULT '',
+		ai_model TEXT NOT NULL DEFAULT '',
+		debate_session_id TEXT NOT NULL DEFAULT '',
+		strategy_version_id INTEGER NOT NULL DEFAULT 0,
+		system_prompt TEXT NOT NULL DEFAULT '',
+		user_prompt TEXT NOT NULL DEFAULT '',
+		prompts TEXT NOT NULL DEFAULT '',
+		market_snapshot TEXT NOT NULL DEFAULT '',
+		raw_output TEXT NOT NULL DEFAULT '',
+		parse_path TEXT NOT NULL DEFAULT '',
+		decision TEXT NOT NULL DEFAULT '',
+		action TEXT NOT NULL DEFAULT '',
+		confidence REAL NOT NULL DEFAULT 0,
+		calibrated_confidence REAL NOT NULL DEFAULT 0,
+		guards TEXT NOT NULL DEFAULT '[]',
+		block_reason TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		executed BOOLEAN NOT NULL DEFAULT 0,
+		orders TEXT NOT NULL DEFAULT '[]',
+		realized_pnl REAL NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_decision_audits_trader ON decision_audits(trader_id, timestamp);
+	CREATE INDEX IF NOT EXISTS idx_decision_audits_symbol ON decision_audits(trader_id, symbol, timestamp);
+	CREATE INDEX IF NOT EXISTS idx_decision_audits_block ON decision_audits(trader_id, block_reason);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// Create saves an audit and sets its ID
+func (s *DecisionAuditStore) Create(a *DecisionAudit) error {
+	if a.Timestamp.IsZero() {
+		a.Timestamp = time.Now()
+	}
+	guards, err := json.Marshal(nonNil(a.Guards))
+	if err != nil {
+		return err
+	}
+	orders, err := json.Marshal(nonNil(a.Orders))
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`
+		INSERT INTO decision_audits (trader_id, decision_id, symbol, timestamp, source, ai_model, debate_session_id,
+			strategy_version_id, system_prompt, user_prompt, prompts, market_snapshot, raw_output, parse_path,
+			decision, action, confidence, calibrated_confidence, guards, block_reason, error, executed, orders,
+			realized_pnl)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, a.TraderID, a.DecisionID, a.Symbol, a.Timestamp, a.Source, a.AIModel, a.DebateSessionID,
+		a.StrategyVersionID, a.SystemPrompt, a.UserPrompt, string(a.Prompts), a.MarketSnapshot, a.RawOutput,
+		a.ParsePath, string(a.Decision), a.Action, a.Confidence, a.CalibratedConfidence, string(guards),
+		a.BlockReason, a.Error, a.Executed, string(orders), a.RealizedPnL)
+	if err != nil {
+		return fmt.Errorf("failed to save decision audit: %w", err)
+	}
+	a.ID, _ = res.LastInsertId()
+	return nil
+}
+
+// nonNil keeps empty lists as [] rather than null in the stored JSON
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// List returns matching audits, newest first. Prompts and raw output are left
+// out unless full is set; Get always includes them.
+func (s *DecisionAuditStore) List(f AuditFilter, limit int, full bool) ([]*DecisionAudit, error) {
+	columns := "'', '', '', ''"
+	if full {
+		columns = "system_prompt, user_prompt, market_snapshot, raw_output"
+	}
+	where, args := f.where()
+	args = append(args, limit)
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT id, trader_id, decision_id, symbol, timestamp, source, ai_model, debate_session_id,
+			strategy_version_id, %s, prompts, parse_path, decision, action, confidence,
+			calibrated_confidence, guards, block_reason, error, executed, orders, realized_pnl
+		FROM decision_audits %s
+		ORDER BY timestamp DESC, id DESC LIMIT ?
+	`, columns, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	audits := make([]*DecisionAudit, 0)
+	for rows.Next() {
+		a, err := scanDecisionAudit(rows)
+		if err != nil {
+			return nil, err
+		}
+		audits = append(audits, a)
+	}
+	return audits, rows.Err()
+}
+
+// Get returns one audit with its prompts and raw output
+func (s *DecisionAuditStore) Get(id int64) (*DecisionAudit, error) {
+	row := db.QueryRow(`
+		SELECT id, trader_id, decision_id, symbol, timestamp, source, ai_model, debate_session_id,
+			strategy_version_id, system_prompt, user_prompt, market_snapshot, raw_output, prompts, parse_path,
+			decision, action, confidence, calibrated_confidence, guards, block_reason, error, executed, orders,
+			realized_pnl
+		FROM decision_audits WHERE id = ?
+	`, id)
+	return scanDecisionAudit(row)
+}
+
+// BlockReasonCount is how often a guard blocked decisions
+type BlockReasonCount struct {
+	BlockReason string `json:"block_reason"`
+	Count       int    `json:"count"`
+}
+
+// CountBlockReasons counts matching audits by the guard that blocked them,
+// most frequent first. Decisions nothing blocked are left out.
+func (s *DecisionAuditStore) CountBlockReasons(f AuditFilter) ([]BlockReasonCount, error) {
+	where, args := f.where()
+	if where == "" {
+		where = "WHERE block_reason != ''"
+	} else {
+		where += " AND block_reason != ''"
+	}
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT block_reason, COUNT(*) FROM decision_audits %s
+		GROUP BY block_reason ORDER BY COUNT(*) DESC, block_reason
+	`, where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]BlockReasonCount, 0)
+	for rows.Next() {
+		var c BlockReasonCount
+		if err := rows.Scan(&c.BlockReason, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+func scanDecisionAudit(row rowScanner) (*DecisionAudit, error) {
+	var a DecisionAudit
+	var prompts, decision, guards, orders string
+	err := row.Scan(&a.ID, &a.TraderID, &a.DecisionID, &a.Symbol, &a.Timestamp, &a.Source, &a.AIModel,
+		&a.DebateSessionID, &a.StrategyVersionID, &a.SystemPrompt, &a.UserPrompt, &a.MarketSnapshot,
+		&a.RawOutput, &prompts, &a.ParsePath, &decision, &a.Action, &a.Confidence, &a.CalibratedConfidence,
+		&guards, &a.BlockReason, &a.Error, &a.Executed, &orders, &a.RealizedPnL)
+	if err != nil {
+		return nil, err
+	}
+	if prompts != "" {
+		a.Prompts = json.RawMessage(prompts)
+	}
+	if decision != "" {
+		a.Decision = json.RawMessage(decision)
+	}
+	if err := json.Unmarshal([]byte(guards), &a.Guards); err != nil {
+		return nil, fmt.Errorf("failed to parse guards of audit %d: %w", a.ID, err)
+	}
+	if err := json.Unmarshal([]byte(orders), &a.Orders); err != nil {
+		return nil, fmt.Errorf("failed to parse orders of audit %d: %w", a.ID, err)
+	}
+	return &a, nil
+}
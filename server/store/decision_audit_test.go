@@ -0,0 +1,99 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecisionAuditStore_FilterAndRoundTrip(t *testing.T) {
+	if err := Init(t.TempDir()); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Close()
+
+	s := NewDecisionAuditStore()
+	start := time.Now().Add(-time.Hour)
+
+	executed := &DecisionAudit{TraderID: "t1", Symbol: "BTCUSDT", Timestamp: start, UserPrompt: "prompt", RawOutput: "{}"}
+	executed.Pass(GuardConfidence, "%d >= %d", 80, 70)
+	executed.Adjust(GuardCalibratedSize, "size %.0f -> %.0f", 100.0, 60.0)
+	executed.AddOrder(AuditOrder{Purpose: AuditOrderEntry, OrderID: 1, Side: "BUY", Type: "MARKET", Quantity: 0.01, Price: 60000})
+	executed.Executed = true
+
+	lowConfidence := &DecisionAudit{TraderID: "t1", Symbol: "ETHUSDT", Timestamp: start.Add(time.Minute)}
+	lowConfidence.Block(GuardConfidence, "%d < %d", 50, 70)
+	lowConfidence.Block(GuardMultiTF, "later blocks don't replace the reason")
+
+	trend := &DecisionAudit{TraderID: "t1", Symbol: "BTCUSDT", Timestamp: start.Add(2 * time.Minute)}
+	trend.Block(GuardMultiTF, "4h trend is down")
+	other := &DecisionAudit{TraderID: "t2", Symbol: "BTCUSDT", Timestamp: start}
+	other.Block(GuardConfidence, "other trader")
+
+	for _, a := range []*DecisionAudit{executed, lowConfidence, trend, other} {
+		if err := s.Create(a); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if lowConfidence.BlockReason != GuardConfidence {
+		t.Errorf("BlockReason = %q, want the first block %q", lowConfidence.BlockReason, GuardConfidence)
+	}
+
+	yes, no := true, false
+	tests := []struct {
+		name   string
+		filter AuditFilter
+		want   []int64
+	}{
+		{"trader, newest first", AuditFilter{TraderID: "t1"}, []int64{trend.ID, lowConfidence.ID, executed.ID}},
+		{"symbol", AuditFilter{TraderID: "t1", Symbol: "BTCUSDT"}, []int64{trend.ID, executed.ID}},
+		{"block reason", AuditFilter{TraderID: "t1", BlockReason: GuardConfidence}, []int64{lowConfidence.ID}},
+		{"not blocked", AuditFilter{TraderID: "t1", BlockReason: "none"}, []int64{executed.ID}},
+		{"executed", AuditFilter{TraderID: "t1", Executed: &yes}, []int64{executed.ID}},
+		{"not executed", AuditFilter{TraderID: "t1", Executed: &no}, []int64{trend.ID, lowConfidence.ID}},
+		{"time range", AuditFilter{TraderID: "t1", From: start.Add(30 * time.Second), To: start.Add(90 * time.Second)}, []int64{lowConfidence.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audits, err := s.List(tt.filter, 10, false)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(audits) != len(tt.want) {
+				t.Fatalf("List() returned %d audits, want %d", len(audits), len(tt.want))
+			}
+			for i, a := range audits {
+				if a.ID != tt.want[i] {
+					t.Errorf("List()[%d].ID = %d, want %d", i, a.ID, tt.want[i])
+				}
+			}
+		})
+	}
+
+	got, err := s.Get(executed.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.UserPrompt != "prompt" || got.RawOutput != "{}" || !got.Executed {
+		t.Errorf("Get() = %+v, want the prompt, raw output and executed flag", got)
+	}
+	if len(got.Guards) != 2 || got.Guards[1].Verdict != VerdictAdjust || got.Guards[1].Detail != "size 100 -> 60" {
+		t.Errorf("Guards = %+v", got.Guards)
+	}
+	if len(got.Orders) != 1 || got.Orders[0].OrderID != 1 || got.Orders[0].Price != 60000 {
+		t.Errorf("Orders = %+v", got.Orders)
+	}
+
+	// The list leaves the prompts out unless asked
+	audits, err := s.List(AuditFilter{TraderID: "t1", Executed: &yes}, 10, false)
+	if err != nil || len(audits) != 1 || audits[0].UserPrompt != "" {
+		t.Errorf("List(full=false) = %+v, %v, want no prompt", audits, err)
+	}
+
+	counts, err := s.CountBlockReasons(AuditFilter{TraderID: "t1"})
+	if err != nil {
+		t.Fatalf("CountBlockReasons() error = %v", err)
+	}
+	if len(counts) != 2 || counts[0].Count != 1 || counts[1].Count != 1 {
+		t.Errorf("CountBlockReasons() = %+v, want one confidence and one multi_tf block", counts)
+	}
+}
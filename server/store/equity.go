@@ -14,6 +14,7 @@ type EquitySnapshot struct {
 	UnrealizedPnL float64   `json:"unrealized_pnl"`
 	PositionCount int       `json:"position_count"`
 	MarginUsagePct float64  `json:"margin_usage_pct"`
+	StrategyVersionID int64 `json:"strategy_version_id"` // Strategy version the trader was running
 }
 
 // EquityStore manages equity snapshot data
@@ -43,8 +44,10 @@ func (s *EquityStore) InitTables() error {
 	CREATE INDEX IF NOT EXISTS idx_equity_timestamp ON trader_equity_snapshots(timestamp);
 	CREATE INDEX IF NOT EXISTS idx_equity_trader_time ON trader_equity_snapshots(trader_id, timestamp);
 	`
-	_, err := db.Exec(query)
-	return err
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+	return addColumn("trader_equity_snapshots", "strategy_version_id", "INTEGER DEFAULT 0")
 }
 
 // Save records an equity snapshot
@@ -52,12 +55,12 @@ func (s *EquityStore) Save(snapshot *EquitySnapshot) error {
 	query := `
 	INSERT INTO trader_equity_snapshots (
 		trader_id, timestamp, total_equity, balance,
-		unrealized_pnl, position_count, margin_usage_pct
-	) VALUES (?, ?, ?, ?, ?, ?, ?)
+		unrealized_pnl, position_count, margin_usage_pct, strategy_version_id
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	_, err := db.Exec(query,
 		snapshot.TraderID, snapshot.Timestamp, snapshot.TotalEquity, snapshot.Balance,
-		snapshot.UnrealizedPnL, snapshot.PositionCount, snapshot.MarginUsagePct,
+		snapshot.UnrealizedPnL, snapshot.PositionCount, snapshot.MarginUsagePct, snapshot.StrategyVersionID,
 	)
 	return err
 }
@@ -67,7 +70,8 @@ func (s *EquityStore) GetLatest(traderID string, limit int) ([]EquitySnapshot, e
 	// Get in reverse order (newest first), then reverse for chronological
 	query := `
 	SELECT id, trader_id, timestamp, total_equity, COALESCE(balance, 0),
-		COALESCE(unrealized_pnl, 0), COALESCE(position_count, 0), COALESCE(margin_usage_pct, 0)
+		COALESCE(unrealized_pnl, 0), COALESCE(position_count, 0), COALESCE(margin_usage_pct, 0),
+		COALESCE(strategy_version_id, 0)
 	FROM trader_equity_snapshots
 	WHERE trader_id = ?
 	ORDER BY timestamp DESC
@@ -84,7 +88,7 @@ func (s *EquityStore) GetLatest(traderID string, limit int) ([]EquitySnapshot, e
 		var s EquitySnapshot
 		err := rows.Scan(
 			&s.ID, &s.TraderID, &s.Timestamp, &s.TotalEquity, &s.Balance,
-			&s.UnrealizedPnL, &s.PositionCount, &s.MarginUsagePct,
+			&s.UnrealizedPnL, &s.PositionCount, &s.MarginUsagePct, &s.StrategyVersionID,
 		)
 		if err != nil {
 			return nil, err
@@ -104,7 +108,8 @@ func (s *EquityStore) GetLatest(traderID string, limit int) ([]EquitySnapshot, e
 func (s *EquityStore) GetByTimeRange(traderID string, start, end time.Time) ([]EquitySnapshot, error) {
 	query := `
 	SELECT id, trader_id, timestamp, total_equity, COALESCE(balance, 0),
-		COALESCE(unrealized_pnl, 0), COALESCE(position_count, 0), COALESCE(margin_usage_pct, 0)
+		COALESCE(unrealized_pnl, 0), COALESCE(position_count, 0), COALESCE(margin_usage_pct, 0),
+		COALESCE(strategy_version_id, 0)
 	FROM trader_equity_snapshots
 	WHERE trader_id = ? AND timestamp BETWEEN ? AND ?
 	ORDER BY timestamp ASC
@@ -120,7 +125,7 @@ func (s *EquityStore) GetByTimeRange(traderID string, start, end time.Time) ([]E
 		var s EquitySnapshot
 		err := rows.Scan(
 			&s.ID, &s.TraderID, &s.Timestamp, &s.TotalEquity, &s.Balance,
-			&s.UnrealizedPnL, &s.PositionCount, &s.MarginUsagePct,
+			&s.UnrealizedPnL, &s.PositionCount, &s.MarginUsagePct, &s.StrategyVersionID,
 		)
 		if err != nil {
 			return nil, err
@@ -135,7 +140,8 @@ func (s *EquityStore) GetByTimeRange(traderID string, start, end time.Time) ([]E
 func (s *EquityStore) GetAllTradersLatest() ([]EquitySnapshot, error) {
 	query := `
 	SELECT e.id, e.trader_id, e.timestamp, e.total_equity, COALESCE(e.balance, 0),
-		COALESCE(e.unrealized_pnl, 0), COALESCE(e.position_count, 0), COALESCE(e.margin_usage_pct, 0)
+		COALESCE(e.unrealized_pnl, 0), COALESCE(e.position_count, 0), COALESCE(e.margin_usage_pct, 0),
+		COALESCE(e.strategy_version_id, 0)
 	FROM trader_equity_snapshots e
 	INNER JOIN (
 		SELECT trader_id, MAX(timestamp) as max_ts
@@ -155,7 +161,7 @@ func (s *EquityStore) GetAllTradersLatest() ([]EquitySnapshot, error) {
 		var s EquitySnapshot
 		err := rows.Scan(
 			&s.ID, &s.TraderID, &s.Timestamp, &s.TotalEquity, &s.Balance,
-			&s.UnrealizedPnL, &s.PositionCount, &s.MarginUsagePct,
+			&s.UnrealizedPnL, &s.PositionCount, &s.MarginUsagePct, &s.StrategyVersionID,
 		)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,167 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Lesson sources
+const (
+	LessonSourceStats = "stats" // Summary written from the trade statistics
+	LessonSourceLLM   = "llm"   // Summary written by an AI summarization call
+)
+
+// TradeLesson is what a trader's closed trades taught about one symbol,
+// direction and setup. Lessons are rebuilt by the reflection pass; the ID of
+// a symbol/side/setup stays the same across rebuilds.
+type TradeLesson struct {
+	ID           int64     `json:"id"`
+	TraderID     string    `json:"trader_id"`
+	Symbol       string    `json:"symbol"`
+	Side         string    `json:"side"`  // long or short
+	Setup        string    `json:"setup"` // Entry setup classification
+	Trades       int       `json:"trades"`
+	Wins         int       `json:"wins"`
+	StopOuts     int       `json:"stop_outs"`   // Trades closed by the stop loss
+	LossStreak   int       `json:"loss_streak"` // Most recent trades lost in a row
+	TotalPnL     float64   `json:"total_pnl"`
+	AvgPnLPct    float64   `json:"avg_pnl_pct"` // Average price move captured, in %
+	LastExitTime time.Time `json:"last_exit_time"`
+	Summary      string    `json:"summary"`
+	Source       string    `json:"source"` // stats or llm
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// WinRate returns the share of winning trades in %
+func (l *TradeLesson) WinRate() float64 {
+	if l.Trades == 0 {
+		return 0
+	}
+	return float64(l.Wins) / float64(l.Trades) * 100
+}
+
+// TradeLessonStore persists reflection lessons
+type TradeLessonStore struct{}
+
+func NewTradeLessonStore() *TradeLessonStore {
+	return &TradeLessonStore{}
+}
+
+// InitTables creates the lesson table
+func (s *TradeLessonStore) InitTables() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS trade_lessons (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		trader_id TEXT NOT NULL,
+		symbol TEXT NOT NULL,
+		side TEXT NOT NULL,
+		setup TEXT NOT NULL DEFAULT '',
+		trades INTEGER NOT NULL DEFAULT 0,
+		wins INTEGER NOT NULL DEFAULT 0,
+		stop_outs INTEGER NOT NULL DEFAULT 0,
+		loss_streak INTEGER NOT NULL DEFAULT 0,
+		total_pnl REAL NOT NULL DEFAULT 0,
+		avg_pnl_pct REAL NOT NULL DEFAULT 0,
+		last_exit_time DATETIME,
+		summary TEXT NOT NULL DEFAULT '',
+		source TEXT NOT NULL DEFAULT 'stats',
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(trader_id, symbol, side, setup)
+	);
+	CREATE INDEX IF NOT EXISTS idx_trade_lessons_trader ON trade_lessons(trader_id);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// Replace stores a trader's lessons from a reflection pass and removes the
+// ones the pass no longer produced. The lessons' IDs are set.
+func (s *TradeLessonStore) Replace(traderID string, lessons []*TradeLesson) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	keep := make([]interface{}, 0, len(lessons)+1)
+	keep = append(keep, traderID)
+	for _, l := range lessons {
+		l.TraderID = traderID
+		l.UpdatedAt = now
+		_, err := tx.Exec(`
+			INSERT INTO trade_lessons (trader_id, symbol, side, setup, trades, wins, stop_outs, loss_streak,
+				total_pnl, avg_pnl_pct, last_exit_time, summary, source, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(trader_id, symbol, side, setup) DO UPDATE SET
+				trades = excluded.trades, wins = excluded.wins, stop_outs = excluded.stop_outs,
+				loss_streak = excluded.loss_streak, total_pnl = excluded.total_pnl,
+				avg_pnl_pct = excluded.avg_pnl_pct, last_exit_time = excluded.last_exit_time,
+				summary = excluded.summary, source = excluded.source, updated_at = excluded.updated_at
+		`, traderID, l.Symbol, l.Side, l.Setup, l.Trades, l.Wins, l.StopOuts, l.LossStreak,
+			l.TotalPnL, l.AvgPnLPct, l.LastExitTime, l.Summary, l.Source, l.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save lesson %s %s: %w", l.Symbol, l.Side, err)
+		}
+		// LastInsertId isn't reliable for the update branch of an upsert
+		err = tx.QueryRow(`
+			SELECT id FROM trade_lessons WHERE trader_id = ? AND symbol = ? AND side = ? AND setup = ?
+		`, traderID, l.Symbol, l.Side, l.Setup).Scan(&l.ID)
+		if err != nil {
+			return err
+		}
+		keep = append(keep, l.ID)
+	}
+
+	query := "DELETE FROM trade_lessons WHERE trader_id = ?"
+	if len(lessons) > 0 {
+		query += " AND id NOT IN (?" + strings.Repeat(", ?", len(lessons)-1) + ")"
+	}
+	if _, err := tx.Exec(query, keep...); err != nil {
+		return fmt.Errorf("failed to remove old lessons: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListByTrader returns a trader's lessons, those backed by the most trades first
+func (s *TradeLessonStore) ListByTrader(traderID string) ([]*TradeLesson, error) {
+	rows, err := db.Query(`
+		SELECT id, trader_id, symbol, side, setup, trades, wins, stop_outs, loss_streak,
+			total_pnl, avg_pnl_pct, last_exit_time, summary, source, updated_at
+		FROM trade_lessons WHERE trader_id = ?
+		ORDER BY trades DESC, last_exit_time DESC
+	`, traderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lessons []*TradeLesson
+	for rows.Next() {
+		l, err := scanTradeLesson(rows)
+		if err != nil {
+			return nil, err
+		}
+		lessons = append(lessons, l)
+	}
+	return lessons, rows.Err()
+}
+
+func scanTradeLesson(row rowScanner) (*TradeLesson, error) {
+	var l TradeLesson
+	var lastExit, updatedAt sql.NullTime
+	err := row.Scan(&l.ID, &l.TraderID, &l.Symbol, &l.Side, &l.Setup, &l.Trades, &l.Wins, &l.StopOuts,
+		&l.LossStreak, &l.TotalPnL, &l.AvgPnLPct, &lastExit, &l.Summary, &l.Source, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastExit.Valid {
+		l.LastExitTime = lastExit.Time
+	}
+	if updatedAt.Valid {
+		l.UpdatedAt = updatedAt.Time
+	}
+	return &l, nil
+}
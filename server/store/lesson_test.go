@@ -0,0 +1,74 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTradeLessonStore_ReplaceKeepsIDs(t *testing.T) {
+	if err := Init(t.TempDir()); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Close()
+
+	s := NewTradeLessonStore()
+	btc := &TradeLesson{Symbol: "BTCUSDT", Side: "short", Setup: "counter_trend", Trades: 5, Summary: "v1", Source: LessonSourceStats}
+	eth := &TradeLesson{Symbol: "ETHUSDT", Side: "long", Setup: "with_trend", Trades: 3, Summary: "v1", Source: LessonSourceStats}
+	if err := s.Replace("t1", []*TradeLesson{btc, eth}); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	btcID := btc.ID
+
+	// The next pass still has BTC, no longer ETH
+	again := &TradeLesson{Symbol: "BTCUSDT", Side: "short", Setup: "counter_trend", Trades: 6, Summary: "v2", Source: LessonSourceLLM}
+	if err := s.Replace("t1", []*TradeLesson{again}); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	if err := s.Replace("t2", []*TradeLesson{{Symbol: "BTCUSDT", Side: "long", Trades: 1}}); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+
+	lessons, err := s.ListByTrader("t1")
+	if err != nil {
+		t.Fatalf("ListByTrader() error = %v", err)
+	}
+	if len(lessons) != 1 || lessons[0].ID != btcID || lessons[0].Trades != 6 || lessons[0].Summary != "v2" || lessons[0].Source != LessonSourceLLM {
+		t.Errorf("ListByTrader() = %+v, want the updated BTC lesson with ID %d", lessons, btcID)
+	}
+}
+
+func TestPositionStore_JournalRoundTrip(t *testing.T) {
+	if err := Init(t.TempDir()); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Close()
+
+	s := NewPositionStore()
+	id, err := s.Create(&TraderPosition{
+		TraderID: "t1", Symbol: "BTCUSDT", Side: "short", EntryQuantity: 1, Quantity: 1,
+		EntryPrice: 100, EntryTime: time.Now().Add(-time.Hour), Leverage: 5, Source: PositionSourceSync, Setup: "with_trend",
+		Confidence: 82, AIModel: "deepseek/deepseek-chat",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Open positions have NULL exit and close columns
+	open, err := s.GetOpenPositions("t1")
+	if err != nil || len(open) != 1 || open[0].Setup != "with_trend" {
+		t.Fatalf("GetOpenPositions() = %+v, %v", open, err)
+	}
+
+	if err := s.ClosePosition(id, 102, 0.1, -2, CloseReasonStopLoss); err != nil {
+		t.Fatalf("ClosePosition() error = %v", err)
+	}
+	closed, err := s.GetClosedPositions("t1", 10)
+	if err != nil || len(closed) != 1 {
+		t.Fatalf("GetClosedPositions() = %+v, %v", closed, err)
+	}
+	pos := closed[0]
+	if pos.CloseReason != CloseReasonStopLoss || pos.RealizedPnL != -2 || pos.ExitTime.IsZero() || pos.Setup != "with_trend" ||
+		pos.Confidence != 82 || pos.AIModel != "deepseek/deepseek-chat" {
+		t.Errorf("closed position = %+v", pos)
+	}
+}
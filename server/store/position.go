@@ -19,6 +19,19 @@ const (
 	PositionSourceSync   = "sync"
 )
 
+// Close reasons recorded on closed positions
+const (
+	CloseReasonAI           = "ai"            // The AI decided to close
+	CloseReasonStopLoss     = "stop_loss"     // Exchange stop-loss order filled
+	CloseReasonTakeProfit   = "take_profit"   // Exchange take-profit order filled
+	CloseReasonTrailingStop = "trailing_stop" // Trailing stop in the drawdown monitor
+	CloseReasonMaxHold      = "max_hold"      // Held longer than the strategy allows
+	CloseReasonLossCut      = "loss_cut"      // Smart loss cut
+	CloseReasonDrawdown     = "drawdown"      // Drawdown protection
+	CloseReasonRiskLimit    = "risk_limit"    // Account-level risk limit, e.g. daily loss
+	CloseReasonUntracked    = "untracked"     // Closed without fills we could match
+)
+
 // TraderPosition represents a complete position lifecycle
 type TraderPosition struct {
 	ID                 int64     `json:"id"`
@@ -41,7 +54,10 @@ type TraderPosition struct {
 	Leverage           int       `json:"leverage"`
 	Status             string    `json:"status"` // OPEN, CLOSED
 	CloseReason        string    `json:"close_reason"`
-	Source             string    `json:"source"` // system, manual, sync
+	Setup              string    `json:"setup"`      // Entry setup classification, see reflection.ClassifySetup
+	Confidence         int       `json:"confidence"` // AI confidence of the opening decision, 0 if unknown
+	AIModel            string    `json:"ai_model"`   // Model (or "debate") that made the opening decision
+	Source             string    `json:"source"`     // system, manual, sync
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
 }
@@ -146,8 +162,16 @@ func (s *PositionStore) InitTables() error {
 	CREATE INDEX IF NOT EXISTS idx_positions_status ON trader_positions(status);
 	CREATE INDEX IF NOT EXISTS idx_positions_exchange ON trader_positions(exchange_id, exchange_position_id);
 	`
-	_, err := db.Exec(query)
-	return err
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+	if err := addColumn("trader_positions", "setup", "TEXT DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := addColumn("trader_positions", "confidence", "INTEGER DEFAULT 0"); err != nil {
+		return err
+	}
+	return addColumn("trader_positions", "ai_model", "TEXT DEFAULT ''")
 }
 
 // Create creates a new position
@@ -156,13 +180,14 @@ func (s *PositionStore) Create(pos *TraderPosition) (int64, error) {
 	INSERT INTO trader_positions (
 		trader_id, exchange_id, exchange_type, exchange_position_id,
 		symbol, side, entry_quantity, quantity, entry_price,
-		entry_order_id, entry_time, leverage, status, source
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		entry_order_id, entry_time, leverage, status, source, setup, confidence, ai_model
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	result, err := db.Exec(query,
 		pos.TraderID, pos.ExchangeID, pos.ExchangeType, pos.ExchangePositionID,
 		pos.Symbol, pos.Side, pos.EntryQuantity, pos.Quantity, pos.EntryPrice,
-		pos.EntryOrderID, pos.EntryTime, pos.Leverage, PositionStatusOpen, pos.Source,
+		pos.EntryOrderID, pos.EntryTime, pos.Leverage, PositionStatusOpen, pos.Source, pos.Setup,
+		pos.Confidence, pos.AIModel,
 	)
 	if err != nil {
 		return 0, err
@@ -173,10 +198,11 @@ func (s *PositionStore) Create(pos *TraderPosition) (int64, error) {
 // GetOpenPositions returns all open positions for a trader
 func (s *PositionStore) GetOpenPositions(traderID string) ([]TraderPosition, error) {
 	query := `
-	SELECT id, trader_id, exchange_id, exchange_type, exchange_position_id,
+	SELECT id, trader_id, COALESCE(exchange_id, ''), COALESCE(exchange_type, ''), COALESCE(exchange_position_id, ''),
 		symbol, side, entry_quantity, quantity, entry_price, exit_price,
-		entry_order_id, exit_order_id, entry_time, COALESCE(exit_time, ''),
-		realized_pnl, fee, leverage, status, close_reason, source, created_at, updated_at
+		COALESCE(entry_order_id, ''), COALESCE(exit_order_id, ''), entry_time, COALESCE(exit_time, ''),
+		realized_pnl, fee, leverage, status, COALESCE(close_reason, ''), COALESCE(source, ''), COALESCE(setup, ''),
+		COALESCE(confidence, 0), COALESCE(ai_model, ''), created_at, updated_at
 	FROM trader_positions
 	WHERE trader_id = ? AND status = ?
 	ORDER BY entry_time DESC
@@ -193,10 +219,11 @@ func (s *PositionStore) GetOpenPositions(traderID string) ([]TraderPosition, err
 // GetClosedPositions returns closed positions for a trader
 func (s *PositionStore) GetClosedPositions(traderID string, limit int) ([]TraderPosition, error) {
 	query := `
-	SELECT id, trader_id, exchange_id, exchange_type, exchange_position_id,
+	SELECT id, trader_id, COALESCE(exchange_id, ''), COALESCE(exchange_type, ''), COALESCE(exchange_position_id, ''),
 		symbol, side, entry_quantity, quantity, entry_price, exit_price,
-		entry_order_id, exit_order_id, entry_time, COALESCE(exit_time, ''),
-		realized_pnl, fee, leverage, status, close_reason, source, created_at, updated_at
+		COALESCE(entry_order_id, ''), COALESCE(exit_order_id, ''), entry_time, COALESCE(exit_time, ''),
+		realized_pnl, fee, leverage, status, COALESCE(close_reason, ''), COALESCE(source, ''), COALESCE(setup, ''),
+		COALESCE(confidence, 0), COALESCE(ai_model, ''), created_at, updated_at
 	FROM trader_positions
 	WHERE trader_id = ? AND status = ?
 	ORDER BY exit_time DESC
@@ -221,20 +248,31 @@ func (s *PositionStore) scanPositions(rows *sql.Rows) ([]TraderPosition, error)
 			&pos.ID, &pos.TraderID, &pos.ExchangeID, &pos.ExchangeType, &pos.ExchangePositionID,
 			&pos.Symbol, &pos.Side, &pos.EntryQuantity, &pos.Quantity, &pos.EntryPrice, &pos.ExitPrice,
 			&pos.EntryOrderID, &pos.ExitOrderID, &pos.EntryTime, &exitTimeStr,
-			&pos.RealizedPnL, &pos.Fee, &pos.Leverage, &pos.Status, &pos.CloseReason, &pos.Source,
-			&pos.CreatedAt, &pos.UpdatedAt,
+			&pos.RealizedPnL, &pos.Fee, &pos.Leverage, &pos.Status, &pos.CloseReason, &pos.Source, &pos.Setup,
+			&pos.Confidence, &pos.AIModel, &pos.CreatedAt, &pos.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
 		if exitTimeStr != "" {
-			pos.ExitTime, _ = time.Parse(time.RFC3339, exitTimeStr)
+			pos.ExitTime = parsePositionTime(exitTimeStr)
 		}
 		positions = append(positions, pos)
 	}
 	return positions, nil
 }
 
+// parsePositionTime parses exit_time read through COALESCE, which the driver
+// returns as the stored text rather than a time
+func parsePositionTime(s string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999-07:00", time.RFC3339Nano, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
 // UpdatePositionQuantityAndPrice handles scale-in with weighted average
 func (s *PositionStore) UpdatePositionQuantityAndPrice(id int64, addQty, addPrice float64) error {
 	// Get current position
@@ -656,10 +694,11 @@ func calculateMaxDrawdown(pnls []float64) float64 {
 // GetOpenPositionBySymbol returns open position for a symbol
 func (s *PositionStore) GetOpenPositionBySymbol(traderID, symbol, side string) (*TraderPosition, error) {
 	query := `
-	SELECT id, trader_id, exchange_id, exchange_type, exchange_position_id,
+	SELECT id, trader_id, COALESCE(exchange_id, ''), COALESCE(exchange_type, ''), COALESCE(exchange_position_id, ''),
 		symbol, side, entry_quantity, quantity, entry_price, exit_price,
-		entry_order_id, exit_order_id, entry_time, COALESCE(exit_time, ''),
-		realized_pnl, fee, leverage, status, close_reason, source, created_at, updated_at
+		COALESCE(entry_order_id, ''), COALESCE(exit_order_id, ''), entry_time, COALESCE(exit_time, ''),
+		realized_pnl, fee, leverage, status, COALESCE(close_reason, ''), COALESCE(source, ''), COALESCE(setup, ''),
+		COALESCE(confidence, 0), COALESCE(ai_model, ''), created_at, updated_at
 	FROM trader_positions
 	WHERE trader_id = ? AND symbol = ? AND side = ? AND status = ?
 	`
@@ -669,8 +708,8 @@ func (s *PositionStore) GetOpenPositionBySymbol(traderID, symbol, side string) (
 		&pos.ID, &pos.TraderID, &pos.ExchangeID, &pos.ExchangeType, &pos.ExchangePositionID,
 		&pos.Symbol, &pos.Side, &pos.EntryQuantity, &pos.Quantity, &pos.EntryPrice, &pos.ExitPrice,
 		&pos.EntryOrderID, &pos.ExitOrderID, &pos.EntryTime, &exitTimeStr,
-		&pos.RealizedPnL, &pos.Fee, &pos.Leverage, &pos.Status, &pos.CloseReason, &pos.Source,
-		&pos.CreatedAt, &pos.UpdatedAt,
+		&pos.RealizedPnL, &pos.Fee, &pos.Leverage, &pos.Status, &pos.CloseReason, &pos.Source, &pos.Setup,
+		&pos.Confidence, &pos.AIModel, &pos.CreatedAt, &pos.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -679,7 +718,7 @@ func (s *PositionStore) GetOpenPositionBySymbol(traderID, symbol, side string) (
 		return nil, err
 	}
 	if exitTimeStr != "" {
-		pos.ExitTime, _ = time.Parse(time.RFC3339, exitTimeStr)
+		pos.ExitTime = parsePositionTime(exitTimeStr)
 	}
 	return &pos, nil
 }
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PromptTemplate is one saved version of a prompt template. Templates saved
+// here override the built-in and file templates of the same variant, name
+// and language when their version is higher.
+type PromptTemplate struct {
+	ID        int64     `json:"id"`
+	Variant   string    `json:"variant"`
+	Name      string    `json:"name"`
+	Language  string    `json:"language"` // Empty for the English/default text
+	Version   int       `json:"version"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PromptTemplateStore persists prompt template versions
+type PromptTemplateStore struct{}
+
+func NewPromptTemplateStore() *PromptTemplateStore {
+	return &PromptTemplateStore{}
+}
+
+// InitTables creates the prompt template table
+func (s *PromptTemplateStore) InitTables() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS prompt_templates (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		variant TEXT NOT NULL,
+		name TEXT NOT NULL,
+		language TEXT NOT NULL DEFAULT '',
+		version INTEGER NOT NULL,
+		body TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(variant, name, language, version)
+	);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// Save adds a new version of a template. The version is at least
+// t.Version and always above the latest saved one; t.ID and t.Version are
+// set to what was stored.
+func (s *PromptTemplateStore) Save(t *PromptTemplate) error {
+	var latest int
+	err := db.QueryRow(`
+		SELECT COALESCE(MAX(version), 0) FROM prompt_templates
+		WHERE variant = ? AND name = ? AND language = ?
+	`, t.Variant, t.Name, t.Language).Scan(&latest)
+	if err != nil {
+		return fmt.Errorf("failed to load latest version: %w", err)
+	}
+	if t.Version <= latest {
+		t.Version = latest + 1
+	}
+	t.CreatedAt = time.Now()
+
+	result, err := db.Exec(`
+		INSERT INTO prompt_templates (variant, name, language, version, body, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, t.Variant, t.Name, t.Language, t.Version, t.Body, t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save prompt template: %w", err)
+	}
+	t.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// List returns every saved template version, oldest first
+func (s *PromptTemplateStore) List() ([]*PromptTemplate, error) {
+	rows, err := db.Query(`
+		SELECT id, variant, name, language, version, body, created_at
+		FROM prompt_templates ORDER BY variant, name, language, version
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*PromptTemplate
+	for rows.Next() {
+		t, err := scanPromptTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// ListVersions returns the saved versions of one template, newest first
+func (s *PromptTemplateStore) ListVersions(variant, name, language string) ([]*PromptTemplate, error) {
+	rows, err := db.Query(`
+		SELECT id, variant, name, language, version, body, created_at
+		FROM prompt_templates WHERE variant = ? AND name = ? AND language = ?
+		ORDER BY version DESC
+	`, variant, name, language)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*PromptTemplate
+	for rows.Next() {
+		t, err := scanPromptTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+func scanPromptTemplate(row rowScanner) (*PromptTemplate, error) {
+	var t PromptTemplate
+	var createdAt sql.NullTime
+	if err := row.Scan(&t.ID, &t.Variant, &t.Name, &t.Language, &t.Version, &t.Body, &createdAt); err != nil {
+		return nil, err
+	}
+	if createdAt.Valid {
+		t.CreatedAt = createdAt.Time
+	}
+	return &t, nil
+}
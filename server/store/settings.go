@@ -101,8 +101,27 @@ func (s *SettingsStore) SetMultiple(settings map[string]string) error {
 // GlobalSettings represents the app-wide configuration
 type GlobalSettings struct {
 	// OpenRouter AI Configuration
-	OpenRouterAPIKey string `json:"openrouter_api_key"`
-	OpenRouterModel  string `json:"openrouter_model"`
+	OpenRouterAPIKey  string `json:"openrouter_api_key"`
+	OpenRouterModel   string `json:"openrouter_model"`
+	OpenRouterBaseURL string `json:"openrouter_base_url"`
+
+	// Route debate participants through OpenRouter when their native provider
+	// isn't configured or its call fails
+	OpenRouterFallback bool `json:"openrouter_fallback"`
+
+	// Native AI providers for debate participants (base URL optional)
+	OpenAIAPIKey     string `json:"openai_api_key"`
+	OpenAIBaseURL    string `json:"openai_base_url"`
+	AnthropicAPIKey  string `json:"anthropic_api_key"`
+	AnthropicBaseURL string `json:"anthropic_base_url"`
+	DeepSeekAPIKey   string `json:"deepseek_api_key"`
+	DeepSeekBaseURL  string `json:"deepseek_base_url"`
+
+	// Self-hosted models: an Ollama server and/or an OpenAI-compatible server
+	// (llama.cpp, vLLM). Empty base URL = not enabled; the API key is optional.
+	OllamaBaseURL  string `json:"ollama_base_url"`
+	LocalAIBaseURL string `json:"local_ai_base_url"`
+	LocalAIAPIKey  string `json:"local_ai_api_key"`
 
 	// Default Binance Configuration (used when trader doesn't specify)
 	BinanceAPIKey    string `json:"binance_api_key"`
@@ -118,11 +137,22 @@ func (s *SettingsStore) GetGlobalSettings() (*GlobalSettings, error) {
 	}
 
 	settings := &GlobalSettings{
-		OpenRouterAPIKey: all["openrouter_api_key"],
-		OpenRouterModel:  all["openrouter_model"],
-		BinanceAPIKey:    all["binance_api_key"],
-		BinanceSecretKey: all["binance_secret_key"],
-		BinanceTestnet:   all["binance_testnet"] == "true",
+		OpenRouterAPIKey:   all["openrouter_api_key"],
+		OpenRouterModel:    all["openrouter_model"],
+		OpenRouterBaseURL:  all["openrouter_base_url"],
+		OpenRouterFallback: all["openrouter_fallback"] != "false", // On unless disabled
+		OpenAIAPIKey:       all["openai_api_key"],
+		OpenAIBaseURL:      all["openai_base_url"],
+		AnthropicAPIKey:    all["anthropic_api_key"],
+		AnthropicBaseURL:   all["anthropic_base_url"],
+		DeepSeekAPIKey:     all["deepseek_api_key"],
+		DeepSeekBaseURL:    all["deepseek_base_url"],
+		OllamaBaseURL:      all["ollama_base_url"],
+		LocalAIBaseURL:     all["local_ai_base_url"],
+		LocalAIAPIKey:      all["local_ai_api_key"],
+		BinanceAPIKey:      all["binance_api_key"],
+		BinanceSecretKey:   all["binance_secret_key"],
+		BinanceTestnet:     all["binance_testnet"] == "true",
 	}
 
 	return settings, nil
@@ -131,11 +161,22 @@ func (s *SettingsStore) GetGlobalSettings() (*GlobalSettings, error) {
 // SaveGlobalSettings saves global settings
 func (s *SettingsStore) SaveGlobalSettings(settings *GlobalSettings) error {
 	data := map[string]string{
-		"openrouter_api_key": settings.OpenRouterAPIKey,
-		"openrouter_model":   settings.OpenRouterModel,
-		"binance_api_key":    settings.BinanceAPIKey,
-		"binance_secret_key": settings.BinanceSecretKey,
-		"binance_testnet":    boolToString(settings.BinanceTestnet),
+		"openrouter_api_key":  settings.OpenRouterAPIKey,
+		"openrouter_model":    settings.OpenRouterModel,
+		"openrouter_base_url": settings.OpenRouterBaseURL,
+		"openrouter_fallback": boolToString(settings.OpenRouterFallback),
+		"openai_api_key":      settings.OpenAIAPIKey,
+		"openai_base_url":     settings.OpenAIBaseURL,
+		"anthropic_api_key":   settings.AnthropicAPIKey,
+		"anthropic_base_url":  settings.AnthropicBaseURL,
+		"deepseek_api_key":    settings.DeepSeekAPIKey,
+		"deepseek_base_url":   settings.DeepSeekBaseURL,
+		"ollama_base_url":     settings.OllamaBaseURL,
+		"local_ai_base_url":   settings.LocalAIBaseURL,
+		"local_ai_api_key":    settings.LocalAIAPIKey,
+		"binance_api_key":     settings.BinanceAPIKey,
+		"binance_secret_key":  settings.BinanceSecretKey,
+		"binance_testnet":     boolToString(settings.BinanceTestnet),
 	}
 	return s.SetMultiple(data)
 }
@@ -155,11 +196,19 @@ func (gs GlobalSettings) MarshalJSON() ([]byte, error) {
 		OpenRouterAPIKey string `json:"openrouter_api_key"`
 		BinanceAPIKey    string `json:"binance_api_key"`
 		BinanceSecretKey string `json:"binance_secret_key"`
+		OpenAIAPIKey     string `json:"openai_api_key"`
+		AnthropicAPIKey  string `json:"anthropic_api_key"`
+		DeepSeekAPIKey   string `json:"deepseek_api_key"`
+		LocalAIAPIKey    string `json:"local_ai_api_key"`
 	}{
 		Alias:            Alias(gs),
 		OpenRouterAPIKey: maskSecret(gs.OpenRouterAPIKey),
 		BinanceAPIKey:    maskSecret(gs.BinanceAPIKey),
 		BinanceSecretKey: maskSecret(gs.BinanceSecretKey),
+		OpenAIAPIKey:     maskSecret(gs.OpenAIAPIKey),
+		AnthropicAPIKey:  maskSecret(gs.AnthropicAPIKey),
+		DeepSeekAPIKey:   maskSecret(gs.DeepSeekAPIKey),
+		LocalAIAPIKey:    maskSecret(gs.LocalAIAPIKey),
 	})
 }
 
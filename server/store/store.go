@@ -96,7 +96,17 @@ func migrate() error {
 		}
 	}
 
+	// Strategy version each decision was made under
+	if err := addColumn("decisions", "strategy_version_id", "INTEGER DEFAULT 0"); err != nil {
+		return err
+	}
+
 	// Initialize new stores
+	strategyStore := NewStrategyStore()
+	if err := strategyStore.InitTables(); err != nil {
+		return fmt.Errorf("strategy store init failed: %w", err)
+	}
+
 	positionStore := NewPositionStore()
 	if err := positionStore.InitTables(); err != nil {
 		return fmt.Errorf("position store init failed: %w", err)
@@ -122,5 +132,74 @@ func migrate() error {
 		return fmt.Errorf("settings store init failed: %w", err)
 	}
 
+	debateStore := NewDebateStore()
+	if err := debateStore.InitTables(); err != nil {
+		return fmt.Errorf("debate store init failed: %w", err)
+	}
+
+	aiUsageStore := NewAIUsageStore()
+	if err := aiUsageStore.InitTables(); err != nil {
+		return fmt.Errorf("AI usage store init failed: %w", err)
+	}
+
+	aiRecordingStore := NewAIRecordingStore()
+	if err := aiRecordingStore.InitTables(); err != nil {
+		return fmt.Errorf("AI recording store init failed: %w", err)
+	}
+
+	promptTemplateStore := NewPromptTemplateStore()
+	if err := promptTemplateStore.InitTables(); err != nil {
+		return fmt.Errorf("prompt template store init failed: %w", err)
+	}
+
+	tradeLessonStore := NewTradeLessonStore()
+	if err := tradeLessonStore.InitTables(); err != nil {
+		return fmt.Errorf("trade lesson store init failed: %w", err)
+	}
+
+	decisionAuditStore := NewDecisionAuditStore()
+	if err := decisionAuditStore.InitTables(); err != nil {
+		return fmt.Errorf("decision audit store init failed: %w", err)
+	}
+
+	return nil
+}
+
+// addColumn adds a column to an existing table unless it's already there
+// (SQLite has no ADD COLUMN IF NOT EXISTS)
+func addColumn(table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", table, err)
+	}
+	exists := false
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		if name == column {
+			exists = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add %s.%s: %w", table, column, err)
+	}
 	return nil
 }
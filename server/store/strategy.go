@@ -16,6 +16,8 @@ type Strategy struct {
 	Description string         `json:"description"`
 	IsActive    bool           `json:"is_active"`
 	Config      StrategyConfig `json:"config"`
+	VersionID   int64          `json:"version_id"` // Current immutable version (see StrategyVersion)
+	Version     int            `json:"version"`    // Per-strategy version number of VersionID
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 }
@@ -34,9 +36,15 @@ type StrategyConfig struct {
 	// AI configuration
 	AI AIConfig `json:"ai"`
 
+	// Entry order execution (market, limit or post-only)
+	EntryExecution EntryExecutionConfig `json:"entry_execution"`
+
 	// Custom AI prompt additions
 	CustomPrompt string `json:"custom_prompt"`
 
+	// Prompt template variant from the prompt registry (empty = default)
+	PromptVariant string `json:"prompt_variant,omitempty"`
+
 	// Trading interval in minutes
 	TradingInterval int `json:"trading_interval"`
 
@@ -48,6 +56,42 @@ type StrategyConfig struct {
 
 	// Trading Mode: "strategy" (default) or "copy_trade"
 	TradingMode string `json:"trading_mode"`
+
+	// Decision Mode: "single_model" (default) or "debate"
+	DecisionMode string `json:"decision_mode"`
+
+	// Debate panel used when DecisionMode is "debate"
+	Debate DebatePanelConfig `json:"debate"`
+
+	// Lessons from closed trades fed back into the prompt
+	Reflection ReflectionConfig `json:"reflection"`
+}
+
+// Decision modes
+const (
+	DecisionModeSingleModel = "single_model" // One AI call per symbol (default)
+	DecisionModeDebate      = "debate"       // Multi-model debate each cycle, consensus is the decision
+)
+
+// DebatePanelConfig defines the participants of a debate-mode trader
+type DebatePanelConfig struct {
+	Participants  []DebateParticipantConfig `json:"participants"`
+	MaxRounds     int                       `json:"max_rounds"`      // Debate rounds before voting (default: 2)
+	Language      string                    `json:"language"`        // "en-US" | "zh-CN" (default: en-US)
+	ConsensusMode string                    `json:"consensus_mode"`  // confidence | accuracy | calibrated | median | unanimous
+	Judge         *DebateParticipantConfig  `json:"judge,omitempty"` // Optional moderator that rules on the debate
+
+	ConvergenceThreshold float64 `json:"convergence_threshold"` // Skip remaining rounds once this share agrees (0 = off)
+	TokenBudget          int     `json:"token_budget"`          // Tokens per decision cycle before voting early (0 = unlimited)
+	CostBudgetUSD        float64 `json:"cost_budget_usd"`       // USD per decision cycle, from the AI price table, before voting early (0 = unlimited)
+}
+
+// DebateParticipantConfig is one model/personality seat on the panel
+type DebateParticipantConfig struct {
+	AIModelID   string `json:"ai_model_id"`
+	AIModelName string `json:"ai_model_name"`
+	Provider    string `json:"provider"`    // "openrouter" (default)
+	Personality string `json:"personality"` // bull, bear, analyst, contrarian, risk_manager
 }
 
 // AIConfig defines AI model settings
@@ -59,6 +103,41 @@ type AIConfig struct {
 	ReasoningModel string `json:"reasoning_model"`
 }
 
+// Entry order types
+const (
+	EntryOrderMarket   = "market"    // Taker MARKET order (default)
+	EntryOrderLimit    = "limit"     // GTC LIMIT order
+	EntryOrderPostOnly = "post_only" // GTX LIMIT order, rejected instead of taking liquidity
+)
+
+// Confidence calibration modes, see RiskControlConfig.ConfidenceCalibration
+const (
+	CalibrationOff           = ""               // Gate on the AI's stated confidence (default)
+	CalibrationProbability   = "calibrated"     // Gate on the stated confidence corrected by the model's track record
+	CalibrationAutoThreshold = "auto_threshold" // Raise MinConfidence to where the model's trades start winning
+)
+
+// EntryExecutionConfig defines how opening orders are placed
+type EntryExecutionConfig struct {
+	OrderType     string  `json:"order_type"`     // "market" | "limit" | "post_only" (default: market)
+	PriceSource   string  `json:"price_source"`   // "best" = best bid/ask, "ai" = decision entry_price (default: best)
+	TimeoutSecs   int     `json:"timeout_secs"`   // Max wait for the limit order to fill (default: 30)
+	TimeoutAction string  `json:"timeout_action"` // "market" = convert remainder to MARKET, "cancel" = give up (default: market)
+	MaxChasePct   float64 `json:"max_chase_pct"`  // Cancel if price runs away from the limit by this % (default: 0.5, 0 = off)
+}
+
+// ReflectionConfig defines the self-reflection memory: closed trades are
+// summarized per symbol, direction and setup, and the lessons are added to
+// the prompt
+type ReflectionConfig struct {
+	Enabled         bool `json:"enabled"`
+	IntervalMinutes int  `json:"interval_minutes"` // Minutes between reflection passes (default: 60)
+	LookbackTrades  int  `json:"lookback_trades"`  // Closed trades the pass looks at (default: 100)
+	MinTrades       int  `json:"min_trades"`       // Trades a symbol/direction/setup needs before it yields a lesson (default: 3)
+	MaxLessons      int  `json:"max_lessons"`      // Lessons added to each prompt (default: 5)
+	LLMSummary      bool `json:"llm_summary"`      // Have the AI write the lessons instead of the stats summary
+}
+
 // CoinSourceConfig defines how to select coins
 type CoinSourceConfig struct {
 	SourceType  string   `json:"source_type"` // "static" | "dynamic"
@@ -124,6 +203,12 @@ type RiskControlConfig struct {
 	MinRiskRewardRatio           float64 `json:"min_risk_reward_ratio"`           // Min TP/SL ratio (default: 3.0)
 	HighConfidenceCloseThreshold float64 `json:"high_confidence_close_threshold"` // Min confidence to close in noise zone (default: 95)
 
+	// CONFIDENCE CALIBRATION - Check the AI's confidence against the trades it opened
+	ConfidenceCalibration    string  `json:"confidence_calibration"`      // "" (off), calibrated or auto_threshold
+	CalibrationMinTrades     int     `json:"calibration_min_trades"`      // Closed trades from the model before calibration applies (default: 20)
+	CalibrationTargetHitRate float64 `json:"calibration_target_hit_rate"` // Win rate auto_threshold requires at and above the threshold (default: 50)
+	CalibratedSizing         bool    `json:"calibrated_sizing"`           // Shrink new positions when the model is overconfident
+
 	// NOISE ZONE PROTECTION - Prevent closing positions too early
 	EnableNoiseZoneProtection bool    `json:"enable_noise_zone_protection"` // Enable noise zone protection (default: true)
 	NoiseZoneLowerBound       float64 `json:"noise_zone_lower_bound"`       // Lower bound of noise zone, below this = allow close (default: -1.5%)
@@ -157,6 +242,9 @@ type RiskControlConfig struct {
 	EnableSmartLossCut bool    `json:"enable_smart_loss_cut"` // Enable time-based loss cutting
 	SmartLossCutMins   int     `json:"smart_loss_cut_mins"`   // Minutes before cutting losers (default: 30)
 	SmartLossCutPct    float64 `json:"smart_loss_cut_pct"`    // Loss % threshold for smart cut (default: -1.0 = -1%)
+
+	// HEDGE MODE - Allow a LONG and a SHORT on the same symbol (Binance dual-side position mode)
+	EnableHedgeMode bool `json:"enable_hedge_mode"` // Switch account to hedge mode on start (default: false)
 }
 
 // DefaultStrategyConfig returns a sensible default strategy
@@ -250,8 +338,20 @@ func DefaultStrategyConfig() StrategyConfig {
 			EnableReasoning: false,
 			ReasoningModel:  "deepseek/deepseek-r1",
 		},
+		EntryExecution: EntryExecutionConfig{
+			OrderType:     EntryOrderMarket,
+			PriceSource:   "best",
+			TimeoutSecs:   30,
+			TimeoutAction: "market",
+			MaxChasePct:   0.5,
+		},
 		CustomPrompt:    "",
 		TradingInterval: 5,
+		DecisionMode:    DecisionModeSingleModel,
+		Debate: DebatePanelConfig{
+			MaxRounds: 2,
+			Language:  "en-US",
+		},
 	}
 }
 
@@ -262,6 +362,11 @@ func NewStrategyStore() *StrategyStore {
 	return &StrategyStore{}
 }
 
+// strategyColumns selects a strategy row along with its current version
+const strategyColumns = `id, name, description, is_active, config, created_at, updated_at,
+	COALESCE((SELECT MAX(v.id) FROM strategy_versions v WHERE v.strategy_id = strategies.id), 0),
+	COALESCE((SELECT MAX(v.version) FROM strategy_versions v WHERE v.strategy_id = strategies.id), 0)`
+
 func (s *StrategyStore) Create(strategy *Strategy) error {
 	if strategy.ID == "" {
 		strategy.ID = uuid.New().String()
@@ -274,15 +379,29 @@ func (s *StrategyStore) Create(strategy *Strategy) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	_, err = db.Exec(`
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
 		INSERT INTO strategies (id, name, description, is_active, config, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`, strategy.ID, strategy.Name, strategy.Description, strategy.IsActive, string(configJSON),
 		strategy.CreatedAt, strategy.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if err := s.saveVersion(tx, strategy, string(configJSON), "created"); err != nil {
+		return err
+	}
 
-	return err
+	return tx.Commit()
 }
 
+// Update saves the strategy and records a new version when its name,
+// description or config changed
 func (s *StrategyStore) Update(strategy *Strategy) error {
 	strategy.UpdatedAt = time.Now()
 
@@ -291,14 +410,26 @@ func (s *StrategyStore) Update(strategy *Strategy) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	_, err = db.Exec(`
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
 		UPDATE strategies
 		SET name = ?, description = ?, is_active = ?, config = ?, updated_at = ?
 		WHERE id = ?
 	`, strategy.Name, strategy.Description, strategy.IsActive, string(configJSON),
 		strategy.UpdatedAt, strategy.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.saveVersion(tx, strategy, string(configJSON), "updated"); err != nil {
+		return err
+	}
 
-	return err
+	return tx.Commit()
 }
 
 func (s *StrategyStore) Delete(id string) error {
@@ -308,7 +439,7 @@ func (s *StrategyStore) Delete(id string) error {
 
 func (s *StrategyStore) Get(id string) (*Strategy, error) {
 	row := db.QueryRow(`
-		SELECT id, name, description, is_active, config, created_at, updated_at
+		SELECT `+strategyColumns+`
 		FROM strategies WHERE id = ?
 	`, id)
 
@@ -317,7 +448,7 @@ func (s *StrategyStore) Get(id string) (*Strategy, error) {
 
 func (s *StrategyStore) GetActive() (*Strategy, error) {
 	row := db.QueryRow(`
-		SELECT id, name, description, is_active, config, created_at, updated_at
+		SELECT ` + strategyColumns + `
 		FROM strategies WHERE is_active = 1 LIMIT 1
 	`)
 
@@ -336,7 +467,7 @@ func (s *StrategyStore) GetActive() (*Strategy, error) {
 
 func (s *StrategyStore) List() ([]*Strategy, error) {
 	rows, err := db.Query(`
-		SELECT id, name, description, is_active, config, created_at, updated_at
+		SELECT ` + strategyColumns + `
 		FROM strategies ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -384,6 +515,7 @@ func (s *StrategyStore) scanStrategy(row *sql.Row) (*Strategy, error) {
 		&strategy.ID, &strategy.Name, &strategy.Description,
 		&strategy.IsActive, &configJSON,
 		&strategy.CreatedAt, &strategy.UpdatedAt,
+		&strategy.VersionID, &strategy.Version,
 	)
 	if err != nil {
 		return nil, err
@@ -404,6 +536,7 @@ func (s *StrategyStore) scanStrategyRow(rows *sql.Rows) (*Strategy, error) {
 		&strategy.ID, &strategy.Name, &strategy.Description,
 		&strategy.IsActive, &configJSON,
 		&strategy.CreatedAt, &strategy.UpdatedAt,
+		&strategy.VersionID, &strategy.Version,
 	)
 	if err != nil {
 		return nil, err
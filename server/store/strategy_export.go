@@ -0,0 +1,348 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StrategySchemaVersion is the version of the export format. Bump it when a
+// change to StrategyConfig needs migrating old exports.
+const StrategySchemaVersion = 1
+
+// Export formats
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// StrategyExport is the portable form of a strategy, used to move it between
+// environments. Config fields missing on import take their defaults.
+type StrategyExport struct {
+	SchemaVersion int            `json:"schema_version"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	Config        StrategyConfig `json:"config"`
+	SourceVersion int            `json:"source_version,omitempty"` // Strategy version that was exported
+	ExportedAt    time.Time      `json:"exported_at"`
+}
+
+// ExportStrategy encodes a strategy as JSON or YAML
+func ExportStrategy(strategy *Strategy, format string) ([]byte, error) {
+	doc := StrategyExport{
+		SchemaVersion: StrategySchemaVersion,
+		Name:          strategy.Name,
+		Description:   strategy.Description,
+		Config:        strategy.Config,
+		SourceVersion: strategy.Version,
+		ExportedAt:    time.Now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode strategy: %w", err)
+	}
+	if format != FormatYAML {
+		return data, nil
+	}
+
+	// Go through JSON so YAML keys match the JSON field names
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("failed to encode strategy: %w", err)
+	}
+	return yaml.Marshal(generic)
+}
+
+// ImportStrategy decodes and validates an exported strategy. The format is
+// detected from the content when empty. Unknown fields are rejected so typos
+// don't silently fall back to defaults.
+func ImportStrategy(data []byte, format string) (*Strategy, error) {
+	if format == "" {
+		format = FormatYAML
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			format = FormatJSON
+		}
+	}
+
+	if format == FormatYAML {
+		var generic interface{}
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		converted, err := json.Marshal(generic)
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		data = converted
+	}
+
+	doc := StrategyExport{Config: DefaultStrategyConfig()}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid strategy document: %w", err)
+	}
+
+	if doc.SchemaVersion < 1 || doc.SchemaVersion > StrategySchemaVersion {
+		return nil, fmt.Errorf("unsupported schema_version %d (supported: 1-%d)", doc.SchemaVersion, StrategySchemaVersion)
+	}
+	if strings.TrimSpace(doc.Name) == "" {
+		return nil, fmt.Errorf("strategy name is required")
+	}
+	if err := ValidateStrategyConfig(&doc.Config); err != nil {
+		return nil, err
+	}
+
+	return &Strategy{
+		Name:        doc.Name,
+		Description: doc.Description,
+		Config:      doc.Config,
+	}, nil
+}
+
+// Timeframes supported for klines
+var validTimeframes = map[string]time.Duration{
+	"1m": time.Minute, "3m": 3 * time.Minute, "5m": 5 * time.Minute, "15m": 15 * time.Minute,
+	"30m": 30 * time.Minute, "1h": time.Hour, "2h": 2 * time.Hour, "4h": 4 * time.Hour,
+	"6h": 6 * time.Hour, "12h": 12 * time.Hour, "1d": 24 * time.Hour,
+}
+
+// configProblems collects every invalid field so one import reports them all
+type configProblems []string
+
+func (p *configProblems) add(field, format string, args ...interface{}) {
+	*p = append(*p, field+": "+fmt.Sprintf(format, args...))
+}
+
+func (p *configProblems) between(field string, v, min, max float64) {
+	if v < min || v > max {
+		p.add(field, "must be between %g and %g, got %g", min, max, v)
+	}
+}
+
+// ValidateStrategyConfig checks a config for values the engine can't trade
+// with. Settings of disabled features are only checked for sign.
+func ValidateStrategyConfig(cfg *StrategyConfig) error {
+	var p configProblems
+
+	switch cfg.CoinSource.SourceType {
+	case "static":
+		if len(cfg.CoinSource.StaticCoins) == 0 {
+			p.add("coin_source.static_coins", "needs at least one symbol for a static source")
+		}
+	case "dynamic", "volume_top", "oi_top":
+	default:
+		p.add("coin_source.source_type", "must be static, dynamic, volume_top or oi_top, got %q", cfg.CoinSource.SourceType)
+	}
+	for _, coin := range cfg.CoinSource.StaticCoins {
+		if coin == "" || coin != strings.ToUpper(coin) {
+			p.add("coin_source.static_coins", "symbols must be upper case, got %q", coin)
+		}
+	}
+
+	validateIndicators(&cfg.Indicators, &p)
+	validateRiskControl(&cfg.RiskControl, &p)
+
+	switch cfg.EntryExecution.OrderType {
+	case "", EntryOrderMarket, EntryOrderLimit, EntryOrderPostOnly:
+	default:
+		p.add("entry_execution.order_type", "must be market, limit or post_only, got %q", cfg.EntryExecution.OrderType)
+	}
+	if cfg.EntryExecution.TimeoutSecs < 0 {
+		p.add("entry_execution.timeout_secs", "can't be negative")
+	}
+	if cfg.EntryExecution.MaxChasePct < 0 {
+		p.add("entry_execution.max_chase_pct", "can't be negative")
+	}
+
+	if cfg.TradingInterval < 1 {
+		p.add("trading_interval", "must be at least 1 minute, got %d", cfg.TradingInterval)
+	}
+	switch cfg.TradingMode {
+	case "", "strategy", "copy_trade":
+	default:
+		p.add("trading_mode", "must be strategy or copy_trade, got %q", cfg.TradingMode)
+	}
+	switch cfg.DecisionMode {
+	case "", DecisionModeSingleModel:
+	case DecisionModeDebate:
+		if len(cfg.Debate.Participants) < 2 {
+			p.add("debate.participants", "debate mode needs at least 2 participants")
+		}
+	default:
+		p.add("decision_mode", "must be single_model or debate, got %q", cfg.DecisionMode)
+	}
+	if cfg.Debate.ConvergenceThreshold < 0 || cfg.Debate.ConvergenceThreshold > 1 {
+		p.add("debate.convergence_threshold", "must be between 0 and 1")
+	}
+	if cfg.Debate.TokenBudget < 0 {
+		p.add("debate.token_budget", "can't be negative")
+	}
+	if cfg.Debate.CostBudgetUSD < 0 {
+		p.add("debate.cost_budget_usd", "can't be negative")
+	}
+
+	if cfg.Reflection.IntervalMinutes < 0 || cfg.Reflection.LookbackTrades < 0 ||
+		cfg.Reflection.MinTrades < 0 || cfg.Reflection.MaxLessons < 0 {
+		p.add("reflection", "interval_minutes, lookback_trades, min_trades and max_lessons can't be negative")
+	}
+
+	if len(p) > 0 {
+		return fmt.Errorf("invalid strategy config: %s", strings.Join(p, "; "))
+	}
+	return nil
+}
+
+func validateIndicators(ind *IndicatorConfig, p *configProblems) {
+	primary, ok := validTimeframes[ind.PrimaryTimeframe]
+	if !ok {
+		p.add("indicators.primary_timeframe", "unsupported timeframe %q", ind.PrimaryTimeframe)
+	}
+	if ind.KlineCount < 20 || ind.KlineCount > 1500 {
+		p.add("indicators.kline_count", "must be between 20 and 1500, got %d", ind.KlineCount)
+	}
+
+	// Every enabled indicator needs a usable period that fits in the klines
+	longest := 0
+	period := func(field string, enabled bool, v int) {
+		if v < 0 || (enabled && v < 2) {
+			p.add(field, "must be at least 2, got %d", v)
+		}
+		if enabled && v > longest {
+			longest = v
+		}
+	}
+	if ind.EnableEMA && len(ind.EMAPeriods) == 0 {
+		p.add("indicators.ema_periods", "needs at least one period when EMA is enabled")
+	}
+	for _, v := range ind.EMAPeriods {
+		period("indicators.ema_periods", ind.EnableEMA, v)
+	}
+	period("indicators.rsi_period", ind.EnableRSI, ind.RSIPeriod)
+	period("indicators.atr_period", ind.EnableATR, ind.ATRPeriod)
+	period("indicators.boll_period", ind.EnableBOLL, ind.BOLLPeriod)
+	period("indicators.macd_fast", ind.EnableMACD, ind.MACDFast)
+	period("indicators.macd_slow", ind.EnableMACD, ind.MACDSlow)
+	period("indicators.macd_signal", ind.EnableMACD, ind.MACDSignal)
+	if ind.EnableMACD && ind.MACDSlow <= ind.MACDFast {
+		p.add("indicators.macd_slow", "must be longer than macd_fast (%d), got %d", ind.MACDFast, ind.MACDSlow)
+	}
+	if ind.KlineCount > 0 && longest >= ind.KlineCount {
+		p.add("indicators.kline_count", "must exceed the longest enabled period (%d), got %d", longest, ind.KlineCount)
+	}
+
+	if ind.EnableMultiTF {
+		confirm, ok := validTimeframes[ind.ConfirmationTimeframe]
+		switch {
+		case !ok:
+			p.add("indicators.confirmation_timeframe", "unsupported timeframe %q", ind.ConfirmationTimeframe)
+		case confirm <= primary:
+			p.add("indicators.confirmation_timeframe", "must be longer than primary_timeframe %s, got %s", ind.PrimaryTimeframe, ind.ConfirmationTimeframe)
+		}
+	}
+}
+
+func validateRiskControl(rc *RiskControlConfig, p *configProblems) {
+	if rc.MaxPositions < 1 || rc.MaxPositions > 50 {
+		p.add("risk_control.max_positions", "must be between 1 and 50, got %d", rc.MaxPositions)
+	}
+
+	// Leverage (0 = fall back to the legacy or exchange default)
+	p.between("risk_control.max_leverage", float64(rc.MaxLeverage), 0, 125)
+	p.between("risk_control.btc_eth_max_leverage", float64(rc.BTCETHMaxLeverage), 0, 125)
+	p.between("risk_control.altcoin_max_leverage", float64(rc.AltcoinMaxLeverage), 0, 125)
+
+	// Sizing
+	p.between("risk_control.max_position_percent", rc.MaxPositionPercent, 0, 100)
+	p.between("risk_control.btc_eth_max_position_value_ratio", rc.BTCETHMaxPositionValueRatio, 0, 20)
+	p.between("risk_control.altcoin_max_position_value_ratio", rc.AltcoinMaxPositionValueRatio, 0, 20)
+	for _, f := range []struct {
+		field string
+		value float64
+	}{
+		{"risk_control.min_position_size", rc.MinPositionSize},
+		{"risk_control.min_position_size_btc_eth", rc.MinPositionSizeBTCETH},
+		{"risk_control.min_position_usd", rc.MinPositionUSD},
+	} {
+		if f.value < 0 {
+			p.add(f.field, "can't be negative, got %g", f.value)
+		}
+	}
+
+	// Margin
+	if rc.MaxMarginUsage <= 0 || rc.MaxMarginUsage > 100 {
+		p.add("risk_control.max_margin_usage", "must be above 0 and at most 100, got %g", rc.MaxMarginUsage)
+	}
+	if rc.MarginBuffer <= 0 || rc.MarginBuffer > 1 {
+		p.add("risk_control.margin_buffer", "must be above 0 and at most 1, got %g", rc.MarginBuffer)
+	}
+
+	// AI thresholds
+	p.between("risk_control.min_confidence", float64(rc.MinConfidence), 0, 100)
+	p.between("risk_control.min_risk_reward_ratio", rc.MinRiskRewardRatio, 0, 20)
+	p.between("risk_control.high_confidence_close_threshold", rc.HighConfidenceCloseThreshold, 0, 100)
+
+	// Confidence calibration
+	switch rc.ConfidenceCalibration {
+	case CalibrationOff, CalibrationProbability, CalibrationAutoThreshold:
+	default:
+		p.add("risk_control.confidence_calibration", "must be empty, calibrated or auto_threshold, got %q", rc.ConfidenceCalibration)
+	}
+	if rc.CalibrationMinTrades < 0 {
+		p.add("risk_control.calibration_min_trades", "can't be negative, got %d", rc.CalibrationMinTrades)
+	}
+	p.between("risk_control.calibration_target_hit_rate", rc.CalibrationTargetHitRate, 0, 100)
+
+	// Noise zone
+	if rc.NoiseZoneLowerBound > 0 {
+		p.add("risk_control.noise_zone_lower_bound", "must be 0 or below, got %g", rc.NoiseZoneLowerBound)
+	}
+	if rc.NoiseZoneUpperBound < 0 {
+		p.add("risk_control.noise_zone_upper_bound", "must be 0 or above, got %g", rc.NoiseZoneUpperBound)
+	}
+	if rc.MinHoldBeforeClose < 0 {
+		p.add("risk_control.min_hold_before_close", "can't be negative, got %d", rc.MinHoldBeforeClose)
+	}
+
+	// Loss limits
+	p.between("risk_control.max_daily_loss_pct", rc.MaxDailyLossPct, 0, 100)
+	p.between("risk_control.max_drawdown_pct", rc.MaxDrawdownPct, 0, 100)
+	if rc.StopTradingMins < 0 {
+		p.add("risk_control.stop_trading_mins", "can't be negative, got %d", rc.StopTradingMins)
+	}
+	p.between("risk_control.drawdown_close_threshold", rc.DrawdownCloseThreshold, 0, 100)
+	if rc.MinProfitForDrawdown < 0 {
+		p.add("risk_control.min_profit_for_drawdown", "can't be negative, got %g", rc.MinProfitForDrawdown)
+	}
+
+	// Emergency shutdown
+	if rc.EmergencyMinBalance < 0 || (rc.EnableEmergencyShutdown && rc.EmergencyMinBalance == 0) {
+		p.add("risk_control.emergency_min_balance", "must be above 0 when emergency shutdown is enabled, got %g", rc.EmergencyMinBalance)
+	}
+
+	// Trailing stop
+	if rc.TrailingStopActivatePct < 0 || (rc.EnableTrailingStop && rc.TrailingStopActivatePct == 0) {
+		p.add("risk_control.trailing_stop_activate_pct", "must be above 0 when the trailing stop is enabled, got %g", rc.TrailingStopActivatePct)
+	}
+	if rc.TrailingStopDistancePct < 0 || rc.TrailingStopDistancePct > 100 || (rc.EnableTrailingStop && rc.TrailingStopDistancePct == 0) {
+		p.add("risk_control.trailing_stop_distance_pct", "must be above 0 and at most 100 when the trailing stop is enabled, got %g", rc.TrailingStopDistancePct)
+	}
+
+	// Max hold duration
+	if rc.MaxHoldDurationMins < 0 || (rc.EnableMaxHoldDuration && rc.MaxHoldDurationMins == 0) {
+		p.add("risk_control.max_hold_duration_mins", "must be above 0 when max hold duration is enabled, got %d", rc.MaxHoldDurationMins)
+	}
+
+	// Smart loss cut
+	if rc.SmartLossCutMins < 0 || (rc.EnableSmartLossCut && rc.SmartLossCutMins == 0) {
+		p.add("risk_control.smart_loss_cut_mins", "must be above 0 when smart loss cut is enabled, got %d", rc.SmartLossCutMins)
+	}
+	if rc.SmartLossCutPct > 0 || (rc.EnableSmartLossCut && rc.SmartLossCutPct == 0) {
+		p.add("risk_control.smart_loss_cut_pct", "must be below 0 (a loss) when smart loss cut is enabled, got %g", rc.SmartLossCutPct)
+	}
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStrategyTemplates_AreValid(t *testing.T) {
+	for _, tmpl := range StrategyTemplates() {
+		t.Run(tmpl.ID, func(t *testing.T) {
+			if err := ValidateStrategyConfig(&tmpl.Config); err != nil {
+				t.Errorf("template %s is invalid: %v", tmpl.ID, err)
+			}
+		})
+	}
+}
+
+func TestExportImportStrategy_RoundTrip(t *testing.T) {
+	tmpl, err := GetStrategyTemplate("conservative_swing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	strategy := &Strategy{Name: "Swing", Description: "d", Config: tmpl.Config, Version: 4}
+
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			data, err := ExportStrategy(strategy, format)
+			if err != nil {
+				t.Fatalf("ExportStrategy() error = %v", err)
+			}
+			got, err := ImportStrategy(data, "")
+			if err != nil {
+				t.Fatalf("ImportStrategy() error = %v", err)
+			}
+			if got.Name != strategy.Name || !reflect.DeepEqual(got.Config, strategy.Config) {
+				t.Errorf("round trip changed the strategy:\n got %+v\nwant %+v", got.Config, strategy.Config)
+			}
+		})
+	}
+}
+
+func TestImportStrategy_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{
+			name:    "missing schema version",
+			doc:     `{"name": "x", "config": {}}`,
+			wantErr: "schema_version",
+		},
+		{
+			name:    "newer schema version",
+			doc:     `{"schema_version": 99, "name": "x"}`,
+			wantErr: "schema_version 99",
+		},
+		{
+			name:    "unknown field",
+			doc:     "schema_version: 1\nname: x\nconfig:\n  risk_control:\n    max_leverag: 5\n",
+			wantErr: "max_leverag",
+		},
+		{
+			name:    "invalid risk control",
+			doc:     `{"schema_version": 1, "name": "x", "config": {"risk_control": {"max_positions": 0, "margin_buffer": 1.5}}}`,
+			wantErr: "risk_control.margin_buffer",
+		},
+		{
+			name:    "invalid indicators",
+			doc:     `{"schema_version": 1, "name": "x", "config": {"indicators": {"primary_timeframe": "7m", "macd_fast": 26, "macd_slow": 12}}}`,
+			wantErr: "indicators.macd_slow",
+		},
+		{
+			name:    "missing name",
+			doc:     `{"schema_version": 1}`,
+			wantErr: "name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ImportStrategy([]byte(tt.doc), "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ImportStrategy() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
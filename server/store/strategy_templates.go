@@ -0,0 +1,153 @@
+package store
+
+import "fmt"
+
+// StrategyTemplate is a built-in starting point for a new strategy
+type StrategyTemplate struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Config      StrategyConfig `json:"config"`
+}
+
+// StrategyTemplates returns the template catalogue. Each template starts
+// from DefaultStrategyConfig, so new defaults reach the templates too.
+func StrategyTemplates() []StrategyTemplate {
+	return []StrategyTemplate{
+		conservativeSwingTemplate(),
+		scalpingTurboTemplate(),
+		btcOnlyTemplate(),
+		copyTradeMonitorTemplate(),
+	}
+}
+
+// GetStrategyTemplate returns a template by ID
+func GetStrategyTemplate(id string) (*StrategyTemplate, error) {
+	for _, t := range StrategyTemplates() {
+		if t.ID == id {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown strategy template: %s", id)
+}
+
+// NewStrategyFromTemplate builds an unsaved strategy from a template
+func NewStrategyFromTemplate(id, name string) (*Strategy, error) {
+	t, err := GetStrategyTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = t.Name
+	}
+	return &Strategy{
+		Name:        name,
+		Description: t.Description,
+		Config:      t.Config,
+	}, nil
+}
+
+func conservativeSwingTemplate() StrategyTemplate {
+	cfg := DefaultStrategyConfig()
+	cfg.TradingInterval = 30
+	cfg.Indicators.PrimaryTimeframe = "1h"
+	cfg.Indicators.ConfirmationTimeframe = "4h"
+	cfg.Indicators.KlineCount = 200
+	cfg.Indicators.EMAPeriods = []int{20, 50}
+	cfg.Indicators.EnableBOLL = true
+
+	rc := &cfg.RiskControl
+	rc.MaxPositions = 2
+	rc.MaxLeverage = 3
+	rc.BTCETHMaxLeverage = 3
+	rc.AltcoinMaxLeverage = 2
+	rc.BTCETHMaxPositionValueRatio = 2.0
+	rc.AltcoinMaxPositionValueRatio = 0.5
+	rc.MaxMarginUsage = 50
+	rc.MinConfidence = 90
+	rc.MinHoldBeforeClose = 120
+	rc.NoiseZoneLowerBound = -3.0
+	rc.NoiseZoneUpperBound = 3.0
+	rc.MaxDailyLossPct = 5.0
+	rc.StopTradingMins = 240
+	rc.EnableTrailingStop = true
+	rc.TrailingStopActivatePct = 3.0
+	rc.TrailingStopDistancePct = 1.5
+
+	cfg.EntryExecution.OrderType = EntryOrderLimit
+
+	return StrategyTemplate{
+		ID:          "conservative_swing",
+		Name:        "Conservative Swing",
+		Description: "Hourly swing trades on BTC/ETH with low leverage, few positions and a trailing stop",
+		Config:      cfg,
+	}
+}
+
+func scalpingTurboTemplate() StrategyTemplate {
+	cfg := DefaultStrategyConfig()
+	cfg.TurboMode = true
+	cfg.TradingInterval = 1
+	cfg.CoinSource.SourceType = "dynamic" // Static coins stay as the fallback list
+	cfg.Indicators.PrimaryTimeframe = "1m"
+	cfg.Indicators.ConfirmationTimeframe = "5m"
+	cfg.Indicators.EMAPeriods = []int{5, 13}
+	cfg.Indicators.RSIPeriod = 7
+
+	rc := &cfg.RiskControl
+	rc.MaxPositions = 5
+	rc.MinConfidence = 75
+	rc.MinRiskRewardRatio = 1.5
+	rc.MinHoldBeforeClose = 2
+	rc.NoiseZoneLowerBound = -0.5
+	rc.NoiseZoneUpperBound = 0.5
+	rc.EnableMaxHoldDuration = true
+	rc.MaxHoldDurationMins = 30
+	rc.EnableSmartLossCut = true
+	rc.SmartLossCutMins = 10
+	rc.SmartLossCutPct = -0.5
+
+	cfg.EntryExecution.OrderType = EntryOrderPostOnly
+	cfg.EntryExecution.TimeoutSecs = 15
+
+	return StrategyTemplate{
+		ID:          "scalping_turbo",
+		Name:        "Scalping (Turbo)",
+		Description: "One-minute scalps on trending coins in turbo mode, short holds and fast loss cuts",
+		Config:      cfg,
+	}
+}
+
+func btcOnlyTemplate() StrategyTemplate {
+	cfg := DefaultStrategyConfig()
+	cfg.CoinSource.SourceType = "static"
+	cfg.CoinSource.StaticCoins = []string{"BTCUSDT"}
+	cfg.TradingInterval = 15
+	cfg.Indicators.PrimaryTimeframe = "15m"
+	cfg.Indicators.ConfirmationTimeframe = "1h"
+
+	rc := &cfg.RiskControl
+	rc.MaxPositions = 1
+	rc.BTCETHMaxLeverage = 10
+	rc.EnableTrailingStop = true
+
+	return StrategyTemplate{
+		ID:          "btc_only",
+		Name:        "BTC Only",
+		Description: "A single BTCUSDT position at a time on the 15m chart, confirmed on 1h",
+		Config:      cfg,
+	}
+}
+
+func copyTradeMonitorTemplate() StrategyTemplate {
+	cfg := DefaultStrategyConfig()
+	cfg.TradingMode = "copy_trade"
+	cfg.TradingInterval = 5
+
+	return StrategyTemplate{
+		ID:          "copy_trade_monitor",
+		Name:        "Copy-Trade Monitor",
+		Description: "Monitors a Binance copy-trading account (status, balance, positions) without placing AI trades",
+		Config:      cfg,
+	}
+}
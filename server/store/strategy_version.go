@@ -0,0 +1,237 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// StrategyVersion is an immutable snapshot of a strategy. Every change to a
+// strategy adds one; decisions, trades and equity snapshots record the
+// version their trader was running. Versions outlive their strategy so the
+// history stays readable.
+type StrategyVersion struct {
+	ID          int64          `json:"id"`
+	StrategyID  string         `json:"strategy_id"`
+	Version     int            `json:"version"` // 1, 2, ... per strategy
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Config      StrategyConfig `json:"config"`
+	Note        string         `json:"note"` // created | updated | rolled back to vN
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// ConfigChange is one field that differs between two strategy configs
+type ConfigChange struct {
+	Path string      `json:"path"` // Dotted JSON path, e.g. risk_control.max_leverage
+	From interface{} `json:"from"` // nil when the field was added
+	To   interface{} `json:"to"`   // nil when the field was removed
+}
+
+// InitTables creates the strategy version table and gives strategies saved
+// before versioning existed their first version
+func (s *StrategyStore) InitTables() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS strategy_versions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		strategy_id TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		description TEXT DEFAULT '',
+		config TEXT NOT NULL,
+		note TEXT DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(strategy_id, version)
+	);
+
+	INSERT INTO strategy_versions (strategy_id, version, name, description, config, note, created_at)
+	SELECT id, 1, name, COALESCE(description, ''), config, 'created', COALESCE(updated_at, CURRENT_TIMESTAMP)
+	FROM strategies
+	WHERE id NOT IN (SELECT strategy_id FROM strategy_versions);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// saveVersion records the strategy as its next version, unless nothing
+// versioned changed since the latest one. Sets strategy.VersionID/Version.
+func (s *StrategyStore) saveVersion(tx *sql.Tx, strategy *Strategy, configJSON, note string) error {
+	var (
+		latestID                             int64
+		latest                               int
+		latestName, latestDesc, latestConfig string
+	)
+	err := tx.QueryRow(`
+		SELECT id, version, name, description, config FROM strategy_versions
+		WHERE strategy_id = ? ORDER BY version DESC LIMIT 1
+	`, strategy.ID).Scan(&latestID, &latest, &latestName, &latestDesc, &latestConfig)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to load latest version: %w", err)
+	}
+	if err == nil && latestName == strategy.Name && latestDesc == strategy.Description && latestConfig == configJSON {
+		strategy.VersionID, strategy.Version = latestID, latest
+		return nil
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO strategy_versions (strategy_id, version, name, description, config, note, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, strategy.ID, latest+1, strategy.Name, strategy.Description, configJSON, note, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save version: %w", err)
+	}
+	strategy.VersionID, _ = result.LastInsertId()
+	strategy.Version = latest + 1
+	return nil
+}
+
+// ListVersions returns a strategy's versions, newest first
+func (s *StrategyStore) ListVersions(strategyID string) ([]*StrategyVersion, error) {
+	rows, err := db.Query(`
+		SELECT id, strategy_id, version, name, description, config, note, created_at
+		FROM strategy_versions WHERE strategy_id = ?
+		ORDER BY version DESC
+	`, strategyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*StrategyVersion
+	for rows.Next() {
+		v, err := scanStrategyVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// GetVersion returns one version of a strategy
+func (s *StrategyStore) GetVersion(strategyID string, version int) (*StrategyVersion, error) {
+	row := db.QueryRow(`
+		SELECT id, strategy_id, version, name, description, config, note, created_at
+		FROM strategy_versions WHERE strategy_id = ? AND version = ?
+	`, strategyID, version)
+	return scanStrategyVersion(row)
+}
+
+// Rollback restores an earlier version's name, description and config. The
+// restore is itself a new version, so history is never rewritten.
+func (s *StrategyStore) Rollback(strategyID string, version int) (*Strategy, error) {
+	target, err := s.GetVersion(strategyID, version)
+	if err != nil {
+		return nil, fmt.Errorf("version %d not found: %w", version, err)
+	}
+	strategy, err := s.Get(strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("strategy not found: %w", err)
+	}
+
+	strategy.Name = target.Name
+	strategy.Description = target.Description
+	strategy.Config = target.Config
+	strategy.UpdatedAt = time.Now()
+
+	configJSON, err := json.Marshal(strategy.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE strategies SET name = ?, description = ?, config = ?, updated_at = ?
+		WHERE id = ?
+	`, strategy.Name, strategy.Description, string(configJSON), strategy.UpdatedAt, strategy.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveVersion(tx, strategy, string(configJSON), fmt.Sprintf("rolled back to v%d", version)); err != nil {
+		return nil, err
+	}
+
+	return strategy, tx.Commit()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanStrategyVersion(row rowScanner) (*StrategyVersion, error) {
+	var v StrategyVersion
+	var configJSON string
+	if err := row.Scan(&v.ID, &v.StrategyID, &v.Version, &v.Name, &v.Description,
+		&configJSON, &v.Note, &v.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(configJSON), &v.Config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return &v, nil
+}
+
+// DiffConfigs lists the fields that differ between two configs, by JSON
+// path. Arrays are compared as a whole.
+func DiffConfigs(from, to StrategyConfig) ([]ConfigChange, error) {
+	a, err := configMap(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := configMap(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]ConfigChange, 0)
+	diffValues("", a, b, &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func configMap(cfg StrategyConfig) (map[string]interface{}, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return m, nil
+}
+
+func diffValues(path string, a, b interface{}, changes *[]ConfigChange) {
+	am, aIsMap := a.(map[string]interface{})
+	bm, bIsMap := b.(map[string]interface{})
+	if !aIsMap || !bIsMap {
+		if !reflect.DeepEqual(a, b) {
+			*changes = append(*changes, ConfigChange{Path: path, From: a, To: b})
+		}
+		return
+	}
+
+	for key, av := range am {
+		diffValues(joinPath(path, key), av, bm[key], changes)
+	}
+	for key, bv := range bm {
+		if _, ok := am[key]; !ok {
+			diffValues(joinPath(path, key), nil, bv, changes)
+		}
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
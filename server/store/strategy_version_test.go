@@ -0,0 +1,69 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	base := DefaultStrategyConfig()
+
+	tests := []struct {
+		name   string
+		modify func(c *StrategyConfig)
+		want   []ConfigChange
+	}{
+		{
+			name:   "identical configs",
+			modify: func(c *StrategyConfig) {},
+			want:   []ConfigChange{},
+		},
+		{
+			name: "nested and top-level fields",
+			modify: func(c *StrategyConfig) {
+				c.TradingInterval = base.TradingInterval + 10
+				c.Debate.MaxRounds = 4
+			},
+			want: []ConfigChange{
+				{Path: "debate.max_rounds", From: float64(base.Debate.MaxRounds), To: float64(4)},
+				{Path: "trading_interval", From: float64(base.TradingInterval), To: float64(base.TradingInterval + 10)},
+			},
+		},
+		{
+			name: "arrays compare as a whole",
+			modify: func(c *StrategyConfig) {
+				c.CoinSource.StaticCoins = []string{"BTCUSDT", "SOLUSDT"}
+			},
+			want: []ConfigChange{
+				{Path: "coin_source.static_coins", From: toInterfaces(base.CoinSource.StaticCoins), To: []interface{}{"BTCUSDT", "SOLUSDT"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			to := DefaultStrategyConfig()
+			tt.modify(&to)
+
+			got, err := DiffConfigs(base, to)
+			if err != nil {
+				t.Fatalf("DiffConfigs() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffConfigs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// toInterfaces mirrors how a JSON round trip decodes a string slice
+func toInterfaces(values []string) interface{} {
+	if values == nil {
+		return nil
+	}
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
@@ -7,17 +7,20 @@ import (
 
 // Trade represents an executed trade from Binance
 type Trade struct {
-	ID          int64     `json:"id"`           // Binance trade ID
-	TraderID    string    `json:"trader_id"`    // Our trader ID
-	Symbol      string    `json:"symbol"`       // Trading pair
-	Side        string    `json:"side"`         // BUY or SELL
-	Price       float64   `json:"price"`        // Execution price
-	Quantity    float64   `json:"quantity"`     // Executed quantity
-	QuoteQty    float64   `json:"quote_qty"`    // Value in USDT
-	RealizedPnL float64   `json:"realized_pnl"` // Realized PnL from this trade
-	Commission  float64   `json:"commission"`   // Trading fee
-	Timestamp   time.Time `json:"timestamp"`    // Trade time
-	OrderID     int64     `json:"order_id"`     // Binance order ID
+	ID           int64     `json:"id"`            // Binance trade ID
+	TraderID     string    `json:"trader_id"`     // Our trader ID
+	Symbol       string    `json:"symbol"`        // Trading pair
+	Side         string    `json:"side"`          // BUY or SELL
+	Price        float64   `json:"price"`         // Execution price
+	Quantity     float64   `json:"quantity"`      // Executed quantity
+	QuoteQty     float64   `json:"quote_qty"`     // Value in USDT
+	RealizedPnL  float64   `json:"realized_pnl"`  // Realized PnL from this trade
+	Commission   float64   `json:"commission"`    // Trading fee
+	Timestamp    time.Time `json:"timestamp"`     // Trade time
+	OrderID      int64     `json:"order_id"`      // Binance order ID
+	PositionSide string    `json:"position_side"` // LONG or SHORT in hedge mode, BOTH in one-way mode
+
+	StrategyVersionID int64 `json:"strategy_version_id"` // Strategy version the trade's position leg was opened under
 }
 
 // TradeStore handles trade persistence
@@ -48,17 +51,35 @@ func (s *TradeStore) InitTables() error {
 	CREATE INDEX IF NOT EXISTS idx_trades_timestamp ON trades(trader_id, timestamp DESC);
 	CREATE INDEX IF NOT EXISTS idx_trades_symbol ON trades(trader_id, symbol);
 	`
-	_, err := db.Exec(query)
-	return err
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+	if err := addColumn("trades", "strategy_version_id", "INTEGER DEFAULT 0"); err != nil {
+		return err
+	}
+	return addColumn("trades", "position_side", "TEXT DEFAULT ''")
 }
 
+// tradeUpsert refreshes a resynced trade but keeps the strategy version it
+// was first stamped with, so later edits or rollbacks don't rewrite history
+const tradeUpsert = `ON CONFLICT(id) DO UPDATE SET
+		trader_id = excluded.trader_id, symbol = excluded.symbol, side = excluded.side, price = excluded.price,
+		quantity = excluded.quantity, quote_qty = excluded.quote_qty, realized_pnl = excluded.realized_pnl,
+		commission = excluded.commission, timestamp = excluded.timestamp, order_id = excluded.order_id,
+		position_side = excluded.position_side,
+		strategy_version_id = CASE WHEN COALESCE(trades.strategy_version_id, 0) = 0
+			THEN excluded.strategy_version_id ELSE trades.strategy_version_id END`
+
 // Save saves a trade to the database
 func (s *TradeStore) Save(trade *Trade) error {
 	_, err := db.Exec(`
-		INSERT OR REPLACE INTO trades (id, trader_id, symbol, side, price, quantity, quote_qty, realized_pnl, commission, timestamp, order_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO trades (id, trader_id, symbol, side, price, quantity, quote_qty, realized_pnl, commission, timestamp, order_id,
+			strategy_version_id, position_side)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`+tradeUpsert+`
 	`, trade.ID, trade.TraderID, trade.Symbol, trade.Side, trade.Price, trade.Quantity,
-		trade.QuoteQty, trade.RealizedPnL, trade.Commission, trade.Timestamp, trade.OrderID)
+		trade.QuoteQty, trade.RealizedPnL, trade.Commission, trade.Timestamp, trade.OrderID, trade.StrategyVersionID,
+		trade.PositionSide)
 	return err
 }
 
@@ -71,8 +92,10 @@ func (s *TradeStore) SaveBatch(trades []*Trade) error {
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO trades (id, trader_id, symbol, side, price, quantity, quote_qty, realized_pnl, commission, timestamp, order_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO trades (id, trader_id, symbol, side, price, quantity, quote_qty, realized_pnl, commission, timestamp, order_id,
+			strategy_version_id, position_side)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		` + tradeUpsert + `
 	`)
 	if err != nil {
 		return err
@@ -81,7 +104,8 @@ func (s *TradeStore) SaveBatch(trades []*Trade) error {
 
 	for _, trade := range trades {
 		_, err := stmt.Exec(trade.ID, trade.TraderID, trade.Symbol, trade.Side, trade.Price,
-			trade.Quantity, trade.QuoteQty, trade.RealizedPnL, trade.Commission, trade.Timestamp, trade.OrderID)
+			trade.Quantity, trade.QuoteQty, trade.RealizedPnL, trade.Commission, trade.Timestamp, trade.OrderID, trade.StrategyVersionID,
+			trade.PositionSide)
 		if err != nil {
 			return err
 		}
@@ -93,7 +117,8 @@ func (s *TradeStore) SaveBatch(trades []*Trade) error {
 // GetByTrader retrieves trades for a trader, ordered by timestamp desc
 func (s *TradeStore) GetByTrader(traderID string, limit int) ([]*Trade, error) {
 	rows, err := db.Query(`
-		SELECT id, trader_id, symbol, side, price, quantity, quote_qty, realized_pnl, commission, timestamp, order_id
+		SELECT id, trader_id, symbol, side, price, quantity, quote_qty, realized_pnl, commission, timestamp, order_id,
+			COALESCE(strategy_version_id, 0), COALESCE(position_side, '')
 		FROM trades WHERE trader_id = ?
 		ORDER BY timestamp DESC LIMIT ?
 	`, traderID, limit)
@@ -106,7 +131,8 @@ func (s *TradeStore) GetByTrader(traderID string, limit int) ([]*Trade, error) {
 	for rows.Next() {
 		var t Trade
 		if err := rows.Scan(&t.ID, &t.TraderID, &t.Symbol, &t.Side, &t.Price, &t.Quantity,
-			&t.QuoteQty, &t.RealizedPnL, &t.Commission, &t.Timestamp, &t.OrderID); err != nil {
+			&t.QuoteQty, &t.RealizedPnL, &t.Commission, &t.Timestamp, &t.OrderID, &t.StrategyVersionID,
+			&t.PositionSide); err != nil {
 			return nil, err
 		}
 		trades = append(trades, &t)
@@ -115,6 +141,85 @@ func (s *TradeStore) GetByTrader(traderID string, limit int) ([]*Trade, error) {
 	return trades, rows.Err()
 }
 
+// GetClosingFills returns the fills that closed a position leg opened at
+// since: fills on the closing side of the leg's position side, oldest first,
+// up to quantity when it's positive. Breakeven closes realize no PnL, so the
+// side is what marks a fill as closing; the quantity cap keeps a one-way
+// account's next position in the other direction, which trades on the same
+// side, out. Fills synced before position sides were recorded match any
+// position side.
+func (s *TradeStore) GetClosingFills(traderID, symbol, closeSide, positionSide string, since time.Time, quantity float64) ([]*Trade, error) {
+	rows, err := db.Query(`
+		SELECT id, trader_id, symbol, side, price, quantity, quote_qty, realized_pnl, commission, timestamp, order_id,
+			COALESCE(strategy_version_id, 0), COALESCE(position_side, '')
+		FROM trades WHERE trader_id = ? AND symbol = ? AND side = ? AND COALESCE(position_side, '') IN (?, '')
+		ORDER BY timestamp DESC LIMIT 200
+	`, traderID, symbol, closeSide, positionSide)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trades []*Trade
+	for rows.Next() {
+		var t Trade
+		if err := rows.Scan(&t.ID, &t.TraderID, &t.Symbol, &t.Side, &t.Price, &t.Quantity,
+			&t.QuoteQty, &t.RealizedPnL, &t.Commission, &t.Timestamp, &t.OrderID, &t.StrategyVersionID,
+			&t.PositionSide); err != nil {
+			return nil, err
+		}
+		// Compared here rather than in SQL, where timestamps are text
+		if t.Timestamp.Before(since) {
+			break
+		}
+		trades = append([]*Trade{&t}, trades...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Stop once the leg's quantity is closed, allowing for fill rounding
+	closed := 0.0
+	for i, t := range trades {
+		if quantity > 0 && closed >= quantity*(1-1e-9) {
+			return trades[:i], nil
+		}
+		closed += t.Quantity
+	}
+	return trades, nil
+}
+
+// GetLatestLegFills returns each position leg's latest fill stamped with a
+// strategy version, keyed by symbol and position side. Binance trade IDs
+// increase per symbol, so the highest ID is the latest fill.
+func (s *TradeStore) GetLatestLegFills(traderID string) ([]*Trade, error) {
+	rows, err := db.Query(`
+		SELECT id, trader_id, symbol, side, price, quantity, quote_qty, realized_pnl, commission, timestamp, order_id,
+			COALESCE(strategy_version_id, 0), COALESCE(position_side, '')
+		FROM trades WHERE id IN (
+			SELECT MAX(id) FROM trades
+			WHERE trader_id = ? AND strategy_version_id > 0 AND COALESCE(position_side, '') != ''
+			GROUP BY symbol, position_side
+		)
+	`, traderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trades []*Trade
+	for rows.Next() {
+		var t Trade
+		if err := rows.Scan(&t.ID, &t.TraderID, &t.Symbol, &t.Side, &t.Price, &t.Quantity,
+			&t.QuoteQty, &t.RealizedPnL, &t.Commission, &t.Timestamp, &t.OrderID, &t.StrategyVersionID,
+			&t.PositionSide); err != nil {
+			return nil, err
+		}
+		trades = append(trades, &t)
+	}
+	return trades, rows.Err()
+}
+
 // GetLastTradeTime returns the timestamp of the most recent trade for a trader (in milliseconds)
 func (s *TradeStore) GetLastTradeTime(traderID string) (int64, error) {
 	var timestampStr string
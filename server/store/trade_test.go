@@ -0,0 +1,132 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTradeStore_ResyncKeepsVersion(t *testing.T) {
+	if err := Init(t.TempDir()); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Close()
+
+	s := NewTradeStore()
+	at := time.Now().Add(-time.Hour)
+	stamped := &Trade{ID: 1, TraderID: "t1", Symbol: "BTCUSDT", Side: "BUY", Price: 60000, Quantity: 0.01, Timestamp: at, StrategyVersionID: 3}
+	unstamped := &Trade{ID: 2, TraderID: "t1", Symbol: "BTCUSDT", Side: "SELL", Price: 61000, Quantity: 0.01, Timestamp: at}
+	if err := s.SaveBatch([]*Trade{stamped, unstamped}); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+
+	// A resync after the strategy moved to version 5 refreshes the fills only
+	resync := []*Trade{
+		{ID: 1, TraderID: "t1", Symbol: "BTCUSDT", Side: "BUY", Price: 60000, Quantity: 0.01, Timestamp: at, Commission: 0.5, StrategyVersionID: 5},
+		{ID: 2, TraderID: "t1", Symbol: "BTCUSDT", Side: "SELL", Price: 61000, Quantity: 0.01, Timestamp: at, StrategyVersionID: 5},
+	}
+	if err := s.SaveBatch(resync); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+
+	trades, err := s.GetByTrader("t1", 10)
+	if err != nil {
+		t.Fatalf("GetByTrader() error = %v", err)
+	}
+	versions := make(map[int64]int64)
+	for _, tr := range trades {
+		versions[tr.ID] = tr.StrategyVersionID
+		if tr.ID == 1 && tr.Commission != 0.5 {
+			t.Errorf("trade 1 commission = %v, want the resynced 0.5", tr.Commission)
+		}
+	}
+	if len(trades) != 2 || versions[1] != 3 || versions[2] != 5 {
+		t.Errorf("versions = %v, want trade 1 kept at 3 and unstamped trade 2 set to 5", versions)
+	}
+}
+
+func TestTradeStore_GetClosingFills(t *testing.T) {
+	if err := Init(t.TempDir()); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Close()
+
+	s := NewTradeStore()
+	entry := time.Now().Add(-time.Hour)
+	fill := func(id int64, side, positionSide string, qty, pnl float64, offset time.Duration) *Trade {
+		return &Trade{ID: id, TraderID: "t1", Symbol: "BTCUSDT", Side: side, PositionSide: positionSide,
+			Price: 60000, Quantity: qty, RealizedPnL: pnl, Timestamp: entry.Add(offset)}
+	}
+	trades := []*Trade{
+		fill(1, "SELL", "BOTH", 0.02, 5, -time.Minute),  // before the entry
+		fill(2, "SELL", "BOTH", 0.01, 3, time.Minute),   // partial close
+		fill(3, "SELL", "BOTH", 0.01, 0, 2*time.Minute), // breakeven close
+		fill(4, "SELL", "BOTH", 0.01, 0, 3*time.Minute), // the next short's open
+		fill(5, "SELL", "LONG", 0.01, 2, time.Minute),   // a hedge leg
+		fill(6, "SELL", "", 0.01, 1, 4*time.Minute),     // synced before position sides
+	}
+	if err := s.SaveBatch(trades); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		positionSide string
+		quantity     float64
+		want         []int64
+	}{
+		{"one-way leg stops at its quantity", "BOTH", 0.02, []int64{2, 3}},
+		{"hedge leg is uncapped", "LONG", 0, []int64{5, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fills, err := s.GetClosingFills("t1", "BTCUSDT", "SELL", tt.positionSide, entry, tt.quantity)
+			if err != nil {
+				t.Fatalf("GetClosingFills() error = %v", err)
+			}
+			var ids []int64
+			for _, f := range fills {
+				ids = append(ids, f.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+				t.Errorf("GetClosingFills() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestTradeStore_GetLatestLegFills(t *testing.T) {
+	if err := Init(t.TempDir()); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer Close()
+
+	s := NewTradeStore()
+	at := time.Now().Add(-time.Hour)
+	fill := func(id int64, symbol, positionSide string, version int64) *Trade {
+		return &Trade{ID: id, TraderID: "t1", Symbol: symbol, Side: "BUY", PositionSide: positionSide,
+			Price: 100, Quantity: 1, Timestamp: at.Add(time.Duration(id) * time.Minute), StrategyVersionID: version}
+	}
+	trades := []*Trade{
+		fill(1, "BTCUSDT", "LONG", 2),
+		fill(2, "BTCUSDT", "LONG", 3),  // The long leg's latest
+		fill(3, "BTCUSDT", "SHORT", 3), // The short leg's latest
+		fill(4, "BTCUSDT", "SHORT", 0), // Unstamped
+		fill(5, "ETHUSDT", "", 4),      // Synced before position sides
+	}
+	if err := s.SaveBatch(trades); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+
+	fills, err := s.GetLatestLegFills("t1")
+	if err != nil {
+		t.Fatalf("GetLatestLegFills() error = %v", err)
+	}
+	got := make(map[string]int64)
+	for _, f := range fills {
+		got[f.Symbol+"_"+f.PositionSide] = f.ID
+	}
+	if fmt.Sprint(got) != fmt.Sprint(map[string]int64{"BTCUSDT_LONG": 2, "BTCUSDT_SHORT": 3}) {
+		t.Errorf("GetLatestLegFills() = %v, want the long's fill 2 and the short's fill 3", got)
+	}
+}
@@ -24,14 +24,25 @@ type Trader struct {
 
 // TraderConfig holds trader-specific configuration
 type TraderConfig struct {
-	// AI Model settings (pre-configured, not selectable yet)
-	AIProvider string `json:"ai_provider"` // "openrouter"
+	// AI model settings; the provider decides where decision calls go
+	AIProvider string `json:"ai_provider"` // openrouter (default), openai, anthropic, deepseek, ollama, local
 	AIModel    string `json:"ai_model"`    // model name
 
 	// Per-trader OpenRouter config (falls back to global if empty)
 	OpenRouterAPIKey string `json:"openrouter_api_key"`
 	OpenRouterModel  string `json:"openrouter_model"`
 
+	// Backends tried in order when the primary model fails or its circuit is
+	// open. Native and local providers use the keys/URLs from global settings.
+	FallbackModels []AIBackend `json:"fallback_models,omitempty"`
+
+	// AI spend limit per calendar month in USD (0 = unlimited). Once reached,
+	// the trader's AI calls are paused until the next month.
+	MonthlyAIBudgetUSD float64 `json:"monthly_ai_budget_usd,omitempty"`
+
+	// Save every AI request/response so decisions can be replayed offline
+	RecordAICalls bool `json:"record_ai_calls,omitempty"`
+
 	// Reasoning mode settings
 	EnableReasoning bool   `json:"enable_reasoning"` // Use chain-of-thought reasoning
 	ReasoningModel  string `json:"reasoning_model"`  // Model to use for reasoning (e.g., "deepseek/deepseek-r1")
@@ -42,6 +53,12 @@ type TraderConfig struct {
 	Testnet   bool   `json:"testnet"`
 }
 
+// AIBackend is one provider/model in a trader's AI fallback chain
+type AIBackend struct {
+	Provider string `json:"provider"` // openrouter, openai, anthropic, deepseek, ollama, local
+	Model    string `json:"model"`
+}
+
 // TraderStore handles trader persistence
 type TraderStore struct{}
 
@@ -192,6 +209,8 @@ type Decision struct {
 	AIResponse string    `json:"ai_response"`
 	Decisions  string    `json:"decisions"` // JSON array of decisions
 	Executed   bool      `json:"executed"`
+
+	StrategyVersionID int64 `json:"strategy_version_id"` // Strategy version the decision was made under
 }
 
 // DecisionStore handles decision persistence
@@ -205,10 +224,10 @@ func (s *DecisionStore) Create(decision *Decision) error {
 	decision.Timestamp = time.Now()
 
 	result, err := db.Exec(`
-		INSERT INTO decisions (trader_id, timestamp, market_data, ai_response, decisions, executed)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO decisions (trader_id, timestamp, market_data, ai_response, decisions, executed, strategy_version_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`, decision.TraderID, decision.Timestamp, decision.MarketData,
-		decision.AIResponse, decision.Decisions, decision.Executed)
+		decision.AIResponse, decision.Decisions, decision.Executed, decision.StrategyVersionID)
 	if err != nil {
 		return err
 	}
@@ -220,7 +239,8 @@ func (s *DecisionStore) Create(decision *Decision) error {
 
 func (s *DecisionStore) ListByTrader(traderID string, limit int) ([]*Decision, error) {
 	rows, err := db.Query(`
-		SELECT id, trader_id, timestamp, market_data, ai_response, decisions, executed
+		SELECT id, trader_id, timestamp, market_data, ai_response, decisions, executed,
+			COALESCE(strategy_version_id, 0)
 		FROM decisions WHERE trader_id = ?
 		ORDER BY timestamp DESC LIMIT ?
 	`, traderID, limit)
@@ -233,7 +253,7 @@ func (s *DecisionStore) ListByTrader(traderID string, limit int) ([]*Decision, e
 	for rows.Next() {
 		var d Decision
 		if err := rows.Scan(&d.ID, &d.TraderID, &d.Timestamp, &d.MarketData,
-			&d.AIResponse, &d.Decisions, &d.Executed); err != nil {
+			&d.AIResponse, &d.Decisions, &d.Executed, &d.StrategyVersionID); err != nil {
 			return nil, err
 		}
 		decisions = append(decisions, &d)
@@ -244,14 +264,15 @@ func (s *DecisionStore) ListByTrader(traderID string, limit int) ([]*Decision, e
 
 func (s *DecisionStore) GetLatest(traderID string) (*Decision, error) {
 	row := db.QueryRow(`
-		SELECT id, trader_id, timestamp, market_data, ai_response, decisions, executed
+		SELECT id, trader_id, timestamp, market_data, ai_response, decisions, executed,
+			COALESCE(strategy_version_id, 0)
 		FROM decisions WHERE trader_id = ?
 		ORDER BY timestamp DESC LIMIT 1
 	`, traderID)
 
 	var d Decision
 	err := row.Scan(&d.ID, &d.TraderID, &d.Timestamp, &d.MarketData,
-		&d.AIResponse, &d.Decisions, &d.Executed)
+		&d.AIResponse, &d.Decisions, &d.Executed, &d.StrategyVersionID)
 	if err != nil {
 		return nil, err
 	}
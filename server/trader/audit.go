@@ -0,0 +1,117 @@
+package trader
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"auto-trader-ahh/exchange"
+	"auto-trader-ahh/store"
+)
+
+// newAudit starts the audit of a symbol's decision
+func (e *Engine) newAudit(symbol, source, model string) *store.DecisionAudit {
+	return &store.DecisionAudit{
+		TraderID:          e.id,
+		Symbol:            symbol,
+		Timestamp:         time.Now(),
+		Source:            source,
+		AIModel:           model,
+		StrategyVersionID: e.strategyVersionID(),
+	}
+}
+
+// saveAudit completes a trade log's audit with the outcome and saves it
+// under the cycle's decision record
+func (e *Engine) saveAudit(tradeLog *TradeLog, decisionID int64) {
+	a := tradeLog.Audit
+	if a == nil {
+		return
+	}
+	a.DecisionID = decisionID
+	a.Action = tradeLog.Action
+	a.RawOutput = tradeLog.RawAI
+	a.CalibratedConfidence = tradeLog.CalibratedConfidence
+	a.Error = tradeLog.Error
+	a.Executed = tradeLog.Executed
+	a.RealizedPnL = tradeLog.RealizedPnL
+	if a.MarketSnapshot == "" {
+		a.MarketSnapshot = tradeLog.MarketData
+	}
+	if len(tradeLog.Prompts) > 0 {
+		a.Prompts, _ = json.Marshal(tradeLog.Prompts)
+	}
+	if d := tradeLog.Decision; d != nil {
+		a.Action = d.Action
+		a.Confidence = d.Confidence
+		a.ParsePath = d.ParsePath
+		a.Decision, _ = json.Marshal(d)
+	}
+	// Errors that no guard claimed, e.g. a failed order, still say what stopped the trade
+	if a.Error != "" && a.BlockReason == "" && !a.Executed {
+		a.BlockReason = store.BlockReasonError
+	}
+
+	if err := e.auditStore.Create(a); err != nil {
+		log.Printf("[%s][%s] %v", e.name, a.Symbol, err)
+	}
+}
+
+// auditOrder converts an exchange order for the audit
+func auditOrder(purpose string, o *exchange.Order) store.AuditOrder {
+	price := o.AvgPrice
+	if price == 0 {
+		price = o.Price
+	}
+	qty := o.ExecutedQty
+	if qty == 0 {
+		qty = o.OrigQty
+	}
+	return store.AuditOrder{
+		Purpose:  purpose,
+		OrderID:  o.OrderID,
+		Side:     o.Side,
+		Type:     o.Type,
+		Quantity: qty,
+		Price:    price,
+		Status:   o.Status,
+	}
+}
+
+// auditBrackets records the SL/TP orders just placed for a position leg
+func (e *Engine) auditBrackets(audit *store.DecisionAudit, symbol, side string, quantity float64) {
+	if audit == nil {
+		return
+	}
+	e.bracketOrdersMutex.RLock()
+	bracket := e.bracketOrders[getPositionKey(symbol, side)]
+	e.bracketOrdersMutex.RUnlock()
+	if bracket == nil {
+		return
+	}
+
+	closeSide, dir := "SELL", 1.0
+	if side == "SHORT" {
+		closeSide, dir = "BUY", -1.0
+	}
+	if bracket.StopLossOrderID > 0 {
+		audit.AddOrder(store.AuditOrder{
+			Purpose:  store.AuditOrderStopLoss,
+			OrderID:  bracket.StopLossOrderID,
+			Side:     closeSide,
+			Type:     "STOP_MARKET",
+			Quantity: quantity,
+			Price:    bracket.EntryPrice * (1 - dir*bracket.StopLossPct/100),
+		})
+	}
+	if bracket.TakeProfitOrderID > 0 {
+		audit.AddOrder(store.AuditOrder{
+			Purpose:  store.AuditOrderTakeProfit,
+			OrderID:  bracket.TakeProfitOrderID,
+			Side:     closeSide,
+			Type:     "TAKE_PROFIT_MARKET",
+			Quantity: quantity,
+			Price:    bracket.EntryPrice * (1 + dir*bracket.TakeProfitPct/100),
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package trader
+
+import (
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"auto-trader-ahh/calibration"
+	"auto-trader-ahh/store"
+)
+
+// calibrationLookback is how many closed positions the reliability curves are built from
+const calibrationLookback = 500
+
+// entryNote is the decision behind an order that opened a position, kept
+// until the position is journaled
+type entryNote struct {
+	confidence float64
+	model      string
+	at         time.Time
+}
+
+// calibrationSettings returns the strategy's calibration settings with defaults applied
+func (e *Engine) calibrationSettings() (mode string, minTrades int, targetHitRate float64, sizing bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.strategy == nil {
+		return store.CalibrationOff, 0, 0, false
+	}
+	rc := e.strategy.Config.RiskControl
+	minTrades = rc.CalibrationMinTrades
+	if minTrades <= 0 {
+		minTrades = 20
+	}
+	targetHitRate = rc.CalibrationTargetHitRate
+	if targetHitRate <= 0 {
+		targetHitRate = 50
+	}
+	return rc.ConfidenceCalibration, minTrades, targetHitRate, rc.CalibratedSizing
+}
+
+// decisionModel names what makes the engine's own decisions: the debate
+// panel or the trader's model
+func (e *Engine) decisionModel() string {
+	if e.debateMode() {
+		return store.DecisionModeDebate
+	}
+	if e.aiClient != nil {
+		return e.aiClient.GetModel()
+	}
+	return ""
+}
+
+// externalModel is the calibration key for an external decision's source.
+// Debate sources carry their session ("debate:<id>") but are all the panel.
+func externalModel(source string) string {
+	if source == store.DecisionModeDebate || strings.HasPrefix(source, store.DecisionModeDebate+":") {
+		return store.DecisionModeDebate
+	}
+	return source
+}
+
+// noteEntry remembers the confidence and model of a decision that opened a
+// position so the journal can record them
+func (e *Engine) noteEntry(symbol, action string, confidence float64, model string) {
+	side := "long"
+	if action == "SELL" || action == "open_short" {
+		side = "short"
+	}
+	e.mu.Lock()
+	e.entryNotes[journalKey(symbol, side)] = entryNote{confidence: confidence, model: model, at: time.Now()}
+	e.mu.Unlock()
+}
+
+// takeEntryNote returns and forgets the note for a leg first seen at
+// firstSeen. Notes from before the leg opened belong to an order that never
+// filled and are dropped.
+func (e *Engine) takeEntryNote(key string, firstSeen time.Time) (entryNote, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	note, ok := e.entryNotes[key]
+	delete(e.entryNotes, key)
+	if !ok || firstSeen.Before(note.at.Add(-time.Minute)) {
+		return entryNote{}, false
+	}
+	return note, true
+}
+
+// refreshCalibration rebuilds the reliability curves from the journal
+func (e *Engine) refreshCalibration() {
+	if mode, _, _, sizing := e.calibrationSettings(); mode == store.CalibrationOff && !sizing {
+		return
+	}
+	positions, err := e.positionStore.GetClosedPositions(e.id, calibrationLookback)
+	if err != nil {
+		log.Printf("[%s] Failed to load closed positions for calibration: %v", e.name, err)
+		return
+	}
+	curves := calibration.Build(positions, calibration.DefaultBucketWidth)
+	e.mu.Lock()
+	e.calibrationCurves = curves
+	e.mu.Unlock()
+}
+
+// calibrationCurve returns a model's curve once it has enough trades, or nil
+func (e *Engine) calibrationCurve(model string) *calibration.Curve {
+	_, minTrades, _, _ := e.calibrationSettings()
+	e.mu.RLock()
+	curve := calibration.Find(e.calibrationCurves, model)
+	e.mu.RUnlock()
+	if curve == nil || curve.Trades < minTrades {
+		return nil
+	}
+	return curve
+}
+
+// confidenceGate returns the confidence a model's decision is gated on and
+// the minimum it must reach. Without calibration, or until the model has
+// enough closed trades, that is the stated confidence and MinConfidence.
+func (e *Engine) confidenceGate(model string, confidence float64) (effective, minimum float64) {
+	effective, minimum = confidence, float64(e.getMinConfidence())
+	mode, minTrades, targetHitRate, _ := e.calibrationSettings()
+	if mode == store.CalibrationOff {
+		return effective, minimum
+	}
+	curve := e.calibrationCurve(model)
+	if curve == nil {
+		return effective, minimum
+	}
+
+	switch mode {
+	case store.CalibrationProbability:
+		effective = curve.Probability(confidence)
+	case store.CalibrationAutoThreshold:
+		// Only ever raises the configured minimum. The trades above a floor
+		// are a subset of the curve's, so they need only half its minimum.
+		if threshold, ok := curve.Threshold(targetHitRate, minTrades/2); ok {
+			minimum = math.Max(minimum, float64(threshold))
+		}
+	}
+	return effective, minimum
+}
+
+// calibratedSize shrinks a new position by how far the model's stated
+// confidence overstates its calibrated win probability. It never grows a
+// position.
+func (e *Engine) calibratedSize(positionSizeUSD, confidence float64, model string) float64 {
+	if _, _, _, sizing := e.calibrationSettings(); !sizing || confidence <= 0 {
+		return positionSizeUSD
+	}
+	curve := e.calibrationCurve(model)
+	if curve == nil {
+		return positionSizeUSD
+	}
+	factor := math.Min(1, math.Max(0.25, curve.Probability(confidence)/confidence))
+	return positionSizeUSD * factor
+}
@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"auto-trader-ahh/ai"
+	"auto-trader-ahh/calibration"
 	"auto-trader-ahh/config"
+	"auto-trader-ahh/debate"
 	"auto-trader-ahh/decision"
 	"auto-trader-ahh/events"
 	"auto-trader-ahh/exchange"
@@ -33,17 +35,21 @@ type Engine struct {
 	aiClient     *ai.Client          // Legacy AI client (for backward compatibility)
 	binance      *exchange.BinanceClient
 	dataProvider *market.DataProvider
+	stream       *market.Stream // Shared WebSocket market feed (nil = REST only)
 	notifier     Notifier
 
 	// Decision Engine (NOFX-style XML parsing with CoT)
 	mcpClient      mcp.AIClient
+	aiFallback     *mcp.FallbackClient // Set when the trader has fallback backends
 	decisionEngine *decision.Engine
-	callCount      int       // Number of AI calls made
-	startTime      time.Time // Engine start time
+	debateEngine   *debate.Engine // Shared debate engine for debate decision mode
+	callCount      int            // Number of AI calls made
+	startTime      time.Time      // Engine start time
 
 	running bool
 	stopCh  chan struct{}
 	mu      sync.RWMutex
+	execMu  sync.Mutex // Serializes trade execution between the trading loop and external decisions
 
 	// State
 	lastDecisions    map[string]*ai.TradingDecision
@@ -55,6 +61,23 @@ type Engine struct {
 	decisionStore *store.DecisionStore
 	equityStore   *store.EquityStore
 	tradeStore    *store.TradeStore
+	positionStore *store.PositionStore
+	lessonStore   *store.TradeLessonStore
+	auditStore    *store.DecisionAuditStore
+
+	// Self-reflection: the position journal and lessons from closed trades
+	closeReasons   map[string]string    // key: journalKey -> why the engine closed the position
+	untrackedSince map[string]time.Time // key: journalKey -> when a closed position was first missing its fills
+	lessons        []*store.TradeLesson
+	lastReflection time.Time
+
+	// Confidence calibration: the decisions behind new positions and the
+	// reliability curves built from the journal
+	entryNotes        map[string]entryNote // key: journalKey -> decision that opened the position
+	calibrationCurves []*calibration.Curve
+
+	// Strategy versions new position legs were opened under, for stamping their fills
+	legVersions map[string][]legVersion // key: "symbol_positionSide" -> recent opens, oldest first
 
 	// Position Management - Peak P&L tracking
 	peakPnLCache      map[string]float64 // key: "symbol_side" -> peak P&L %
@@ -73,7 +96,7 @@ type Engine struct {
 	orderSyncStop chan struct{}
 
 	// SL/TP Order Tracking
-	bracketOrders      map[string]*BracketOrderIDs // key: symbol -> SL/TP order IDs
+	bracketOrders      map[string]*BracketOrderIDs // key: symbol_side -> SL/TP order IDs
 	bracketOrdersMutex sync.RWMutex
 
 	// Dynamic Coin Source Cache
@@ -98,8 +121,14 @@ type TradeLog struct {
 	RawAI       string
 	MarketData  string
 	Error       string
-	CoTTrace    string  // Chain of thought from AI reasoning
-	RealizedPnL float64 // PnL realized when closing a position
+	CoTTrace    string               // Chain of thought from AI reasoning
+	RealizedPnL float64              // PnL realized when closing a position
+	Executed    bool                 // An order was placed for the decision
+	Prompts     []decision.PromptRef // Template versions that rendered the prompts
+	Lessons     []int64              // Reflection lessons in the prompt
+
+	CalibratedConfidence float64              // Confidence the decision was gated on, when calibration changed it
+	Audit                *store.DecisionAudit // Inputs, guard verdicts and orders, saved with the decision record
 }
 
 // NewEngine creates a new trading engine with strategy support
@@ -127,17 +156,7 @@ func NewEngine(id, name string, aiClient *ai.Client, binance *exchange.BinanceCl
 
 	// Configure validation from strategy if available
 	if strategy != nil {
-		validationCfg := &decision.ValidationConfig{
-			AccountEquity:     10000, // Will be updated at runtime
-			BTCETHLeverage:    strategy.Config.RiskControl.BTCETHMaxLeverage,
-			AltcoinLeverage:   strategy.Config.RiskControl.AltcoinMaxLeverage,
-			BTCETHPosRatio:    strategy.Config.RiskControl.BTCETHMaxPositionValueRatio,
-			AltcoinPosRatio:   strategy.Config.RiskControl.AltcoinMaxPositionValueRatio,
-			MinPositionBTCETH: strategy.Config.RiskControl.MinPositionSizeBTCETH,
-			MinPositionAlt:    strategy.Config.RiskControl.MinPositionSize,
-			MinRiskReward:     strategy.Config.RiskControl.MinRiskRewardRatio,
-		}
-		decisionEngine.SetValidationConfig(validationCfg)
+		decisionEngine.SetValidationConfig(strategyValidationConfig(strategy, 10000)) // Equity is updated at runtime
 	}
 
 	return &Engine{
@@ -158,6 +177,13 @@ func NewEngine(id, name string, aiClient *ai.Client, binance *exchange.BinanceCl
 		decisionStore:  store.NewDecisionStore(),
 		equityStore:    store.NewEquityStore(),
 		tradeStore:     store.NewTradeStore(),
+		positionStore:  store.NewPositionStore(),
+		lessonStore:    store.NewTradeLessonStore(),
+		auditStore:     store.NewDecisionAuditStore(),
+		closeReasons:   make(map[string]string),
+		untrackedSince: make(map[string]time.Time),
+		entryNotes:     make(map[string]entryNote),
+		legVersions:    make(map[string][]legVersion),
 
 		// Initialize position management maps
 		peakPnLCache:          make(map[string]float64),
@@ -197,6 +223,9 @@ func (e *Engine) Start(ctx context.Context) error {
 	e.lastResetTime = time.Now()
 	log.Printf("[%s] Connected to Binance. Balance: $%.2f", e.name, account.TotalWalletBalance)
 
+	// Detect (and if the strategy allows, enable) hedge mode before any order is placed
+	e.syncPositionMode(ctx)
+
 	// Set leverage for all pairs (separate limits for BTC/ETH vs altcoins)
 	coins := e.getTradingPairs()
 	for _, pair := range coins {
@@ -208,6 +237,18 @@ func (e *Engine) Start(ctx context.Context) error {
 		}
 	}
 
+	// Fills of legs opened before a restart keep the version they were opened under
+	e.restoreLegVersions()
+
+	// Lessons from earlier runs are used until the first reflection pass
+	if lessons, err := e.lessonStore.ListByTrader(e.id); err != nil {
+		log.Printf("[%s] Failed to load lessons: %v", e.name, err)
+	} else {
+		e.mu.Lock()
+		e.lessons = lessons
+		e.mu.Unlock()
+	}
+
 	// Start background goroutines
 	go e.tradingLoop(ctx)
 	go e.startDrawdownMonitor(ctx)
@@ -229,9 +270,19 @@ func (e *Engine) Stop() {
 	if e.orderSyncStop != nil {
 		close(e.orderSyncStop)
 	}
+	if e.stream != nil {
+		e.stream.Release(e.id)
+	}
 	e.running = false
 }
 
+// SetMarketStream attaches the shared market stream used for candles and
+// real-time mark prices. Must be called before Start.
+func (e *Engine) SetMarketStream(stream *market.Stream) {
+	e.stream = stream
+	e.dataProvider.SetStream(stream, e.id)
+}
+
 func (e *Engine) IsRunning() bool {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
@@ -268,7 +319,7 @@ func (e *Engine) SetStrategy(strategy *store.Strategy) {
 		log.Printf("[%s] Strategy updated: TrailingStop changed from %v to %v", e.name, oldTrailingStop, newTrailingStop)
 	}
 
-	log.Printf("[%s] Strategy config reloaded successfully", e.name)
+	log.Printf("[%s] Strategy config reloaded successfully (v%d)", e.name, strategy.Version)
 }
 
 // GetStrategyID returns the current strategy ID
@@ -282,6 +333,18 @@ func (e *Engine) GetStrategyID() string {
 	return ""
 }
 
+// strategyVersionID returns the strategy version the engine is running,
+// stamped on its decisions, trades and equity snapshots
+func (e *Engine) strategyVersionID() int64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.strategy != nil {
+		return e.strategy.VersionID
+	}
+	return 0
+}
+
 func (e *Engine) getTradingPairs() []string {
 	if e.strategy != nil {
 		sourceType := e.strategy.Config.CoinSource.SourceType
@@ -402,11 +465,12 @@ func (e *Engine) runTradingCycle(ctx context.Context) {
 
 		// Save equity snapshot
 		e.equityStore.Save(&store.EquitySnapshot{
-			TraderID:      e.id,
-			Timestamp:     time.Now(),
-			TotalEquity:   account.TotalMarginBalance,
-			Balance:       account.TotalWalletBalance,
-			UnrealizedPnL: account.TotalUnrealizedProfit,
+			TraderID:          e.id,
+			Timestamp:         time.Now(),
+			TotalEquity:       account.TotalMarginBalance,
+			Balance:           account.TotalWalletBalance,
+			UnrealizedPnL:     account.TotalUnrealizedProfit,
+			StrategyVersionID: e.strategyVersionID(),
 		})
 	}
 
@@ -418,7 +482,7 @@ func (e *Engine) runTradingCycle(ctx context.Context) {
 		e.mu.Lock()
 		e.positions = make(map[string]*exchange.Position)
 		for i := range positions {
-			e.positions[positions[i].Symbol] = &positions[i]
+			e.positions[positionMapKey(&positions[i])] = &positions[i]
 		}
 		e.mu.Unlock()
 	}
@@ -433,10 +497,12 @@ func (e *Engine) runTradingCycle(ctx context.Context) {
 		maxPositions = e.strategy.Config.RiskControl.MaxPositions
 	}
 
-	// Collect active positions
+	// Collect active positions (a hedged symbol holds two legs but is analyzed once)
 	activeSymbols := make([]string, 0)
+	seenSymbols := make(map[string]bool)
 	for _, pos := range e.positions {
-		if pos.PositionAmt != 0 {
+		if pos.PositionAmt != 0 && !seenSymbols[pos.Symbol] {
+			seenSymbols[pos.Symbol] = true
 			activeSymbols = append(activeSymbols, pos.Symbol)
 		}
 	}
@@ -451,17 +517,39 @@ func (e *Engine) runTradingCycle(ctx context.Context) {
 		pairsToAnalyze = e.getTradingPairs()
 	}
 
+	// Debate mode: one panel debates all pairs, its consensus replaces the per-symbol AI call
+	var panel *debatePanel
+	if e.debateMode() {
+		panel = e.runDebatePanel(ctx, pairsToAnalyze)
+	}
+
 	// Process each trading pair
 	allDecisions := make([]map[string]interface{}, 0)
+	tradeLogs := make([]*TradeLog, 0, len(pairsToAnalyze))
 	for _, symbol := range pairsToAnalyze {
 		log.Printf("[%s] Analyzing %s...", e.name, symbol)
 
-		tradeLog := e.analyzeAndTrade(ctx, symbol)
+		var tradeLog *TradeLog
+		if panel != nil {
+			tradeLog = e.tradeOnPanelDecision(ctx, symbol, panel)
+		} else {
+			tradeLog = e.analyzeAndTrade(ctx, symbol)
+		}
 
 		decisionData := map[string]interface{}{
 			"symbol": symbol,
 			"action": "NONE",
 		}
+		if panel != nil && panel.session != nil {
+			decisionData["source"] = "debate"
+			decisionData["debate_session_id"] = panel.session.ID
+		}
+		if len(tradeLog.Prompts) > 0 {
+			decisionData["prompts"] = tradeLog.Prompts
+		}
+		if len(tradeLog.Lessons) > 0 {
+			decisionData["lessons"] = tradeLog.Lessons
+		}
 
 		if tradeLog.Error != "" {
 			log.Printf("[%s][%s] Error: %s", e.name, symbol, tradeLog.Error)
@@ -473,7 +561,13 @@ func (e *Engine) runTradingCycle(ctx context.Context) {
 
 			decisionData["action"] = tradeLog.Decision.Action
 			decisionData["confidence"] = tradeLog.Decision.Confidence
+			if tradeLog.CalibratedConfidence != 0 {
+				decisionData["calibrated_confidence"] = tradeLog.CalibratedConfidence
+			}
 			decisionData["reasoning"] = tradeLog.Decision.Reasoning
+			if tradeLog.Decision.ParsePath != "" {
+				decisionData["parse_path"] = tradeLog.Decision.ParsePath
+			}
 
 			// Include realized PnL if position was closed
 			if tradeLog.RealizedPnL != 0 {
@@ -483,18 +577,24 @@ func (e *Engine) runTradingCycle(ctx context.Context) {
 		}
 
 		allDecisions = append(allDecisions, decisionData)
-
-		// Small delay between pairs to avoid rate limits
-		time.Sleep(2 * time.Second)
+		tradeLogs = append(tradeLogs, tradeLog)
 	}
 
-	// Save decision record
+	// Save decision record (debate mode keeps the transcript with it)
 	decisionsJSON, _ := json.Marshal(allDecisions)
-	e.decisionStore.Create(&store.Decision{
-		TraderID:  e.id,
-		Decisions: string(decisionsJSON),
-		Executed:  true,
-	})
+	record := &store.Decision{
+		TraderID:          e.id,
+		Decisions:         string(decisionsJSON),
+		Executed:          true,
+		StrategyVersionID: e.strategyVersionID(),
+	}
+	if panel != nil {
+		record.AIResponse = debateTranscript(panel.session)
+	}
+	e.decisionStore.Create(record)
+	for _, tradeLog := range tradeLogs {
+		e.saveAudit(tradeLog, record.ID)
+	}
 
 	// Check if daily loss limit has been exceeded
 	if e.checkDailyLoss() {
@@ -504,6 +604,11 @@ func (e *Engine) runTradingCycle(ctx context.Context) {
 	// Sync trade history from Binance (captures SL/TP fills)
 	e.syncTradeHistory(ctx)
 
+	// Journal opened and closed positions, then learn from the closed ones
+	e.journalPositions(ctx)
+	e.reflectIfDue()
+	e.refreshCalibration()
+
 	log.Printf("[%s] === Trading cycle complete ===", e.name)
 }
 
@@ -511,6 +616,7 @@ func (e *Engine) analyzeAndTrade(ctx context.Context, symbol string) *TradeLog {
 	tradeLog := &TradeLog{
 		Timestamp: time.Now(),
 		Symbol:    symbol,
+		Audit:     e.newAudit(symbol, store.DecisionModeSingleModel, e.decisionModel()),
 	}
 
 	// Get market data with strategy config
@@ -537,16 +643,6 @@ func (e *Engine) analyzeAndTrade(ctx context.Context, symbol string) *TradeLog {
 	// Format data for AI
 	formattedData := e.dataProvider.FormatForAI(marketData)
 
-	// Inject Turbo Mode instructions
-	if e.strategy != nil && e.strategy.Config.TurboMode {
-		formattedData += "\n\n*** TURBO MODE: HIGH FREQUENCY SCALPING ***\n"
-		formattedData += "- EXECUTION STYLE: Aggressive. Do not wait for perfect confirmation.\n"
-		formattedData += "- STRATEGY: Chase Momentum & Volatility. Focus on Volume Spikes.\n"
-		formattedData += "- PERMISSION: You are authorized to ignore conservative safety filters if Price Action is strong.\n"
-		formattedData += "- ENTRY: Enter immediately on Candle Close if trend aligns. Don't hesitate.\n"
-		formattedData += "- GOAL: Capture quick moves. Activity > Passivity.\n"
-	}
-
 	tradeLog.MarketData = formattedData
 
 	// Add account info
@@ -558,13 +654,20 @@ func (e *Engine) analyzeAndTrade(ctx context.Context, symbol string) *TradeLog {
 		formattedData += fmt.Sprintf("Unrealized PnL: $%.2f\n", e.account.TotalUnrealizedProfit)
 	}
 
-	// Add position info if exists
-	pos, hasPosition := e.positions[symbol]
+	// Add position info if exists (hedge mode can hold a LONG and a SHORT leg)
+	longPos, shortPos := e.symbolPositionsLocked(symbol)
 	e.mu.RUnlock()
+	hedging := e.hedgeEnabled()
 
-	if hasPosition {
+	if longPos == nil && shortPos == nil {
+		formattedData += "\n--- No Current Position ---\n"
+	}
+	for _, pos := range []*exchange.Position{longPos, shortPos} {
+		if pos == nil {
+			continue
+		}
 		formattedData += "\n--- Current Position ---\n"
-		sideStr := map[bool]string{true: "LONG", false: "SHORT"}[pos.PositionAmt > 0]
+		sideStr := positionSide(pos)
 		formattedData += fmt.Sprintf("Side: %s\n", sideStr)
 
 		duration := e.GetHoldDuration(symbol, sideStr)
@@ -574,8 +677,19 @@ func (e *Engine) analyzeAndTrade(ctx context.Context, symbol string) *TradeLog {
 		formattedData += fmt.Sprintf("Entry Price: $%.2f\n", pos.EntryPrice)
 		formattedData += fmt.Sprintf("Mark Price: $%.2f\n", pos.MarkPrice)
 		formattedData += fmt.Sprintf("Unrealized PnL: $%.2f\n", pos.UnrealizedProfit)
-	} else {
-		formattedData += "\n--- No Current Position ---\n"
+	}
+
+	if hedging {
+		formattedData += "\n--- Hedge Mode ---\n"
+		formattedData += "- A LONG and a SHORT can be held on this symbol at the same time.\n"
+		formattedData += "- BUY opens/adds the LONG leg, SELL opens/adds the SHORT leg.\n"
+		formattedData += "- To close one leg use action \"close_long\" or \"close_short\" (CLOSE is ambiguous when both legs are open).\n"
+	}
+
+	// Limit entries with AI pricing: let the model suggest the limit price
+	if entry := e.entryExecution(); entry.OrderType != store.EntryOrderMarket && entry.PriceSource == "ai" {
+		formattedData += "\n--- Entry Orders ---\n"
+		formattedData += fmt.Sprintf("- Entries use %s orders. For BUY/SELL you may add \"entry_price\" (limit price); omit it to join the best bid/ask.\n", entry.OrderType)
 	}
 
 	// Add strategy rules
@@ -583,26 +697,36 @@ func (e *Engine) analyzeAndTrade(ctx context.Context, symbol string) *TradeLog {
 		formattedData += fmt.Sprintf("\n--- Strategy Rules ---\n%s\n", e.strategy.Config.CustomPrompt)
 	}
 
+	// Add lessons from closed trades
+	if lessons := e.lessonDigest([]string{symbol}); len(lessons) > 0 {
+		formattedData += "\n--- Lessons From Closed Trades ---\n" + decision.FormatLessons(lessons)
+		tradeLog.Lessons = lessonIDs(lessons)
+	}
+
 	// Log if reasoning mode is enabled
 	if e.traderConfig != nil && e.traderConfig.EnableReasoning {
 		log.Printf("[%s][%s] Reasoning mode enabled, expecting chain-of-thought output", e.name, symbol)
 	}
 
 	// Get AI decision - use simple prompt in Simple Mode
-	var decision *ai.TradingDecision
-	var rawResponse string
-	var aiErr error
-
-	if e.strategy != nil && e.strategy.Config.SimpleMode {
+	prompt := ai.SignalPrompt{Symbol: symbol, MarketData: formattedData}
+	if e.strategy != nil {
+		prompt.Variant = e.strategy.Config.PromptVariant
+		prompt.Simple = e.strategy.Config.SimpleMode
+		prompt.Turbo = e.strategy.Config.TurboMode
+	}
+	if prompt.Simple {
 		log.Printf("[%s][%s] 🌿 SIMPLE MODE: Using v1.4.7-style minimal prompt", e.name, symbol)
-		decision, rawResponse, aiErr = e.aiClient.GetTradingDecisionSimple(formattedData)
-	} else {
-		decision, rawResponse, aiErr = e.aiClient.GetTradingDecision(formattedData)
 	}
+
+	decision, rawResponse, sent, aiErr := e.aiClient.GetTradingDecision(prompt)
+	tradeLog.Prompts = sent.Refs
 	tradeLog.RawAI = rawResponse
+	tradeLog.Audit.SystemPrompt, tradeLog.Audit.UserPrompt = sent.System, sent.User
 
 	if aiErr != nil {
 		tradeLog.Error = fmt.Sprintf("AI decision failed: %v", aiErr)
+		tradeLog.Audit.Block(store.GuardAIResponse, "%v", aiErr)
 		if e.notifier != nil {
 			e.notifier.Broadcast(events.Event{
 				Type:      events.TypeError,
@@ -614,18 +738,47 @@ func (e *Engine) analyzeAndTrade(ctx context.Context, symbol string) *TradeLog {
 		}
 		return tradeLog
 	}
+	tradeLog.Audit.Pass(store.GuardAIResponse, "parsed (%s)", decision.ParsePath)
 
+	return e.actOnDecision(ctx, symbol, decision, tradeLog, longPos, shortPos, hedging)
+}
+
+// actOnDecision applies a decision to one symbol: picks the position leg, then
+// gates on confidence and multi-timeframe confirmation before executing.
+// Shared by the single-model and debate decision modes and external decisions.
+func (e *Engine) actOnDecision(ctx context.Context, symbol string, decision *ai.TradingDecision, tradeLog *TradeLog, longPos, shortPos *exchange.Position, hedging bool) *TradeLog {
 	tradeLog.Decision = decision
 	tradeLog.Action = decision.Action
 
+	// Pick the position leg the action applies to
+	audit := tradeLog.Audit
+	pos, err := resolvePositionForAction(decision.Action, longPos, shortPos, hedging)
+	if err != nil {
+		log.Printf("[%s][%s] %v", e.name, symbol, err)
+		tradeLog.Error = err.Error()
+		audit.Block(store.GuardPositionLeg, "%v", err)
+		return tradeLog
+	}
+	hasPosition := pos != nil
+
 	// Store last decision
 	e.mu.Lock()
 	e.lastDecisions[symbol] = decision
 	e.mu.Unlock()
 
-	// Execute trade if confidence is high enough
-	minConfidence := float64(e.getMinConfidence())
-	if decision.Confidence >= minConfidence {
+	// Execute trade if confidence is high enough. External decisions are
+	// calibrated under their source's model.
+	model := e.decisionModel()
+	if audit != nil {
+		model = audit.AIModel
+	}
+	confidence, minConfidence := e.confidenceGate(model, decision.Confidence)
+	if confidence != decision.Confidence {
+		tradeLog.CalibratedConfidence = confidence
+	}
+	if confidence >= minConfidence {
+		audit.Pass(store.GuardConfidence, "%.0f%% >= %.0f%%", confidence, minConfidence)
+
 		// Multi-Timeframe Confirmation (only for new positions)
 		if !hasPosition && (decision.Action == "BUY" || decision.Action == "SELL") {
 			if e.strategy != nil && e.strategy.Config.Indicators.EnableMultiTF {
@@ -653,15 +806,20 @@ func (e *Engine) analyzeAndTrade(ctx context.Context, symbol string) *TradeLog {
 							confirmTF,
 							map[bool]string{true: "BULLISH", false: "BEARISH"}[htfBullish],
 							decision.Action)
+						audit.Block(store.GuardMultiTF, "%s trend disagrees with %s (EMA9 %.4f, EMA21 %.4f)",
+							confirmTF, decision.Action, htfData.EMA9, htfData.EMA21)
 						return tradeLog
 					}
+					audit.Pass(store.GuardMultiTF, "%s trend agrees with %s", confirmTF, decision.Action)
 					log.Printf("[%s][%s] ✅ Multi-TF confirmed: Both 5m and %s agree on %s",
 						e.name, symbol, confirmTF, decision.Action)
 				}
 			}
 		}
 
-		realizedPnL, err := e.executeTrade(ctx, symbol, decision, hasPosition, pos)
+		e.execMu.Lock()
+		realizedPnL, err := e.executeTrade(ctx, symbol, decision, hasPosition, pos, audit)
+		e.execMu.Unlock()
 		if err != nil {
 			tradeLog.Error = fmt.Sprintf("trade execution failed: %v", err)
 			if e.notifier != nil {
@@ -673,19 +831,35 @@ func (e *Engine) analyzeAndTrade(ctx context.Context, symbol string) *TradeLog {
 					Timestamp: time.Now().UnixMilli(),
 				})
 			}
-		} else if realizedPnL != 0 {
-			tradeLog.RealizedPnL = realizedPnL
+		} else {
+			tradeLog.Executed = decision.Action != "HOLD"
+			if !hasPosition && (decision.Action == "BUY" || decision.Action == "SELL") {
+				e.noteEntry(symbol, decision.Action, decision.Confidence, model)
+			}
+			if realizedPnL != 0 {
+				tradeLog.RealizedPnL = realizedPnL
+			}
 		}
 	} else {
 		log.Printf("[%s][%s] Confidence too low (%.0f%% < %.0f%%), skipping trade",
-			e.name, symbol, decision.Confidence, minConfidence)
+			e.name, symbol, confidence, minConfidence)
+		if decision.Action != "HOLD" {
+			audit.Block(store.GuardConfidence, "%.0f%% < %.0f%%", confidence, minConfidence)
+		}
 	}
 
 	return tradeLog
 }
 
-// executeTrade executes the trade and returns realized PnL (if closing) and error
-func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.TradingDecision, hasPosition bool, currentPos *exchange.Position) (float64, error) {
+// executeTrade executes the trade and returns realized PnL (if closing) and error.
+// Guard verdicts and orders are recorded on audit, whose model is used for
+// calibrated sizing.
+func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.TradingDecision, hasPosition bool, currentPos *exchange.Position, audit *store.DecisionAudit) (float64, error) {
+	model := ""
+	if audit != nil {
+		model = audit.AIModel
+	}
+
 	// CRITICAL: Reject invalid symbols - "ALL" is only for wait/hold, never for actual trades
 	if symbol == "ALL" || symbol == "" {
 		return 0, fmt.Errorf("invalid symbol '%s' - cannot execute trade on ALL/empty symbol", symbol)
@@ -711,8 +885,10 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 		// 1. Check max positions
 		if err := e.enforceMaxPositions(); err != nil {
 			log.Printf("[%s][%s] %v, skipping new position", e.name, symbol, err)
+			audit.Block(store.GuardMaxPositions, "%v", err)
 			return 0, fmt.Errorf("skipped: %w", err)
 		}
+		audit.Pass(store.GuardMaxPositions, "")
 
 		// 2. Adjust and Validate SL/TP
 		// Apply auto-adjustment based on strategy config (fixes R:R mismatches)
@@ -722,8 +898,10 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 
 		if err := e.validateRiskRewardRatioPct(slPct, tpPct); err != nil {
 			log.Printf("[%s][%s] %v, skipping trade", e.name, symbol, err)
+			audit.Block(store.GuardRiskReward, "%v", err)
 			return 0, fmt.Errorf("skipped: %w", err)
 		}
+		audit.Pass(store.GuardRiskReward, "SL %.2f%%, TP %.2f%%", slPct, tpPct)
 	}
 
 	// Calculate position size using equity and leverage
@@ -779,17 +957,29 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 		positionSizeUSD, wasCapped = e.enforcePositionValueRatio(positionSizeUSD, equity, symbol)
 		if wasCapped {
 			log.Printf("[%s][%s] Position capped to $%.2f by value ratio", e.name, symbol, positionSizeUSD)
+			audit.Adjust(store.GuardValueRatio, "capped to $%.2f", positionSizeUSD)
+		} else {
+			audit.Pass(store.GuardValueRatio, "")
+		}
+
+		// 4. Shrink the position when the model has been overconfident
+		if sized := e.calibratedSize(positionSizeUSD, decision.Confidence, model); sized < positionSizeUSD {
+			log.Printf("[%s][%s] Position scaled to $%.2f by calibrated confidence", e.name, symbol, sized)
+			audit.Adjust(store.GuardCalibratedSize, "$%.2f -> $%.2f", positionSizeUSD, sized)
+			positionSizeUSD = sized
 		}
 
-		// 4. Apply margin buffer (use 98% of calculated size)
+		// 5. Apply margin buffer (use 98% of calculated size)
 		positionSizeUSD = e.applyMarginBuffer(positionSizeUSD)
 		log.Printf("[%s][%s] After margin buffer: $%.2f", e.name, symbol, positionSizeUSD)
 
-		// 5. Enforce minimum position size
+		// 6. Enforce minimum position size
 		if err := e.enforceMinPositionSize(positionSizeUSD, symbol); err != nil {
 			log.Printf("[%s][%s] %v, skipping trade", e.name, symbol, err)
+			audit.Block(store.GuardMinSize, "%v", err)
 			return 0, fmt.Errorf("skipped: %w", err)
 		}
+		audit.Pass(store.GuardMinSize, "$%.2f", positionSizeUSD)
 	}
 
 	// IMPORTANT: positionSizeUSD is the MARGIN amount, not position value!
@@ -800,43 +990,64 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 	log.Printf("[%s][%s] Position calculation: margin=$%.2f × %dx leverage = $%.2f position value → %.8f %s",
 		e.name, symbol, positionSizeUSD, leverage, actualPositionValue, quantity, symbol)
 
-	// VALIDATION: Ensure quantity meets minimum requirements after rounding
-	// Binance enforces minimum quantities per symbol (e.g., 0.001 BTC)
-	minQuantity := 0.001 // Default for BTC/ETH
-	if !isBTCETH(symbol) {
-		// For altcoins, calculate based on a reasonable minimum notional ($10)
-		minQuantity = 10.0 / ticker.Price
-	}
-
-	if quantity < minQuantity {
-		return 0, fmt.Errorf("skipped: quantity %.8f below minimum %.8f for %s (position $%.2f too small, increase position size)",
-			quantity, minQuantity, symbol, positionSizeUSD)
+	// VALIDATION: Check the entry and its SL/TP against the symbol's exchange filters
+	// so Binance doesn't reject them after the decision was accepted
+	if isOpenAction {
+		isLong := decision.Action == "BUY" || decision.Action == "open_long"
+		adjusted, err := e.enforceSymbolFilters(symbol, isLong, quantity, ticker.Price, decision.StopLossPct, decision.TakeProfitPct)
+		if err != nil {
+			log.Printf("[%s][%s] ❌ Order rejected by symbol filters: %v (position $%.2f)", e.name, symbol, err, positionSizeUSD)
+			audit.Block(store.GuardSymbolFilters, "%v", err)
+			return 0, fmt.Errorf("skipped: %w", err)
+		}
+		if adjusted != quantity {
+			log.Printf("[%s][%s] Quantity adjusted to symbol filters: %.8f → %.8f", e.name, symbol, quantity, adjusted)
+			audit.Adjust(store.GuardSymbolFilters, "quantity %.8f -> %.8f", quantity, adjusted)
+			quantity = adjusted
+		} else {
+			audit.Pass(store.GuardSymbolFilters, "")
+		}
 	}
 
 	// CRITICAL: Before opening any new position, cancel any orphaned SL/TP orders for this symbol
 	// This prevents the "-4130: An open stop or take profit order...is existing" error
 	if !hasPosition && (decision.Action == "BUY" || decision.Action == "SELL" || decision.Action == "open_long" || decision.Action == "open_short") {
-		e.cancelOrphanedOrders(ctx, symbol)
+		openSide := "LONG"
+		if decision.Action == "SELL" || decision.Action == "open_short" {
+			openSide = "SHORT"
+		}
+		e.cancelOrphanedOrders(ctx, symbol, openSide)
 	}
 
 	switch decision.Action {
 	case "BUY", "open_long":
 		if hasPosition && currentPos.PositionAmt > 0 {
 			log.Printf("[%s][%s] Already in LONG position, skipping BUY", e.name, symbol)
+			audit.Block(store.GuardPosition, "already in LONG position")
 			return 0, fmt.Errorf("skipped: already in LONG position")
 		}
 		// Require explicit close of opposite position (matching NOFX behavior)
 		if hasPosition && currentPos.PositionAmt < 0 {
 			log.Printf("[%s][%s] Already has SHORT position, close it first before opening LONG", e.name, symbol)
+			audit.Block(store.GuardPosition, "SHORT position open, close it first")
 			return 0, fmt.Errorf("skipped: %s already has SHORT position, close it first", symbol)
 		}
 		log.Printf("[%s][%s] Opening LONG: %.4f @ $%.2f (margin: $%.2f, position: $%.2f, leverage: %dx)",
 			e.name, symbol, quantity, ticker.Price, positionSizeUSD, actualPositionValue, leverage)
-		openOrder, err := e.binance.PlaceOrder(ctx, symbol, "BUY", "MARKET", quantity, 0, false)
+		placedAt := time.Now()
+		openOrder, err := e.placeEntryOrder(ctx, symbol, "BUY", quantity, decision)
 		if err != nil {
+			if strings.HasPrefix(err.Error(), "skipped:") {
+				audit.Block(store.GuardEntryOrder, "%v", err)
+				return 0, err
+			}
 			return 0, fmt.Errorf("failed to open long: %w", err)
 		}
+		if openOrder != nil {
+			audit.AddOrder(auditOrder(store.AuditOrderEntry, openOrder))
+		}
 		e.setPositionFirstSeen(symbol, "LONG")
+		e.stampLegVersion(symbol, "LONG", placedAt)
 
 		// Use actual fill data from order response
 		entryPrice := ticker.Price
@@ -849,7 +1060,7 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 				// Query actual position from Binance to get real fill data
 				if positions, err := e.binance.GetPositions(ctx); err == nil {
 					for _, pos := range positions {
-						if pos.Symbol == symbol && pos.PositionAmt > 0 {
+						if pos.Symbol == symbol && pos.PositionAmt > 0 && pos.PositionSide != "SHORT" {
 							filledQty = pos.PositionAmt
 							entryPrice = pos.EntryPrice
 							log.Printf("[%s][%s] Position verified from exchange: qty=%.4f, entry=$%.4f",
@@ -872,7 +1083,7 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 
 		// Update positions map with actual fill data
 		e.mu.Lock()
-		e.positions[symbol] = &exchange.Position{
+		e.positions[getPositionKey(symbol, "LONG")] = &exchange.Position{
 			Symbol:      symbol,
 			PositionAmt: filledQty,
 			EntryPrice:  entryPrice,
@@ -892,25 +1103,37 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 			} else if tpPct > 0 {
 				e.placeBracketOrders(ctx, symbol, true, entryPrice, slPct, tpPct)
 			}
+			e.auditBrackets(audit, symbol, "LONG", filledQty)
 		}
 
 	case "SELL", "open_short":
 		if hasPosition && currentPos.PositionAmt < 0 {
 			log.Printf("[%s][%s] Already in SHORT position, skipping SELL", e.name, symbol)
+			audit.Block(store.GuardPosition, "already in SHORT position")
 			return 0, fmt.Errorf("skipped: already in SHORT position")
 		}
 		// Require explicit close of opposite position (matching NOFX behavior)
 		if hasPosition && currentPos.PositionAmt > 0 {
 			log.Printf("[%s][%s] Already has LONG position, close it first before opening SHORT", e.name, symbol)
+			audit.Block(store.GuardPosition, "LONG position open, close it first")
 			return 0, fmt.Errorf("skipped: %s already has LONG position, close it first", symbol)
 		}
 		log.Printf("[%s][%s] Opening SHORT: %.4f @ $%.2f (margin: $%.2f, position: $%.2f, leverage: %dx)",
 			e.name, symbol, quantity, ticker.Price, positionSizeUSD, actualPositionValue, leverage)
-		openOrder, err := e.binance.PlaceOrder(ctx, symbol, "SELL", "MARKET", quantity, 0, false)
+		placedAt := time.Now()
+		openOrder, err := e.placeEntryOrder(ctx, symbol, "SELL", quantity, decision)
 		if err != nil {
+			if strings.HasPrefix(err.Error(), "skipped:") {
+				audit.Block(store.GuardEntryOrder, "%v", err)
+				return 0, err
+			}
 			return 0, fmt.Errorf("failed to open short: %w", err)
 		}
+		if openOrder != nil {
+			audit.AddOrder(auditOrder(store.AuditOrderEntry, openOrder))
+		}
 		e.setPositionFirstSeen(symbol, "SHORT")
+		e.stampLegVersion(symbol, "SHORT", placedAt)
 
 		// Use actual fill data from order response
 		entryPrice := ticker.Price
@@ -923,7 +1146,7 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 				// Query actual position from Binance to get real fill data
 				if positions, err := e.binance.GetPositions(ctx); err == nil {
 					for _, pos := range positions {
-						if pos.Symbol == symbol && pos.PositionAmt < 0 {
+						if pos.Symbol == symbol && pos.PositionAmt < 0 && pos.PositionSide != "LONG" {
 							filledQty = -pos.PositionAmt // Convert to positive
 							entryPrice = pos.EntryPrice
 							log.Printf("[%s][%s] Position verified from exchange: qty=%.4f, entry=$%.4f",
@@ -946,7 +1169,7 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 
 		// Update positions map with actual fill data
 		e.mu.Lock()
-		e.positions[symbol] = &exchange.Position{
+		e.positions[getPositionKey(symbol, "SHORT")] = &exchange.Position{
 			Symbol:      symbol,
 			PositionAmt: -filledQty, // Negative for short
 			EntryPrice:  entryPrice,
@@ -966,11 +1189,13 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 			} else if tpPct > 0 {
 				e.placeBracketOrders(ctx, symbol, false, entryPrice, slPct, tpPct)
 			}
+			e.auditBrackets(audit, symbol, "SHORT", filledQty)
 		}
 
 	case "CLOSE", "close_long", "close_short":
 		if !hasPosition {
 			log.Printf("[%s][%s] No position to close", e.name, symbol)
+			audit.Block(store.GuardPosition, "no position to close")
 			return 0, fmt.Errorf("skipped: no position to close")
 		}
 		side := "LONG"
@@ -1041,6 +1266,7 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 		// Skip noise zone protection if disabled
 		if !enableNoiseZone {
 			log.Printf("[%s][%s] ⚙️ Noise zone protection DISABLED - allowing close (PnL: %.2f%%)", e.name, symbol, pnlPct)
+			audit.Pass(store.GuardNoiseZone, "disabled")
 			// Continue to close...
 		} else if pnlPct < significantLossThreshold {
 			// Case 1: Significant loss - ALLOW (but log warning if position is new)
@@ -1050,11 +1276,13 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 			}
 			log.Printf("[%s][%s] ✅ ALLOWING loss cut: Loss %.2f%% exceeds threshold %.2f%%.",
 				e.name, symbol, pnlPct, significantLossThreshold)
+			audit.Pass(store.GuardNoiseZone, "loss %.2f%% below %.2f%%", pnlPct, significantLossThreshold)
 			// Continue to close...
 		} else if pnlPct >= noiseZoneCeiling {
 			// Case 2: Good profit - ALLOW (taking profits)
 			log.Printf("[%s][%s] ✅ ALLOWING profit take: Profit %.2f%% exceeds threshold %.2f%%.",
 				e.name, symbol, pnlPct, noiseZoneCeiling)
+			audit.Pass(store.GuardNoiseZone, "profit %.2f%% above %.2f%%", pnlPct, noiseZoneCeiling)
 			// Continue to close...
 		} else {
 			// Case 3: NOISE ZONE (lower to upper bound) - BLOCK unless very high confidence
@@ -1062,16 +1290,20 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 				// NEW positions in noise zone: ALWAYS block, no override
 				log.Printf("[%s][%s] ❌ BLOCKED: Position too new (%.1f mins) and in noise zone (PnL: %.2f%%). Let it develop.",
 					e.name, symbol, holdMins, pnlPct)
+				audit.Block(store.GuardNoiseZone, "held %.1f of %d mins, PnL %.2f%%", holdMins, minHoldBeforeClose, pnlPct)
 				return 0, fmt.Errorf("blocked: position only %.1f mins old, in noise zone (%.2f%%). Wait for development", holdMins, pnlPct)
 			} else if isHighConfidence {
 				// OLDER positions with very high confidence: Allow override (rare)
 				log.Printf("[%s][%s] ⚡ OVERRIDE: High confidence (%.1f%%) allows closing in noise zone (PnL: %.2f%%, held %.1f mins).",
 					e.name, symbol, decision.Confidence, pnlPct, holdMins)
+				audit.Pass(store.GuardNoiseZone, "PnL %.2f%% in noise zone, confidence %.0f%% overrides", pnlPct, decision.Confidence)
 				// Continue to close...
 			} else {
 				// OLDER positions without high confidence: BLOCK
 				log.Printf("[%s][%s] ❌ BLOCKED: Cannot close in noise zone (PnL: %.2f%%). Need %.0f%% confidence, got %.1f%%.",
 					e.name, symbol, pnlPct, highConfidenceThreshold, decision.Confidence)
+				audit.Block(store.GuardNoiseZone, "PnL %.2f%% in noise zone, confidence %.0f%% < %.0f%%",
+					pnlPct, decision.Confidence, highConfidenceThreshold)
 				return 0, fmt.Errorf("blocked: PnL %.2f%% in noise zone (need >%.1f%% profit OR >%.0f%% confidence)",
 					pnlPct, noiseZoneCeiling, highConfidenceThreshold)
 			}
@@ -1085,8 +1317,11 @@ func (e *Engine) executeTrade(ctx context.Context, symbol string, decision *ai.T
 		if err != nil {
 			return 0, fmt.Errorf("failed to close position: %w", err)
 		}
-		e.clearPositionTracking(symbol, side)
-		e.cancelBracketOrders(ctx, symbol)
+		if closeOrder != nil {
+			audit.AddOrder(auditOrder(store.AuditOrderClose, closeOrder))
+		}
+		e.clearPositionTracking(symbol, side, store.CloseReasonAI)
+		e.cancelBracketOrders(ctx, symbol, side)
 
 		// Calculate actual realized P&L from fill price
 		realizedPnL := estimatedPnL // Default to estimated if we can't calculate
@@ -1124,6 +1359,7 @@ func (e *Engine) GetStatus() map[string]interface{} {
 	for _, pos := range e.positions {
 		positions = append(positions, map[string]interface{}{
 			"symbol":    pos.Symbol,
+			"side":      positionSide(pos),
 			"amount":    pos.PositionAmt,
 			"entry":     pos.EntryPrice,
 			"markPrice": pos.MarkPrice,
@@ -1146,7 +1382,7 @@ func (e *Engine) GetStatus() map[string]interface{} {
 		strategyName = e.strategy.Name
 	}
 
-	return map[string]interface{}{
+	status := map[string]interface{}{
 		"trader_id":   e.id,
 		"trader_name": e.name,
 		"running":     e.running,
@@ -1155,6 +1391,10 @@ func (e *Engine) GetStatus() map[string]interface{} {
 		"positions":   positions,
 		"decisions":   decisions,
 	}
+	if e.aiFallback != nil {
+		status["ai_backends"] = e.aiFallback.Stats()
+	}
+	return status
 }
 
 // GetAccount returns account information
@@ -1211,6 +1451,8 @@ func (e *Engine) GetPositions() []map[string]interface{} {
 
 // buildDecisionContext creates a decision.Context for AI decision making
 func (e *Engine) buildDecisionContext(ctx context.Context) *decision.Context {
+	tradingStats, recentOrders := e.closedTradeContext()
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -1293,6 +1535,11 @@ func (e *Engine) buildDecisionContext(ctx context.Context) *decision.Context {
 		}
 	}
 
+	symbols := make([]string, 0, len(candidateCoins))
+	for _, coin := range candidateCoins {
+		symbols = append(symbols, coin.Symbol)
+	}
+
 	return &decision.Context{
 		CurrentTime:     time.Now().Format(time.RFC3339),
 		RuntimeMinutes:  int(time.Since(e.startTime).Minutes()),
@@ -1300,6 +1547,9 @@ func (e *Engine) buildDecisionContext(ctx context.Context) *decision.Context {
 		Account:         accountInfo,
 		Positions:       positions,
 		CandidateCoins:  candidateCoins,
+		TradingStats:    tradingStats,
+		RecentOrders:    recentOrders,
+		Lessons:         e.lessonDigestLocked(symbols),
 		BTCETHLeverage:  btcEthLeverage,
 		AltcoinLeverage: altcoinLeverage,
 		BTCETHPosRatio:  btcEthPosRatio,
@@ -1307,6 +1557,22 @@ func (e *Engine) buildDecisionContext(ctx context.Context) *decision.Context {
 	}
 }
 
+// strategyValidationConfig builds the decision validator's limits from a
+// strategy's risk controls
+func strategyValidationConfig(strategy *store.Strategy, equity float64) *decision.ValidationConfig {
+	rc := strategy.Config.RiskControl
+	return &decision.ValidationConfig{
+		AccountEquity:     equity,
+		BTCETHLeverage:    rc.BTCETHMaxLeverage,
+		AltcoinLeverage:   rc.AltcoinMaxLeverage,
+		BTCETHPosRatio:    rc.BTCETHMaxPositionValueRatio,
+		AltcoinPosRatio:   rc.AltcoinMaxPositionValueRatio,
+		MinPositionBTCETH: rc.MinPositionSizeBTCETH,
+		MinPositionAlt:    rc.MinPositionSize,
+		MinRiskReward:     rc.MinRiskRewardRatio,
+	}
+}
+
 // decisionToTradingDecision converts a decision.Decision to ai.TradingDecision for compatibility
 func decisionToTradingDecision(d *decision.Decision) *ai.TradingDecision {
 	// Map action types
@@ -1367,6 +1633,45 @@ func (e *Engine) GetLastCoT() string {
 	return ""
 }
 
+// SetAIFallback routes the engine's AI calls through a fallback chain, so
+// decisions keep flowing while a single provider or model is down
+func (e *Engine) SetAIFallback(chain *mcp.FallbackClient) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.aiFallback = chain
+	e.mcpClient = chain
+	e.aiClient.SetBackend(chain)
+	e.decisionEngine.SetClient(chain)
+}
+
+// SetAIMeter records every AI call the engine makes and checks gate first,
+// which pauses AI calls once the trader's budget is spent. Call it after
+// SetAIFallback so the chain is metered as a whole.
+func (e *Engine) SetAIMeter(observe mcp.CallObserver, gate mcp.CallGate) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	metered := mcp.NewMeteredClient(e.mcpClient, observe, gate)
+	e.mcpClient = metered
+	e.decisionEngine.SetClient(metered)
+	e.aiClient.SetMeter(observe, gate)
+	if e.aiFallback != nil {
+		e.aiClient.SetBackend(metered)
+	}
+}
+
+// SetAIRecorder saves every AI request and response the engine makes to a
+// cassette, so a decision can be replayed offline
+func (e *Engine) SetAIRecorder(cassette mcp.Cassette) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	recorded := mcp.NewRecordingClient(e.mcpClient, cassette, func(err error) {
+		log.Printf("[%s] Failed to record AI call: %v", e.name, err)
+	})
+	e.mcpClient = recorded
+	e.decisionEngine.SetClient(recorded)
+	e.aiClient.SetRecorder(cassette)
+}
+
 // GetDecisionEngineStatus returns status information about the decision engine
 func (e *Engine) GetDecisionEngineStatus() map[string]interface{} {
 	e.mu.RLock()
@@ -1465,6 +1770,55 @@ func getPositionKey(symbol, side string) string {
 	return symbol + "_" + side
 }
 
+// positionSide returns LONG or SHORT from the sign of the position amount
+func positionSide(pos *exchange.Position) string {
+	if pos.PositionAmt < 0 {
+		return "SHORT"
+	}
+	return "LONG"
+}
+
+// positionMapKey keys a position by symbol and side so hedge-mode legs
+// on the same symbol don't overwrite each other
+func positionMapKey(pos *exchange.Position) string {
+	return getPositionKey(pos.Symbol, positionSide(pos))
+}
+
+// resolvePositionForAction picks the position leg an AI action applies to.
+// In one-way mode there is at most one leg, so opens see it (and are rejected
+// as "close it first") and closes apply to it. In hedge mode opens only see
+// their own side and a bare CLOSE with both legs open is ambiguous.
+func resolvePositionForAction(action string, longPos, shortPos *exchange.Position, hedging bool) (*exchange.Position, error) {
+	single := longPos
+	if single == nil {
+		single = shortPos
+	}
+
+	switch action {
+	case "BUY", "open_long":
+		if hedging {
+			return longPos, nil
+		}
+	case "SELL", "open_short":
+		if hedging {
+			return shortPos, nil
+		}
+	case "close_long":
+		if longPos != nil || hedging {
+			return longPos, nil
+		}
+	case "close_short":
+		if shortPos != nil || hedging {
+			return shortPos, nil
+		}
+	case "CLOSE":
+		if longPos != nil && shortPos != nil {
+			return nil, fmt.Errorf("skipped: CLOSE is ambiguous with both LONG and SHORT open, use close_long or close_short")
+		}
+	}
+	return single, nil
+}
+
 // =============================================================================
 // Risk Control Enforcement Functions
 // =============================================================================
@@ -1542,6 +1896,49 @@ func (e *Engine) enforceMinPositionSize(positionSizeUSD float64, symbol string)
 	return nil
 }
 
+// enforceSymbolFilters validates an entry and its SL/TP trigger prices against the
+// symbol's exchange filters (LOT_SIZE, MARKET_LOT_SIZE, MIN_NOTIONAL, PRICE_FILTER)
+// and returns the step-rounded quantity. Without exchange info it falls back to a
+// minimum quantity of 0.001 for BTC/ETH and a $10 notional for altcoins.
+func (e *Engine) enforceSymbolFilters(symbol string, isLong bool, quantity, price, slPct, tpPct float64) (float64, error) {
+	if _, ok := e.binance.GetSymbolInfo(symbol); !ok {
+		minQuantity := 0.001
+		if !isBTCETH(symbol) {
+			minQuantity = 10.0 / price
+		}
+		if quantity < minQuantity {
+			return 0, fmt.Errorf("quantity %.8f below minimum %.8f for %s (increase position size)", quantity, minQuantity, symbol)
+		}
+		return quantity, nil
+	}
+
+	orderType := "MARKET"
+	if e.entryExecution().OrderType != store.EntryOrderMarket {
+		orderType = "LIMIT"
+	}
+	// MARKET entries are checked at the current price; LIMIT prices are validated when placed
+	quantity, _, err := e.binance.ValidateOrder(symbol, orderType, quantity, price, 0, false)
+	if err != nil {
+		return 0, err
+	}
+
+	direction := 1.0
+	if !isLong {
+		direction = -1.0
+	}
+	if slPct > 0 {
+		if _, err := e.binance.ValidateTriggerPrice(symbol, price*(1-direction*slPct/100)); err != nil {
+			return 0, fmt.Errorf("stop loss: %w", err)
+		}
+	}
+	if tpPct > 0 {
+		if _, err := e.binance.ValidateTriggerPrice(symbol, price*(1+direction*tpPct/100)); err != nil {
+			return 0, fmt.Errorf("take profit: %w", err)
+		}
+	}
+	return quantity, nil
+}
+
 // enforceMaxPositions checks if we've reached max positions
 func (e *Engine) enforceMaxPositions() error {
 	if e.strategy == nil {
@@ -1664,6 +2061,76 @@ func (e *Engine) setPositionFirstSeen(symbol, side string) {
 	}
 }
 
+// legVersion is the strategy version a position leg was opened under
+type legVersion struct {
+	versionID int64
+	openedAt  time.Time
+}
+
+// maxLegVersions is how many recent opens are kept per leg
+const maxLegVersions = 8
+
+// legVersionKey keys a leg the way Binance reports its fills: by LONG/SHORT
+// in hedge mode, BOTH in one-way mode
+func legVersionKey(symbol, positionSide string) string {
+	return symbol + "_" + positionSide
+}
+
+// stampLegVersion records the running strategy version for a leg whose entry
+// order was placed at placedAt
+func (e *Engine) stampLegVersion(symbol, side string, placedAt time.Time) {
+	positionSide := "BOTH"
+	if e.binance.IsHedgeMode() {
+		positionSide = side
+	}
+	versionID := e.strategyVersionID()
+	key := legVersionKey(symbol, positionSide)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	versions := append(e.legVersions[key], legVersion{versionID: versionID, openedAt: placedAt})
+	if len(versions) > maxLegVersions {
+		versions = versions[len(versions)-maxLegVersions:]
+	}
+	e.legVersions[key] = versions
+}
+
+// restoreLegVersions seeds legVersions from each leg's latest stamped fill,
+// which carries the version of the leg open at the time. A leg still open
+// after a restart keeps that version for its remaining fills; a closed leg's
+// entry is superseded when the engine opens the next one.
+func (e *Engine) restoreLegVersions() {
+	fills, err := e.tradeStore.GetLatestLegFills(e.id)
+	if err != nil {
+		log.Printf("[%s] Failed to restore position leg versions: %v", e.name, err)
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, f := range fills {
+		key := legVersionKey(f.Symbol, f.PositionSide)
+		if len(e.legVersions[key]) == 0 {
+			e.legVersions[key] = []legVersion{{versionID: f.StrategyVersionID, openedAt: f.Timestamp}}
+		}
+	}
+}
+
+// tradeVersionID returns the strategy version a fill belongs to: that of the
+// latest open of its leg at or before the fill. Fills of legs the engine
+// didn't open get the running version.
+func (e *Engine) tradeVersionID(symbol, positionSide string, at time.Time, running int64) int64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	versions := e.legVersions[legVersionKey(symbol, positionSide)]
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !versions[i].openedAt.After(at) {
+			return versions[i].versionID
+		}
+	}
+	return running
+}
+
 // syncTradeHistory fetches recent trades from Binance and saves them to the database
 func (e *Engine) syncTradeHistory(ctx context.Context) {
 	// Get last synced trade time
@@ -1687,6 +2154,7 @@ func (e *Engine) syncTradeHistory(ctx context.Context) {
 		return
 	}
 
+	running := e.strategyVersionID()
 	var allTrades []*store.Trade
 	for _, symbol := range coins {
 		trades, err := e.binance.GetTradeHistory(ctx, symbol, lastTradeTime, 100)
@@ -1708,6 +2176,9 @@ func (e *Engine) syncTradeHistory(ctx context.Context) {
 				Commission:  t.Commission,
 				Timestamp:   time.UnixMilli(t.Time),
 				OrderID:     t.OrderID,
+
+				PositionSide:      t.PositionSide,
+				StrategyVersionID: e.tradeVersionID(t.Symbol, t.PositionSide, time.UnixMilli(t.Time), running),
 			}
 			allTrades = append(allTrades, trade)
 		}
@@ -1735,12 +2206,14 @@ func (e *Engine) GetHoldDuration(symbol, side string) time.Duration {
 	return 0
 }
 
-// clearPositionTracking clears all tracking data for a closed position
-func (e *Engine) clearPositionTracking(symbol, side string) {
+// clearPositionTracking clears all tracking data for a closed position and
+// notes why the engine closed it for the position journal
+func (e *Engine) clearPositionTracking(symbol, side, reason string) {
 	key := getPositionKey(symbol, side)
 
 	e.mu.Lock()
 	delete(e.positionFirstSeenTime, key)
+	e.closeReasons[journalKey(symbol, side)] = reason
 	e.mu.Unlock()
 
 	e.ClearPeakPnL(symbol, side)
@@ -1789,8 +2262,14 @@ func (e *Engine) placeBracketOrders(ctx context.Context, symbol string, isLong b
 	log.Printf("[%s][%s] Placing bracket orders: SL=%.1f%%, TP=%.1f%%, entry=$%.2f",
 		e.name, symbol, slPct, tpPct, entryPrice)
 
+	side := "LONG"
+	if !isLong {
+		side = "SHORT"
+	}
+	bracketKey := getPositionKey(symbol, side)
+
 	// CLEANUP: Cancel any existing open orders before placing new ones to avoid "order exists" errors (Code -4130)
-	if err := e.binance.CancelAllOrders(ctx, symbol); err != nil {
+	if err := e.clearSideOrders(ctx, symbol, side); err != nil {
 		log.Printf("[%s][%s] Warning: failed to clear existing orders before brackets: %v", e.name, symbol, err)
 	}
 
@@ -1840,7 +2319,7 @@ func (e *Engine) placeBracketOrders(ctx context.Context, symbol string, isLong b
 			log.Printf("[%s][%s] 🟡 Emergency SL placed at $%.2f (%.1f%% from entry)", e.name, symbol, slPrice, emergencySLPct)
 			// Store the emergency SL for tracking
 			e.bracketOrdersMutex.Lock()
-			e.bracketOrders[symbol] = &BracketOrderIDs{
+			e.bracketOrders[bracketKey] = &BracketOrderIDs{
 				StopLossOrderID:   slOrder.OrderID,
 				TakeProfitOrderID: 0, // No TP
 				EntryPrice:        entryPrice,
@@ -1854,7 +2333,7 @@ func (e *Engine) placeBracketOrders(ctx context.Context, symbol string, isLong b
 
 	// Store order IDs for tracking
 	e.bracketOrdersMutex.Lock()
-	e.bracketOrders[symbol] = &BracketOrderIDs{
+	e.bracketOrders[bracketKey] = &BracketOrderIDs{
 		StopLossOrderID:   slOrder.OrderID,
 		TakeProfitOrderID: tpOrder.OrderID,
 		EntryPrice:        entryPrice,
@@ -1873,8 +2352,13 @@ func (e *Engine) placeStopLossOnly(ctx context.Context, symbol string, isLong bo
 	log.Printf("[%s][%s] Placing SL only: SL=%.1f%%, entry=$%.2f (TSL will handle profits)",
 		e.name, symbol, slPct, entryPrice)
 
+	side := "LONG"
+	if !isLong {
+		side = "SHORT"
+	}
+
 	// CLEANUP: Cancel any existing open orders before placing new ones
-	if err := e.binance.CancelAllOrders(ctx, symbol); err != nil {
+	if err := e.clearSideOrders(ctx, symbol, side); err != nil {
 		log.Printf("[%s][%s] Warning: failed to clear existing orders before SL: %v", e.name, symbol, err)
 	}
 
@@ -1912,7 +2396,7 @@ func (e *Engine) placeStopLossOnly(ctx context.Context, symbol string, isLong bo
 			return
 		}
 		for _, pos := range positions {
-			if pos.Symbol == symbol && pos.PositionAmt != 0 {
+			if pos.Symbol == symbol && pos.PositionAmt != 0 && positionSide(&pos) == side {
 				if _, closeErr := e.binance.ClosePosition(ctx, symbol, pos.PositionAmt); closeErr != nil {
 					log.Printf("[%s][%s] ERROR: Failed to close unprotected position: %v", e.name, symbol, closeErr)
 				} else {
@@ -1926,7 +2410,7 @@ func (e *Engine) placeStopLossOnly(ctx context.Context, symbol string, isLong bo
 
 	// Store SL order ID for tracking (no TP)
 	e.bracketOrdersMutex.Lock()
-	e.bracketOrders[symbol] = &BracketOrderIDs{
+	e.bracketOrders[getPositionKey(symbol, side)] = &BracketOrderIDs{
 		StopLossOrderID:   slOrder.OrderID,
 		TakeProfitOrderID: 0, // No TP when TSL is enabled
 		EntryPrice:        entryPrice,
@@ -1939,14 +2423,15 @@ func (e *Engine) placeStopLossOnly(ctx context.Context, symbol string, isLong bo
 		e.name, symbol, slOrder.OrderID)
 }
 
-// cancelOrphanedOrders cancels any orphaned SL/TP orders for a symbol before opening a new position
+// cancelOrphanedOrders cancels any orphaned SL/TP orders for a symbol side before opening a new position
 // This prevents the "-4130: An open stop or take profit order...is existing" error
-func (e *Engine) cancelOrphanedOrders(ctx context.Context, symbol string) {
+func (e *Engine) cancelOrphanedOrders(ctx context.Context, symbol, side string) {
 	// First, cancel any tracked bracket orders
+	key := getPositionKey(symbol, side)
 	e.bracketOrdersMutex.Lock()
-	bracket, exists := e.bracketOrders[symbol]
+	bracket, exists := e.bracketOrders[key]
 	if exists {
-		delete(e.bracketOrders, symbol)
+		delete(e.bracketOrders, key)
 	}
 	e.bracketOrdersMutex.Unlock()
 
@@ -1966,7 +2451,7 @@ func (e *Engine) cancelOrphanedOrders(ctx context.Context, symbol string) {
 
 	// ALSO cancel ALL open algo orders for this symbol from Binance directly
 	// This catches any orphaned orders that our tracking missed
-	if err := e.binance.CancelAllOrders(ctx, symbol); err != nil {
+	if err := e.clearSideOrders(ctx, symbol, side); err != nil {
 		// This is expected to fail sometimes (no orders), ignore
 		log.Printf("[%s][%s] 🧹 Attempted to cancel all open orders: %v", e.name, symbol, err)
 	} else {
@@ -1974,12 +2459,50 @@ func (e *Engine) cancelOrphanedOrders(ctx context.Context, symbol string) {
 	}
 }
 
-// cancelBracketOrders cancels any existing SL/TP orders for a symbol
-func (e *Engine) cancelBracketOrders(ctx context.Context, symbol string) {
+// clearSideOrders cancels open orders for one side of a symbol. In one-way mode
+// a symbol only has one side, so everything is cancelled; in hedge mode only
+// orders belonging to the given positionSide are, leaving the other leg protected.
+// SL/TP brackets are algo orders and are listed and cancelled separately.
+func (e *Engine) clearSideOrders(ctx context.Context, symbol, side string) error {
+	if !e.binance.IsHedgeMode() {
+		return e.binance.CancelAllOrders(ctx, symbol)
+	}
+
+	orders, err := e.binance.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		if order.PositionSide != side {
+			continue
+		}
+		if err := e.binance.CancelOrder(ctx, symbol, order.OrderID); err != nil {
+			log.Printf("[%s][%s] Failed to cancel %s order %d: %v", e.name, symbol, side, order.OrderID, err)
+		}
+	}
+
+	algoOrders, err := e.binance.GetOpenAlgoOrders(ctx, symbol)
+	if err != nil {
+		return err
+	}
+	for _, order := range algoOrders {
+		if order.PositionSide != side {
+			continue
+		}
+		if err := e.binance.CancelAlgoOrder(ctx, symbol, order.AlgoID); err != nil {
+			log.Printf("[%s][%s] Failed to cancel %s algo order %d: %v", e.name, symbol, side, order.AlgoID, err)
+		}
+	}
+	return nil
+}
+
+// cancelBracketOrders cancels any existing SL/TP orders for a symbol side
+func (e *Engine) cancelBracketOrders(ctx context.Context, symbol, side string) {
+	key := getPositionKey(symbol, side)
 	e.bracketOrdersMutex.Lock()
-	bracket, exists := e.bracketOrders[symbol]
+	bracket, exists := e.bracketOrders[key]
 	if exists {
-		delete(e.bracketOrders, symbol)
+		delete(e.bracketOrders, key)
 	}
 	e.bracketOrdersMutex.Unlock()
 
@@ -2042,7 +2565,7 @@ func (e *Engine) cancelBracketOrders(ctx context.Context, symbol string) {
 	}
 }
 
-// GetBracketOrders returns the current bracket orders for all symbols
+// GetBracketOrders returns the current bracket orders keyed by symbol_side
 func (e *Engine) GetBracketOrders() map[string]*BracketOrderIDs {
 	e.bracketOrdersMutex.RLock()
 	defer e.bracketOrdersMutex.RUnlock()
@@ -2159,8 +2682,8 @@ func (e *Engine) closeAllPositions(ctx context.Context, reason string) {
 			log.Printf("[%s][%s] Failed to close position: %v", e.name, pos.Symbol, err)
 		} else {
 			log.Printf("[%s][%s] ✅ Position closed successfully", e.name, pos.Symbol)
-			e.clearPositionTracking(pos.Symbol, side)
-			e.cancelBracketOrders(ctx, pos.Symbol)
+			e.clearPositionTracking(pos.Symbol, side, store.CloseReasonRiskLimit)
+			e.cancelBracketOrders(ctx, pos.Symbol, side)
 		}
 	}
 }
@@ -2191,6 +2714,14 @@ func (e *Engine) startDrawdownMonitor(ctx context.Context) {
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
+	// Mark price ticks from the stream trigger checks within a second;
+	// the ticker remains as a fallback while the stream is down
+	var priceCh <-chan market.PriceUpdate
+	if e.stream != nil {
+		priceCh = e.stream.Listen(e.id)
+	}
+	var lastStreamCheck time.Time
+
 	log.Printf("[%s] Drawdown monitor started", e.name)
 
 	for {
@@ -2202,10 +2733,88 @@ func (e *Engine) startDrawdownMonitor(ctx context.Context) {
 			return
 		case <-ticker.C:
 			e.checkPositionDrawdown(ctx)
+		case update, ok := <-priceCh:
+			if !ok {
+				priceCh = nil
+				continue
+			}
+			if time.Since(lastStreamCheck) < time.Second || !e.hasOpenPosition(update.Symbol) {
+				continue
+			}
+			lastStreamCheck = time.Now()
+			e.checkPositionDrawdown(ctx)
 		}
 	}
 }
 
+// forgetPosition drops a closed position from local state so streamed price
+// ticks don't re-trigger a close before the next position sync
+func (e *Engine) forgetPosition(symbol, side string) {
+	e.mu.Lock()
+	delete(e.positions, getPositionKey(symbol, side))
+	e.mu.Unlock()
+}
+
+// hasOpenPosition reports whether the engine holds a non-zero position in symbol
+func (e *Engine) hasOpenPosition(symbol string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	longPos, shortPos := e.symbolPositionsLocked(symbol)
+	return longPos != nil || shortPos != nil
+}
+
+// symbolPositionsLocked returns the open LONG and SHORT legs for symbol (nil if flat).
+// Caller must hold e.mu.
+func (e *Engine) symbolPositionsLocked(symbol string) (longPos, shortPos *exchange.Position) {
+	if pos, ok := e.positions[getPositionKey(symbol, "LONG")]; ok && pos.PositionAmt > 0 {
+		longPos = pos
+	}
+	if pos, ok := e.positions[getPositionKey(symbol, "SHORT")]; ok && pos.PositionAmt < 0 {
+		shortPos = pos
+	}
+	return longPos, shortPos
+}
+
+// hedgeEnabled reports whether the AI may hold a LONG and a SHORT on the same symbol:
+// the strategy must allow it and the account must actually be in hedge mode
+func (e *Engine) hedgeEnabled() bool {
+	e.mu.RLock()
+	allowed := e.strategy != nil && e.strategy.Config.RiskControl.EnableHedgeMode
+	e.mu.RUnlock()
+	return allowed && e.binance.IsHedgeMode()
+}
+
+// syncPositionMode detects the account's position mode and, if the strategy
+// allows hedging, tries to switch the account to dual-side positions.
+// Binance refuses the switch while positions or open orders exist.
+func (e *Engine) syncPositionMode(ctx context.Context) {
+	dualSide, err := e.binance.GetPositionMode(ctx)
+	if err != nil {
+		log.Printf("[%s] Warning: failed to detect position mode, assuming one-way: %v", e.name, err)
+		return
+	}
+
+	e.mu.RLock()
+	wantHedge := e.strategy != nil && e.strategy.Config.RiskControl.EnableHedgeMode
+	e.mu.RUnlock()
+
+	if wantHedge && !dualSide {
+		if err := e.binance.SetPositionMode(ctx, true); err != nil {
+			log.Printf("[%s] Warning: failed to enable hedge mode (close all positions/orders first): %v", e.name, err)
+		} else {
+			dualSide = true
+			log.Printf("[%s] Switched account to hedge mode", e.name)
+		}
+	}
+
+	e.binance.SetHedgeMode(dualSide)
+	if dualSide {
+		log.Printf("[%s] Hedge mode active - orders use positionSide (hedging %s by strategy)",
+			e.name, map[bool]string{true: "allowed", false: "not allowed"}[wantHedge])
+	}
+}
+
 // lastRiskSettingsLog tracks when we last logged risk settings (for rate limiting)
 var lastRiskSettingsLog = make(map[string]time.Time)
 var lastRiskSettingsLogMu sync.Mutex
@@ -2269,7 +2878,8 @@ func (e *Engine) checkPositionDrawdown(ctx context.Context) {
 	e.mu.RLock()
 	positions := make([]*exchange.Position, 0)
 	for _, pos := range e.positions {
-		positions = append(positions, pos)
+		p := *pos
+		positions = append(positions, &p)
 	}
 	e.mu.RUnlock()
 
@@ -2278,6 +2888,14 @@ func (e *Engine) checkPositionDrawdown(ctx context.Context) {
 			continue
 		}
 
+		// Use the streamed mark price when fresh (polled price can be up to 30s old)
+		if e.stream != nil {
+			e.stream.Watch(e.id, pos.Symbol)
+			if markPrice, ok := e.stream.MarkPrice(pos.Symbol); ok {
+				pos.MarkPrice = markPrice
+			}
+		}
+
 		// Calculate current P&L % (raw price movement, NOT leveraged)
 		// This matches the CLOSE action calculation and SL/TP percentages
 		// Note: The dollar P&L already reflects leverage, but percentage thresholds
@@ -2329,8 +2947,9 @@ func (e *Engine) checkPositionDrawdown(ctx context.Context) {
 						log.Printf("[%s][%s] Failed to close position (trailing stop): %v", e.name, pos.Symbol, err)
 					} else {
 						log.Printf("[%s][%s] ✅ Closed position via trailing stop. Realized profit locked in.", e.name, pos.Symbol)
-						e.clearPositionTracking(pos.Symbol, side)
-						e.cancelBracketOrders(ctx, pos.Symbol)
+						e.clearPositionTracking(pos.Symbol, side, store.CloseReasonTrailingStop)
+						e.forgetPosition(pos.Symbol, positionSide(pos))
+						e.cancelBracketOrders(ctx, pos.Symbol, positionSide(pos))
 					}
 					continue // Move to next position
 				}
@@ -2356,8 +2975,9 @@ func (e *Engine) checkPositionDrawdown(ctx context.Context) {
 					log.Printf("[%s][%s] Failed to close position (max hold): %v", e.name, pos.Symbol, err)
 				} else {
 					log.Printf("[%s][%s] ✅ Closed position due to max hold duration. PnL: %.2f%%", e.name, pos.Symbol, pnlPct)
-					e.clearPositionTracking(pos.Symbol, side)
-					e.cancelBracketOrders(ctx, pos.Symbol)
+					e.clearPositionTracking(pos.Symbol, side, store.CloseReasonMaxHold)
+					e.forgetPosition(pos.Symbol, positionSide(pos))
+					e.cancelBracketOrders(ctx, pos.Symbol, positionSide(pos))
 				}
 				continue // Move to next position
 			}
@@ -2387,8 +3007,9 @@ func (e *Engine) checkPositionDrawdown(ctx context.Context) {
 					log.Printf("[%s][%s] Failed to close position (smart loss cut): %v", e.name, pos.Symbol, err)
 				} else {
 					log.Printf("[%s][%s] ✅ Cut losing position. Loss: %.2f%%", e.name, pos.Symbol, pnlPct)
-					e.clearPositionTracking(pos.Symbol, side)
-					e.cancelBracketOrders(ctx, pos.Symbol)
+					e.clearPositionTracking(pos.Symbol, side, store.CloseReasonLossCut)
+					e.forgetPosition(pos.Symbol, positionSide(pos))
+					e.cancelBracketOrders(ctx, pos.Symbol, positionSide(pos))
 				}
 				continue // Move to next position
 			}
@@ -2427,8 +3048,9 @@ func (e *Engine) checkPositionDrawdown(ctx context.Context) {
 			if _, err := e.binance.ClosePosition(ctx, pos.Symbol, pos.PositionAmt); err != nil {
 				log.Printf("[%s][%s] Failed to close position: %v", e.name, pos.Symbol, err)
 			} else {
-				e.clearPositionTracking(pos.Symbol, side)
-				e.cancelBracketOrders(ctx, pos.Symbol)
+				e.clearPositionTracking(pos.Symbol, side, store.CloseReasonDrawdown)
+				e.forgetPosition(pos.Symbol, positionSide(pos))
+				e.cancelBracketOrders(ctx, pos.Symbol, positionSide(pos))
 			}
 		}
 	}
@@ -2480,7 +3102,7 @@ func (e *Engine) syncOrdersFromBinance(ctx context.Context) {
 	newPositions := make(map[string]*exchange.Position)
 	for i := range positions {
 		pos := &positions[i]
-		newPositions[pos.Symbol] = pos
+		newPositions[positionMapKey(pos)] = pos
 		currentSymbols[pos.Symbol] = true
 
 		// Track new positions
@@ -2498,14 +3120,12 @@ func (e *Engine) syncOrdersFromBinance(ctx context.Context) {
 	}
 
 	// Detect closed positions
-	for symbol, oldPos := range e.positions {
+	for posKey, oldPos := range e.positions {
 		if oldPos.PositionAmt != 0 {
-			newPos, exists := newPositions[symbol]
+			newPos, exists := newPositions[posKey]
 			if !exists || newPos.PositionAmt == 0 {
-				side := "LONG"
-				if oldPos.PositionAmt < 0 {
-					side = "SHORT"
-				}
+				symbol := oldPos.Symbol
+				side := positionSide(oldPos)
 				log.Printf("[%s] Position closed: %s %s", e.name, symbol, side)
 
 				// Clear tracking data
@@ -2524,8 +3144,8 @@ func (e *Engine) syncOrdersFromBinance(ctx context.Context) {
 	// we don't want to lose that update. Binance data is authoritative for
 	// existing positions, but we preserve local state for positions not yet
 	// visible on exchange (due to API latency).
-	for symbol, newPos := range newPositions {
-		e.positions[symbol] = newPos
+	for posKey, newPos := range newPositions {
+		e.positions[posKey] = newPos
 	}
 	// NOTE: We don't remove positions that aren't in newPositions here.
 	// A locally-opened position might not be visible on Binance yet due to
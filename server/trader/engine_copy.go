@@ -27,16 +27,18 @@ func (e *Engine) runCopyTradingCycle(ctx context.Context) {
 	if err != nil {
 		log.Printf("[%s] Error getting account info: %v", e.name, err)
 	} else {
+		versionID := e.strategyVersionID()
 		e.mu.Lock()
 		e.account = account
 
 		// Save equity snapshot for history/charts
 		e.equityStore.Save(&store.EquitySnapshot{
-			TraderID:      e.id,
-			Timestamp:     time.Now(),
-			TotalEquity:   account.TotalMarginBalance,
-			Balance:       account.TotalWalletBalance,
-			UnrealizedPnL: account.TotalUnrealizedProfit,
+			TraderID:          e.id,
+			Timestamp:         time.Now(),
+			TotalEquity:       account.TotalMarginBalance,
+			Balance:           account.TotalWalletBalance,
+			UnrealizedPnL:     account.TotalUnrealizedProfit,
+			StrategyVersionID: versionID,
 		})
 		e.mu.Unlock()
 
@@ -53,7 +55,7 @@ func (e *Engine) runCopyTradingCycle(ctx context.Context) {
 		e.positions = make(map[string]*exchange.Position)
 		activeCount := 0
 		for i := range positions {
-			e.positions[positions[i].Symbol] = &positions[i]
+			e.positions[positionMapKey(&positions[i])] = &positions[i]
 			if positions[i].PositionAmt != 0 {
 				activeCount++
 				log.Printf("[%s] Active Position: %s %s %.4f (PnL: $%.2f)",
@@ -0,0 +1,326 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"auto-trader-ahh/ai"
+	"auto-trader-ahh/debate"
+	"auto-trader-ahh/decision"
+	"auto-trader-ahh/events"
+	"auto-trader-ahh/exchange"
+	"auto-trader-ahh/store"
+)
+
+// debatePanel is the outcome of one debate-mode cycle
+type debatePanel struct {
+	session   *debate.SessionWithDetails
+	decisions map[string]*ai.TradingDecision // symbol -> consensus decision
+	lessons   []int64                        // Reflection lessons in the panel's prompt
+	snapshots map[string]string              // symbol -> formatted context the panel saw, for the audit
+	rejected  map[string]error               // symbol -> why its consensus can't be traded
+	err       error
+}
+
+// SetDebateEngine attaches the shared debate engine used in debate decision
+// mode. Must be called before Start.
+func (e *Engine) SetDebateEngine(engine *debate.Engine) {
+	e.debateEngine = engine
+}
+
+// debateMode reports whether the strategy asks for debate-panel decisions
+func (e *Engine) debateMode() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.strategy != nil && e.strategy.Config.DecisionMode == store.DecisionModeDebate
+}
+
+// runDebatePanel debates all symbols at once with the strategy's panel and
+// maps the consensus to one decision per symbol. Errors are kept on the panel
+// so every symbol of the cycle reports them.
+func (e *Engine) runDebatePanel(ctx context.Context, symbols []string) *debatePanel {
+	panel := &debatePanel{decisions: make(map[string]*ai.TradingDecision), rejected: make(map[string]error)}
+
+	if e.debateEngine == nil {
+		panel.err = fmt.Errorf("debate engine not available")
+		return panel
+	}
+
+	var cfg store.DebatePanelConfig
+	var promptVariant string
+	e.mu.RLock()
+	if e.strategy != nil {
+		cfg = e.strategy.Config.Debate
+		promptVariant = e.strategy.Config.PromptVariant
+	}
+	e.mu.RUnlock()
+	if len(cfg.Participants) < 2 {
+		panel.err = fmt.Errorf("debate mode needs at least 2 participants, strategy has %d", len(cfg.Participants))
+		return panel
+	}
+
+	req := &debate.CreateSessionRequest{
+		Name:                 fmt.Sprintf("%s cycle %s", e.name, time.Now().Format("15:04")),
+		Symbols:              symbols,
+		MaxRounds:            cfg.MaxRounds,
+		TraderID:             e.id,
+		Language:             cfg.Language,
+		ConsensusMode:        cfg.ConsensusMode,
+		ConvergenceThreshold: cfg.ConvergenceThreshold,
+		TokenBudget:          cfg.TokenBudget,
+		CostBudgetUSD:        cfg.CostBudgetUSD,
+		PromptVariant:        promptVariant,
+	}
+	if req.MaxRounds <= 0 {
+		req.MaxRounds = 2
+	}
+	for _, p := range cfg.Participants {
+		provider := p.Provider
+		if provider == "" {
+			provider = "openrouter"
+		}
+		req.Participants = append(req.Participants, debate.CreateParticipantRequest{
+			AIModelID:   p.AIModelID,
+			AIModelName: p.AIModelName,
+			Provider:    provider,
+			Personality: debate.Personality(p.Personality),
+		})
+	}
+
+	if j := cfg.Judge; j != nil && j.AIModelID != "" {
+		req.Judge = &debate.CreateParticipantRequest{
+			AIModelID:   j.AIModelID,
+			AIModelName: j.AIModelName,
+			Provider:    j.Provider,
+			Personality: debate.PersonalityJudge,
+		}
+		if req.Judge.Provider == "" {
+			req.Judge.Provider = "openrouter"
+		}
+	}
+
+	marketCtx := e.buildDebateMarketContext(ctx, symbols)
+	panel.lessons = lessonIDs(marketCtx.Lessons)
+	lang := decision.LangEnglish
+	if cfg.Language == "zh-CN" {
+		lang = decision.LangChinese
+	}
+	panel.snapshots = make(map[string]string, len(marketCtx.MarketData))
+	for symbol, md := range marketCtx.MarketData {
+		panel.snapshots[symbol] = decision.FormatContextForAI(&decision.Context{
+			CurrentTime:   marketCtx.CurrentTime,
+			Account:       marketCtx.Account,
+			Positions:     marketCtx.Positions,
+			MarketDataMap: map[string]*decision.MarketData{symbol: md},
+		}, lang)
+	}
+
+	log.Printf("[%s] 🗣️ DEBATE MODE: %d participants, %d rounds on %v", e.name, len(req.Participants), req.MaxRounds, symbols)
+
+	e.mu.Lock()
+	e.callCount++
+	e.mu.Unlock()
+
+	session, err := e.debateEngine.RunPanel(ctx, req, marketCtx)
+	panel.session = session
+	if err != nil {
+		panel.err = fmt.Errorf("debate failed: %w", err)
+		if e.notifier != nil {
+			e.notifier.Broadcast(events.Event{
+				Type:      events.TypeError,
+				TraderID:  e.id,
+				Message:   panel.err.Error(),
+				Timestamp: time.Now().UnixMilli(),
+			})
+		}
+		return panel
+	}
+
+	for _, d := range session.FinalDecisions {
+		price := 0.0
+		if md := marketCtx.MarketData[d.Symbol]; md != nil {
+			price = md.Price
+		}
+		decision, err := debateToTradingDecision(d, price)
+		if err != nil {
+			panel.rejected[d.Symbol] = err
+			continue
+		}
+		panel.decisions[d.Symbol] = decision
+	}
+	log.Printf("[%s] 🗣️ Debate %s reached consensus on %d symbols", e.name, session.ID, len(panel.decisions))
+
+	return panel
+}
+
+// tradeOnPanelDecision acts on the panel's consensus for one symbol, the debate-mode
+// counterpart of analyzeAndTrade. Symbols without a consensus hold.
+func (e *Engine) tradeOnPanelDecision(ctx context.Context, symbol string, panel *debatePanel) *TradeLog {
+	tradeLog := &TradeLog{
+		Timestamp: time.Now(),
+		Symbol:    symbol,
+		Audit:     e.newAudit(symbol, store.DecisionModeDebate, store.DecisionModeDebate),
+	}
+	tradeLog.Audit.MarketSnapshot = panel.snapshots[symbol]
+	if panel.session != nil {
+		tradeLog.Prompts = panel.session.Prompts
+		tradeLog.Audit.DebateSessionID = panel.session.ID
+		tradeLog.Audit.SystemPrompt = panel.session.SystemPrompt
+		tradeLog.Audit.UserPrompt = panel.session.UserPrompt
+	}
+	if len(panel.lessons) > 0 {
+		tradeLog.Lessons = panel.lessons
+	}
+	if panel.err != nil {
+		tradeLog.Error = panel.err.Error()
+		tradeLog.Audit.Block(store.GuardAIResponse, "%v", panel.err)
+		return tradeLog
+	}
+
+	if err := panel.rejected[symbol]; err != nil {
+		tradeLog.Error = fmt.Sprintf("rejected: %v", err)
+		tradeLog.Audit.Pass(store.GuardAIResponse, "panel consensus")
+		tradeLog.Audit.Block(store.GuardBrackets, "%v", err)
+		return tradeLog
+	}
+
+	decision := panel.decisions[symbol]
+	if decision == nil {
+		decision = &ai.TradingDecision{
+			Action:    "HOLD",
+			Symbol:    symbol,
+			Reasoning: "No consensus from debate panel",
+		}
+		tradeLog.Audit.Pass(store.GuardAIResponse, "no consensus, holding")
+	} else {
+		tradeLog.Audit.Pass(store.GuardAIResponse, "panel consensus")
+	}
+
+	e.mu.RLock()
+	longPos, shortPos := e.symbolPositionsLocked(symbol)
+	e.mu.RUnlock()
+
+	tradeLog = e.actOnDecision(ctx, symbol, decision, tradeLog, longPos, shortPos, e.hedgeEnabled())
+	if tradeLog.Executed && panel.session != nil {
+		// Score the panel's votes on this symbol once the trade plays out
+		e.debateEngine.MarkExecuted(panel.session.ID, panel.session.CycleCount, symbol, e.id)
+	}
+	return tradeLog
+}
+
+// buildDebateMarketContext gathers account, positions and candles for the panel
+func (e *Engine) buildDebateMarketContext(ctx context.Context, symbols []string) *debate.MarketContext {
+	decisionCtx := e.buildDecisionContext(ctx)
+
+	timeframe := "5m"
+	klineCount := 100
+	e.mu.RLock()
+	if e.strategy != nil {
+		timeframe = e.strategy.Config.Indicators.PrimaryTimeframe
+		klineCount = e.strategy.Config.Indicators.KlineCount
+	}
+	e.mu.RUnlock()
+
+	marketData := make(map[string]*decision.MarketData)
+	for _, symbol := range symbols {
+		data, err := e.dataProvider.GetMarketDataWithConfig(ctx, symbol, timeframe, klineCount)
+		if err != nil {
+			log.Printf("[%s][%s] Failed to get market data for debate: %v", e.name, symbol, err)
+			continue
+		}
+		marketData[symbol] = toDecisionMarketData(data.Symbol, data.CurrentPrice, data.PriceChange24h, data.Klines)
+	}
+
+	return &debate.MarketContext{
+		CurrentTime: decisionCtx.CurrentTime,
+		Account:     decisionCtx.Account,
+		Positions:   decisionCtx.Positions,
+		MarketData:  marketData,
+		Lessons:     decisionCtx.Lessons,
+	}
+}
+
+// toDecisionMarketData converts candles into the debate prompt's market data
+func toDecisionMarketData(symbol string, price, change float64, klines []exchange.Kline) *decision.MarketData {
+	md := &decision.MarketData{
+		Symbol:    symbol,
+		Price:     price,
+		Change24h: change,
+		Timestamp: time.Now(),
+	}
+	for i, k := range klines {
+		if i == 0 || k.High > md.HighPrice24h {
+			md.HighPrice24h = k.High
+		}
+		if i == 0 || k.Low < md.LowPrice24h {
+			md.LowPrice24h = k.Low
+		}
+		md.Volume24h += k.Volume
+		md.Klines = append(md.Klines, decision.Kline{
+			OpenTime:  k.OpenTime,
+			Open:      k.Open,
+			High:      k.High,
+			Low:       k.Low,
+			Close:     k.Close,
+			Volume:    k.Volume,
+			CloseTime: k.CloseTime,
+		})
+	}
+	return md
+}
+
+// engineAction maps the structured decision actions to the engine's: opens
+// become BUY/SELL and waiting holds. Leg-specific closes are kept.
+func engineAction(action string) string {
+	switch action {
+	case "open_long":
+		return "BUY"
+	case "open_short":
+		return "SELL"
+	case "hold", "wait":
+		return "HOLD"
+	}
+	return action
+}
+
+// debateToTradingDecision converts a debate consensus into the engine's decision
+// format. Leg-specific closes are kept so hedge mode closes the right side, and
+// absolute SL/TP prices become percentages of price (when known). SL/TP on the
+// wrong side of the price are an error.
+func debateToTradingDecision(d *debate.Decision, price float64) (*ai.TradingDecision, error) {
+	td := &ai.TradingDecision{
+		Action:     engineAction(d.Action),
+		Symbol:     d.Symbol,
+		Confidence: float64(d.Confidence),
+		Reasoning:  d.Reasoning,
+		StopLoss:   d.StopLoss,
+		TakeProfit: d.TakeProfit,
+	}
+	var err error
+	if td.StopLossPct, td.TakeProfitPct, err = bracketPcts(td.Action, price, d.StopLoss, d.TakeProfit); err != nil {
+		return nil, err
+	}
+	return td, nil
+}
+
+// debateTranscript serializes a panel's messages, votes and consensus for the decision record
+func debateTranscript(session *debate.SessionWithDetails) string {
+	if session == nil {
+		return ""
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"debate_session_id": session.ID,
+		"participants":      session.Participants,
+		"messages":          session.Messages,
+		"votes":             session.Votes,
+		"final_decisions":   session.FinalDecisions,
+		"error":             session.Error,
+	})
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
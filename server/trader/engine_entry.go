@@ -0,0 +1,236 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"auto-trader-ahh/ai"
+	"auto-trader-ahh/exchange"
+	"auto-trader-ahh/store"
+)
+
+// entryExecution returns the strategy's entry order settings with defaults applied
+func (e *Engine) entryExecution() store.EntryExecutionConfig {
+	var cfg store.EntryExecutionConfig
+	e.mu.RLock()
+	if e.strategy != nil {
+		cfg = e.strategy.Config.EntryExecution
+	}
+	e.mu.RUnlock()
+
+	if cfg.OrderType == "" {
+		cfg.OrderType = store.EntryOrderMarket
+	}
+	if cfg.TimeoutSecs <= 0 {
+		cfg.TimeoutSecs = 30
+	}
+	if cfg.TimeoutAction != "cancel" {
+		cfg.TimeoutAction = "market"
+	}
+	return cfg
+}
+
+// placeEntryOrder opens a position using the strategy's entry order type and
+// returns the fill. MARKET entries return immediately. LIMIT and post-only
+// entries are polled until filled; on timeout the unfilled remainder is either
+// converted to MARKET or cancelled, and the order is cancelled early if price
+// runs away from the limit. Bracket orders must only be placed after this returns.
+func (e *Engine) placeEntryOrder(ctx context.Context, symbol, side string, quantity float64, decision *ai.TradingDecision) (*exchange.Order, error) {
+	cfg := e.entryExecution()
+	if cfg.OrderType != store.EntryOrderLimit && cfg.OrderType != store.EntryOrderPostOnly {
+		return e.binance.PlaceOrder(ctx, symbol, side, "MARKET", quantity, 0, false)
+	}
+
+	postOnly := cfg.OrderType == store.EntryOrderPostOnly
+	timeInForce := "GTC"
+	if postOnly {
+		timeInForce = "GTX"
+	}
+	aiPrice := 0.0
+	if cfg.PriceSource == "ai" {
+		aiPrice = decision.EntryPrice
+	}
+	deadline := time.Now().Add(time.Duration(cfg.TimeoutSecs) * time.Second)
+
+	// Post-only orders that would cross the book are rejected; re-quote a few times
+	var order *exchange.Order
+	var limitPrice float64
+	for attempt := 1; ; attempt++ {
+		book, err := e.binance.GetBookTicker(ctx, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order book: %w", err)
+		}
+		limitPrice = limitEntryPrice(side, book, aiPrice, postOnly)
+
+		// Check tick size, notional and PERCENT_PRICE against the mark before submitting
+		quantity, limitPrice, err = e.binance.ValidateOrder(symbol, "LIMIT", quantity, limitPrice, e.currentPrice(ctx, symbol), false)
+		if err != nil {
+			return nil, fmt.Errorf("skipped: %w", err)
+		}
+
+		log.Printf("[%s][%s] 📝 Placing %s %s entry: %.4f @ $%.4f (bid $%.4f / ask $%.4f, timeout %ds then %s)",
+			e.name, symbol, cfg.OrderType, side, quantity, limitPrice, book.BidPrice, book.AskPrice, cfg.TimeoutSecs, cfg.TimeoutAction)
+
+		order, err = e.binance.PlaceLimitOrder(ctx, symbol, side, quantity, limitPrice, timeInForce)
+		if err != nil && !isPostOnlyReject(err) {
+			return nil, fmt.Errorf("failed to place %s entry: %w", cfg.OrderType, err)
+		}
+		if err == nil && order.Status != "EXPIRED" {
+			break
+		}
+
+		log.Printf("[%s][%s] Post-only entry rejected (would take liquidity), attempt %d", e.name, symbol, attempt)
+		if attempt >= 3 || time.Now().After(deadline) {
+			return e.finishEntry(ctx, symbol, side, quantity, nil, cfg.TimeoutAction, "post-only rejected")
+		}
+	}
+
+	poll := time.NewTicker(time.Second)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			cancelCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			e.binance.CancelOrder(cancelCtx, symbol, order.OrderID)
+			cancel()
+			return nil, fmt.Errorf("entry aborted: %w", ctx.Err())
+		case <-poll.C:
+		}
+
+		status, err := e.binance.GetOrder(ctx, symbol, order.OrderID)
+		if err != nil {
+			log.Printf("[%s][%s] Failed to poll entry order %d: %v", e.name, symbol, order.OrderID, err)
+			continue
+		}
+		order = status
+
+		switch order.Status {
+		case "FILLED":
+			log.Printf("[%s][%s] ✅ %s entry filled: %.4f @ $%.4f", e.name, symbol, cfg.OrderType, order.ExecutedQty, order.AvgPrice)
+			return order, nil
+		case "CANCELED", "EXPIRED", "REJECTED":
+			return e.finishEntry(ctx, symbol, side, quantity, order, cfg.TimeoutAction, "order "+strings.ToLower(order.Status))
+		}
+
+		if cfg.MaxChasePct > 0 {
+			if current := e.currentPrice(ctx, symbol); priceRanAway(side, limitPrice, current, cfg.MaxChasePct) {
+				log.Printf("[%s][%s] 🏃 Price ran away from limit ($%.4f → $%.4f, > %.2f%%), cancelling entry",
+					e.name, symbol, limitPrice, current, cfg.MaxChasePct)
+				return e.finishEntry(ctx, symbol, side, quantity, order, "cancel",
+					fmt.Sprintf("price ran away %.2f%% from limit", cfg.MaxChasePct))
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return e.finishEntry(ctx, symbol, side, quantity, order, cfg.TimeoutAction,
+				fmt.Sprintf("not filled within %ds", cfg.TimeoutSecs))
+		}
+	}
+}
+
+// finishEntry cancels a still-working entry order and, depending on action,
+// fills the remainder at MARKET ("market") or keeps only what filled ("cancel").
+// Returns an error if nothing was filled.
+func (e *Engine) finishEntry(ctx context.Context, symbol, side string, quantity float64, order *exchange.Order, action, reason string) (*exchange.Order, error) {
+	filled := &exchange.Order{Symbol: symbol, Side: side, Type: "LIMIT", Status: "FILLED"}
+
+	if order != nil {
+		if order.Status == "NEW" || order.Status == "PARTIALLY_FILLED" {
+			if err := e.binance.CancelOrder(ctx, symbol, order.OrderID); err != nil {
+				log.Printf("[%s][%s] Failed to cancel entry order %d: %v", e.name, symbol, order.OrderID, err)
+			}
+			// Re-read: the order may have filled between the last poll and the cancel
+			if final, err := e.binance.GetOrder(ctx, symbol, order.OrderID); err == nil {
+				order = final
+			}
+		}
+		filled.OrderID = order.OrderID
+		filled.ExecutedQty = order.ExecutedQty
+		filled.AvgPrice = order.AvgPrice
+	}
+
+	remaining := quantity - filled.ExecutedQty
+	if action != "market" || remaining <= 0 {
+		if filled.ExecutedQty <= 0 {
+			log.Printf("[%s][%s] ❌ Limit entry cancelled with no fill: %s", e.name, symbol, reason)
+			return nil, fmt.Errorf("skipped: limit entry %s", reason)
+		}
+		log.Printf("[%s][%s] Limit entry partially filled (%.4f of %.4f): %s", e.name, symbol, filled.ExecutedQty, quantity, reason)
+		return filled, nil
+	}
+
+	log.Printf("[%s][%s] ⏱️ Limit entry %s, converting remaining %.4f to MARKET", e.name, symbol, reason, remaining)
+	marketOrder, err := e.binance.PlaceOrder(ctx, symbol, side, "MARKET", remaining, 0, false)
+	if err != nil {
+		if filled.ExecutedQty > 0 {
+			log.Printf("[%s][%s] MARKET remainder failed, keeping partial fill %.4f: %v", e.name, symbol, filled.ExecutedQty, err)
+			return filled, nil
+		}
+		return nil, fmt.Errorf("failed to convert entry to market: %w", err)
+	}
+
+	if marketOrder.Status != "FILLED" || marketOrder.AvgPrice <= 0 {
+		// Fill details unknown - the caller verifies the position from the exchange
+		filled.Status = marketOrder.Status
+		return filled, nil
+	}
+
+	totalQty := filled.ExecutedQty + marketOrder.ExecutedQty
+	if totalQty > 0 {
+		filled.AvgPrice = (filled.AvgPrice*filled.ExecutedQty + marketOrder.AvgPrice*marketOrder.ExecutedQty) / totalQty
+	}
+	filled.ExecutedQty = totalQty
+	return filled, nil
+}
+
+// currentPrice returns the streamed mark price when fresh, else the last traded price
+func (e *Engine) currentPrice(ctx context.Context, symbol string) float64 {
+	if e.stream != nil {
+		if price, ok := e.stream.MarkPrice(symbol); ok {
+			return price
+		}
+	}
+	ticker, err := e.binance.GetTicker(ctx, symbol)
+	if err != nil {
+		return 0
+	}
+	return ticker.Price
+}
+
+// limitEntryPrice picks the limit price for an entry: join the best bid (BUY)
+// or best ask (SELL), or use the AI's price. Post-only orders never use an AI
+// price that would cross the spread, since GTX would reject it.
+func limitEntryPrice(side string, book *exchange.BookTicker, aiPrice float64, postOnly bool) float64 {
+	price := book.BidPrice
+	if side == "SELL" {
+		price = book.AskPrice
+	}
+
+	if aiPrice > 0 {
+		crosses := (side == "BUY" && aiPrice >= book.AskPrice) || (side == "SELL" && aiPrice <= book.BidPrice)
+		if !(postOnly && crosses) {
+			price = aiPrice
+		}
+	}
+	return price
+}
+
+// priceRanAway reports whether price moved away from an unfilled limit entry by more than maxPct
+func priceRanAway(side string, limitPrice, current, maxPct float64) bool {
+	if limitPrice <= 0 || current <= 0 {
+		return false
+	}
+	if side == "BUY" {
+		return current > limitPrice*(1+maxPct/100)
+	}
+	return current < limitPrice*(1-maxPct/100)
+}
+
+// isPostOnlyReject reports whether Binance rejected a GTX order for taking liquidity
+func isPostOnlyReject(err error) bool {
+	return strings.Contains(err.Error(), "-5022")
+}
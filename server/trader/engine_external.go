@@ -0,0 +1,212 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"auto-trader-ahh/ai"
+	"auto-trader-ahh/exchange"
+	"auto-trader-ahh/store"
+)
+
+// ExecuteDecision runs a decision made outside the trading loop (e.g. a debate
+// consensus) through the same pipeline as the engine's own AI decisions:
+// trading pause, confidence and multi-timeframe gates, position resolution,
+// risk controls, symbol filters, bracket tracking and decision persistence.
+// Only the trader's own pairs are traded, since leverage and fill syncing are
+// set up for those. source identifies the caller in logs and the decision
+// record. Rejections are reported in TradeLog.Error.
+func (e *Engine) ExecuteDecision(ctx context.Context, decision *ai.TradingDecision, source string) *TradeLog {
+	symbol := decision.Symbol
+	decision.Action = engineAction(decision.Action)
+	tradeLog := &TradeLog{
+		Timestamp: time.Now(),
+		Symbol:    symbol,
+		Action:    decision.Action,
+		Decision:  decision,
+		Audit:     e.newAudit(symbol, source, externalModel(source)),
+	}
+	defer e.recordExternalDecision(tradeLog, source)
+	audit := tradeLog.Audit
+	if session, ok := strings.CutPrefix(source, store.DecisionModeDebate+":"); ok {
+		audit.DebateSessionID = session
+	}
+
+	if !e.IsRunning() {
+		tradeLog.Error = "skipped: trader is not running"
+		return tradeLog
+	}
+
+	e.mu.RLock()
+	copyTrading := e.strategy != nil && e.strategy.Config.TradingMode == "copy_trade"
+	e.mu.RUnlock()
+	if copyTrading {
+		tradeLog.Error = "skipped: trader is in copy trading mode"
+		audit.Block(store.GuardCopyTrading, "trader is in copy trading mode")
+		return tradeLog
+	}
+
+	if decision.Action == "HOLD" {
+		return tradeLog
+	}
+
+	if !e.isTradingPair(symbol) {
+		tradeLog.Error = fmt.Sprintf("rejected: %s is not one of the trader's pairs", symbol)
+		audit.Block(store.GuardTradingPair, "%s is not one of the trader's pairs", symbol)
+		return tradeLog
+	}
+	audit.Pass(store.GuardTradingPair, "")
+
+	e.resetDailyPnLIfNeeded()
+	if e.shouldStopTrading() {
+		e.mu.RLock()
+		stopUntil := e.stopUntil
+		e.mu.RUnlock()
+		tradeLog.Error = fmt.Sprintf("blocked: trading paused until %s", stopUntil.Format(time.RFC3339))
+		audit.Block(store.GuardTradingPause, "paused until %s", stopUntil.Format(time.RFC3339))
+		return tradeLog
+	}
+	audit.Pass(store.GuardTradingPause, "")
+
+	log.Printf("[%s][%s] 📥 External decision from %s: %s (confidence %.0f%%)",
+		e.name, symbol, source, decision.Action, decision.Confidence)
+
+	// Callers may send absolute SL/TP prices; the engine works in percentages
+	if decision.StopLossPct <= 0 && decision.StopLoss > 0 || decision.TakeProfitPct <= 0 && decision.TakeProfit > 0 {
+		ticker, err := e.binance.GetTicker(ctx, symbol)
+		if err != nil {
+			tradeLog.Error = fmt.Sprintf("failed to get price for SL/TP: %v", err)
+			return tradeLog
+		}
+		slPct, tpPct, err := bracketPcts(decision.Action, ticker.Price, decision.StopLoss, decision.TakeProfit)
+		if err != nil {
+			tradeLog.Error = fmt.Sprintf("rejected: %v", err)
+			audit.Block(store.GuardBrackets, "%v", err)
+			return tradeLog
+		}
+		audit.Pass(store.GuardBrackets, "")
+		if decision.StopLossPct <= 0 {
+			decision.StopLossPct = slPct
+		}
+		if decision.TakeProfitPct <= 0 {
+			decision.TakeProfitPct = tpPct
+		}
+	}
+
+	// Fresh account and positions - the last cycle may be minutes old
+	e.execMu.Lock()
+	if account, err := e.binance.GetAccountInfo(ctx); err == nil {
+		e.mu.Lock()
+		e.account = account
+		e.mu.Unlock()
+	}
+	if positions, err := e.binance.GetPositions(ctx); err == nil {
+		e.mu.Lock()
+		e.positions = make(map[string]*exchange.Position)
+		for i := range positions {
+			e.positions[positionMapKey(&positions[i])] = &positions[i]
+		}
+		e.mu.Unlock()
+	}
+	e.execMu.Unlock()
+
+	e.mu.RLock()
+	longPos, shortPos := e.symbolPositionsLocked(symbol)
+	e.mu.RUnlock()
+
+	tradeLog = e.actOnDecision(ctx, symbol, decision, tradeLog, longPos, shortPos, e.hedgeEnabled())
+	if !tradeLog.Executed {
+		// actOnDecision only logs a low confidence skip; the caller needs the reason
+		if tradeLog.Error == "" && audit.BlockReason == store.GuardConfidence {
+			tradeLog.Error = fmt.Sprintf("skipped: confidence %s", audit.Guards[len(audit.Guards)-1].Detail)
+		}
+		return tradeLog
+	}
+
+	if e.checkDailyLoss() {
+		e.triggerTradingPause(ctx)
+	}
+	e.syncTradeHistory(ctx)
+
+	return tradeLog
+}
+
+// isTradingPair reports whether symbol is one of the pairs the trader analyzes
+func (e *Engine) isTradingPair(symbol string) bool {
+	for _, pair := range e.getTradingPairs() {
+		if pair == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// recordExternalDecision saves an external decision in the same format as the trading cycle
+func (e *Engine) recordExternalDecision(tradeLog *TradeLog, source string) {
+	decisionData := map[string]interface{}{
+		"symbol": tradeLog.Symbol,
+		"action": tradeLog.Action,
+		"source": source,
+	}
+	if tradeLog.Decision != nil {
+		decisionData["confidence"] = tradeLog.Decision.Confidence
+		if tradeLog.CalibratedConfidence != 0 {
+			decisionData["calibrated_confidence"] = tradeLog.CalibratedConfidence
+		}
+		decisionData["reasoning"] = tradeLog.Decision.Reasoning
+	}
+	if tradeLog.Error != "" {
+		log.Printf("[%s][%s] External decision (%s) not executed: %s", e.name, tradeLog.Symbol, source, tradeLog.Error)
+		decisionData["error"] = tradeLog.Error
+	}
+	if tradeLog.RealizedPnL != 0 {
+		decisionData["pnl"] = tradeLog.RealizedPnL
+	}
+
+	decisionsJSON, _ := json.Marshal([]map[string]interface{}{decisionData})
+	record := &store.Decision{
+		TraderID:          e.id,
+		Decisions:         string(decisionsJSON),
+		Executed:          tradeLog.Error == "",
+		StrategyVersionID: e.strategyVersionID(),
+	}
+	e.decisionStore.Create(record)
+	e.saveAudit(tradeLog, record.ID)
+}
+
+// bracketPcts converts absolute SL/TP prices for an opening action into the
+// engine's percentage distances from price. A stop on the profit side or a
+// target on the loss side is an error. Unset prices stay zero, as do brackets
+// on closing actions and without a price.
+func bracketPcts(action string, price, stopLoss, takeProfit float64) (slPct, tpPct float64, err error) {
+	var long bool
+	switch action {
+	case "BUY", "open_long":
+		long = true
+	case "SELL", "open_short":
+	default:
+		return 0, 0, nil
+	}
+	if price <= 0 {
+		return 0, 0, nil
+	}
+
+	if stopLoss > 0 {
+		if long && stopLoss >= price || !long && stopLoss <= price {
+			return 0, 0, fmt.Errorf("stop loss %g is on the wrong side of price %g for %s", stopLoss, price, action)
+		}
+		slPct = math.Abs(price-stopLoss) / price * 100
+	}
+	if takeProfit > 0 {
+		if long && takeProfit <= price || !long && takeProfit >= price {
+			return 0, 0, fmt.Errorf("take profit %g is on the wrong side of price %g for %s", takeProfit, price, action)
+		}
+		tpPct = math.Abs(takeProfit-price) / price * 100
+	}
+	return slPct, tpPct, nil
+}
@@ -0,0 +1,90 @@
+package trader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"auto-trader-ahh/ai"
+	"auto-trader-ahh/config"
+	"auto-trader-ahh/exchange"
+	"auto-trader-ahh/store"
+)
+
+// TestExecuteDecisionGating tests the gates an external decision meets before
+// it reaches the exchange
+func TestExecuteDecisionGating(t *testing.T) {
+	if err := store.Init(t.TempDir()); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer store.Close()
+
+	tests := []struct {
+		name      string
+		symbol    string
+		action    string
+		setup     func(e *Engine)
+		wantBlock string
+		wantError bool
+	}{
+		{"not running", "BTCUSDT", "open_long", func(e *Engine) { e.running = false }, store.BlockReasonError, true},
+		{"copy trading", "BTCUSDT", "open_long", func(e *Engine) { e.strategy.Config.TradingMode = "copy_trade" }, store.GuardCopyTrading, true},
+		{"hold", "BTCUSDT", "wait", nil, "", false},
+		{"other pair", "DOGEUSDT", "open_long", nil, store.GuardTradingPair, true},
+		{"trading paused", "BTCUSDT", "open_short", func(e *Engine) { e.stopUntil = time.Now().Add(time.Hour) }, store.GuardTradingPause, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := &store.Strategy{}
+			strategy.Config.CoinSource.StaticCoins = []string{"BTCUSDT", "ETHUSDT"}
+			e := NewEngine("t1", "test", nil, &exchange.BinanceClient{}, strategy, &store.TraderConfig{}, &config.Config{}, nil)
+			e.running = true
+			if tt.setup != nil {
+				tt.setup(e)
+			}
+
+			tradeLog := e.ExecuteDecision(context.Background(), &ai.TradingDecision{Action: tt.action, Symbol: tt.symbol, Confidence: 90}, "debate:s1")
+			if tradeLog.Executed {
+				t.Fatalf("Executed = true, want the decision gated")
+			}
+			if (tradeLog.Error != "") != tt.wantError {
+				t.Errorf("Error = %q, wantError %v", tradeLog.Error, tt.wantError)
+			}
+			if tradeLog.Audit.BlockReason != tt.wantBlock {
+				t.Errorf("BlockReason = %q, want %q", tradeLog.Audit.BlockReason, tt.wantBlock)
+			}
+		})
+	}
+}
+
+// TestBracketPcts tests converting absolute SL/TP to distances and rejecting
+// ones on the wrong side of the price
+func TestBracketPcts(t *testing.T) {
+	tests := []struct {
+		name           string
+		action         string
+		sl, tp         float64
+		wantSL, wantTP float64
+		wantErr        bool
+	}{
+		{"long", "BUY", 98, 105, 2, 5, false},
+		{"short", "SELL", 104, 97, 4, 3, false},
+		{"long stop above price", "BUY", 101, 105, 0, 0, true},
+		{"long target below price", "open_long", 98, 99, 0, 0, true},
+		{"short stop below price", "open_short", 99, 97, 0, 0, true},
+		{"short target above price", "SELL", 104, 102, 0, 0, true},
+		{"stop only", "BUY", 98, 0, 2, 0, false},
+		{"close ignores brackets", "CLOSE", 101, 99, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sl, tp, err := bracketPcts(tt.action, 100, tt.sl, tt.tp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sl != tt.wantSL || tp != tt.wantTP {
+				t.Errorf("bracketPcts() = %v/%v, want %v/%v", sl, tp, tt.wantSL, tt.wantTP)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package trader
+
+import (
+	"testing"
+	"time"
+
+	"auto-trader-ahh/config"
+	"auto-trader-ahh/exchange"
+	"auto-trader-ahh/store"
+)
+
+// TestRestoreLegVersions tests that a leg opened before a restart keeps its
+// version for fills synced after it
+func TestRestoreLegVersions(t *testing.T) {
+	if err := store.Init(t.TempDir()); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer store.Close()
+
+	opened := time.Now().Add(-time.Hour)
+	if err := store.NewTradeStore().Save(&store.Trade{ID: 1, TraderID: "t1", Symbol: "BTCUSDT", Side: "BUY",
+		PositionSide: "LONG", Price: 100, Quantity: 1, Timestamp: opened, StrategyVersionID: 3}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	e := NewEngine("t1", "test", nil, &exchange.BinanceClient{}, nil, &store.TraderConfig{}, &config.Config{}, nil)
+	e.restoreLegVersions()
+
+	const running = 5
+	tests := []struct {
+		name         string
+		positionSide string
+		at           time.Time
+		want         int64
+	}{
+		{"close after the restart", "LONG", time.Now(), 3},
+		{"other leg", "SHORT", time.Now(), running},
+		{"before the open", "LONG", opened.Add(-time.Minute), running},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.tradeVersionID("BTCUSDT", tt.positionSide, tt.at, running); got != tt.want {
+				t.Errorf("tradeVersionID() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,9 +8,13 @@ import (
 
 	"auto-trader-ahh/ai"
 	"auto-trader-ahh/config"
+	"auto-trader-ahh/debate"
 	"auto-trader-ahh/events"
 	"auto-trader-ahh/exchange"
+	"auto-trader-ahh/market"
+	"auto-trader-ahh/mcp"
 	"auto-trader-ahh/store"
+	"auto-trader-ahh/usage"
 )
 
 // EngineManager manages multiple trading engine instances
@@ -19,7 +23,11 @@ type EngineManager struct {
 	engines       map[string]*Engine
 	traderStore   *store.TraderStore
 	strategyStore *store.StrategyStore
+	settingsStore *store.SettingsStore
+	meter         *usage.Meter // Records AI calls and enforces trader AI budgets
 	hub           *events.Hub
+	streams       map[bool]*market.Stream // key: testnet - one WebSocket feed shared by all engines
+	debateEngine  *debate.Engine          // Shared with engines in debate decision mode
 	mu            sync.RWMutex
 }
 
@@ -29,10 +37,20 @@ func NewEngineManager(cfg *config.Config, hub *events.Hub) *EngineManager {
 		engines:       make(map[string]*Engine),
 		traderStore:   store.NewTraderStore(),
 		strategyStore: store.NewStrategyStore(),
+		settingsStore: store.NewSettingsStore(),
+		meter:         usage.NewMeter(),
 		hub:           hub,
+		streams:       make(map[bool]*market.Stream),
 	}
 }
 
+// SetDebateEngine sets the debate engine used by traders in debate decision mode
+func (m *EngineManager) SetDebateEngine(engine *debate.Engine) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.debateEngine = engine
+}
+
 // Start starts a trader by ID
 func (m *EngineManager) Start(traderID string) error {
 	m.mu.Lock()
@@ -66,13 +84,7 @@ func (m *EngineManager) Start(traderID string) error {
 	if apiKey == "" {
 		apiKey = m.cfg.OpenRouterAPIKey
 	}
-	model := trader.Config.OpenRouterModel
-	if model == "" {
-		model = trader.Config.AIModel // Legacy field
-	}
-	if model == "" {
-		model = m.cfg.OpenRouterModel
-	}
+	model := traderModel(&trader.Config, mcp.ProviderOpenRouter, m.cfg.OpenRouterModel)
 	aiClient := ai.NewClient(apiKey, model)
 
 	// Create exchange client
@@ -88,6 +100,19 @@ func (m *EngineManager) Start(traderID string) error {
 
 	// Create engine
 	engine := NewEngine(traderID, trader.Name, aiClient, binanceClient, strategy, &trader.Config, m.cfg, m.hub)
+	if stream := m.getMarketStream(testnet); stream != nil {
+		engine.SetMarketStream(stream)
+	}
+	if m.debateEngine != nil {
+		engine.SetDebateEngine(m.debateEngine)
+	}
+	if chain := m.aiFallbackChain(trader, apiKey); chain != nil {
+		engine.SetAIFallback(chain)
+	}
+	engine.SetAIMeter(m.meter.Observer(store.CallerTrader, traderID, traderID), m.meter.Gate(traderID))
+	if trader.Config.RecordAICalls {
+		engine.SetAIRecorder(newRecordingCassette(traderID))
+	}
 
 	// Start engine
 	ctx := context.Background()
@@ -122,6 +147,42 @@ func (m *EngineManager) StopAll() {
 		log.Printf("Stopped trader: %s", id)
 	}
 	m.engines = make(map[string]*Engine)
+
+	for _, stream := range m.streams {
+		stream.Stop()
+	}
+	m.streams = make(map[bool]*market.Stream)
+}
+
+// getMarketStream returns the shared market stream for the network, creating it
+// on first use. Returns nil when streaming is disabled. Caller must hold m.mu.
+func (m *EngineManager) getMarketStream(testnet bool) *market.Stream {
+	if !m.cfg.MarketStreamEnabled {
+		return nil
+	}
+	if stream, ok := m.streams[testnet]; ok {
+		return stream
+	}
+	stream := market.NewStream(testnet)
+	stream.Start()
+	m.streams[testnet] = stream
+	return stream
+}
+
+// GetMarketStreamStats returns statistics for the active market streams
+func (m *EngineManager) GetMarketStreamStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := make(map[string]interface{})
+	for testnet, stream := range m.streams {
+		network := "mainnet"
+		if testnet {
+			network = "testnet"
+		}
+		stats[network] = stream.Stats()
+	}
+	return stats
 }
 
 // IsRunning checks if a trader is running
@@ -177,6 +238,19 @@ func (m *EngineManager) GetPositions(traderID string) []map[string]interface{} {
 	return []map[string]interface{}{}
 }
 
+// ExecuteDecision submits an externally made decision (e.g. a debate consensus)
+// to a running trader so it goes through that trader's risk engine
+func (m *EngineManager) ExecuteDecision(ctx context.Context, traderID string, decision *ai.TradingDecision, source string) (*TradeLog, error) {
+	m.mu.RLock()
+	engine, exists := m.engines[traderID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("trader %s is not running", traderID)
+	}
+	return engine.ExecuteDecision(ctx, decision, source), nil
+}
+
 // GetRunningTraders returns list of running trader IDs
 func (m *EngineManager) GetRunningTraders() []string {
 	m.mu.RLock()
@@ -221,3 +295,93 @@ func (m *EngineManager) ReloadStrategyForTraders(strategyID string) error {
 
 	return nil
 }
+
+// aiFallbackChain builds the trader's AI backend chain: its primary provider
+// and model first, then each configured fallback. Returns nil when the trader
+// just uses its OpenRouter model, which the legacy client handles directly.
+func (m *EngineManager) aiFallbackChain(trader *store.Trader, apiKey string) *mcp.FallbackClient {
+	primary := trader.Config.AIProvider
+	if primary == "" {
+		primary = mcp.ProviderOpenRouter
+	}
+	if primary == mcp.ProviderOpenRouter && len(trader.Config.FallbackModels) == 0 {
+		return nil
+	}
+
+	settings, err := m.settingsStore.GetGlobalSettings()
+	if err != nil {
+		log.Printf("[%s] Failed to load settings for AI backends: %v", trader.Name, err)
+		settings = &store.GlobalSettings{}
+	}
+	credentials := map[string]struct{ apiKey, baseURL string }{
+		mcp.ProviderOpenRouter: {apiKey, settings.OpenRouterBaseURL},
+		mcp.ProviderOpenAI:     {settings.OpenAIAPIKey, settings.OpenAIBaseURL},
+		mcp.ProviderAnthropic:  {settings.AnthropicAPIKey, settings.AnthropicBaseURL},
+		mcp.ProviderDeepSeek:   {settings.DeepSeekAPIKey, settings.DeepSeekBaseURL},
+		mcp.ProviderOllama:     {"", settings.OllamaBaseURL},
+		mcp.ProviderLocal:      {settings.LocalAIAPIKey, settings.LocalAIBaseURL},
+	}
+
+	var backends []mcp.BackendConfig
+	add := func(provider, model string) {
+		cred, ok := credentials[provider]
+		if model == "" {
+			log.Printf("[%s] Skipping AI backend %s: no model set", trader.Name, provider)
+			return
+		}
+		if !ok || (cred.apiKey == "" && !mcp.IsLocalProvider(provider)) {
+			log.Printf("[%s] Skipping AI backend %s/%s: provider not configured", trader.Name, provider, model)
+			return
+		}
+		backends = append(backends, mcp.BackendConfig{
+			Provider: provider,
+			Model:    model,
+			APIKey:   cred.apiKey,
+			BaseURL:  cred.baseURL, // Provider default when empty
+		})
+	}
+
+	openRouterModel := traderModel(&trader.Config, mcp.ProviderOpenRouter, m.cfg.OpenRouterModel)
+	add(primary, traderModel(&trader.Config, primary, m.cfg.OpenRouterModel))
+	if len(backends) == 0 && primary != mcp.ProviderOpenRouter {
+		add(mcp.ProviderOpenRouter, openRouterModel) // Unusable primary: stay on OpenRouter
+	}
+	for _, fb := range trader.Config.FallbackModels {
+		provider := fb.Provider
+		if provider == "" {
+			provider = mcp.ProviderOpenRouter
+		}
+		add(provider, mcp.NativeModelID(provider, fb.Model))
+	}
+	if len(backends) == 0 || (len(backends) == 1 && backends[0].Provider == mcp.ProviderOpenRouter) {
+		return nil
+	}
+
+	names := make([]string, 0, len(backends))
+	for _, b := range backends {
+		names = append(names, b.Provider+"/"+b.Model)
+	}
+	log.Printf("[%s] AI backends: %v", trader.Name, names)
+	return mcp.NewFallbackClientFromConfigs(mcp.DefaultBreakerConfig(), backends...)
+}
+
+// traderModel resolves the model ID a trader sends to provider. OpenRouter
+// takes the trader's OpenRouter model, then the legacy AIModel, then the
+// global model. A vendor's own API takes AIModel first and the same IDs
+// without OpenRouter's vendor prefix. A local provider only serves the models
+// it hosts, so only AIModel applies; empty means it can't be used.
+func traderModel(cfg *store.TraderConfig, provider, global string) string {
+	if mcp.IsLocalProvider(provider) {
+		return cfg.AIModel
+	}
+	candidates := []string{cfg.OpenRouterModel, cfg.AIModel, global}
+	if provider != "" && provider != mcp.ProviderOpenRouter {
+		candidates = []string{cfg.AIModel, cfg.OpenRouterModel, global}
+	}
+	for _, model := range candidates {
+		if model != "" {
+			return mcp.NativeModelID(provider, model)
+		}
+	}
+	return ""
+}
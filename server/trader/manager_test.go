@@ -0,0 +1,36 @@
+package trader
+
+import (
+	"testing"
+
+	"auto-trader-ahh/mcp"
+	"auto-trader-ahh/store"
+)
+
+func TestTraderModel(t *testing.T) {
+	both := &store.TraderConfig{OpenRouterModel: "deepseek/deepseek-chat", AIModel: "llama3.1:8b"}
+	openRouterOnly := &store.TraderConfig{OpenRouterModel: "openai/gpt-4o"}
+	tests := []struct {
+		name     string
+		cfg      *store.TraderConfig
+		provider string
+		want     string
+	}{
+		{"openrouter prefers its own model", both, mcp.ProviderOpenRouter, "deepseek/deepseek-chat"},
+		{"ollama prefers the ai model", both, mcp.ProviderOllama, "llama3.1:8b"},
+		{"local prefers the ai model", both, mcp.ProviderLocal, "llama3.1:8b"},
+		{"local needs the ai model", openRouterOnly, mcp.ProviderLocal, ""},
+		{"ollama ignores the global model", &store.TraderConfig{}, mcp.ProviderOllama, ""},
+		{"openrouter falls back to the legacy field", &store.TraderConfig{AIModel: "openai/gpt-4o"}, mcp.ProviderOpenRouter, "openai/gpt-4o"},
+		{"openrouter falls back to the global model", &store.TraderConfig{}, mcp.ProviderOpenRouter, "global/model"},
+		{"vendor strips its prefix", openRouterOnly, mcp.ProviderOpenAI, "gpt-4o"},
+		{"vendor prefers the ai model", &store.TraderConfig{OpenRouterModel: "openai/gpt-4o", AIModel: "gpt-4o-mini"}, mcp.ProviderOpenAI, "gpt-4o-mini"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := traderModel(tt.cfg, tt.provider, "global/model"); got != tt.want {
+				t.Errorf("traderModel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package trader
 
 import (
+	"math"
 	"testing"
 
+	"auto-trader-ahh/debate"
 	"auto-trader-ahh/exchange"
 )
 
@@ -568,3 +570,133 @@ func TestPositionSyncMergeLogic(t *testing.T) {
 		t.Errorf("Merged positions count = %d, want 3", len(merged))
 	}
 }
+
+// TestResolvePositionForAction tests which position leg an AI action applies to
+func TestResolvePositionForAction(t *testing.T) {
+	long := &exchange.Position{Symbol: "BTCUSDT", PositionAmt: 0.1}
+	short := &exchange.Position{Symbol: "BTCUSDT", PositionAmt: -0.1}
+
+	tests := []struct {
+		name     string
+		action   string
+		longPos  *exchange.Position
+		shortPos *exchange.Position
+		hedging  bool
+		want     *exchange.Position
+		wantErr  bool
+	}{
+		{"one-way BUY sees existing short", "BUY", nil, short, false, short, false},
+		{"hedge BUY ignores short leg", "BUY", nil, short, true, nil, false},
+		{"hedge SELL sees short leg", "SELL", long, short, true, short, false},
+		{"one-way close_long on short closes it", "close_long", nil, short, false, short, false},
+		{"hedge close_long with no long", "close_long", nil, short, true, nil, false},
+		{"hedge close_short", "close_short", long, short, true, short, false},
+		{"CLOSE single leg", "CLOSE", long, nil, true, long, false},
+		{"CLOSE both legs is ambiguous", "CLOSE", long, short, true, nil, true},
+		{"hold", "HOLD", nil, nil, false, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolvePositionForAction(tt.action, tt.longPos, tt.shortPos, tt.hedging)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLimitEntryPrice tests limit price selection for limit/post-only entries
+func TestLimitEntryPrice(t *testing.T) {
+	book := &exchange.BookTicker{BidPrice: 100, AskPrice: 100.1}
+
+	tests := []struct {
+		name     string
+		side     string
+		aiPrice  float64
+		postOnly bool
+		want     float64
+	}{
+		{"BUY joins bid", "BUY", 0, false, 100},
+		{"SELL joins ask", "SELL", 0, false, 100.1},
+		{"BUY uses AI price", "BUY", 99.5, true, 99.5},
+		{"post-only BUY ignores crossing AI price", "BUY", 100.2, true, 100},
+		{"GTC BUY may cross with AI price", "BUY", 100.2, false, 100.2},
+		{"post-only SELL ignores crossing AI price", "SELL", 99.9, true, 100.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limitEntryPrice(tt.side, book, tt.aiPrice, tt.postOnly); got != tt.want {
+				t.Errorf("limitEntryPrice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPriceRanAway tests the cancel-on-runaway check for unfilled limit entries
+func TestPriceRanAway(t *testing.T) {
+	tests := []struct {
+		name    string
+		side    string
+		current float64
+		want    bool
+	}{
+		{"BUY within range", "BUY", 100.4, false},
+		{"BUY ran up", "BUY", 100.6, true},
+		{"BUY price dropped (will fill)", "BUY", 99, false},
+		{"SELL ran down", "SELL", 99.4, true},
+		{"SELL within range", "SELL", 99.6, false},
+		{"no price", "BUY", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := priceRanAway(tt.side, 100, tt.current, 0.5); got != tt.want {
+				t.Errorf("priceRanAway(%s, 100, %v, 0.5) = %v, want %v", tt.side, tt.current, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDebateToTradingDecision tests mapping debate consensus to engine decisions
+func TestDebateToTradingDecision(t *testing.T) {
+	tests := []struct {
+		name      string
+		decision  debate.Decision
+		price     float64
+		wantAct   string
+		wantSLPct float64
+		wantTPPct float64
+		wantErr   bool
+	}{
+		{"open_long with brackets", debate.Decision{Action: "open_long", StopLoss: 98, TakeProfit: 106}, 100, "BUY", 2, 6, false},
+		{"open_short with brackets", debate.Decision{Action: "open_short", StopLoss: 103, TakeProfit: 91}, 100, "SELL", 3, 9, false},
+		{"close_short keeps leg", debate.Decision{Action: "close_short"}, 100, "close_short", 0, 0, false},
+		{"wait holds", debate.Decision{Action: "wait"}, 100, "HOLD", 0, 0, false},
+		{"unknown price leaves pct unset", debate.Decision{Action: "open_long", StopLoss: 98, TakeProfit: 106}, 0, "BUY", 0, 0, false},
+		{"stop above a long's price", debate.Decision{Action: "open_long", StopLoss: 102, TakeProfit: 106}, 100, "", 0, 0, true},
+		{"target above a short's price", debate.Decision{Action: "open_short", StopLoss: 103, TakeProfit: 101}, 100, "", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := debateToTradingDecision(&tt.decision, tt.price)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Action != tt.wantAct {
+				t.Errorf("Action = %s, want %s", got.Action, tt.wantAct)
+			}
+			if math.Abs(got.StopLossPct-tt.wantSLPct) > 1e-9 || math.Abs(got.TakeProfitPct-tt.wantTPPct) > 1e-9 {
+				t.Errorf("SL/TP = %v/%v%%, want %v/%v%%", got.StopLossPct, got.TakeProfitPct, tt.wantSLPct, tt.wantTPPct)
+			}
+		})
+	}
+}
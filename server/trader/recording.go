@@ -0,0 +1,73 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"auto-trader-ahh/mcp"
+	"auto-trader-ahh/store"
+)
+
+// recordingCassette keeps a trader's AI recordings in SQLite
+type recordingCassette struct {
+	traderID string
+	store    *store.AIRecordingStore
+}
+
+func newRecordingCassette(traderID string) *recordingCassette {
+	return &recordingCassette{traderID: traderID, store: store.NewAIRecordingStore()}
+}
+
+// Save implements mcp.Cassette
+func (c *recordingCassette) Save(rec *mcp.Recording) error {
+	request, err := json.Marshal(rec.Request)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	var response []byte
+	if rec.Response != nil {
+		if response, err = json.Marshal(rec.Response); err != nil {
+			return fmt.Errorf("failed to encode response: %w", err)
+		}
+	}
+	return c.store.Save(&store.AIRecording{
+		CallerType: store.CallerTrader,
+		CallerID:   c.traderID,
+		PromptHash: rec.Key,
+		Provider:   rec.Provider,
+		Model:      rec.Model,
+		Request:    string(request),
+		Response:   string(response),
+		Error:      rec.Error,
+		CreatedAt:  rec.RecordedAt,
+	})
+}
+
+// Find implements mcp.Cassette
+func (c *recordingCassette) Find(key string) (*mcp.Recording, error) {
+	rec, err := c.store.FindByHash(c.traderID, key)
+	if err != nil || rec == nil {
+		return nil, err
+	}
+	return DecodeRecording(rec)
+}
+
+// DecodeRecording converts a stored recording back to an mcp recording
+func DecodeRecording(rec *store.AIRecording) (*mcp.Recording, error) {
+	out := &mcp.Recording{
+		Key:        rec.PromptHash,
+		Provider:   rec.Provider,
+		Model:      rec.Model,
+		Error:      rec.Error,
+		RecordedAt: rec.CreatedAt,
+	}
+	if err := json.Unmarshal([]byte(rec.Request), &out.Request); err != nil {
+		return nil, fmt.Errorf("recording %d: bad request: %w", rec.ID, err)
+	}
+	if rec.Response != "" {
+		if err := json.Unmarshal([]byte(rec.Response), &out.Response); err != nil {
+			return nil, fmt.Errorf("recording %d: bad response: %w", rec.ID, err)
+		}
+	}
+	return out, nil
+}
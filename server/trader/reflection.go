@@ -0,0 +1,321 @@
+package trader
+
+import (
+	"context"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"auto-trader-ahh/decision"
+	"auto-trader-ahh/reflection"
+	"auto-trader-ahh/store"
+)
+
+// untrackedTimeout is how long a closed position waits for its fills to sync
+// before it is journaled without a result
+const untrackedTimeout = time.Hour
+
+// journalKey keys the position journal by symbol and lower-case side
+func journalKey(symbol, side string) string {
+	return symbol + "/" + strings.ToLower(side)
+}
+
+// reflectionConfig returns the strategy's reflection settings with defaults applied
+func (e *Engine) reflectionConfig() store.ReflectionConfig {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.reflectionConfigLocked()
+}
+
+func (e *Engine) reflectionConfigLocked() store.ReflectionConfig {
+	var cfg store.ReflectionConfig
+	if e.strategy != nil {
+		cfg = e.strategy.Config.Reflection
+	}
+
+	if cfg.IntervalMinutes <= 0 {
+		cfg.IntervalMinutes = 60
+	}
+	if cfg.LookbackTrades <= 0 {
+		cfg.LookbackTrades = 100
+	}
+	if cfg.MinTrades <= 0 {
+		cfg.MinTrades = 3
+	}
+	if cfg.MaxLessons <= 0 {
+		cfg.MaxLessons = 5
+	}
+	return cfg
+}
+
+// journalPositions keeps the trader's position journal in step with the
+// exchange. Legs without a journal entry are opened with their entry setup;
+// journal entries whose leg is gone are closed from the synced fills, with
+// the reason the engine noted or, for exchange-side closes, the bracket
+// order that must have filled.
+func (e *Engine) journalPositions(ctx context.Context) {
+	open, err := e.positionStore.GetOpenPositions(e.id)
+	if err != nil {
+		log.Printf("[%s] Failed to load journaled positions: %v", e.name, err)
+		return
+	}
+	journaled := make(map[string]store.TraderPosition, len(open))
+	for _, pos := range open {
+		journaled[journalKey(pos.Symbol, pos.Side)] = pos
+	}
+
+	type leg struct {
+		symbol, side string
+		qty, price   float64
+		leverage     int
+		firstSeen    time.Time
+	}
+	live := make(map[string]leg)
+	e.mu.RLock()
+	for _, pos := range e.positions {
+		if pos.PositionAmt == 0 {
+			continue
+		}
+		side := positionSide(pos)
+		l := leg{
+			symbol:    pos.Symbol,
+			side:      strings.ToLower(side),
+			qty:       math.Abs(pos.PositionAmt),
+			price:     pos.EntryPrice,
+			leverage:  pos.Leverage,
+			firstSeen: time.Now(),
+		}
+		if ms, ok := e.positionFirstSeenTime[getPositionKey(pos.Symbol, side)]; ok {
+			l.firstSeen = time.UnixMilli(ms)
+		}
+		live[journalKey(pos.Symbol, side)] = l
+	}
+	e.mu.RUnlock()
+
+	for key, l := range live {
+		if _, ok := journaled[key]; ok {
+			continue
+		}
+		// A reason noted for a leg that closed before it was journaled
+		// doesn't apply to this one
+		e.mu.Lock()
+		delete(e.closeReasons, key)
+		e.mu.Unlock()
+
+		note, _ := e.takeEntryNote(key, l.firstSeen)
+
+		_, err := e.positionStore.Create(&store.TraderPosition{
+			TraderID:      e.id,
+			ExchangeID:    "binance",
+			ExchangeType:  "binance",
+			Symbol:        l.symbol,
+			Side:          l.side,
+			EntryQuantity: l.qty,
+			Quantity:      l.qty,
+			EntryPrice:    l.price,
+			EntryTime:     l.firstSeen,
+			Leverage:      l.leverage,
+			Source:        store.PositionSourceSync,
+			Setup:         e.entrySetup(ctx, l.symbol, l.side),
+			Confidence:    int(math.Round(note.confidence)),
+			AIModel:       note.model,
+		})
+		if err != nil {
+			log.Printf("[%s][%s] Failed to journal %s position: %v", e.name, l.symbol, l.side, err)
+		}
+	}
+
+	for key, pos := range journaled {
+		if _, ok := live[key]; ok {
+			continue
+		}
+		e.journalClose(pos)
+	}
+}
+
+// journalClose closes a journal entry from the fills that closed the leg
+func (e *Engine) journalClose(pos store.TraderPosition) {
+	key := journalKey(pos.Symbol, pos.Side)
+	closeSide := "SELL"
+	if pos.Side == "short" {
+		closeSide = "BUY"
+	}
+
+	// A hedge leg trades on its own position side, so everything after the
+	// entry on the closing side is the leg's. In one-way mode a reversal's
+	// opening fills share the side, so stop at the leg's quantity.
+	positionSide, legQty := "BOTH", math.Max(pos.EntryQuantity, pos.Quantity)
+	if e.binance.IsHedgeMode() {
+		positionSide, legQty = strings.ToUpper(pos.Side), 0
+	}
+
+	fills, err := e.tradeStore.GetClosingFills(e.id, pos.Symbol, closeSide, positionSide, pos.EntryTime, legQty)
+	if err != nil {
+		log.Printf("[%s][%s] Failed to load closing fills: %v", e.name, pos.Symbol, err)
+		return
+	}
+
+	e.mu.Lock()
+	reason, noted := e.closeReasons[key]
+	if len(fills) == 0 {
+		// The fills sync at the end of each cycle; give them time to arrive
+		since, waiting := e.untrackedSince[key]
+		if !waiting {
+			e.untrackedSince[key] = time.Now()
+		}
+		if !waiting || time.Since(since) < untrackedTimeout {
+			e.mu.Unlock()
+			return
+		}
+		reason, noted = store.CloseReasonUntracked, true
+	}
+	delete(e.closeReasons, key)
+	delete(e.untrackedSince, key)
+	e.mu.Unlock()
+
+	var qty, value, pnl, fee float64
+	for _, f := range fills {
+		qty += f.Quantity
+		value += f.Price * f.Quantity
+		pnl += f.RealizedPnL
+		fee += f.Commission
+	}
+	exitPrice := 0.0
+	if qty > 0 {
+		exitPrice = value / qty
+	}
+	if !noted {
+		reason = store.CloseReasonTakeProfit
+		if pnl < 0 {
+			reason = store.CloseReasonStopLoss
+		}
+	}
+
+	if err := e.positionStore.ClosePosition(pos.ID, exitPrice, fee, pnl, reason); err != nil {
+		log.Printf("[%s][%s] Failed to journal closed %s position: %v", e.name, pos.Symbol, pos.Side, err)
+		return
+	}
+	log.Printf("[%s][%s] Journaled closed %s (%s setup): PnL $%.2f, %s",
+		e.name, pos.Symbol, pos.Side, pos.Setup, pnl, reason)
+
+	// Credit the debate votes that traded this position with its net PnL
+	if e.debateEngine != nil {
+		e.debateEngine.ResolvePosition(e.id, pos.Symbol, pos.Side, exitPrice, pnl-fee)
+	}
+}
+
+// entrySetup classifies a new position's setup from the primary timeframe
+func (e *Engine) entrySetup(ctx context.Context, symbol, side string) string {
+	timeframe := "5m"
+	e.mu.RLock()
+	if e.strategy != nil && e.strategy.Config.Indicators.PrimaryTimeframe != "" {
+		timeframe = e.strategy.Config.Indicators.PrimaryTimeframe
+	}
+	e.mu.RUnlock()
+
+	md, err := e.dataProvider.GetMarketDataWithConfig(ctx, symbol, timeframe, 100)
+	if err != nil {
+		log.Printf("[%s][%s] Failed to get market data to classify the entry: %v", e.name, symbol, err)
+		return reflection.SetupUnknown
+	}
+	return reflection.ClassifySetup(side, md.EMA9, md.EMA21, md.RSI)
+}
+
+// reflectIfDue runs a reflection pass once the strategy's interval has passed
+func (e *Engine) reflectIfDue() {
+	e.mu.RLock()
+	cfg := e.reflectionConfigLocked()
+	enabled := e.strategy != nil && cfg.Enabled
+	due := time.Since(e.lastReflection) >= time.Duration(cfg.IntervalMinutes)*time.Minute
+	client := e.mcpClient
+	e.mu.RUnlock()
+	if !enabled || !due {
+		return
+	}
+
+	lessons, err := reflection.NewReflector(e.id, client).Run(cfg)
+	e.mu.Lock()
+	e.lastReflection = time.Now()
+	if err == nil {
+		e.lessons = lessons
+	}
+	e.mu.Unlock()
+	if err != nil {
+		log.Printf("[%s] Reflection failed: %v", e.name, err)
+	}
+}
+
+// lessonDigest returns the lessons to add to a prompt about symbols, or nil
+// when reflection is off
+func (e *Engine) lessonDigest(symbols []string) []decision.Lesson {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.lessonDigestLocked(symbols)
+}
+
+func (e *Engine) lessonDigestLocked(symbols []string) []decision.Lesson {
+	cfg := e.reflectionConfigLocked()
+	if e.strategy == nil || !cfg.Enabled || len(e.lessons) == 0 {
+		return nil
+	}
+	return reflection.Digest(e.lessons, symbols, cfg.MaxLessons)
+}
+
+// closedTradeContext summarizes the journal's closed positions for the
+// decision context
+func (e *Engine) closedTradeContext() (*decision.TradingStats, []decision.RecentOrder) {
+	stats, err := e.positionStore.GetFullStats(e.id)
+	if err != nil || stats.TotalTrades == 0 {
+		return nil, nil
+	}
+	tradingStats := &decision.TradingStats{
+		TotalTrades:    stats.TotalTrades,
+		WinRate:        stats.WinRate,
+		ProfitFactor:   stats.ProfitFactor,
+		SharpeRatio:    stats.SharpeRatio,
+		TotalPnL:       stats.TotalPnL,
+		AvgWin:         stats.AvgWin,
+		AvgLoss:        stats.AvgLoss,
+		MaxDrawdownPct: stats.MaxDrawdownPct,
+	}
+
+	closed, err := e.positionStore.GetClosedPositions(e.id, 5)
+	if err != nil {
+		return tradingStats, nil
+	}
+	var recent []decision.RecentOrder
+	for _, pos := range closed {
+		if pos.CloseReason == store.CloseReasonUntracked {
+			continue
+		}
+		pnlPct := 0.0
+		if pos.EntryPrice > 0 && pos.ExitPrice > 0 {
+			pnlPct = (pos.ExitPrice - pos.EntryPrice) / pos.EntryPrice * 100
+			if pos.Side == "short" {
+				pnlPct = -pnlPct
+			}
+		}
+		recent = append(recent, decision.RecentOrder{
+			Symbol:       pos.Symbol,
+			Side:         pos.Side,
+			EntryPrice:   pos.EntryPrice,
+			ExitPrice:    pos.ExitPrice,
+			RealizedPnL:  pos.RealizedPnL,
+			PnLPct:       pnlPct,
+			EntryTime:    pos.EntryTime.Format(time.RFC3339),
+			ExitTime:     pos.ExitTime.Format(time.RFC3339),
+			HoldDuration: pos.ExitTime.Sub(pos.EntryTime).Round(time.Minute).String(),
+		})
+	}
+	return tradingStats, recent
+}
+
+// lessonIDs lists the IDs of lessons added to a prompt
+func lessonIDs(lessons []decision.Lesson) []int64 {
+	ids := make([]int64, len(lessons))
+	for i, l := range lessons {
+		ids[i] = l.ID
+	}
+	return ids
+}
@@ -0,0 +1,138 @@
+package trader
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"auto-trader-ahh/ai"
+	"auto-trader-ahh/config"
+	"auto-trader-ahh/decision"
+	"auto-trader-ahh/mcp"
+	"auto-trader-ahh/store"
+)
+
+// Replay formats: the per-symbol JSON object the trading loop asks for, or
+// the decision engine's chain of thought plus decision array
+const (
+	ReplayFormatSingle = "single"
+	ReplayFormatEngine = "engine"
+)
+
+var reTotalEquity = regexp.MustCompile(`Total Equity: \$([0-9.]+)`)
+
+// ReplayResult is an offline re-run of one recorded AI decision
+type ReplayResult struct {
+	Format       string                 `json:"format"`
+	Decision     *ai.TradingDecision    `json:"decision,omitempty"`      // Single format
+	FullDecision *decision.FullDecision `json:"full_decision,omitempty"` // Engine format
+	ParsePath    string                 `json:"parse_path,omitempty"`    // schema, lenient or fallback
+	ParseError   string                 `json:"parse_error,omitempty"`
+	Rejections   []string               `json:"rejections,omitempty"` // Validator and risk checks the decision failed
+	WouldExecute bool                   `json:"would_execute"`
+}
+
+// ReplayDecision runs a recorded AI response back through the parser and
+// validator under a strategy, without calling the AI or the exchange.
+// Checks that need live state (open positions, multi-timeframe
+// confirmation, margin) are not replayed.
+func ReplayDecision(rec *mcp.Recording, strategy *store.Strategy, cfg *config.Config) (*ReplayResult, error) {
+	if rec.Response == nil {
+		if rec.Error != "" {
+			return nil, fmt.Errorf("recorded call failed: %s", rec.Error)
+		}
+		return nil, fmt.Errorf("recording has no response")
+	}
+	content := rec.Response.Content
+
+	trimmed := strings.TrimSpace(content)
+	_, structuredErr := decision.ParseStructured(content)
+	if structuredErr == nil || strings.Contains(content, "<decision>") || strings.HasPrefix(trimmed, "[") {
+		return replayEngineDecision(rec, content, strategy), nil
+	}
+	return replaySingleDecision(content, rec.Provider, strategy, cfg), nil
+}
+
+// replaySingleDecision applies the trading loop's checks to a per-symbol
+// decision recorded from provider
+func replaySingleDecision(content, provider string, strategy *store.Strategy, cfg *config.Config) *ReplayResult {
+	result := &ReplayResult{Format: ReplayFormatSingle}
+
+	d, err := ai.ParseTradingDecision(content, mcp.SupportsResponseSchema(provider))
+	if err != nil {
+		result.ParseError = err.Error()
+		return result
+	}
+	result.Decision = d
+	result.ParsePath = d.ParsePath
+
+	// The same checks the engine runs, with the recorded strategy
+	e := &Engine{strategy: strategy, cfg: cfg}
+
+	switch d.Action {
+	case "BUY", "SELL", "HOLD", "CLOSE", "close_long", "close_short":
+	default:
+		result.Rejections = append(result.Rejections, fmt.Sprintf("unknown action %q", d.Action))
+	}
+
+	if minConfidence := float64(e.getMinConfidence()); d.Confidence < minConfidence {
+		result.Rejections = append(result.Rejections,
+			fmt.Sprintf("confidence too low (%.0f%% < %.0f%%)", d.Confidence, minConfidence))
+	}
+
+	if d.Action == "BUY" || d.Action == "SELL" {
+		slPct, tpPct := e.getSLTPPercentages(d)
+		if err := e.validateRiskRewardRatioPct(slPct, tpPct); err != nil {
+			result.Rejections = append(result.Rejections, err.Error())
+		}
+	}
+
+	result.WouldExecute = len(result.Rejections) == 0 && d.Action != "HOLD"
+	return result
+}
+
+// replayEngineDecision parses and validates a decision engine response. The
+// account equity for position limits is read back from the recorded prompt.
+func replayEngineDecision(rec *mcp.Recording, content string, strategy *store.Strategy) *ReplayResult {
+	result := &ReplayResult{Format: ReplayFormatEngine}
+
+	var validationCfg *decision.ValidationConfig
+	if strategy != nil {
+		validationCfg = strategyValidationConfig(strategy, recordedEquity(rec, 10000))
+	}
+
+	full, err := decision.ParseFullDecisionResponse(content, validationCfg)
+	result.FullDecision = full
+	result.ParsePath = full.ParsePath
+	if err != nil {
+		if full == nil || len(full.Decisions) == 0 {
+			result.ParseError = err.Error()
+			return result
+		}
+		result.Rejections = append(result.Rejections, err.Error())
+	}
+
+	for _, d := range full.Decisions {
+		if decision.IsOpeningAction(d.Action) || decision.IsClosingAction(d.Action) {
+			result.WouldExecute = len(result.Rejections) == 0
+			break
+		}
+	}
+	return result
+}
+
+// recordedEquity reads the account equity from a recorded prompt
+func recordedEquity(rec *mcp.Recording, fallback float64) float64 {
+	if rec.Request == nil {
+		return fallback
+	}
+	for _, m := range rec.Request.Messages {
+		if match := reTotalEquity.FindStringSubmatch(m.Content); len(match) > 1 {
+			if equity, err := strconv.ParseFloat(match[1], 64); err == nil && equity > 0 {
+				return equity
+			}
+		}
+	}
+	return fallback
+}
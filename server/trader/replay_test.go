@@ -0,0 +1,90 @@
+package trader
+
+import (
+	"testing"
+
+	"auto-trader-ahh/mcp"
+	"auto-trader-ahh/store"
+)
+
+func TestReplayDecision(t *testing.T) {
+	strategy := &store.Strategy{Config: store.DefaultStrategyConfig()}
+	strategy.Config.RiskControl.MinConfidence = 70
+	strategy.Config.RiskControl.MinRiskRewardRatio = 2
+
+	tests := []struct {
+		name          string
+		response      string
+		wantFormat    string
+		wantParseErr  bool
+		wantRejected  bool
+		wantExecution bool
+	}{
+		{"valid short", "```json\n" + `{"action":"SELL","symbol":"BTCUSDT","confidence":80,"stop_loss_pct":2,"take_profit_pct":6}` + "\n```",
+			ReplayFormatSingle, false, false, true},
+		{"low confidence", `{"action":"BUY","symbol":"BTCUSDT","confidence":50,"stop_loss_pct":2,"take_profit_pct":6}`,
+			ReplayFormatSingle, false, true, false},
+		{"poor risk reward", `{"action":"BUY","symbol":"BTCUSDT","confidence":90,"stop_loss_pct":3,"take_profit_pct":3}`,
+			ReplayFormatSingle, false, true, false},
+		{"hold", `{"action":"HOLD","symbol":"BTCUSDT","confidence":90}`,
+			ReplayFormatSingle, false, false, false},
+		{"unparsable", `I think the market will go down`,
+			ReplayFormatSingle, true, false, false},
+		{"engine wait", `<reasoning>flat</reasoning><decision>[{"symbol":"ALL","action":"wait","reasoning":"no setup"}]</decision>`,
+			ReplayFormatEngine, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &mcp.Recording{
+				Request:  &mcp.Request{Messages: []mcp.Message{{Role: "user", Content: "Total Equity: $5000.00"}}},
+				Response: &mcp.Response{Content: tt.response},
+			}
+			got, err := ReplayDecision(rec, strategy, nil)
+			if err != nil {
+				t.Fatalf("ReplayDecision() error = %v", err)
+			}
+			if got.Format != tt.wantFormat {
+				t.Errorf("format = %s, want %s", got.Format, tt.wantFormat)
+			}
+			if (got.ParseError != "") != tt.wantParseErr {
+				t.Errorf("parse error = %q, want error %v", got.ParseError, tt.wantParseErr)
+			}
+			if (len(got.Rejections) > 0) != tt.wantRejected {
+				t.Errorf("rejections = %v, want rejected %v", got.Rejections, tt.wantRejected)
+			}
+			if got.WouldExecute != tt.wantExecution {
+				t.Errorf("would execute = %v, want %v", got.WouldExecute, tt.wantExecution)
+			}
+		})
+	}
+
+	if _, err := ReplayDecision(&mcp.Recording{Error: "timeout"}, strategy, nil); err == nil {
+		t.Error("expected an error replaying a failed call")
+	}
+}
+
+func TestReplayDecision_ParsePathFollowsProvider(t *testing.T) {
+	strategy := &store.Strategy{Config: store.DefaultStrategyConfig()}
+	response := `{"action":"BUY","symbol":"BTCUSDT","confidence":80,"stop_loss_pct":2,"take_profit_pct":6}`
+
+	tests := []struct {
+		provider string
+		want     string
+	}{
+		{mcp.ProviderOpenAI, "schema"},
+		{mcp.ProviderDeepSeek, "lenient"}, // Never sent the schema
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			rec := &mcp.Recording{Provider: tt.provider, Response: &mcp.Response{Content: response}}
+			got, err := ReplayDecision(rec, strategy, nil)
+			if err != nil {
+				t.Fatalf("ReplayDecision() error = %v", err)
+			}
+			if got.ParsePath != tt.want {
+				t.Errorf("parse path = %q, want %q", got.ParsePath, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package usage records AI calls to the store and enforces per-trader
+// monthly AI budgets.
+package usage
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"auto-trader-ahh/mcp"
+	"auto-trader-ahh/store"
+)
+
+// Meter turns AI calls into store records and gates them on trader budgets
+type Meter struct {
+	calls   *store.AIUsageStore
+	traders *store.TraderStore
+	now     func() time.Time
+}
+
+// NewMeter creates a meter over the default stores
+func NewMeter() *Meter {
+	return &Meter{
+		calls:   store.NewAIUsageStore(),
+		traders: store.NewTraderStore(),
+		now:     time.Now,
+	}
+}
+
+// Observer returns a callback that records calls for one caller. traderID
+// is the trader billed for them, empty if none.
+func (m *Meter) Observer(callerType, callerID, traderID string) mcp.CallObserver {
+	return func(rec mcp.CallRecord) {
+		call := &store.AICall{
+			CallerType:       callerType,
+			CallerID:         callerID,
+			TraderID:         traderID,
+			Provider:         rec.Provider,
+			Model:            rec.Model,
+			PromptTokens:     rec.Usage.PromptTokens,
+			CompletionTokens: rec.Usage.CompletionTokens,
+			LatencyMs:        rec.Latency.Milliseconds(),
+			Success:          rec.Err == nil,
+		}
+		if rec.Err != nil {
+			call.Error = rec.Err.Error()
+		}
+		if err := m.calls.Record(call); err != nil {
+			log.Printf("[Usage] Failed to record AI call for %s %s: %v", callerType, callerID, err)
+		}
+	}
+}
+
+// Gate returns a check that blocks AI calls once the trader's monthly budget
+// is spent. Calls without a trader are never blocked.
+func (m *Meter) Gate(traderID string) mcp.CallGate {
+	return func() error {
+		return m.CheckBudget(traderID)
+	}
+}
+
+// CheckBudget returns an error if the trader has a monthly AI budget and
+// this month's spend has reached it
+func (m *Meter) CheckBudget(traderID string) error {
+	status, err := m.Budget(traderID)
+	if err != nil {
+		log.Printf("[Usage] Budget check failed for trader %s: %v", traderID, err)
+		return nil // Don't stop trading over an accounting error
+	}
+	if status != nil && status.Paused {
+		return fmt.Errorf("monthly AI budget exceeded: spent $%.2f of $%.2f, AI calls paused until %s",
+			status.SpentUSD, status.BudgetUSD, status.ResetsAt.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// BudgetStatus is a trader's AI spend against its monthly budget
+type BudgetStatus struct {
+	TraderID  string    `json:"trader_id"`
+	BudgetUSD float64   `json:"budget_usd"` // 0 = unlimited
+	SpentUSD  float64   `json:"spent_usd"`  // This calendar month
+	Paused    bool      `json:"paused"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// Budget returns a trader's budget status, or nil for calls without a trader
+func (m *Meter) Budget(traderID string) (*BudgetStatus, error) {
+	if traderID == "" {
+		return nil, nil
+	}
+	trader, err := m.traders.Get(traderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trader: %w", err)
+	}
+
+	monthStart := store.MonthStart(m.now())
+	spent, err := m.calls.TraderSpend(traderID, monthStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spend: %w", err)
+	}
+
+	budget := trader.Config.MonthlyAIBudgetUSD
+	return &BudgetStatus{
+		TraderID:  traderID,
+		BudgetUSD: budget,
+		SpentUSD:  spent,
+		Paused:    budget > 0 && spent >= budget,
+		ResetsAt:  monthStart.AddDate(0, 1, 0),
+	}, nil
+}
+
+// Wrap meters a client for one caller, gated on the trader's budget
+func (m *Meter) Wrap(client mcp.AIClient, callerType, callerID, traderID string) mcp.AIClient {
+	if m == nil {
+		return client
+	}
+	var gate mcp.CallGate
+	if traderID != "" {
+		gate = m.Gate(traderID)
+	}
+	return mcp.NewMeteredClient(client, m.Observer(callerType, callerID, traderID), gate)
+}